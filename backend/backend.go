@@ -16,8 +16,13 @@
 package backend
 
 import (
+	"context"
 	"crypto/sha256"
+	"errors"
+	"fmt"
 	"hash"
+	"strings"
+	"time"
 
 	"github.com/consensys/gnark/constraint/solver"
 )
@@ -29,11 +34,12 @@ const (
 	UNKNOWN ID = iota
 	GROTH16
 	PLONK
+	PLONKFRI
 )
 
 // Implemented return the list of proof systems implemented in gnark
 func Implemented() []ID {
-	return []ID{GROTH16, PLONK}
+	return []ID{GROTH16, PLONK, PLONKFRI}
 }
 
 // String returns the string representation of a proof system
@@ -43,6 +49,8 @@ func (id ID) String() string {
 		return "groth16"
 	case PLONK:
 		return "plonk"
+	case PLONKFRI:
+		return "plonkFRI"
 	default:
 		return "unknown"
 	}
@@ -60,6 +68,16 @@ type ProverConfig struct {
 	ChallengeHash  hash.Hash
 	KZGFoldingHash hash.Hash
 	Accelerator    string
+	MemoryBudget   uint64
+	Context        context.Context
+	Progress       ProgressFunc
+
+	// MSMExecutor and FFTExecutor are the curve specific executors of the
+	// multi-scalar multiplications and of the FFTs of the Groth16 prover (see
+	// for example groth16/bn254.WithMSMExecutor). The prover computes them
+	// locally if they are nil.
+	MSMExecutor any
+	FFTExecutor any
 }
 
 // NewProverConfig returns a default ProverConfig with given prover options opts
@@ -70,6 +88,7 @@ func NewProverConfig(opts ...ProverOption) (ProverConfig, error) {
 		// separation tags for PLONK and Groth16
 		ChallengeHash:  sha256.New(),
 		KZGFoldingHash: sha256.New(),
+		Context:        context.Background(),
 	}
 	for _, option := range opts {
 		if err := option(&opt); err != nil {
@@ -120,6 +139,26 @@ func WithProverKZGFoldingHashFunction(hFunc hash.Hash) ProverOption {
 	}
 }
 
+// WithMemoryBudget bounds the memory used by the prover to approximately
+// budget bytes. The multi-scalar multiplications are split into chunks of
+// points so that the chunks and the vectors of the prover fit in the budget.
+// The prover returns an error if the budget is too small for the vectors of
+// the prover, which are proportional to the size of the circuit.
+//
+// The budget does not account for the constraint system, nor for the proving
+// key if it is held in memory. It is only supported by the Groth16 prover, and
+// is most useful with a proving key read from disk on demand (see
+// groth16.OpenRaw).
+func WithMemoryBudget(budget uint64) ProverOption {
+	return func(pc *ProverConfig) error {
+		if budget == 0 {
+			return errors.New("memory budget must be positive")
+		}
+		pc.MemoryBudget = budget
+		return nil
+	}
+}
+
 // WithIcicleAcceleration requests to use [ICICLE] GPU proving backend for the
 // prover. This option requires that the program is compiled with `icicle` build
 // tag and the ICICLE dependencies are properly installed. See [ICICLE] for
@@ -133,6 +172,119 @@ func WithIcicleAcceleration() ProverOption {
 	}
 }
 
+// WithContext sets the context of the prover. The prover aborts and returns
+// the error of the context as soon as possible after the context is done: the
+// solver checks it between levels of constraints, and the prover between
+// FFTs and chunks of multi-scalar multiplications.
+func WithContext(ctx context.Context) ProverOption {
+	return func(pc *ProverConfig) error {
+		if ctx == nil {
+			return errors.New("nil context")
+		}
+		pc.Context = ctx
+		return nil
+	}
+}
+
+// WithProgress sets a callback reporting the start and the end of the phases
+// of the prover. As the phases of the PLONK prover overlap, the callback may
+// be called concurrently.
+func WithProgress(progress ProgressFunc) ProverOption {
+	return func(pc *ProverConfig) error {
+		pc.Progress = progress
+		return nil
+	}
+}
+
+// SetupOption defines option for altering the behavior of the setup. See the
+// descriptions of functions returning instances of this type for implemented
+// options.
+type SetupOption func(*SetupConfig) error
+
+// SetupConfig is the configuration for the setup with the options applied.
+type SetupConfig struct {
+	Context  context.Context
+	Progress ProgressFunc
+}
+
+// NewSetupConfig returns a default [SetupConfig] with given setup options
+// applied.
+func NewSetupConfig(opts ...SetupOption) (SetupConfig, error) {
+	opt := SetupConfig{
+		Context: context.Background(),
+	}
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			return SetupConfig{}, err
+		}
+	}
+	return opt, nil
+}
+
+// WithSetupContext sets the context of the setup. The setup aborts and returns
+// the error of the context as soon as possible after the context is done.
+func WithSetupContext(ctx context.Context) SetupOption {
+	return func(sc *SetupConfig) error {
+		if ctx == nil {
+			return errors.New("nil context")
+		}
+		sc.Context = ctx
+		return nil
+	}
+}
+
+// WithSetupProgress sets a callback reporting the start and the end of the
+// phases of the setup.
+func WithSetupProgress(progress ProgressFunc) SetupOption {
+	return func(sc *SetupConfig) error {
+		sc.Progress = progress
+		return nil
+	}
+}
+
+// Phase is a phase of a prover or of a setup, reported to a [ProgressFunc].
+type Phase string
+
+const (
+	// PhaseSolve is the solving of the constraint system.
+	PhaseSolve Phase = "solve"
+	// PhasePreprocess is the computation of the polynomials of the constraint
+	// system during the setup.
+	PhasePreprocess Phase = "preprocess"
+	// PhaseCommit is the computation of the commitments: to the wires in the
+	// prover, to the polynomials of the constraint system in the setup.
+	PhaseCommit Phase = "commit"
+	// PhaseQuotient is the computation of the quotient polynomial.
+	PhaseQuotient Phase = "quotient"
+	// PhaseOpening is the computation of the openings of the polynomials.
+	PhaseOpening Phase = "opening"
+)
+
+// ProgressEvent reports the start or the end of a phase.
+type ProgressEvent struct {
+	Phase Phase
+	// Done is false at the start of the phase and true at its end.
+	Done bool
+	// Elapsed is the duration of the phase, set at its end.
+	Elapsed time.Duration
+}
+
+// ProgressFunc is a callback reporting the progress of a prover or of a setup.
+type ProgressFunc func(ProgressEvent)
+
+// Start reports the start of the phase and returns a function reporting its
+// end. It does nothing if f is nil.
+func (f ProgressFunc) Start(phase Phase) (end func()) {
+	if f == nil {
+		return func() {}
+	}
+	start := time.Now()
+	f(ProgressEvent{Phase: phase})
+	return func() {
+		f(ProgressEvent{Phase: phase, Done: true, Elapsed: time.Since(start)})
+	}
+}
+
 // VerifierOption defines option for altering the behavior of the verifier. See
 // the descriptions of functions returning instances of this type for
 // implemented options.
@@ -194,3 +346,45 @@ func WithVerifierKZGFoldingHashFunction(hFunc hash.Hash) VerifierOption {
 		return nil
 	}
 }
+
+// BatchVerificationError is returned by the batch verifiers when some proofs
+// of the batch are invalid. After a failed batch check, the batch verifiers
+// verify the proofs individually to identify the invalid ones.
+type BatchVerificationError struct {
+	// Failed are the indexes of the invalid proofs in the batch, in increasing
+	// order.
+	Failed []int
+	// Errs are the errors returned by the verification of the invalid proofs.
+	Errs []error
+}
+
+// NewBatchVerificationError returns a [BatchVerificationError] for the non-nil
+// errors, where errs[i] is the result of the verification of the i-th proof
+// of the batch. It returns nil if all errors are nil.
+func NewBatchVerificationError(errs []error) error {
+	var res BatchVerificationError
+	for i, err := range errs {
+		if err != nil {
+			res.Failed = append(res.Failed, i)
+			res.Errs = append(res.Errs, err)
+		}
+	}
+	if len(res.Failed) == 0 {
+		return nil
+	}
+	return &res
+}
+
+func (e *BatchVerificationError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("batch verification failed")
+	for i := range e.Failed {
+		fmt.Fprintf(&sb, "; proof %d: %v", e.Failed[i], e.Errs[i])
+	}
+	return sb.String()
+}
+
+// Unwrap returns the errors of the invalid proofs.
+func (e *BatchVerificationError) Unwrap() []error {
+	return e.Errs
+}
@@ -0,0 +1,203 @@
+// Package aggregate implements the aggregation of Groth16 proofs from
+// SnarkPack.
+//
+// N proofs for the same [groth16.VerifyingKey] are aggregated into a single
+// proof of size O(log N), which is verified in time O(log N) plus the
+// processing of the public witnesses. The aggregation uses a structured
+// reference string derived from two independent powers of tau, which is
+// independent of the circuit and can be reused for any Groth16 verifying key.
+//
+// Aggregation is supported for BN254 and BLS12-381. Proofs of circuits using
+// commitments (see frontend.Committer) are not supported.
+//
+// # See also
+//
+// https://eprint.iacr.org/2021/529.pdf
+package aggregate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	fr_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/backend/groth16/bls12-381"
+	aggregate_bls12381 "github.com/consensys/gnark/backend/groth16/bls12-381/aggregate"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	aggregate_bn254 "github.com/consensys/gnark/backend/groth16/bn254/aggregate"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+type aggregateObject interface {
+	io.WriterTo
+	io.ReaderFrom
+	CurveID() ecc.ID
+}
+
+// Proof represents an aggregated Groth16 proof generated by aggregate.Aggregate
+//
+// it's underlying implementation is curve specific (see backend/groth16/<curve>/aggregate)
+type Proof interface {
+	aggregateObject
+}
+
+// ProvingKey represents the structured reference string used for aggregating
+// proofs
+//
+// it's underlying implementation is curve specific (see backend/groth16/<curve>/aggregate)
+type ProvingKey interface {
+	aggregateObject
+}
+
+// VerifyingKey represents the part of the structured reference string used
+// for verifying aggregated proofs
+//
+// it's underlying implementation is curve specific (see backend/groth16/<curve>/aggregate)
+type VerifyingKey interface {
+	aggregateObject
+}
+
+var errUnsupportedCurve = errors.New("unsupported curve")
+
+// Setup returns the keys for aggregating up to size proofs on the given
+// curve.
+//
+// Setup samples the trapdoors of the structured reference string at random
+// and discards them. If the process or machine leaks this randomness, an
+// attacker could forge aggregated proofs. In a production environment, the
+// keys should be derived from the outputs of two independent powers of tau
+// ceremonies.
+func Setup(curveID ecc.ID, size uint64) (ProvingKey, VerifyingKey, error) {
+	switch curveID {
+	case ecc.BN254:
+		pk, vk, err := aggregate_bn254.Setup(size)
+		if err != nil {
+			return nil, nil, err
+		}
+		return pk, vk, nil
+	case ecc.BLS12_381:
+		pk, vk, err := aggregate_bls12381.Setup(size)
+		if err != nil {
+			return nil, nil, err
+		}
+		return pk, vk, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", errUnsupportedCurve, curveID)
+	}
+}
+
+// Aggregate aggregates the proofs for the given public witnesses, which must
+// all be verifiable with vk. It does not verify the proofs: if a proof is
+// invalid, the aggregated proof does not verify.
+func Aggregate(pk ProvingKey, vk groth16.VerifyingKey, proofs []groth16.Proof, publicWitnesses []witness.Witness) (Proof, error) {
+	switch _pk := pk.(type) {
+	case *aggregate_bn254.ProvingKey:
+		_proofs := make([]*groth16_bn254.Proof, len(proofs))
+		for i := range proofs {
+			_proofs[i] = proofs[i].(*groth16_bn254.Proof)
+		}
+		w, err := vectors[fr_bn254.Vector](publicWitnesses)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := aggregate_bn254.Aggregate(_pk, vk.(*groth16_bn254.VerifyingKey), _proofs, w)
+		if err != nil {
+			return nil, err
+		}
+		return proof, nil
+	case *aggregate_bls12381.ProvingKey:
+		_proofs := make([]*groth16_bls12381.Proof, len(proofs))
+		for i := range proofs {
+			_proofs[i] = proofs[i].(*groth16_bls12381.Proof)
+		}
+		w, err := vectors[fr_bls12381.Vector](publicWitnesses)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := aggregate_bls12381.Aggregate(_pk, vk.(*groth16_bls12381.VerifyingKey), _proofs, w)
+		if err != nil {
+			return nil, err
+		}
+		return proof, nil
+	default:
+		panic("unrecognized aggregation proving key type")
+	}
+}
+
+// Verify verifies the aggregated proof of the Groth16 proofs for the given
+// public witnesses, where key is the verifying key of the aggregation and vk
+// the verifying key of the proofs.
+func Verify(proof Proof, key VerifyingKey, vk groth16.VerifyingKey, publicWitnesses []witness.Witness) error {
+	switch _proof := proof.(type) {
+	case *aggregate_bn254.Proof:
+		w, err := vectors[fr_bn254.Vector](publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return aggregate_bn254.Verify(_proof, key.(*aggregate_bn254.VerifyingKey), vk.(*groth16_bn254.VerifyingKey), w)
+	case *aggregate_bls12381.Proof:
+		w, err := vectors[fr_bls12381.Vector](publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return aggregate_bls12381.Verify(_proof, key.(*aggregate_bls12381.VerifyingKey), vk.(*groth16_bls12381.VerifyingKey), w)
+	default:
+		panic("unrecognized aggregated proof type")
+	}
+}
+
+// NewProof instantiates a curve-typed aggregated Proof and returns an
+// interface object. This function exists for serialization purposes.
+func NewProof(curveID ecc.ID) Proof {
+	switch curveID {
+	case ecc.BN254:
+		return &aggregate_bn254.Proof{}
+	case ecc.BLS12_381:
+		return &aggregate_bls12381.Proof{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// NewProvingKey instantiates a curve-typed aggregation ProvingKey and returns
+// an interface object. This function exists for serialization purposes.
+func NewProvingKey(curveID ecc.ID) ProvingKey {
+	switch curveID {
+	case ecc.BN254:
+		return &aggregate_bn254.ProvingKey{}
+	case ecc.BLS12_381:
+		return &aggregate_bls12381.ProvingKey{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// NewVerifyingKey instantiates a curve-typed aggregation VerifyingKey and
+// returns an interface object. This function exists for serialization
+// purposes.
+func NewVerifyingKey(curveID ecc.ID) VerifyingKey {
+	switch curveID {
+	case ecc.BN254:
+		return &aggregate_bn254.VerifyingKey{}
+	case ecc.BLS12_381:
+		return &aggregate_bls12381.VerifyingKey{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// vectors returns the curve-typed vectors of the public witnesses.
+func vectors[V any](publicWitnesses []witness.Witness) ([]V, error) {
+	res := make([]V, len(publicWitnesses))
+	for i := range publicWitnesses {
+		w, ok := publicWitnesses[i].Vector().(V)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		res[i] = w
+	}
+	return res, nil
+}
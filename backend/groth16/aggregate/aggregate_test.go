@@ -0,0 +1,112 @@
+package aggregate_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/groth16/aggregate"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+type cubicCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+	Z frontend.Variable `gnark:",public"`
+}
+
+func (c *cubicCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(c.X, c.X, c.X)
+	api.AssertIsEqual(c.Y, api.Add(x3, c.X, 5))
+	api.AssertIsEqual(c.Z, api.Mul(c.X, 3))
+	return nil
+}
+
+func TestAggregate(t *testing.T) {
+	const maxProofs = 8
+	assert := test.NewAssert(t)
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		assert.Run(func(assert *test.Assert) {
+			ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &cubicCircuit{})
+			assert.NoError(err)
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			aggPk, aggVk, err := aggregate.Setup(curve, maxProofs)
+			assert.NoError(err)
+
+			var proofs []groth16.Proof
+			var publicWitnesses []witness.Witness
+			for x := 0; x < maxProofs; x++ {
+				w, err := frontend.NewWitness(&cubicCircuit{X: x, Y: x*x*x + x + 5, Z: 3 * x}, curve.ScalarField())
+				assert.NoError(err)
+				proof, err := groth16.Prove(ccs, pk, w)
+				assert.NoError(err)
+				pw, err := w.Public()
+				assert.NoError(err)
+				proofs = append(proofs, proof)
+				publicWitnesses = append(publicWitnesses, pw)
+			}
+
+			// the number of proofs is padded to a power of two
+			for _, n := range []int{1, 5, maxProofs} {
+				assert.Run(func(assert *test.Assert) {
+					proof, err := aggregate.Aggregate(aggPk, vk, proofs[:n], publicWitnesses[:n])
+					assert.NoError(err)
+					assert.NoError(aggregate.Verify(proof, aggVk, vk, publicWitnesses[:n]))
+
+					// serialization round trip
+					var buf bytes.Buffer
+					_, err = proof.WriteTo(&buf)
+					assert.NoError(err)
+					decoded := aggregate.NewProof(curve)
+					_, err = decoded.ReadFrom(&buf)
+					assert.NoError(err)
+					assert.NoError(aggregate.Verify(decoded, aggVk, vk, publicWitnesses[:n]))
+
+					// wrong public witnesses
+					wrong := append([]witness.Witness{}, publicWitnesses[:n]...)
+					wrong[n-1] = publicWitnesses[n%maxProofs]
+					assert.Error(aggregate.Verify(proof, aggVk, vk, wrong))
+					if n > 1 {
+						wrong = append([]witness.Witness{}, publicWitnesses[:n]...)
+						wrong[0], wrong[1] = wrong[1], wrong[0]
+						assert.Error(aggregate.Verify(proof, aggVk, vk, wrong))
+					}
+				}, fmt.Sprintf("n=%d", n))
+			}
+
+			// an invalid proof
+			invalid := append([]groth16.Proof{}, proofs...)
+			invalid[2] = proofs[3]
+			proof, err := aggregate.Aggregate(aggPk, vk, invalid, publicWitnesses)
+			assert.NoError(err)
+			assert.Error(aggregate.Verify(proof, aggVk, vk, publicWitnesses))
+
+			// too many proofs for the key
+			_, err = aggregate.Aggregate(aggPk, vk, append(proofs, proofs[0]), append(publicWitnesses, publicWitnesses[0]))
+			assert.Error(err)
+
+			// serialization of the keys
+			var buf bytes.Buffer
+			_, err = aggPk.WriteTo(&buf)
+			assert.NoError(err)
+			decodedPk := aggregate.NewProvingKey(curve)
+			_, err = decodedPk.ReadFrom(&buf)
+			assert.NoError(err)
+			buf.Reset()
+			_, err = aggVk.WriteTo(&buf)
+			assert.NoError(err)
+			decodedVk := aggregate.NewVerifyingKey(curve)
+			_, err = decodedVk.ReadFrom(&buf)
+			assert.NoError(err)
+			proof, err = aggregate.Aggregate(decodedPk, vk, proofs, publicWitnesses)
+			assert.NoError(err)
+			assert.NoError(aggregate.Verify(proof, decodedVk, vk, publicWitnesses))
+		}, curve.String())
+	}
+}
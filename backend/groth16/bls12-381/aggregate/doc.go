@@ -0,0 +1,2 @@
+// Package aggregate implements the SnarkPack aggregation of BLS12-381 Groth16 proofs.
+package aggregate
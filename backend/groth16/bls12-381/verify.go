@@ -19,7 +19,9 @@ package groth16
 import (
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"math/big"
 	"time"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -73,6 +75,38 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		close(chDone)
 	}()
 
+	kSumAff, folded, err := vk.foldPublicWitness(proof, publicWitness, opt.HashToFieldFn)
+	if err != nil {
+		return err
+	}
+	if err = vk.CommitmentKey.Verify(folded, proof.CommitmentPok); err != nil {
+		return err
+	}
+
+	// compute e(Σx.[Kvk(t)]1, -[γ]2)
+	right, err := curve.MillerLoop([]curve.G1Affine{kSumAff}, []curve.G2Affine{vk.G2.gammaNeg})
+	if err != nil {
+		return err
+	}
+
+	// wait for (eKrsδ, eArBs)
+	if err := <-chDone; err != nil {
+		return err
+	}
+
+	right = curve.FinalExponentiation(&right, &doubleML)
+	if !vk.e.Equal(&right) {
+		return errPairingCheckFailed
+	}
+
+	log.Debug().Dur("took", time.Since(start)).Msg("verifier done")
+	return nil
+}
+
+// foldPublicWitness returns Σx.[Kvk(t)]1, where x is the public witness
+// extended with the hashes of the commitments, and the folded commitment of
+// the proof checked against the proof of knowledge.
+func (vk *VerifyingKey) foldPublicWitness(proof *Proof, publicWitness fr.Vector, hashToField hash.Hash) (kSumAff, folded curve.G1Affine, err error) {
 	maxNbPublicCommitted := 0
 	for _, s := range vk.PublicAndCommitmentCommitted { // iterate over commitments
 		maxNbPublicCommitted = utils.Max(maxNbPublicCommitted, len(s))
@@ -86,12 +120,12 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 			copy(commitmentPrehashSerialized[offset:], publicWitness[vk.PublicAndCommitmentCommitted[i][j]-1].Marshal())
 			offset += fr.Bytes
 		}
-		opt.HashToFieldFn.Write(commitmentPrehashSerialized[:offset])
-		hashBts := opt.HashToFieldFn.Sum(nil)
-		opt.HashToFieldFn.Reset()
+		hashToField.Write(commitmentPrehashSerialized[:offset])
+		hashBts := hashToField.Sum(nil)
+		hashToField.Reset()
 		nbBuf := fr.Bytes
-		if opt.HashToFieldFn.Size() < fr.Bytes {
-			nbBuf = opt.HashToFieldFn.Size()
+		if hashToField.Size() < fr.Bytes {
+			nbBuf = hashToField.Size()
 		}
 		var res fr.Element
 		res.SetBytes(hashBts[:nbBuf])
@@ -99,18 +133,13 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		copy(commitmentsSerialized[i*fr.Bytes:], res.Marshal())
 	}
 
-	if folded, err := pedersen.FoldCommitments(proof.Commitments, commitmentsSerialized); err != nil {
-		return err
-	} else {
-		if err = vk.CommitmentKey.Verify(folded, proof.CommitmentPok); err != nil {
-			return err
-		}
+	if folded, err = pedersen.FoldCommitments(proof.Commitments, commitmentsSerialized); err != nil {
+		return
 	}
 
-	// compute e(Σx.[Kvk(t)]1, -[γ]2)
 	var kSum curve.G1Jac
-	if _, err := kSum.MultiExp(vk.G1.K[1:], publicWitness, ecc.MultiExpConfig{}); err != nil {
-		return err
+	if _, err = kSum.MultiExp(vk.G1.K[1:], publicWitness, ecc.MultiExpConfig{}); err != nil {
+		return
 	}
 	kSum.AddMixed(&vk.G1.K[0])
 
@@ -118,26 +147,139 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		kSum.AddMixed(&proof.Commitments[i])
 	}
 
-	var kSumAff curve.G1Affine
 	kSumAff.FromJacobian(&kSum)
+	return
+}
 
-	right, err := curve.MillerLoop([]curve.G1Affine{kSumAff}, []curve.G2Affine{vk.G2.gammaNeg})
+// BatchVerify verifies the proofs with the given VerifyingKey and public
+// witnesses, where publicWitnesses[i] is the public witness of proofs[i].
+//
+// The verification equations of the proofs, including the checks of the
+// commitments, are combined with random coefficients into a single
+// multi-pairing. If the batch check fails, the proofs are verified
+// individually and the returned [backend.BatchVerificationError] reports the
+// invalid ones. An empty batch is valid.
+func BatchVerify(proofs []*Proof, vk *VerifyingKey, publicWitnesses []fr.Vector, opts ...backend.VerifierOption) error {
+	if len(proofs) != len(publicWitnesses) {
+		return fmt.Errorf("got %d proofs and %d public witnesses", len(proofs), len(publicWitnesses))
+	}
+	if len(proofs) == 0 {
+		return nil
+	}
+	opt, err := backend.NewVerifierConfig(opts...)
 	if err != nil {
-		return err
+		return fmt.Errorf("new verifier config: %w", err)
 	}
+	if opt.HashToFieldFn == nil {
+		opt.HashToFieldFn = hash_to_field.New([]byte(constraint.CommitmentDst))
+	}
+	log := logger.Logger().With().Str("curve", vk.CurveID().String()).Str("backend", "groth16").Int("nbProofs", len(proofs)).Logger()
+	start := time.Now()
 
-	// wait for (eKrsδ, eArBs)
-	if err := <-chDone; err != nil {
-		return err
+	nbPublicVars := len(vk.G1.K) - len(vk.PublicAndCommitmentCommitted)
+
+	// the random coefficients ρᵢ of the Groth16 equations and σᵢ of the
+	// commitment checks.
+	rho := make([]fr.Element, len(proofs))
+	sigma := make([]fr.Element, len(proofs))
+	ar := make([]curve.G1Affine, len(proofs))
+	bs := make([]curve.G2Affine, len(proofs))
+	kSums := make([]curve.G1Affine, len(proofs))
+	krs := make([]curve.G1Affine, len(proofs))
+	folded := make([]curve.G1Affine, len(proofs))
+	poks := make([]curve.G1Affine, len(proofs))
+	errs := make([]error, len(proofs))
+	batchable := true
+	for i, proof := range proofs {
+		if len(publicWitnesses[i]) != nbPublicVars-1 {
+			errs[i] = fmt.Errorf("invalid witness size, got %d, expected %d (public - ONE_WIRE)", len(publicWitnesses[i]), nbPublicVars-1)
+		} else if !proof.isValid() {
+			errs[i] = errCorrectSubgroupCheckFailed
+		} else if !proof.CommitmentPok.IsInSubGroup() {
+			errs[i] = errCorrectSubgroupCheckFailed
+		} else {
+			kSums[i], folded[i], errs[i] = vk.foldPublicWitness(proof, publicWitnesses[i], opt.HashToFieldFn)
+		}
+		if errs[i] == nil && !folded[i].IsInSubGroup() {
+			errs[i] = errCorrectSubgroupCheckFailed
+		}
+		if errs[i] != nil {
+			batchable = false
+			continue
+		}
+		if _, err := rho[i].SetRandom(); err != nil {
+			return err
+		}
+		if _, err := sigma[i].SetRandom(); err != nil {
+			return err
+		}
+		var rhoBi big.Int
+		ar[i].ScalarMultiplication(&proof.Ar, rho[i].BigInt(&rhoBi))
+		bs[i] = proof.Bs
+		krs[i] = proof.Krs
+		poks[i] = proof.CommitmentPok
 	}
 
-	right = curve.FinalExponentiation(&right, &doubleML)
-	if !vk.e.Equal(&right) {
-		return errPairingCheckFailed
+	if batchable {
+		ok, err := batchCheck(vk, rho, sigma, ar, bs, kSums, krs, folded, poks)
+		if err != nil {
+			return err
+		}
+		if ok {
+			log.Debug().Dur("took", time.Since(start)).Msg("batch verifier done")
+			return nil
+		}
 	}
 
-	log.Debug().Dur("took", time.Since(start)).Msg("verifier done")
-	return nil
+	// identify the invalid proofs
+	for i := range proofs {
+		if errs[i] == nil {
+			errs[i] = Verify(proofs[i], vk, publicWitnesses[i], opts...)
+		}
+	}
+	log.Debug().Dur("took", time.Since(start)).Msg("batch verifier done")
+	return backend.NewBatchVerificationError(errs)
+}
+
+// batchCheck checks
+//
+//	∏ e(ρᵢ·Arᵢ, Bsᵢ) · e(Σρᵢ·kSumᵢ, -[γ]2) · e(Σρᵢ·Krsᵢ, -[δ]2) = e(α, β)^(Σρᵢ)
+//
+// together with the commitment checks e(foldedᵢ, G)·e(pokᵢ, GRootSigmaNeg) = 1
+// combined with the coefficients σᵢ.
+func batchCheck(vk *VerifyingKey, rho, sigma []fr.Element, ar []curve.G1Affine, bs []curve.G2Affine, kSums, krs, folded, poks []curve.G1Affine) (bool, error) {
+	var kSum, krsSum, foldedSum, pokSum curve.G1Affine
+	for _, m := range []struct {
+		res     *curve.G1Affine
+		points  []curve.G1Affine
+		scalars []fr.Element
+	}{
+		{&kSum, kSums, rho},
+		{&krsSum, krs, rho},
+		{&foldedSum, folded, sigma},
+		{&pokSum, poks, sigma},
+	} {
+		if _, err := m.res.MultiExp(m.points, m.scalars, ecc.MultiExpConfig{}); err != nil {
+			return false, err
+		}
+	}
+
+	p := append(ar, kSum, krsSum, foldedSum, pokSum)
+	q := append(bs, vk.G2.gammaNeg, vk.G2.deltaNeg, vk.CommitmentKey.G, vk.CommitmentKey.GRootSigmaNeg)
+	ml, err := curve.MillerLoop(p, q)
+	if err != nil {
+		return false, err
+	}
+	left := curve.FinalExponentiation(&ml)
+
+	var rhoSum fr.Element
+	for i := range rho {
+		rhoSum.Add(&rhoSum, &rho[i])
+	}
+	var rhoSumBi big.Int
+	var right curve.GT
+	right.Exp(vk.e, rhoSum.BigInt(&rhoSumBi))
+	return left.Equal(&right), nil
 }
 
 // ExportSolidity not implemented for BLS12-381
@@ -17,12 +17,15 @@
 package groth16
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	curve "github.com/consensys/gnark-crypto/ecc/bls24-317"
 	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr"
 	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr/fft"
 	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr/pedersen"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16/internal"
 	"github.com/consensys/gnark/constraint"
 	cs "github.com/consensys/gnark/constraint/bls24-317"
@@ -82,7 +85,7 @@ type VerifyingKey struct {
 }
 
 // Setup constructs the SRS
-func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
+func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey, opts ...backend.SetupOption) error {
 	/*
 		Setup
 		-----
@@ -93,6 +96,12 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 		- loop through the pure structural constraints, eValuate A(X), B(X), C(X) with simple formula, the gate number is len(gateOrdering)+len(InpureStructuralConstraints)+current iterator
 	*/
 
+	opt, err := backend.NewSetupConfig(opts...)
+	if err != nil {
+		return fmt.Errorf("new setup config: %w", err)
+	}
+	ctx := opt.Context
+
 	// get R1CS nb constraints, wires and public/private inputs
 	nbWires := r1cs.NbInternalVariables + r1cs.GetNbPublicVariables() + r1cs.GetNbSecretVariables()
 
@@ -117,6 +126,7 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	}
 
 	// Setup coeffs to compute pk.G1.A, pk.G1.B, pk.G1.K
+	endPreprocess := opt.Progress.Start(backend.PhasePreprocess)
 	A, B, C := setupABC(r1cs, domain, toxicWaste)
 
 	// To fill in the Proving and Verifying keys, we need to perform a lot of ecc scalar multiplication (with generator)
@@ -248,7 +258,13 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 		g1Scalars = append(g1Scalars, ckK[i]...)
 	}
 
-	g1PointsAff := curve.BatchScalarMultiplicationG1(&g1, g1Scalars)
+	endPreprocess()
+	defer opt.Progress.Start(backend.PhaseCommit)()
+
+	g1PointsAff, err := batchScalarMultiplicationG1(ctx, &g1, g1Scalars)
+	if err != nil {
+		return err
+	}
 
 	// sets pk: [α]₁, [β]₁, [δ]₁
 	pk.G1.Alpha = g1PointsAff[0]
@@ -305,7 +321,10 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	// compute our batch scalar multiplication with g2 elements
 	g2Scalars := append(B, toxicWaste.beta, toxicWaste.delta, toxicWaste.gamma)
 
-	g2PointsAff := curve.BatchScalarMultiplicationG2(&g2, g2Scalars)
+	g2PointsAff, err := batchScalarMultiplicationG2(ctx, &g2, g2Scalars)
+	if err != nil {
+		return err
+	}
 
 	pk.G2.B = g2PointsAff[:len(B)]
 
@@ -336,6 +355,38 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	return nil
 }
 
+// batchScalarMultiplicationG1 is curve.BatchScalarMultiplicationG1, computed
+// in chunks to return the error of ctx if it is done in between.
+func batchScalarMultiplicationG1(ctx context.Context, base *curve.G1Affine, scalars []fr.Element) ([]curve.G1Affine, error) {
+	res := make([]curve.G1Affine, 0, len(scalars))
+	for start := 0; start < len(scalars); start += setupChunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := min(start+setupChunkSize, len(scalars))
+		res = append(res, curve.BatchScalarMultiplicationG1(base, scalars[start:end])...)
+	}
+	return res, nil
+}
+
+// batchScalarMultiplicationG2 is curve.BatchScalarMultiplicationG2, computed
+// in chunks to return the error of ctx if it is done in between.
+func batchScalarMultiplicationG2(ctx context.Context, base *curve.G2Affine, scalars []fr.Element) ([]curve.G2Affine, error) {
+	res := make([]curve.G2Affine, 0, len(scalars))
+	for start := 0; start < len(scalars); start += setupChunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := min(start+setupChunkSize, len(scalars))
+		res = append(res, curve.BatchScalarMultiplicationG2(base, scalars[start:end])...)
+	}
+	return res, nil
+}
+
+// setupChunkSize is the number of scalar multiplications of the setup between
+// two checks of the context.
+const setupChunkSize = 1 << 20
+
 // Precompute sets e, -[δ]₂, -[γ]₂
 // This is meant to be called internally during setup or deserialization.
 func (vk *VerifyingKey) Precompute() error {
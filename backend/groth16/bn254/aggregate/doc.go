@@ -0,0 +1,2 @@
+// Package aggregate implements the SnarkPack aggregation of BN254 Groth16 proofs.
+package aggregate
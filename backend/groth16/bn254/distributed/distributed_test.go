@@ -0,0 +1,128 @@
+package distributed_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	groth16 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/groth16/bn254/distributed"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+type circuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *circuit) Define(api frontend.API) error {
+	cmt, err := api.(frontend.Committer).Commit(c.X)
+	if err != nil {
+		return err
+	}
+	x := c.X
+	for i := 0; i < 100; i++ {
+		x = api.Mul(x, c.X)
+	}
+	api.AssertIsEqual(x, c.Y)
+	api.AssertIsDifferent(cmt, c.Y)
+	return nil
+}
+
+func startWorkers(t *testing.T, n int) []string {
+	addresses := make([]string, n)
+	for i := range addresses {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { l.Close() })
+		go distributed.Serve(l)
+		addresses[i] = l.Addr().String()
+	}
+	return addresses
+}
+
+func TestProve(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit{})
+	assert.NoError(err)
+	var pk groth16.ProvingKey
+	var vk groth16.VerifyingKey
+	assert.NoError(groth16.Setup(ccs.(*cs.R1CS), &pk, &vk))
+
+	w, err := frontend.NewWitness(&circuit{X: 1, Y: 1}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+
+	executor, err := distributed.Dial("tcp", startWorkers(t, 3)...)
+	assert.NoError(err)
+	defer executor.Close()
+
+	opts := []backend.ProverOption{groth16.WithMSMExecutor(executor), groth16.WithFFTExecutor(executor)}
+	_, err = groth16.Prove(ccs.(*cs.R1CS), &pk, w, opts...)
+	assert.Error(err, "the key is not loaded")
+
+	assert.NoError(executor.LoadKey(context.Background(), &pk))
+	proof, err := groth16.Prove(ccs.(*cs.R1CS), &pk, w, opts...)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, &vk, publicWitness.Vector().(fr.Vector)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = groth16.Prove(ccs.(*cs.R1CS), &pk, w, append(opts, backend.WithContext(ctx))...)
+	assert.ErrorIs(err, context.Canceled)
+}
+
+func TestMultiExpShards(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit{})
+	assert.NoError(err)
+	var pk groth16.ProvingKey
+	var vk groth16.VerifyingKey
+	assert.NoError(groth16.Setup(ccs.(*cs.R1CS), &pk, &vk))
+
+	executor, err := distributed.Dial("tcp", startWorkers(t, 3)...)
+	assert.NoError(err)
+	defer executor.Close()
+	assert.NoError(executor.LoadKey(context.Background(), &pk))
+
+	// chunks of points within a shard and over several shards
+	n := min(len(pk.G1.A), len(pk.G2.B))
+	scalars := make([]fr.Element, n)
+	for i := range scalars {
+		scalars[i].SetRandom()
+	}
+	for _, chunk := range [][2]int{{0, n}, {0, 1}, {1, n/3 + 2}, {n / 3, n - 1}, {n - 1, n}} {
+		start, end := chunk[0], chunk[1]
+		key := groth16.KeyPoints{Vector: groth16.KeyG1A, Offset: start}
+		res, err := executor.MultiExpG1(context.Background(), key, pk.G1.A[start:end], scalars[start:end], ecc.MultiExpConfig{})
+		assert.NoError(err)
+		var expected curve.G1Jac
+		_, err = expected.MultiExp(pk.G1.A[start:end], scalars[start:end], ecc.MultiExpConfig{})
+		assert.NoError(err)
+		assert.True(res.Equal(&expected), "points [%d, %d)", start, end)
+
+		key.Vector = groth16.KeyG2B
+		res2, err := executor.MultiExpG2(context.Background(), key, pk.G2.B[start:end], scalars[start:end], ecc.MultiExpConfig{})
+		assert.NoError(err)
+		var expected2 curve.G2Jac
+		_, err = expected2.MultiExp(pk.G2.B[start:end], scalars[start:end], ecc.MultiExpConfig{})
+		assert.NoError(err)
+		assert.True(res2.Equal(&expected2), "points [%d, %d)", start, end)
+	}
+
+	// the points must be the points of the loaded key
+	_, err = executor.MultiExpG1(context.Background(), groth16.KeyPoints{Vector: groth16.KeyG1A, Offset: 1}, pk.G1.A[:2], scalars[:2], ecc.MultiExpConfig{})
+	assert.Error(err)
+	_, err = executor.MultiExpG1(context.Background(), groth16.KeyPoints{Vector: groth16.KeyG2B}, pk.G1.A[:2], scalars[:2], ecc.MultiExpConfig{})
+	assert.Error(err)
+}
@@ -0,0 +1,33 @@
+// Package distributed spreads the multi-scalar multiplications and the FFTs of
+// the BN254 Groth16 prover over worker processes, through net/rpc.
+//
+// A worker serves the connections of a listener:
+//
+//	l, err := net.Listen("tcp", ":9000")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Fatal(distributed.Serve(l))
+//
+// The prover dials the workers, loads the proving key in the workers and
+// passes the [Executor] to the prover:
+//
+//	executor, err := distributed.Dial("tcp", "host1:9000", "host2:9000")
+//	if err != nil {
+//		return err
+//	}
+//	defer executor.Close()
+//	if err := executor.LoadKey(ctx, pk); err != nil {
+//		return err
+//	}
+//	proof, err := groth16.Prove(r1cs, pk, fullWitness,
+//		groth16.WithMSMExecutor(executor), groth16.WithFFTExecutor(executor))
+//
+// Each vector of points of the proving key is split in as many shards as there
+// are workers, and each worker receives its shards once, when the key is
+// loaded. A multi-scalar multiplication then sends to each worker the scalars
+// of its shard, and the FFTs of a batch are sent to different workers. The
+// vectors of the FFTs are sent with each request, so that this reference
+// implementation is best used on a fast local network. The connections are
+// neither authenticated nor encrypted.
+package distributed
@@ -0,0 +1,280 @@
+package distributed
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net/rpc"
+	"sync/atomic"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	groth16 "github.com/consensys/gnark/backend/groth16/bn254"
+	"golang.org/x/sync/errgroup"
+)
+
+// Executor spreads the multi-scalar multiplications and the FFTs of the
+// prover over workers. It implements [groth16.MSMExecutor] and
+// [groth16.FFTExecutor].
+//
+// The points of the proving key are sharded over the workers once, with
+// [Executor.LoadKey]. A multi-scalar multiplication then sends to each worker
+// the scalars of its shard only.
+type Executor struct {
+	clients []*rpc.Client
+	next    atomic.Uint64 // worker of the next FFT
+	key     atomic.Pointer[loadedKey]
+}
+
+// loadedKey is a proving key whose points are loaded in the workers.
+type loadedKey struct {
+	id uint64
+	pk *groth16.ProvingKey
+}
+
+var (
+	_ groth16.MSMExecutor = (*Executor)(nil)
+	_ groth16.FFTExecutor = (*Executor)(nil)
+)
+
+// Dial connects to the workers at the given addresses.
+func Dial(network string, addresses ...string) (*Executor, error) {
+	if len(addresses) == 0 {
+		return nil, errors.New("no worker")
+	}
+	clients := make([]*rpc.Client, 0, len(addresses))
+	for _, address := range addresses {
+		client, err := rpc.Dial(network, address)
+		if err != nil {
+			for _, c := range clients {
+				c.Close()
+			}
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return NewExecutor(clients...), nil
+}
+
+// NewExecutor returns an Executor over connections to workers. It panics if
+// there are no clients.
+func NewExecutor(clients ...*rpc.Client) *Executor {
+	if len(clients) == 0 {
+		panic("no worker")
+	}
+	return &Executor{clients: clients}
+}
+
+// Close closes the connections to the workers.
+func (e *Executor) Close() error {
+	var errs []error
+	for _, c := range e.clients {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// LoadKey sends to each worker its shards of the points of the proving key,
+// which replace the shards of the key previously loaded. The executor can
+// then only be used to prove with pk, which must not be modified.
+func (e *Executor) LoadKey(ctx context.Context, pk *groth16.ProvingKey) error {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return err
+	}
+	key := &loadedKey{id: binary.LittleEndian.Uint64(buf[:]), pk: pk}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for worker := range e.clients {
+		worker := worker
+		g.Go(func() error {
+			for _, vector := range []groth16.KeyVector{groth16.KeyG1A, groth16.KeyG1B, groth16.KeyG1Z, groth16.KeyG1K, groth16.KeyG2B} {
+				args := LoadShardArgs{ID: ShardID{Key: key.id, Vector: vector}}
+				n := key.len(vector)
+				start, end := worker*n/len(e.clients), (worker+1)*n/len(e.clients)
+				if g1, ok := key.g1(vector); ok {
+					args.G1 = g1[start:end]
+				} else {
+					args.G2 = pk.G2.B[start:end]
+				}
+				if err := call(ctx, e.clients[worker], "LoadShard", &args, &struct{}{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	e.key.Store(key)
+	return nil
+}
+
+// g1 returns the points of the vector, if it is a vector of G1 points.
+func (k *loadedKey) g1(vector groth16.KeyVector) ([]curve.G1Affine, bool) {
+	switch vector {
+	case groth16.KeyG1A:
+		return k.pk.G1.A, true
+	case groth16.KeyG1B:
+		return k.pk.G1.B, true
+	case groth16.KeyG1Z:
+		return k.pk.G1.Z, true
+	case groth16.KeyG1K:
+		return k.pk.G1.K, true
+	}
+	return nil, false
+}
+
+// len returns the number of points of the vector.
+func (k *loadedKey) len(vector groth16.KeyVector) int {
+	if g1, ok := k.g1(vector); ok {
+		return len(g1)
+	}
+	return len(k.pk.G2.B)
+}
+
+// errKeyNotLoaded is returned by the multi-scalar multiplications of an
+// Executor without loaded key, or whose points are not of the loaded key.
+var errKeyNotLoaded = errors.New("the points are not the points of the proving key loaded with LoadKey")
+
+// MultiExpG1 implements [groth16.MSMExecutor]. Each worker computes the
+// multi-scalar multiplication of the points of its shard.
+func (e *Executor) MultiExpG1(ctx context.Context, key groth16.KeyPoints, points []curve.G1Affine, scalars []fr.Element, _ ecc.MultiExpConfig) (curve.G1Jac, error) {
+	var res curve.G1Jac
+	if len(points) != len(scalars) {
+		return res, errors.New("points and scalars have different lengths")
+	}
+	k := e.key.Load()
+	if k == nil {
+		return res, errKeyNotLoaded
+	}
+	vector, ok := k.g1(key.Vector)
+	if !ok || !isSlice(vector, key.Offset, points) {
+		return res, errKeyNotLoaded
+	}
+	shards := make([]curve.G1Jac, len(e.clients))
+	err := e.shard(ctx, len(vector), key.Offset, len(scalars), func(ctx context.Context, worker, shardStart, start, end int) error {
+		args := MultiExpArgs{ID: ShardID{Key: k.id, Vector: key.Vector}, Start: start - shardStart, Scalars: scalars[start-key.Offset : end-key.Offset]}
+		return call(ctx, e.clients[worker], "MultiExpG1", &args, &shards[worker])
+	})
+	if err != nil {
+		return res, err
+	}
+	for i := range shards {
+		res.AddAssign(&shards[i])
+	}
+	return res, nil
+}
+
+// MultiExpG2 implements [groth16.MSMExecutor]. Each worker computes the
+// multi-scalar multiplication of the points of its shard.
+func (e *Executor) MultiExpG2(ctx context.Context, key groth16.KeyPoints, points []curve.G2Affine, scalars []fr.Element, _ ecc.MultiExpConfig) (curve.G2Jac, error) {
+	var res curve.G2Jac
+	if len(points) != len(scalars) {
+		return res, errors.New("points and scalars have different lengths")
+	}
+	k := e.key.Load()
+	if k == nil {
+		return res, errKeyNotLoaded
+	}
+	if _, ok := k.g1(key.Vector); ok || !isSlice(k.pk.G2.B, key.Offset, points) {
+		return res, errKeyNotLoaded
+	}
+	shards := make([]curve.G2Jac, len(e.clients))
+	err := e.shard(ctx, len(k.pk.G2.B), key.Offset, len(scalars), func(ctx context.Context, worker, shardStart, start, end int) error {
+		args := MultiExpArgs{ID: ShardID{Key: k.id, Vector: key.Vector}, Start: start - shardStart, Scalars: scalars[start-key.Offset : end-key.Offset]}
+		return call(ctx, e.clients[worker], "MultiExpG2", &args, &shards[worker])
+	})
+	if err != nil {
+		return res, err
+	}
+	for i := range shards {
+		res.AddAssign(&shards[i])
+	}
+	return res, nil
+}
+
+// isSlice reports whether points are the points of vector at offset. Only the
+// first point is compared.
+func isSlice[T curve.G1Affine | curve.G2Affine](vector []T, offset int, points []T) bool {
+	if offset < 0 || offset+len(points) > len(vector) {
+		return false
+	}
+	return len(points) == 0 || points[0] == vector[offset]
+}
+
+// shard calls f concurrently for each worker whose shard of a vector of n
+// points intersects the points [offset, offset+m), with the start of the
+// shard and the intersection [start, end).
+func (e *Executor) shard(ctx context.Context, n, offset, m int, f func(ctx context.Context, worker, shardStart, start, end int) error) error {
+	g, ctx := errgroup.WithContext(ctx)
+	nbWorkers := len(e.clients)
+	for worker := 0; worker < nbWorkers; worker++ {
+		shardStart := worker * n / nbWorkers
+		start, end := max(shardStart, offset), min((worker+1)*n/nbWorkers, offset+m)
+		if start >= end {
+			continue
+		}
+		worker := worker
+		g.Go(func() error {
+			return f(ctx, worker, shardStart, start, end)
+		})
+	}
+	return g.Wait()
+}
+
+// FFT implements [groth16.FFTExecutor]. The vectors are sent to different
+// workers.
+func (e *Executor) FFT(ctx context.Context, domain *fft.Domain, a [][]fr.Element, decimation fft.Decimation, coset bool) error {
+	return e.fft(ctx, domain, a, decimation, coset, false)
+}
+
+// FFTInverse implements [groth16.FFTExecutor]. The vectors are sent to
+// different workers.
+func (e *Executor) FFTInverse(ctx context.Context, domain *fft.Domain, a [][]fr.Element, decimation fft.Decimation, coset bool) error {
+	return e.fft(ctx, domain, a, decimation, coset, true)
+}
+
+func (e *Executor) fft(ctx context.Context, domain *fft.Domain, a [][]fr.Element, decimation fft.Decimation, coset, inverse bool) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for i := range a {
+		i, client := i, e.clients[e.next.Add(1)%uint64(len(e.clients))]
+		g.Go(func() error {
+			args := FFTArgs{
+				Cardinality: domain.Cardinality,
+				Generator:   domain.Generator,
+				Shift:       domain.FrMultiplicativeGen,
+				Vector:      a[i],
+				Decimation:  decimation,
+				Coset:       coset,
+				Inverse:     inverse,
+			}
+			var reply []fr.Element
+			if err := call(ctx, client, "FFT", &args, &reply); err != nil {
+				return err
+			}
+			if len(reply) != len(a[i]) {
+				return errors.New("unexpected size of the FFT")
+			}
+			copy(a[i], reply)
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// call calls the method of the worker, and returns the error of ctx if ctx is
+// done before the reply.
+func call(ctx context.Context, client *rpc.Client, method string, args, reply any) error {
+	c := client.Go(serviceName+"."+method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.Done:
+		return c.Error
+	}
+}
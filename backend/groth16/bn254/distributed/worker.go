@@ -0,0 +1,171 @@
+package distributed
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	groth16 "github.com/consensys/gnark/backend/groth16/bn254"
+)
+
+// serviceName is the name of the net/rpc service of the workers.
+const serviceName = "Groth16BN254Worker"
+
+// ShardID identifies a shard of a vector of points of a proving key.
+type ShardID struct {
+	Key    uint64 // identifier of the proving key, chosen by the executor
+	Vector groth16.KeyVector
+}
+
+// LoadShardArgs are the arguments of [Worker.LoadShard]. Only one of G1 and G2
+// is set, depending on the vector of the shard.
+type LoadShardArgs struct {
+	ID ShardID
+	G1 []curve.G1Affine
+	G2 []curve.G2Affine
+}
+
+// MultiExpArgs are the arguments of [Worker.MultiExpG1] and
+// [Worker.MultiExpG2]: the scalars of the points of the shard starting at
+// Start.
+type MultiExpArgs struct {
+	ID      ShardID
+	Start   int
+	Scalars []fr.Element
+}
+
+// FFTArgs are the arguments of [Worker.FFT].
+type FFTArgs struct {
+	// Cardinality, Generator and Shift define the domain of the FFT.
+	Cardinality uint64
+	Generator   fr.Element
+	Shift       fr.Element
+
+	Vector     []fr.Element
+	Decimation fft.Decimation
+	Coset      bool
+	Inverse    bool
+}
+
+// Worker computes the multi-scalar multiplications and the FFTs sent by an
+// [Executor]. Its exported methods are the methods of the net/rpc service.
+//
+// A worker holds the shards of the points of one proving key at a time:
+// loading a shard of another key drops the shards of the previous one.
+type Worker struct {
+	lock    sync.Mutex
+	domains map[uint64]*fft.Domain // by cardinality
+	key     uint64                 // key of the shards
+	shards  map[groth16.KeyVector]*LoadShardArgs
+}
+
+// NewWorker returns a new Worker.
+func NewWorker() *Worker {
+	return &Worker{domains: make(map[uint64]*fft.Domain), shards: make(map[groth16.KeyVector]*LoadShardArgs)}
+}
+
+// Serve serves the connections accepted by l with a new [Worker], until l is
+// closed.
+func Serve(l net.Listener) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName(serviceName, NewWorker()); err != nil {
+		return err
+	}
+	server.Accept(l)
+	return nil
+}
+
+// LoadShard stores the points of the shard of args, used by the following
+// multi-scalar multiplications.
+func (w *Worker) LoadShard(args *LoadShardArgs, _ *struct{}) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if args.ID.Key != w.key {
+		w.key = args.ID.Key
+		w.shards = make(map[groth16.KeyVector]*LoadShardArgs)
+	}
+	w.shards[args.ID.Vector] = args
+	return nil
+}
+
+// shard returns the shard of args.
+func (w *Worker) shard(args *MultiExpArgs) (*LoadShardArgs, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	shard, ok := w.shards[args.ID.Vector]
+	if !ok || args.ID.Key != w.key {
+		return nil, fmt.Errorf("shard %v not loaded", args.ID)
+	}
+	return shard, nil
+}
+
+// MultiExpG1 sets reply to the multi-scalar multiplication of the scalars of
+// args and the points of the shard.
+func (w *Worker) MultiExpG1(args *MultiExpArgs, reply *curve.G1Jac) error {
+	shard, err := w.shard(args)
+	if err != nil {
+		return err
+	}
+	if args.Start < 0 || args.Start+len(args.Scalars) > len(shard.G1) {
+		return fmt.Errorf("points [%d, %d) out of the %d points of the shard", args.Start, args.Start+len(args.Scalars), len(shard.G1))
+	}
+	_, err = reply.MultiExp(shard.G1[args.Start:args.Start+len(args.Scalars)], args.Scalars, ecc.MultiExpConfig{})
+	return err
+}
+
+// MultiExpG2 sets reply to the multi-scalar multiplication of the scalars of
+// args and the points of the shard.
+func (w *Worker) MultiExpG2(args *MultiExpArgs, reply *curve.G2Jac) error {
+	shard, err := w.shard(args)
+	if err != nil {
+		return err
+	}
+	if args.Start < 0 || args.Start+len(args.Scalars) > len(shard.G2) {
+		return fmt.Errorf("points [%d, %d) out of the %d points of the shard", args.Start, args.Start+len(args.Scalars), len(shard.G2))
+	}
+	_, err = reply.MultiExp(shard.G2[args.Start:args.Start+len(args.Scalars)], args.Scalars, ecc.MultiExpConfig{})
+	return err
+}
+
+// FFT sets reply to the FFT, or the inverse FFT, of the vector of args.
+func (w *Worker) FFT(args *FFTArgs, reply *[]fr.Element) error {
+	if uint64(len(args.Vector)) != args.Cardinality {
+		return fmt.Errorf("vector of size %d on a domain of cardinality %d", len(args.Vector), args.Cardinality)
+	}
+	domain, err := w.domain(args)
+	if err != nil {
+		return err
+	}
+	var opts []fft.Option
+	if args.Coset {
+		opts = append(opts, fft.OnCoset())
+	}
+	if args.Inverse {
+		domain.FFTInverse(args.Vector, args.Decimation, opts...)
+	} else {
+		domain.FFT(args.Vector, args.Decimation, opts...)
+	}
+	*reply = args.Vector
+	return nil
+}
+
+// domain returns the domain of the FFT of args, computed on the first use.
+func (w *Worker) domain(args *FFTArgs) (*fft.Domain, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	domain, ok := w.domains[args.Cardinality]
+	if !ok || !domain.FrMultiplicativeGen.Equal(&args.Shift) {
+		domain = fft.NewDomain(args.Cardinality, fft.WithShift(args.Shift))
+		w.domains[args.Cardinality] = domain
+	}
+	if !domain.Generator.Equal(&args.Generator) {
+		return nil, errors.New("unexpected generator of the domain")
+	}
+	return domain, nil
+}
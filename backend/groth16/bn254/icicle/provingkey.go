@@ -3,6 +3,7 @@ package icicle_bn254
 import (
 	"unsafe"
 
+	"github.com/consensys/gnark/backend"
 	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
 	cs "github.com/consensys/gnark/constraint/bn254"
 )
@@ -27,8 +28,8 @@ type ProvingKey struct {
 	*deviceInfo
 }
 
-func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *groth16_bn254.VerifyingKey) error {
-	return groth16_bn254.Setup(r1cs, &pk.ProvingKey, vk)
+func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *groth16_bn254.VerifyingKey, opts ...backend.SetupOption) error {
+	return groth16_bn254.Setup(r1cs, &pk.ProvingKey, vk, opts...)
 }
 
 func DummySetup(r1cs *cs.R1CS, pk *ProvingKey) error {
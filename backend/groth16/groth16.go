@@ -20,6 +20,8 @@
 package groth16
 
 import (
+	"errors"
+	"fmt"
 	"io"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -86,6 +88,15 @@ type ProvingKey interface {
 	IsDifferent(interface{}) bool
 }
 
+// RawProvingKey represents a Groth16 ProvingKey written by WriteRawTo and
+// opened with OpenRaw, whose slices of points stay on disk.
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type RawProvingKey interface {
+	io.Closer
+	CurveID() ecc.ID
+}
+
 // VerifyingKey represents a Groth16 VerifyingKey
 //
 // it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
@@ -162,6 +173,68 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness, opts ..
 	}
 }
 
+// BatchVerify verifies the proofs with the given VerifyingKey and public
+// witnesses, where publicWitnesses[i] is the public witness of proofs[i]. The
+// pairing equations of the proofs are combined with random coefficients into
+// a single multi-pairing.
+//
+// If some proofs are invalid, the returned error is a
+// [backend.BatchVerificationError] reporting the index of the invalid proofs.
+func BatchVerify(proofs []Proof, vk VerifyingKey, publicWitnesses []witness.Witness, opts ...backend.VerifierOption) error {
+	switch _vk := vk.(type) {
+	case *groth16_bls12377.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*groth16_bls12377.Proof, w []fr_bls12377.Vector) error {
+			return groth16_bls12377.BatchVerify(p, _vk, w, opts...)
+		})
+	case *groth16_bls12381.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*groth16_bls12381.Proof, w []fr_bls12381.Vector) error {
+			return groth16_bls12381.BatchVerify(p, _vk, w, opts...)
+		})
+	case *groth16_bn254.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*groth16_bn254.Proof, w []fr_bn254.Vector) error {
+			return groth16_bn254.BatchVerify(p, _vk, w, opts...)
+		})
+	case *groth16_bw6761.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*groth16_bw6761.Proof, w []fr_bw6761.Vector) error {
+			return groth16_bw6761.BatchVerify(p, _vk, w, opts...)
+		})
+	case *groth16_bls24317.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*groth16_bls24317.Proof, w []fr_bls24317.Vector) error {
+			return groth16_bls24317.BatchVerify(p, _vk, w, opts...)
+		})
+	case *groth16_bls24315.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*groth16_bls24315.Proof, w []fr_bls24315.Vector) error {
+			return groth16_bls24315.BatchVerify(p, _vk, w, opts...)
+		})
+	case *groth16_bw6633.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*groth16_bw6633.Proof, w []fr_bw6633.Vector) error {
+			return groth16_bw6633.BatchVerify(p, _vk, w, opts...)
+		})
+	default:
+		panic("unrecognized verifying key type")
+	}
+}
+
+// batchVerify converts the proofs and public witnesses to the curve-typed
+// ones expected by verify.
+func batchVerify[P any, V any](proofs []Proof, publicWitnesses []witness.Witness, verify func([]P, []V) error) error {
+	if len(proofs) != len(publicWitnesses) {
+		return fmt.Errorf("got %d proofs and %d public witnesses", len(proofs), len(publicWitnesses))
+	}
+	_proofs := make([]P, len(proofs))
+	w := make([]V, len(publicWitnesses))
+	for i := range proofs {
+		var ok bool
+		if _proofs[i], ok = proofs[i].(P); !ok {
+			return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+		}
+		if w[i], ok = publicWitnesses[i].Vector().(V); !ok {
+			return fmt.Errorf("public witness %d: %w", i, witness.ErrInvalidWitness)
+		}
+	}
+	return verify(_proofs, w)
+}
+
 // Prove runs the groth16.Prove algorithm.
 //
 // if the force flag is set:
@@ -200,6 +273,182 @@ func Prove(r1cs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.
 	}
 }
 
+// OpenRaw opens the ProvingKey written with WriteRawTo at path for proving with
+// ProveRaw. The slices of points of the key are not read in memory: the
+// prover reads them as the multi-scalar multiplications progress. The key must
+// be closed once it is no longer used.
+func OpenRaw(curveID ecc.ID, path string) (RawProvingKey, error) {
+	switch curveID {
+	case ecc.BLS12_377:
+		pk, err := groth16_bls12377.OpenRaw(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BLS12_381:
+		pk, err := groth16_bls12381.OpenRaw(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BN254:
+		pk, err := groth16_bn254.OpenRaw(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BW6_761:
+		pk, err := groth16_bw6761.OpenRaw(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BLS24_317:
+		pk, err := groth16_bls24317.OpenRaw(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BLS24_315:
+		pk, err := groth16_bls24315.OpenRaw(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case ecc.BW6_633:
+		pk, err := groth16_bw6633.OpenRaw(path)
+		if err != nil {
+			return nil, err
+		}
+		return pk, nil
+	default:
+		panic("not implemented")
+	}
+}
+
+// ProveRaw runs the groth16.Prove algorithm with a proving key opened with
+// OpenRaw. Combined with backend.WithMemoryBudget, the memory used by the
+// prover is bounded and the proving key does not need to fit in memory.
+func ProveRaw(r1cs constraint.ConstraintSystem, pk RawProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (Proof, error) {
+	switch _r1cs := r1cs.(type) {
+	case *cs_bls12377.R1CS:
+		return groth16_bls12377.ProveRaw(_r1cs, pk.(*groth16_bls12377.RawProvingKey), fullWitness, opts...)
+
+	case *cs_bls12381.R1CS:
+		return groth16_bls12381.ProveRaw(_r1cs, pk.(*groth16_bls12381.RawProvingKey), fullWitness, opts...)
+
+	case *cs_bn254.R1CS:
+		return groth16_bn254.ProveRaw(_r1cs, pk.(*groth16_bn254.RawProvingKey), fullWitness, opts...)
+
+	case *cs_bw6761.R1CS:
+		return groth16_bw6761.ProveRaw(_r1cs, pk.(*groth16_bw6761.RawProvingKey), fullWitness, opts...)
+
+	case *cs_bls24317.R1CS:
+		return groth16_bls24317.ProveRaw(_r1cs, pk.(*groth16_bls24317.RawProvingKey), fullWitness, opts...)
+
+	case *cs_bls24315.R1CS:
+		return groth16_bls24315.ProveRaw(_r1cs, pk.(*groth16_bls24315.RawProvingKey), fullWitness, opts...)
+
+	case *cs_bw6633.R1CS:
+		return groth16_bw6633.ProveRaw(_r1cs, pk.(*groth16_bw6633.RawProvingKey), fullWitness, opts...)
+
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// PreparedProver represents a constraint system and a Groth16 ProvingKey prepared
+// with Prepare to generate many proofs.
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type PreparedProver interface {
+	io.WriterTo
+	io.ReaderFrom
+	gnarkio.UnsafeReaderFrom
+	CurveID() ecc.ID
+}
+
+// Prepare computes once the data of the prover which only depends on the
+// constraint system and the proving key, to generate many proofs with
+// ProvePrepared. The proofs may be generated concurrently. The prepared prover
+// holds the constraint system and the key, and can be written to disk and read
+// back in a PreparedProver returned by NewPreparedProver.
+func Prepare(ccs constraint.ConstraintSystem, pk ProvingKey) (PreparedProver, error) {
+	switch tccs := ccs.(type) {
+	case *cs_bn254.R1CS:
+		if icicle_bn254.HasIcicle {
+			return nil, errors.New("prepared provers do not support the ICICLE acceleration")
+		}
+		return prepared(groth16_bn254.Prepare(tccs, pk.(*groth16_bn254.ProvingKey)))
+	case *cs_bls12377.R1CS:
+		return prepared(groth16_bls12377.Prepare(tccs, pk.(*groth16_bls12377.ProvingKey)))
+	case *cs_bls12381.R1CS:
+		return prepared(groth16_bls12381.Prepare(tccs, pk.(*groth16_bls12381.ProvingKey)))
+	case *cs_bw6761.R1CS:
+		return prepared(groth16_bw6761.Prepare(tccs, pk.(*groth16_bw6761.ProvingKey)))
+	case *cs_bls24317.R1CS:
+		return prepared(groth16_bls24317.Prepare(tccs, pk.(*groth16_bls24317.ProvingKey)))
+	case *cs_bls24315.R1CS:
+		return prepared(groth16_bls24315.Prepare(tccs, pk.(*groth16_bls24315.ProvingKey)))
+	case *cs_bw6633.R1CS:
+		return prepared(groth16_bw6633.Prepare(tccs, pk.(*groth16_bw6633.ProvingKey)))
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+func prepared[P PreparedProver](p P, err error) (PreparedProver, error) {
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ProvePrepared generates a proof with a prepared prover. See Prove.
+func ProvePrepared(p PreparedProver, fullWitness witness.Witness, opts ...backend.ProverOption) (Proof, error) {
+	switch tp := p.(type) {
+	case *groth16_bn254.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	case *groth16_bls12377.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	case *groth16_bls12381.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	case *groth16_bw6761.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	case *groth16_bls24317.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	case *groth16_bls24315.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	case *groth16_bw6633.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	default:
+		panic("unrecognized prepared prover curve type")
+	}
+}
+
+// NewPreparedProver instantiates a curve-typed PreparedProver and returns an
+// interface. This function exists for serialization purposes.
+func NewPreparedProver(curveID ecc.ID) PreparedProver {
+	switch curveID {
+	case ecc.BN254:
+		return &groth16_bn254.PreparedProver{}
+	case ecc.BLS12_377:
+		return &groth16_bls12377.PreparedProver{}
+	case ecc.BLS12_381:
+		return &groth16_bls12381.PreparedProver{}
+	case ecc.BW6_761:
+		return &groth16_bw6761.PreparedProver{}
+	case ecc.BLS24_317:
+		return &groth16_bls24317.PreparedProver{}
+	case ecc.BLS24_315:
+		return &groth16_bls24315.PreparedProver{}
+	case ecc.BW6_633:
+		return &groth16_bw6633.PreparedProver{}
+	default:
+		panic("not implemented")
+	}
+}
+
 // Setup runs groth16.Setup with provided R1CS and outputs a key pair associated with the circuit.
 //
 // Note that careful consideration must be given to this step in a production environment.
@@ -208,20 +457,23 @@ func Prove(r1cs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.
 //
 // Two main solutions to this deployment issues are: running the Setup through a MPC (multi party computation)
 // or using a ZKP backend like PLONK where the per-circuit Setup is deterministic.
-func Setup(r1cs constraint.ConstraintSystem) (ProvingKey, VerifyingKey, error) {
+//
+// The context and the progress callback of the setup can be set with
+// [backend.WithSetupContext] and [backend.WithSetupProgress].
+func Setup(r1cs constraint.ConstraintSystem, opts ...backend.SetupOption) (ProvingKey, VerifyingKey, error) {
 
 	switch _r1cs := r1cs.(type) {
 	case *cs_bls12377.R1CS:
 		var pk groth16_bls12377.ProvingKey
 		var vk groth16_bls12377.VerifyingKey
-		if err := groth16_bls12377.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bls12377.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *cs_bls12381.R1CS:
 		var pk groth16_bls12381.ProvingKey
 		var vk groth16_bls12381.VerifyingKey
-		if err := groth16_bls12381.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bls12381.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
@@ -229,41 +481,41 @@ func Setup(r1cs constraint.ConstraintSystem) (ProvingKey, VerifyingKey, error) {
 		var vk groth16_bn254.VerifyingKey
 		if icicle_bn254.HasIcicle {
 			var pk icicle_bn254.ProvingKey
-			if err := icicle_bn254.Setup(_r1cs, &pk, &vk); err != nil {
+			if err := icicle_bn254.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 				return nil, nil, err
 			}
 			return &pk, &vk, nil
 		}
 		var pk groth16_bn254.ProvingKey
-		if err := groth16_bn254.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bn254.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *cs_bw6761.R1CS:
 		var pk groth16_bw6761.ProvingKey
 		var vk groth16_bw6761.VerifyingKey
-		if err := groth16_bw6761.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bw6761.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *cs_bls24317.R1CS:
 		var pk groth16_bls24317.ProvingKey
 		var vk groth16_bls24317.VerifyingKey
-		if err := groth16_bls24317.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bls24317.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *cs_bls24315.R1CS:
 		var pk groth16_bls24315.ProvingKey
 		var vk groth16_bls24315.VerifyingKey
-		if err := groth16_bls24315.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bls24315.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *cs_bw6633.R1CS:
 		var pk groth16_bw6633.ProvingKey
 		var vk groth16_bw6633.VerifyingKey
-		if err := groth16_bw6633.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bw6633.Setup(_r1cs, &pk, &vk, opts...); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
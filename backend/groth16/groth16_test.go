@@ -1,14 +1,25 @@
 package groth16_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/consensys/gnark"
 	"github.com/consensys/gnark-crypto/ecc"
+	curve_bn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
@@ -51,6 +62,225 @@ func TestCustomHashToField(t *testing.T) {
 	}
 }
 
+func TestBatchVerify(t *testing.T) {
+	const nbProofs = 4
+	assert := test.NewAssert(t)
+	for _, curve := range getCurves() {
+		assert.Run(func(assert *test.Assert) {
+			ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &batchCircuit{})
+			assert.NoError(err)
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+
+			proofs := make([]groth16.Proof, nbProofs)
+			publicWitnesses := make([]witness.Witness, nbProofs)
+			for i := range proofs {
+				w, err := frontend.NewWitness(&batchCircuit{X: i + 2, Y: (i + 2) * (i + 2)}, curve.ScalarField())
+				assert.NoError(err)
+				proofs[i], err = groth16.Prove(ccs, pk, w)
+				assert.NoError(err)
+				publicWitnesses[i], err = w.Public()
+				assert.NoError(err)
+			}
+			assert.NoError(groth16.BatchVerify(proofs, vk, publicWitnesses))
+
+			// swapping two public witnesses invalidates both proofs
+			wrong := append([]witness.Witness{}, publicWitnesses...)
+			wrong[1], wrong[3] = wrong[3], wrong[1]
+			err = groth16.BatchVerify(proofs, vk, wrong)
+			var batchErr *backend.BatchVerificationError
+			assert.True(errors.As(err, &batchErr))
+			assert.Equal([]int{1, 3}, batchErr.Failed)
+
+			// an empty batch is valid
+			assert.NoError(groth16.BatchVerify(nil, vk, nil))
+		}, curve.String())
+	}
+}
+
+// countingExecutor counts the multi-scalar multiplications of the prover.
+type countingExecutor struct {
+	groth16_bn254.LocalExecutor
+	nbG1, nbG2 atomic.Int64
+}
+
+func (e *countingExecutor) MultiExpG1(ctx context.Context, key groth16_bn254.KeyPoints, points []curve_bn254.G1Affine, scalars []fr_bn254.Element, config ecc.MultiExpConfig) (curve_bn254.G1Jac, error) {
+	e.nbG1.Add(1)
+	return e.LocalExecutor.MultiExpG1(ctx, key, points, scalars, config)
+}
+
+func (e *countingExecutor) MultiExpG2(ctx context.Context, key groth16_bn254.KeyPoints, points []curve_bn254.G2Affine, scalars []fr_bn254.Element, config ecc.MultiExpConfig) (curve_bn254.G2Jac, error) {
+	e.nbG2.Add(1)
+	return e.LocalExecutor.MultiExpG2(ctx, key, points, scalars, config)
+}
+
+func TestProveRaw(t *testing.T) {
+	const nbConstraints = 3000
+	const curve = ecc.BN254
+	assert := test.NewAssert(t)
+
+	ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &refCircuit{nbConstraints: nbConstraints})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	y := new(big.Int).Exp(big.NewInt(2), new(big.Int).Lsh(big.NewInt(1), nbConstraints), curve.ScalarField())
+	fullWitness, err := frontend.NewWitness(&refCircuit{X: 2, Y: y}, curve.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+
+	path := filepath.Join(t.TempDir(), "pk.raw")
+	f, err := os.Create(path)
+	assert.NoError(err)
+	_, err = pk.WriteRawTo(f)
+	assert.NoError(err)
+	assert.NoError(f.Close())
+
+	raw, err := groth16.OpenRaw(curve, path)
+	assert.NoError(err)
+	defer raw.Close()
+
+	// without budget, each multi-scalar multiplication is computed at once
+	var executor countingExecutor
+	proof, err := groth16.ProveRaw(ccs, raw, fullWitness, groth16_bn254.WithMSMExecutor(&executor))
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, publicWitness))
+	assert.Equal(int64(4), executor.nbG1.Load())
+	assert.Equal(int64(1), executor.nbG2.Load())
+
+	// the budget allows chunks of about 1200 points, which splits the
+	// multi-scalar multiplications over the 3000 wires in several chunks
+	for _, onDisk := range []bool{true, false} {
+		var executor countingExecutor
+		opts := []backend.ProverOption{backend.WithMemoryBudget(2 << 20), groth16_bn254.WithMSMExecutor(&executor)}
+		if onDisk {
+			proof, err = groth16.ProveRaw(ccs, raw, fullWitness, opts...)
+		} else {
+			proof, err = groth16.Prove(ccs, pk, fullWitness, opts...)
+		}
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, publicWitness))
+		assert.Greater(executor.nbG1.Load(), int64(4))
+		assert.Greater(executor.nbG2.Load(), int64(1))
+	}
+
+	_, err = groth16.ProveRaw(ccs, raw, fullWitness, backend.WithMemoryBudget(1<<20))
+	assert.Error(err)
+}
+
+func TestContextAndProgress(t *testing.T) {
+	const curve = ecc.BN254
+	assert := test.NewAssert(t)
+
+	ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &batchCircuit{})
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&batchCircuit{X: 2, Y: 4}, curve.ScalarField())
+	assert.NoError(err)
+
+	var phases []backend.Phase
+	progress := func(e backend.ProgressEvent) {
+		if e.Done {
+			phases = append(phases, e.Phase)
+		}
+	}
+
+	pk, _, err := groth16.Setup(ccs, backend.WithSetupProgress(progress))
+	assert.NoError(err)
+	assert.Equal([]backend.Phase{backend.PhasePreprocess, backend.PhaseCommit}, phases)
+
+	phases = nil
+	_, err = groth16.Prove(ccs, pk, w, backend.WithProgress(progress))
+	assert.NoError(err)
+	assert.Equal([]backend.Phase{backend.PhaseSolve, backend.PhaseQuotient, backend.PhaseCommit}, phases)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = groth16.Prove(ccs, pk, w, backend.WithContext(ctx))
+	assert.ErrorIs(err, context.Canceled)
+	_, _, err = groth16.Setup(ccs, backend.WithSetupContext(ctx))
+	assert.ErrorIs(err, context.Canceled)
+
+	// cancel the prover at the start of a phase: the phase ends with the
+	// error of the context and the next phases do not start
+	for _, phase := range []backend.Phase{backend.PhaseSolve, backend.PhaseQuotient} {
+		assert.Run(func(assert *test.Assert) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var started []backend.Phase
+			phases = nil
+			progress := func(e backend.ProgressEvent) {
+				if !e.Done {
+					started = append(started, e.Phase)
+					if e.Phase == phase {
+						cancel()
+					}
+				}
+				progress(e)
+			}
+			_, err := groth16.Prove(ccs, pk, w, backend.WithContext(ctx), backend.WithProgress(progress))
+			assert.ErrorIs(err, context.Canceled)
+			assert.Equal(started, phases)
+			assert.Equal(phase, started[len(started)-1])
+		}, string(phase))
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	const curve = ecc.BN254
+	assert := test.NewAssert(t)
+
+	ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &batchCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	prepared, err := groth16.Prepare(ccs, pk)
+	assert.NoError(err)
+
+	// write and read back the prepared prover
+	var buf bytes.Buffer
+	_, err = prepared.WriteTo(&buf)
+	assert.NoError(err)
+	read := groth16.NewPreparedProver(curve)
+	_, err = read.ReadFrom(&buf)
+	assert.NoError(err)
+
+	// generate proofs concurrently
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p := prepared
+			if i%2 == 1 {
+				p = read
+			}
+			w, err := frontend.NewWitness(&batchCircuit{X: i + 2, Y: (i + 2) * (i + 2)}, curve.ScalarField())
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			proof, err := groth16.ProvePrepared(p, w)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			publicWitness, err := w.Public()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = groth16.Verify(proof, vk, publicWitness)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(err)
+	}
+}
+
 //--------------------//
 //     benches		  //
 //--------------------//
@@ -168,6 +398,21 @@ func (c *commitmentCircuit) Define(api frontend.API) error {
 	return nil
 }
 
+type batchCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *batchCircuit) Define(api frontend.API) error {
+	cmt, err := api.(frontend.Committer).Commit(c.X)
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	api.AssertIsDifferent(cmt, c.Y)
+	return nil
+}
+
 type constantHash struct{}
 
 func (h constantHash) Write(p []byte) (n int, err error) { return len(p), nil }
@@ -17,12 +17,14 @@
 package plonk
 
 import (
+	"context"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/iop"
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/kzg"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/plonk/internal"
 	"github.com/consensys/gnark/constraint"
 	cs "github.com/consensys/gnark/constraint/bls24-315"
@@ -33,6 +35,8 @@ import (
 // * Commitments of ql prepended with as many ones as there are public inputs
 // * Commitments of qr, qm, qo, qk prepended with as many zeroes as there are public inputs
 // * Commitments to S1, S2, S3
+// * Commitments of the selectors and permutation polynomials of the advice wires,
+// if the constraint system has more than 3 wires
 type VerifyingKey struct {
 	// Size circuit
 	Size              uint64
@@ -55,6 +59,62 @@ type VerifyingKey struct {
 	Qcp                []kzg.Digest
 
 	CommitmentConstraintIndexes []uint64
+
+	// Commitments to the selectors of the custom gates, and the custom gates.
+	Qg          []kzg.Digest
+	CustomGates []CustomGate
+
+	// Commitments to the selectors qD, qE and to the permutation polynomials
+	// S4, S5 of the advice wires xd, xe, for a constraint system with more
+	// than 3 wires.
+	Qw, Sw []kzg.Digest
+}
+
+// nbWires returns the number of wires of the constraints.
+func (vk *VerifyingKey) nbWires() int {
+	return 3 + len(vk.Qw)
+}
+
+// nbQuotientChunks returns the number of chunks of the quotient in the proofs,
+// the number of wires or the degree of the custom gates if it is larger.
+func (vk *VerifyingKey) nbQuotientChunks() int {
+	res := vk.nbWires()
+	for i := range vk.CustomGates {
+		res = max(res, vk.CustomGates[i].degree())
+	}
+	return res
+}
+
+// CustomGate is the polynomial ∑ᵢ Coefficients[i]*lᴬ*rᴮ*oᶜ of a custom gate of
+// the constraint system, where (A, B, C) = Exponents[i]. A constraint using it
+// adds Qg*CustomGate(l, r, o) to the standard gate, see [constraint.CustomGate].
+type CustomGate struct {
+	Coefficients []fr.Element
+	Exponents    [][3]uint8
+}
+
+// degree returns the degree of the gate.
+func (g *CustomGate) degree() int {
+	res := 0
+	for _, e := range g.Exponents {
+		res = max(res, int(e[0])+int(e[1])+int(e[2]))
+	}
+	return res
+}
+
+// evaluate returns the value of the gate at l, r, o.
+func (g *CustomGate) evaluate(l, r, o fr.Element) fr.Element {
+	var res, m fr.Element
+	for i := range g.Coefficients {
+		m = g.Coefficients[i]
+		for j, x := range [3]*fr.Element{&l, &r, &o} {
+			for k := uint8(0); k < g.Exponents[i][j]; k++ {
+				m.Mul(&m, x)
+			}
+		}
+		res.Add(&res, &m)
+	}
+	return res
 }
 
 // Trace stores a plonk trace as columns
@@ -67,11 +127,20 @@ type Trace struct {
 	Ql, Qr, Qm, Qo, Qk *iop.Polynomial
 	Qcp                []*iop.Polynomial
 
+	// Selectors of the custom gates.
+	Qg []*iop.Polynomial
+
+	// Selectors of the advice wires, and the polynomials S4, S5 of the
+	// permutation for the advice wires.
+	Qw, Sw []*iop.Polynomial
+
 	// Polynomials representing the splitted permutation. The full permutation's support is 3*N where N=nb wires.
 	// The set of interpolation is <g> of size N, so to represent the permutation S we let S acts on the
 	// set A=(<g>, u*<g>, u^{2}*<g>) of size 3*N, where u is outside <g> (its use is to shift the set <g>).
 	// We obtain a permutation of A, A'. We split A' in 3 (A'_{1}, A'_{2}, A'_{3}), and S1, S2, S3 are
 	// respectively the interpolation of A'_{1}, A'_{2}, A'_{3} on <g>.
+	// With k > 3 wires, the permutation acts on (<g>, .., u^{k-1}*<g>) and Sw
+	// are the interpolations of A'_{4}, .., A'_{k}.
 	S1, S2, S3 *iop.Polynomial
 
 	// S full permutation, i -> S[i]
@@ -86,7 +155,12 @@ type ProvingKey struct {
 	Vk *VerifyingKey
 }
 
-func Setup(spr *cs.SparseR1CS, srs, srsLagrange kzg.SRS) (*ProvingKey, *VerifyingKey, error) {
+func Setup(spr *cs.SparseR1CS, srs, srsLagrange kzg.SRS, opts ...backend.SetupOption) (*ProvingKey, *VerifyingKey, error) {
+
+	opt, err := backend.NewSetupConfig(opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("new setup config: %w", err)
+	}
 
 	var pk ProvingKey
 	var vk VerifyingKey
@@ -119,17 +193,23 @@ func Setup(spr *cs.SparseR1CS, srs, srsLagrange kzg.SRS) (*ProvingKey, *Verifyin
 	pk.Kzg.G1 = srs.Pk.G1[:int(vk.Size)+3]
 	pk.KzgLagrange.G1 = srsLagrange.Pk.G1
 	vk.Kzg = srs.Vk
+	vk.CustomGates = newCustomGates(spr)
 
-	// step 2: ql, qr, qm, qo, qk, qcp in Lagrange Basis
+	// step 2: ql, qr, qm, qo, qk, qcp, qg in Lagrange Basis
 	// step 3: build the permutation and build the polynomials S1, S2, S3 to encode the permutation.
 	// Note: at this stage, the permutation takes in account the placeholders
+	endPreprocess := opt.Progress.Start(backend.PhasePreprocess)
 	trace := NewTrace(spr, domain)
+	endPreprocess()
 
-	// step 4: commit to s1, s2, s3, ql, qr, qm, qo, and (the incomplete version of) qk.
+	// step 4: commit to s1, s2, s3, ql, qr, qm, qo, qcp, qg, qw, sw, and (the incomplete version of) qk.
 	// All the above polynomials are expressed in canonical basis afterwards. This is why
 	// we save lqk before, because the prover needs to complete it in Lagrange form, and
 	// then express it on the Lagrange coset basis.
-	if err := vk.commitTrace(trace, domain, pk.KzgLagrange); err != nil {
+	endCommit := opt.Progress.Start(backend.PhaseCommit)
+	err = vk.commitTrace(opt.Context, trace, domain, pk.KzgLagrange)
+	endCommit()
+	if err != nil {
 		return nil, nil, err
 	}
 
@@ -147,7 +227,7 @@ func (pk *ProvingKey) VerifyingKey() interface{} {
 }
 
 // NewTrace returns a new Trace object from the constraint system.
-// It fills the constant columns ql, qr, qm, qo, qk, and qcp with the
+// It fills the constant columns ql, qr, qm, qo, qk, qcp, qg and qw with the
 // coefficients of the constraints.
 // Size is the size of the system that is next power of 2 (nb_constraints+nb_public_variables)
 // The permutation is also computed and stored in the Trace.
@@ -165,6 +245,14 @@ func NewTrace(spr *cs.SparseR1CS, domain *fft.Domain) *Trace {
 	qo := make([]fr.Element, size)
 	qk := make([]fr.Element, size)
 	qcp := make([][]fr.Element, len(commitmentInfo))
+	qg := make([][]fr.Element, len(spr.CustomGates))
+	for i := range qg {
+		qg[i] = make([]fr.Element, size)
+	}
+	qw := make([][]fr.Element, spr.GetNbWires()-3)
+	for i := range qw {
+		qw[i] = make([]fr.Element, size)
+	}
 
 	for i := 0; i < len(spr.Public); i++ { // placeholders (-PUB_INPUT_i + qk_i = 0) TODO should return error if size is inconsistent
 		ql[i].SetOne().Neg(&ql[i])
@@ -183,6 +271,13 @@ func NewTrace(spr *cs.SparseR1CS, domain *fft.Domain) *Trace {
 		qm[offset+j].Set(&spr.Coefficients[c.QM])
 		qo[offset+j].Set(&spr.Coefficients[c.QO])
 		qk[offset+j].Set(&spr.Coefficients[c.QC])
+		if c.QG != constraint.CoeffIdZero {
+			qg[c.Gate][offset+j].Set(&spr.Coefficients[c.QG])
+		}
+		advice := [...]uint32{c.QD, c.QE}
+		for i := range qw {
+			qw[i][offset+j].Set(&spr.Coefficients[advice[i]])
+		}
 		j++
 	}
 
@@ -203,6 +298,15 @@ func NewTrace(spr *cs.SparseR1CS, domain *fft.Domain) *Trace {
 		trace.Qcp[i] = iop.NewPolynomial(&qcp[i], lagReg)
 	}
 
+	trace.Qg = make([]*iop.Polynomial, len(qg))
+	for i := range qg {
+		trace.Qg[i] = iop.NewPolynomial(&qg[i], lagReg)
+	}
+	trace.Qw = make([]*iop.Polynomial, len(qw))
+	for i := range qw {
+		trace.Qw[i] = iop.NewPolynomial(&qw[i], lagReg)
+	}
+
 	// build the permutation and build the polynomials S1, S2, S3 to encode the permutation.
 	// Note: at this stage, the permutation takes in account the placeholders
 	nbVariables := spr.NbInternalVariables + len(spr.Public) + len(spr.Secret)
@@ -211,48 +315,63 @@ func NewTrace(spr *cs.SparseR1CS, domain *fft.Domain) *Trace {
 	trace.S1 = s[0]
 	trace.S2 = s[1]
 	trace.S3 = s[2]
+	trace.Sw = s[3:]
 
 	return &trace
 }
 
 // commitTrace commits to every polynomial in the trace, and put
-// the commitments int the verifying key.
-func (vk *VerifyingKey) commitTrace(trace *Trace, domain *fft.Domain, srsPk kzg.ProvingKey) error {
-
-	var err error
+// the commitments int the verifying key. It returns the error of ctx if ctx is
+// done between two commitments.
+func (vk *VerifyingKey) commitTrace(ctx context.Context, trace *Trace, domain *fft.Domain, srsPk kzg.ProvingKey) error {
 	vk.Qcp = make([]kzg.Digest, len(trace.Qcp))
+	vk.Qg = make([]kzg.Digest, len(trace.Qg))
+	vk.Qw = make([]kzg.Digest, len(trace.Qw))
+	vk.Sw = make([]kzg.Digest, len(trace.Sw))
+	commitments := make([]*kzg.Digest, 0, len(trace.Qcp)+len(trace.Qg)+2*len(trace.Qw)+8)
+	polynomials := make([]*iop.Polynomial, 0, len(trace.Qcp)+len(trace.Qg)+2*len(trace.Qw)+8)
 	for i := range trace.Qcp {
-		if vk.Qcp[i], err = kzg.Commit(trace.Qcp[i].Coefficients(), srsPk); err != nil {
-			return err
-		}
-	}
-	if vk.Ql, err = kzg.Commit(trace.Ql.Coefficients(), srsPk); err != nil {
-		return err
+		commitments = append(commitments, &vk.Qcp[i])
+		polynomials = append(polynomials, trace.Qcp[i])
 	}
-	if vk.Qr, err = kzg.Commit(trace.Qr.Coefficients(), srsPk); err != nil {
-		return err
+	for i := range trace.Qg {
+		commitments = append(commitments, &vk.Qg[i])
+		polynomials = append(polynomials, trace.Qg[i])
 	}
-	if vk.Qm, err = kzg.Commit(trace.Qm.Coefficients(), srsPk); err != nil {
-		return err
+	for i := range trace.Qw {
+		commitments = append(commitments, &vk.Qw[i], &vk.Sw[i])
+		polynomials = append(polynomials, trace.Qw[i], trace.Sw[i])
 	}
-	if vk.Qo, err = kzg.Commit(trace.Qo.Coefficients(), srsPk); err != nil {
-		return err
-	}
-	if vk.Qk, err = kzg.Commit(trace.Qk.Coefficients(), srsPk); err != nil {
-		return err
-	}
-	if vk.S[0], err = kzg.Commit(trace.S1.Coefficients(), srsPk); err != nil {
-		return err
-	}
-	if vk.S[1], err = kzg.Commit(trace.S2.Coefficients(), srsPk); err != nil {
-		return err
-	}
-	if vk.S[2], err = kzg.Commit(trace.S3.Coefficients(), srsPk); err != nil {
-		return err
+	commitments = append(commitments, &vk.Ql, &vk.Qr, &vk.Qm, &vk.Qo, &vk.Qk, &vk.S[0], &vk.S[1], &vk.S[2])
+	polynomials = append(polynomials, trace.Ql, trace.Qr, trace.Qm, trace.Qo, trace.Qk, trace.S1, trace.S2, trace.S3)
+
+	for i := range polynomials {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var err error
+		if *commitments[i], err = kzg.Commit(polynomials[i].Coefficients(), srsPk); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// newCustomGates returns the custom gates of the constraint system.
+func newCustomGates(spr *cs.SparseR1CS) []CustomGate {
+	gates := spr.GetCustomGates()
+	res := make([]CustomGate, len(gates))
+	for i, g := range gates {
+		res[i].Coefficients = make([]fr.Element, len(g.Terms))
+		res[i].Exponents = make([][3]uint8, len(g.Terms))
+		for j, t := range g.Terms {
+			res[i].Coefficients[j] = spr.Coefficients[t.CID]
+			res[i].Exponents[j] = [3]uint8{t.A, t.B, t.C}
+		}
+	}
+	return res
+}
+
 func initFFTDomain(spr *cs.SparseR1CS) *fft.Domain {
 	nbConstraints := spr.GetNbConstraints()
 	sizeSystem := uint64(nbConstraints + len(spr.Public)) // len(spr.Public) is for the placeholder constraints
@@ -266,16 +385,18 @@ func initFFTDomain(spr *cs.SparseR1CS) *fft.Domain {
 //	s. (l∥r∥o) = (l∥r∥o)
 //
 // , where l∥r∥o is the concatenation of the indices of l, r, o in
-// ql.l+qr.r+qm.l.r+qo.O+k = 0.
+// ql.l+qr.r+qm.l.r+qo.O+k = 0, followed by the indices of the advice wires
+// if the constraints have more than 3 wires.
 //
-// The permutation is encoded as a slice s of size 3*size(l), where the
+// The permutation is encoded as a slice s of size nbWires*size(l), where the
 // i-th entry of l∥r∥o is sent to the s[i]-th entry, so it acts on a tab
 // like this: for i in tab: tab[i] = tab[permutation[i]]
 func buildPermutation(spr *cs.SparseR1CS, trace *Trace, nbVariables int) {
 
 	// nbVariables := spr.NbInternalVariables + len(spr.Public) + len(spr.Secret)
 	sizeSolution := len(trace.Ql.Coefficients())
-	sizePermutation := 3 * sizeSolution
+	nbWires := spr.GetNbWires()
+	sizePermutation := nbWires * sizeSolution
 
 	// init permutation
 	permutation := make([]int64, sizePermutation)
@@ -297,6 +418,10 @@ func buildPermutation(spr *cs.SparseR1CS, trace *Trace, nbVariables int) {
 		lro[offset+j] = int(c.XA)
 		lro[sizeSolution+offset+j] = int(c.XB)
 		lro[2*sizeSolution+offset+j] = int(c.XC)
+		advice := [...]uint32{c.XD, c.XE}
+		for i := 0; i < nbWires-3; i++ {
+			lro[(3+i)*sizeSolution+offset+j] = int(advice[i])
+		}
 
 		j++
 	}
@@ -328,49 +453,48 @@ func buildPermutation(spr *cs.SparseR1CS, trace *Trace, nbVariables int) {
 }
 
 // computePermutationPolynomials computes the LDE (Lagrange basis) of the permutation.
-// We let the permutation act on <g> || u<g> || u^{2}<g>, split the result in 3 parts,
-// and interpolate each of the 3 parts on <g>.
-func computePermutationPolynomials(trace *Trace, domain *fft.Domain) [3]*iop.Polynomial {
+// We let the permutation act on <g> || u<g> || .. || u^{k-1}<g>, where k is the
+// number of wires, split the result in k parts, and interpolate each of the k
+// parts on <g>.
+func computePermutationPolynomials(trace *Trace, domain *fft.Domain) []*iop.Polynomial {
 
 	nbElmts := int(domain.Cardinality)
+	nbWires := len(trace.S) / nbElmts
 
-	var res [3]*iop.Polynomial
+	res := make([]*iop.Polynomial, nbWires)
 
 	// Lagrange form of ID
-	evaluationIDSmallDomain := getSupportPermutation(domain)
-
-	// Lagrange form of S1, S2, S3
-	s1Canonical := make([]fr.Element, nbElmts)
-	s2Canonical := make([]fr.Element, nbElmts)
-	s3Canonical := make([]fr.Element, nbElmts)
-	for i := 0; i < nbElmts; i++ {
-		s1Canonical[i].Set(&evaluationIDSmallDomain[trace.S[i]])
-		s2Canonical[i].Set(&evaluationIDSmallDomain[trace.S[nbElmts+i]])
-		s3Canonical[i].Set(&evaluationIDSmallDomain[trace.S[2*nbElmts+i]])
-	}
+	evaluationIDSmallDomain := getSupportPermutation(domain, nbWires)
 
+	// Lagrange form of S1, S2, S3, ..
 	lagReg := iop.Form{Basis: iop.Lagrange, Layout: iop.Regular}
-	res[0] = iop.NewPolynomial(&s1Canonical, lagReg)
-	res[1] = iop.NewPolynomial(&s2Canonical, lagReg)
-	res[2] = iop.NewPolynomial(&s3Canonical, lagReg)
+	for k := range res {
+		sCanonical := make([]fr.Element, nbElmts)
+		for i := 0; i < nbElmts; i++ {
+			sCanonical[i].Set(&evaluationIDSmallDomain[trace.S[k*nbElmts+i]])
+		}
+		res[k] = iop.NewPolynomial(&sCanonical, lagReg)
+	}
 
 	return res
 }
 
 // getSupportPermutation returns the support on which the permutation acts, it is
-// <g> || u<g> || u^{2}<g>
-func getSupportPermutation(domain *fft.Domain) []fr.Element {
+// <g> || u<g> || .. || u^{k-1}<g> for k wires.
+func getSupportPermutation(domain *fft.Domain, nbWires int) []fr.Element {
 
-	res := make([]fr.Element, 3*domain.Cardinality)
+	n := domain.Cardinality
+	res := make([]fr.Element, uint64(nbWires)*n)
 
 	res[0].SetOne()
-	res[domain.Cardinality].Set(&domain.FrMultiplicativeGen)
-	res[2*domain.Cardinality].Square(&domain.FrMultiplicativeGen)
+	for k := uint64(1); k < uint64(nbWires); k++ {
+		res[k*n].Mul(&res[(k-1)*n], &domain.FrMultiplicativeGen)
+	}
 
-	for i := uint64(1); i < domain.Cardinality; i++ {
-		res[i].Mul(&res[i-1], &domain.Generator)
-		res[domain.Cardinality+i].Mul(&res[domain.Cardinality+i-1], &domain.Generator)
-		res[2*domain.Cardinality+i].Mul(&res[2*domain.Cardinality+i-1], &domain.Generator)
+	for i := uint64(1); i < n; i++ {
+		for k := uint64(0); k < uint64(nbWires); k++ {
+			res[k*n+i].Mul(&res[k*n+i-1], &domain.Generator)
+		}
 	}
 
 	return res
@@ -53,12 +53,97 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		return fmt.Errorf("create backend config: %w", err)
 	}
 
+	o, err := verify(proof, vk, publicWitness, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Batch verify
+	err = kzg.BatchVerifyMultiPoints(o.digests[:], o.proofs[:], o.points[:], vk.Kzg)
+
+	log.Debug().Dur("took", time.Since(start)).Msg("verifier done")
+
+	return err
+}
+
+// BatchVerify verifies the proofs with the given VerifyingKey and public
+// witnesses, where publicWitnesses[i] is the public witness of proofs[i].
+//
+// The algebraic relation is checked for each proof, and the KZG openings of
+// all the proofs are checked with a single batch opening, folded with random
+// coefficients. If the batch check fails, the openings are checked
+// individually and the returned [backend.BatchVerificationError] reports the
+// invalid proofs.
+func BatchVerify(proofs []*Proof, vk *VerifyingKey, publicWitnesses []fr.Vector, opts ...backend.VerifierOption) error {
+	if len(proofs) != len(publicWitnesses) {
+		return fmt.Errorf("got %d proofs and %d public witnesses", len(proofs), len(publicWitnesses))
+	}
+
+	log := logger.Logger().With().Str("curve", "bls24-317").Str("backend", "plonk").Int("nbProofs", len(proofs)).Logger()
+	start := time.Now()
+	cfg, err := backend.NewVerifierConfig(opts...)
+	if err != nil {
+		return fmt.Errorf("create backend config: %w", err)
+	}
+
+	errs := make([]error, len(proofs))
+	o := make([]openings, len(proofs))
+	digests := make([]kzg.Digest, 0, 2*len(proofs))
+	openingProofs := make([]kzg.OpeningProof, 0, 2*len(proofs))
+	points := make([]fr.Element, 0, 2*len(proofs))
+	for i := range proofs {
+		if o[i], errs[i] = verify(proofs[i], vk, publicWitnesses[i], cfg); errs[i] != nil {
+			continue
+		}
+		digests = append(digests, o[i].digests[:]...)
+		openingProofs = append(openingProofs, o[i].proofs[:]...)
+		points = append(points, o[i].points[:]...)
+	}
+
+	if len(digests) != 0 && kzg.BatchVerifyMultiPoints(digests, openingProofs, points, vk.Kzg) != nil {
+		// identify the invalid proofs
+		for i := range proofs {
+			if errs[i] == nil {
+				errs[i] = kzg.BatchVerifyMultiPoints(o[i].digests[:], o[i].proofs[:], o[i].points[:], vk.Kzg)
+			}
+		}
+	}
+
+	log.Debug().Dur("took", time.Since(start)).Msg("batch verifier done")
+
+	return backend.NewBatchVerificationError(errs)
+}
+
+// openings are the KZG openings a proof is reduced to by the verifier: the
+// folded opening at ζ and the opening of Z at ωζ.
+type openings struct {
+	digests [2]kzg.Digest
+	proofs  [2]kzg.OpeningProof
+	points  [2]fr.Element
+}
+
+// verify checks the algebraic relation of the proof and returns the KZG
+// openings remaining to be checked.
+func verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, cfg backend.VerifierConfig) (res openings, err error) {
 	if len(proof.Bsb22Commitments) != len(vk.Qcp) {
-		return errors.New("BSB22 Commitment number mismatch")
+		return res, errors.New("BSB22 Commitment number mismatch")
+	}
+	if len(vk.CustomGates) != len(vk.Qg) {
+		return res, errors.New("custom gates number mismatch")
+	}
+	nbAdvice := len(vk.Qw)
+	if len(vk.Sw) != nbAdvice || len(proof.W) != nbAdvice {
+		return res, errors.New("wires number mismatch")
+	}
+	if len(proof.HExtra) != vk.nbQuotientChunks()-3 {
+		return res, errors.New("quotient chunks number mismatch")
+	}
+	if len(proof.BatchedProof.ClaimedValues) != 6+len(vk.Qcp)+2*nbAdvice {
+		return res, errors.New("claimed values number mismatch")
 	}
 
 	if len(publicWitness) != int(vk.NbPublicVariables) {
-		return errInvalidWitness
+		return res, errInvalidWitness
 	}
 
 	// transcript to derive the challenge
@@ -68,17 +153,17 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 	// the coefficients of the circuit, and the public inputs.
 	// derive gamma from the Comm(blinded cl), Comm(blinded cr), Comm(blinded co)
 	if err := bindPublicData(fs, "gamma", vk, publicWitness); err != nil {
-		return err
+		return res, err
 	}
-	gamma, err := deriveRandomness(fs, "gamma", &proof.LRO[0], &proof.LRO[1], &proof.LRO[2])
+	gamma, err := deriveRandomness(fs, "gamma", wireCommitments(proof)...)
 	if err != nil {
-		return err
+		return res, err
 	}
 
 	// derive beta from Comm(l), Comm(r), Comm(o)
 	beta, err := deriveRandomness(fs, "beta")
 	if err != nil {
-		return err
+		return res, err
 	}
 
 	// derive alpha from Com(Z), Bsb22Commitments
@@ -89,13 +174,13 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 	alphaDeps[len(alphaDeps)-1] = &proof.Z
 	alpha, err := deriveRandomness(fs, "alpha", alphaDeps...)
 	if err != nil {
-		return err
+		return res, err
 	}
 
 	// derive zeta, the point of evaluation
-	zeta, err := deriveRandomness(fs, "zeta", &proof.H[0], &proof.H[1], &proof.H[2])
+	zeta, err := deriveRandomness(fs, "zeta", quotientCommitments(proof)...)
 	if err != nil {
-		return err
+		return res, err
 	}
 
 	// evaluation of zhZeta=ζⁿ-1
@@ -172,6 +257,23 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 	s1 := proof.BatchedProof.ClaimedValues[4]
 	s2 := proof.BatchedProof.ClaimedValues[5]
 
+	// wₖ(ζ), swₖ(ζ) of the advice wires
+	w := proof.BatchedProof.ClaimedValues[6+len(vk.Qcp) : 6+len(vk.Qcp)+nbAdvice]
+	sw := proof.BatchedProof.ClaimedValues[6+len(vk.Qcp)+nbAdvice:]
+
+	// ∏ₖ(wₖ(ζ)+β*swₖ(ζ)+γ), ∏ₖ(wₖ(ζ)+β*uᵏ⁺³*ζ+γ)
+	var prodSw, prodIdw, uZeta fr.Element
+	prodSw.SetOne()
+	prodIdw.SetOne()
+	uZeta.Mul(&vk.CosetShift, &vk.CosetShift).Mul(&uZeta, &zeta)
+	for k := 0; k < nbAdvice; k++ {
+		tmp.Mul(&beta, &sw[k]).Add(&tmp, &w[k]).Add(&tmp, &gamma)
+		prodSw.Mul(&prodSw, &tmp)
+		uZeta.Mul(&uZeta, &vk.CosetShift)
+		tmp.Mul(&beta, &uZeta).Add(&tmp, &w[k]).Add(&tmp, &gamma)
+		prodIdw.Mul(&prodIdw, &tmp)
+	}
+
 	// Z(ωζ)
 	zu := proof.ZShiftedOpening.ClaimedValue
 
@@ -183,12 +285,14 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 	// computing the constant coefficient of the full algebraic relation
 	// , corresponding to the value of the linearisation polynomiat at ζ
 	// PI(ζ) - α²*L₁(ζ) + α(l(ζ)+β*s1(ζ)+γ)(r(ζ)+β*s2(ζ)+γ)(o(ζ)+γ)*z(ωζ)
+	// , where the last term is multiplied by ∏ₖ(wₖ(ζ)+β*swₖ(ζ)+γ) with advice wires
 	var constLin fr.Element
 	constLin.Mul(&beta, &s1).Add(&constLin, &gamma).Add(&constLin, &l)       // (l(ζ)+β*s1(ζ)+γ)
 	tmp.Mul(&s2, &beta).Add(&tmp, &gamma).Add(&tmp, &r)                      // (r(ζ)+β*s2(ζ)+γ)
 	constLin.Mul(&constLin, &tmp)                                            // (l(ζ)+β*s1(ζ)+γ)(r(ζ)+β*s2(ζ)+γ)
 	tmp.Add(&o, &gamma)                                                      // (o(ζ)+γ)
 	constLin.Mul(&tmp, &constLin).Mul(&constLin, &alpha).Mul(&constLin, &zu) // α(l(ζ)+β*s1(ζ)+γ)(r(ζ)+β*s2(ζ)+γ)(o(ζ)+γ)*z(ωζ)
+	constLin.Mul(&constLin, &prodSw)
 
 	constLin.Sub(&constLin, &alphaSquareLagrangeOne).Add(&constLin, &pi) // PI(ζ) - α²*L₁(ζ) + α(l(ζ)+β*s1(ζ)+γ)(r(ζ)+β*s2(ζ)+γ)(o(ζ)+γ)*z(ωζ)
 	constLin.Neg(&constLin)                                              // -[PI(ζ) - α²*L₁(ζ) + α(l(ζ)+β*s1(ζ)+γ)(r(ζ)+β*s2(ζ)+γ)(o(ζ)+γ)*z(ωζ)]
@@ -196,22 +300,24 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 	// check that the opening of the linearised polynomial is equal to -constLin
 	openingLinPol := proof.BatchedProof.ClaimedValues[0]
 	if !constLin.Equal(&openingLinPol) {
-		return errAlgebraicRelation
+		return res, errAlgebraicRelation
 	}
 
 	// computing the linearised polynomial digest
 	// α²*L₁(ζ)*[Z] +
 	// _s1*[s3]+_s2*[Z] + l(ζ)*[Ql] +
-	// l(ζ)r(ζ)*[Qm] + r(ζ)*[Qr] + o(ζ)*[Qo] + [Qk] + ∑ᵢQcp_(ζ)[Pi_i] -
-	// Z_{H}(ζ)*(([H₀] + ζᵐ⁺²*[H₁] + ζ²⁽ᵐ⁺²⁾*[H₂])
+	// l(ζ)r(ζ)*[Qm] + r(ζ)*[Qr] + o(ζ)*[Qo] + [Qk] + ∑ᵢQcp_(ζ)[Pi_i] +
+	// ∑ⱼGⱼ(l(ζ), r(ζ), o(ζ))[Qgⱼ] + ∑ₖwₖ(ζ)[Qwₖ] - Z_{H}(ζ)*(([H₀] + ζᵐ⁺²*[H₁] + ζ²⁽ᵐ⁺²⁾*[H₂] + ..)
 	// where
-	// _s1 =  α*(l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*β*Z(μζ)
-	// _s2 = -α*(l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ)
+	// Gⱼ are the custom gates, wₖ the advice wires
+	// _s1 =  α*(l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*β*Z(μζ)*∏ₖ(wₖ(ζ)+β*swₖ(ζ)+γ)
+	// _s2 = -α*(l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ)*∏ₖ(wₖ(ζ)+β*uᵏ⁺³*ζ+γ)
 
 	// _s1 = α*(l(ζ)+β*s1(β)+γ)*(r(ζ)+β*s2(β)+γ)*β*Z(μζ)
 	_s1.Mul(&beta, &s1).Add(&_s1, &l).Add(&_s1, &gamma)                   // (l(ζ)+β*s1(β)+γ)
 	tmp.Mul(&beta, &s2).Add(&tmp, &r).Add(&tmp, &gamma)                   // (r(ζ)+β*s2(β)+γ)
 	_s1.Mul(&_s1, &tmp).Mul(&_s1, &beta).Mul(&_s1, &alpha).Mul(&_s1, &zu) // α*(l(ζ)+β*s1(β)+γ)*(r(ζ)+β*s2(β)+γ)*β*Z(μζ)
+	_s1.Mul(&_s1, &prodSw)
 
 	// _s2 = -α*(l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ)
 	_s2.Mul(&beta, &zeta).Add(&_s2, &gamma).Add(&_s2, &l)                                                     // (l(ζ)+β*ζ+γ)
@@ -219,6 +325,7 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 	_s2.Mul(&_s2, &tmp)                                                                                       // (l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)
 	tmp.Mul(&beta, &vk.CosetShift).Mul(&tmp, &vk.CosetShift).Mul(&tmp, &zeta).Add(&tmp, &o).Add(&tmp, &gamma) // (o(ζ)+β*u²*ζ+γ)
 	_s2.Mul(&_s2, &tmp).Mul(&_s2, &alpha).Neg(&_s2)                                                           // -α*(l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ)
+	_s2.Mul(&_s2, &prodIdw)
 
 	// α²*L₁(ζ) - α*(l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ)
 	var coeffZ fr.Element
@@ -230,8 +337,9 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 
 	// -ζⁿ⁺²*(ζⁿ-1), -ζ²⁽ⁿ⁺²⁾*(ζⁿ-1), -(ζⁿ-1)
 	nPlusTwo := big.NewInt(int64(vk.Size) + 2)
-	var zetaNPlusTwoZh, zetaNPlusTwoSquareZh, zh fr.Element
-	zetaNPlusTwoZh.Exp(zeta, nPlusTwo)
+	var zetaNPlusTwo, zetaNPlusTwoZh, zetaNPlusTwoSquareZh, zh fr.Element
+	zetaNPlusTwo.Exp(zeta, nPlusTwo)
+	zetaNPlusTwoZh.Set(&zetaNPlusTwo)
 	zetaNPlusTwoSquareZh.Mul(&zetaNPlusTwoZh, &zetaNPlusTwoZh)                          // ζ²⁽ⁿ⁺²⁾
 	zetaNPlusTwoZh.Mul(&zetaNPlusTwoZh, &zhZeta).Neg(&zetaNPlusTwoZh)                   // -ζⁿ⁺²*(ζⁿ-1)
 	zetaNPlusTwoSquareZh.Mul(&zetaNPlusTwoSquareZh, &zhZeta).Neg(&zetaNPlusTwoSquareZh) // -ζ²⁽ⁿ⁺²⁾*(ζⁿ-1)
@@ -252,13 +360,31 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		_s1, coeffZ,
 		zh, zetaNPlusTwoZh, zetaNPlusTwoSquareZh,
 	)
+
+	// custom gates
+	points = append(points, vk.Qg...)
+	for j := range vk.CustomGates {
+		scalars = append(scalars, vk.CustomGates[j].evaluate(l, r, o))
+	}
+
+	// advice wires and chunks h4, .. of the quotient
+	points = append(points, vk.Qw...)
+	scalars = append(scalars, w...)
+	points = append(points, proof.HExtra...)
+	zetaNPlusTwoPowerZh := zetaNPlusTwoSquareZh
+	for range proof.HExtra {
+		zetaNPlusTwoPowerZh.Mul(&zetaNPlusTwoPowerZh, &zetaNPlusTwo) // -ζᵏ⁽ⁿ⁺²⁾*(ζⁿ-1)
+		scalars = append(scalars, zetaNPlusTwoPowerZh)
+	}
 	if _, err := linearizedPolynomialDigest.MultiExp(points, scalars, ecc.MultiExpConfig{}); err != nil {
-		return err
+		return res, err
 	}
 
 	// Fold the first proof
-	digestsToFold := make([]curve.G1Affine, len(vk.Qcp)+6)
+	digestsToFold := make([]curve.G1Affine, len(vk.Qcp)+6, len(vk.Qcp)+6+2*nbAdvice)
 	copy(digestsToFold[6:], vk.Qcp)
+	digestsToFold = append(digestsToFold, proof.W...)
+	digestsToFold = append(digestsToFold, vk.Sw...)
 	digestsToFold[0] = linearizedPolynomialDigest
 	digestsToFold[1] = proof.LRO[0]
 	digestsToFold[2] = proof.LRO[1]
@@ -273,30 +399,15 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		zu.Marshal(),
 	)
 	if err != nil {
-		return err
+		return res, err
 	}
 
-	// Batch verify
 	var shiftedZeta fr.Element
 	shiftedZeta.Mul(&zeta, &vk.Generator)
-	err = kzg.BatchVerifyMultiPoints([]kzg.Digest{
-		foldedDigest,
-		proof.Z,
-	},
-		[]kzg.OpeningProof{
-			foldedProof,
-			proof.ZShiftedOpening,
-		},
-		[]fr.Element{
-			zeta,
-			shiftedZeta,
-		},
-		vk.Kzg,
-	)
-
-	log.Debug().Dur("took", time.Since(start)).Msg("verifier done")
-
-	return err
+	res.digests = [2]kzg.Digest{foldedDigest, proof.Z}
+	res.proofs = [2]kzg.OpeningProof{foldedProof, proof.ZShiftedOpening}
+	res.points = [2]fr.Element{zeta, shiftedZeta}
+	return res, nil
 }
 
 func bindPublicData(fs *fiatshamir.Transcript, challenge string, vk *VerifyingKey, publicInputs []fr.Element) error {
@@ -333,6 +444,29 @@ func bindPublicData(fs *fiatshamir.Transcript, challenge string, vk *VerifyingKe
 			return err
 		}
 	}
+	for i := range vk.Qg {
+		if err := fs.Bind(challenge, vk.Qg[i].Marshal()); err != nil {
+			return err
+		}
+	}
+
+	// terms of the custom gates
+	for _, term := range customGateTerms(vk) {
+		if err := fs.Bind(challenge, term[0].Marshal()); err != nil {
+			return err
+		}
+		if err := fs.Bind(challenge, term[1].Marshal()); err != nil {
+			return err
+		}
+	}
+	for i := range vk.Qw {
+		if err := fs.Bind(challenge, vk.Qw[i].Marshal()); err != nil {
+			return err
+		}
+		if err := fs.Bind(challenge, vk.Sw[i].Marshal()); err != nil {
+			return err
+		}
+	}
 
 	// public inputs
 	for i := 0; i < len(publicInputs); i++ {
@@ -345,6 +479,40 @@ func bindPublicData(fs *fiatshamir.Transcript, challenge string, vk *VerifyingKe
 
 }
 
+// customGateTerms returns the terms of the custom gates bound to derive gamma,
+// as pairs of the coefficient and the exponents A + 2⁸B + 2¹⁶C.
+func customGateTerms(vk *VerifyingKey) [][2]fr.Element {
+	var res [][2]fr.Element
+	for _, g := range vk.CustomGates {
+		for i, e := range g.Exponents {
+			var exponents fr.Element
+			exponents.SetUint64(uint64(e[0]) | uint64(e[1])<<8 | uint64(e[2])<<16)
+			res = append(res, [2]fr.Element{g.Coefficients[i], exponents})
+		}
+	}
+	return res
+}
+
+// wireCommitments returns the commitments to the wires bound to derive gamma:
+// l, r, o and the advice wires.
+func wireCommitments(proof *Proof) []*curve.G1Affine {
+	res := []*curve.G1Affine{&proof.LRO[0], &proof.LRO[1], &proof.LRO[2]}
+	for i := range proof.W {
+		res = append(res, &proof.W[i])
+	}
+	return res
+}
+
+// quotientCommitments returns the commitments to the chunks of the quotient
+// bound to derive zeta.
+func quotientCommitments(proof *Proof) []*curve.G1Affine {
+	res := []*curve.G1Affine{&proof.H[0], &proof.H[1], &proof.H[2]}
+	for i := range proof.HExtra {
+		res = append(res, &proof.HExtra[i])
+	}
+	return res
+}
+
 func deriveRandomness(fs *fiatshamir.Transcript, challenge string, points ...*curve.G1Affine) (fr.Element, error) {
 
 	var buf [curve.SizeOfG1AffineUncompressed]byte
@@ -19,8 +19,13 @@ package plonk
 import (
 	curve "github.com/consensys/gnark-crypto/ecc/bn254"
 
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+
+	"errors"
 	"github.com/consensys/gnark-crypto/ecc/bn254/kzg"
 	"io"
+
+	"github.com/consensys/gnark/constraint"
 )
 
 // WriteRawTo writes binary encoding of Proof to w without point compression
@@ -49,6 +54,8 @@ func (proof *Proof) writeTo(w io.Writer, options ...func(*curve.Encoder)) (int64
 		&proof.ZShiftedOpening.H,
 		&proof.ZShiftedOpening.ClaimedValue,
 		proof.Bsb22Commitments,
+		proof.W,
+		proof.HExtra,
 	}
 
 	for _, v := range toEncode {
@@ -76,6 +83,8 @@ func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
 		&proof.ZShiftedOpening.H,
 		&proof.ZShiftedOpening.ClaimedValue,
 		&proof.Bsb22Commitments,
+		&proof.W,
+		&proof.HExtra,
 	}
 
 	for _, v := range toDecode {
@@ -87,6 +96,12 @@ func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
 	if proof.Bsb22Commitments == nil {
 		proof.Bsb22Commitments = []kzg.Digest{}
 	}
+	if proof.W == nil {
+		proof.W = []kzg.Digest{}
+	}
+	if proof.HExtra == nil {
+		proof.HExtra = []kzg.Digest{}
+	}
 
 	return dec.BytesRead(), nil
 }
@@ -185,6 +200,7 @@ func (vk *VerifyingKey) WriteRawTo(w io.Writer) (int64, error) {
 func (vk *VerifyingKey) writeTo(w io.Writer, options ...func(*curve.Encoder)) (n int64, err error) {
 	enc := curve.NewEncoder(w)
 
+	coefficients, exponents := encodeCustomGates(vk.CustomGates)
 	toEncode := []interface{}{
 		vk.Size,
 		&vk.SizeInv,
@@ -205,6 +221,11 @@ func (vk *VerifyingKey) writeTo(w io.Writer, options ...func(*curve.Encoder)) (n
 		&vk.Kzg.G2[1],
 		&vk.Kzg.Lines,
 		vk.CommitmentConstraintIndexes,
+		vk.Qg,
+		coefficients,
+		exponents,
+		vk.Qw,
+		vk.Sw,
 	}
 
 	for _, v := range toEncode {
@@ -225,6 +246,8 @@ func (vk *VerifyingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
 // ReadFrom reads from binary representation in r into VerifyingKey
 func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
 	dec := curve.NewDecoder(r)
+	var coefficients [][]fr.Element
+	var exponents [][]uint64
 	toDecode := []interface{}{
 		&vk.Size,
 		&vk.SizeInv,
@@ -245,6 +268,11 @@ func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
 		&vk.Kzg.G2[1],
 		&vk.Kzg.Lines,
 		&vk.CommitmentConstraintIndexes,
+		&vk.Qg,
+		&coefficients,
+		&exponents,
+		&vk.Qw,
+		&vk.Sw,
 	}
 
 	for _, v := range toDecode {
@@ -256,6 +284,64 @@ func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
 	if vk.Qcp == nil {
 		vk.Qcp = []kzg.Digest{}
 	}
+	if vk.Qg == nil {
+		vk.Qg = []kzg.Digest{}
+	}
+	if vk.Qw == nil {
+		vk.Qw = []kzg.Digest{}
+	}
+	if vk.Sw == nil {
+		vk.Sw = []kzg.Digest{}
+	}
+
+	var err error
+	if vk.CustomGates, err = decodeCustomGates(coefficients, exponents); err != nil {
+		return dec.BytesRead(), err
+	}
+	if len(vk.CustomGates) != len(vk.Qg) {
+		return dec.BytesRead(), errors.New("invalid number of custom gates")
+	}
+	if len(vk.Qw) != len(vk.Sw) || len(vk.Qw) > constraint.MaxNbWires-3 {
+		return dec.BytesRead(), errors.New("invalid number of wires")
+	}
 
 	return dec.BytesRead(), nil
 }
+
+// encodeCustomGates returns the coefficients and the exponents of the gates,
+// three exponents by term.
+func encodeCustomGates(gates []CustomGate) (coefficients [][]fr.Element, exponents [][]uint64) {
+	coefficients = make([][]fr.Element, len(gates))
+	exponents = make([][]uint64, len(gates))
+	for i, g := range gates {
+		coefficients[i] = g.Coefficients
+		exponents[i] = make([]uint64, 0, 3*len(g.Exponents))
+		for _, e := range g.Exponents {
+			exponents[i] = append(exponents[i], uint64(e[0]), uint64(e[1]), uint64(e[2]))
+		}
+	}
+	return
+}
+
+// decodeCustomGates returns the gates encoded by encodeCustomGates.
+func decodeCustomGates(coefficients [][]fr.Element, exponents [][]uint64) ([]CustomGate, error) {
+	if len(coefficients) != len(exponents) {
+		return nil, errors.New("invalid custom gates")
+	}
+	res := make([]CustomGate, len(coefficients))
+	for i := range res {
+		if len(exponents[i]) != 3*len(coefficients[i]) {
+			return nil, errors.New("invalid custom gates")
+		}
+		res[i].Coefficients = coefficients[i]
+		res[i].Exponents = make([][3]uint8, len(coefficients[i]))
+		for j := range res[i].Exponents {
+			e := exponents[i][3*j : 3*j+3]
+			if e[0]+e[1]+e[2] > constraint.MaxCustomGateDegree {
+				return nil, errors.New("invalid degree of a custom gate")
+			}
+			res[i].Exponents[j] = [3]uint8{uint8(e[0]), uint8(e[1]), uint8(e[2])}
+		}
+	}
+	return res, nil
+}
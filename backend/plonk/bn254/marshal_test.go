@@ -89,6 +89,17 @@ func (vk *VerifyingKey) randomize() {
 	vk.Qo = randomG1Point()
 	vk.Qk = randomG1Point()
 	vk.Qcp = randomG1Points(rand.Intn(4)) //#nosec G404 weak rng is fine here
+	vk.Qg = randomG1Points(rand.Intn(4))  //#nosec G404 weak rng is fine here
+	vk.CustomGates = make([]CustomGate, len(vk.Qg))
+	for i := range vk.CustomGates {
+		vk.CustomGates[i].Coefficients = randomScalars(2)
+		vk.CustomGates[i].Exponents = [][3]uint8{
+			{1, 0, 1},
+			{0, 2, 0},
+		}
+	}
+	vk.Qw = randomG1Points(rand.Intn(3)) //#nosec G404 weak rng is fine here
+	vk.Sw = randomG1Points(len(vk.Qw))
 }
 
 func (proof *Proof) randomize() {
@@ -104,6 +115,8 @@ func (proof *Proof) randomize() {
 	proof.ZShiftedOpening.H = randomG1Point()
 	proof.ZShiftedOpening.ClaimedValue.SetRandom()
 	proof.Bsb22Commitments = randomG1Points(rand.Intn(4)) //#nosec G404 weak rng is fine here
+	proof.W = randomG1Points(rand.Intn(3))                //#nosec G404 weak rng is fine here
+	proof.HExtra = randomG1Points(len(proof.W))
 }
 
 func randomG2Point() curve.G2Affine {
@@ -65,6 +65,11 @@ contract PlonkVerifier {
   {{ end -}}
   uint256 private constant VK_NB_CUSTOM_GATES = {{ len .Vk.CommitmentConstraintIndexes }};
 
+  {{ range $index, $element := .Vk.Qg}}
+  uint256 private constant VK_QG_{{ $index }}_COM_X = {{ (fpstr $element.X) }};
+  uint256 private constant VK_QG_{{ $index }}_COM_Y = {{ (fpstr $element.Y) }};
+  {{ end }}
+
   // ------------------------------------------------
 
   // offset proof
@@ -76,13 +81,17 @@ contract PlonkVerifier {
   uint256 private constant PROOF_O_COM_X = {{ hex $offset }};{{ $offset = add $offset 0x20}}
   uint256 private constant PROOF_O_COM_Y = {{ hex $offset }};{{ $offset = add $offset 0x20}}
 
-  // h = h_0 + x^{n+2}h_1 + x^{2(n+2)}h_2
+  // h = h_0 + x^{n+2}h_1 + x^{2(n+2)}h_2 + ..
   uint256 private constant PROOF_H_0_X = {{ hex $offset }};{{ $offset = add $offset 0x20}}
   uint256 private constant PROOF_H_0_Y = {{ hex $offset }};{{ $offset = add $offset 0x20}}
   uint256 private constant PROOF_H_1_X = {{ hex $offset }};{{ $offset = add $offset 0x20}}
   uint256 private constant PROOF_H_1_Y = {{ hex $offset }};{{ $offset = add $offset 0x20}}
   uint256 private constant PROOF_H_2_X = {{ hex $offset }};{{ $offset = add $offset 0x20}}
   uint256 private constant PROOF_H_2_Y = {{ hex $offset }};{{ $offset = add $offset 0x20}}
+  {{- range $i := .HExtra }}
+  uint256 private constant PROOF_H_{{ $i }}_X = {{ hex $offset }};{{ $offset = add $offset 0x20}}
+  uint256 private constant PROOF_H_{{ $i }}_Y = {{ hex $offset }};{{ $offset = add $offset 0x20}}
+  {{- end }}
 
   // wire values at zeta
   uint256 private constant PROOF_L_AT_ZETA = {{ hex $offset }};{{ $offset = add $offset 0x20}}
@@ -319,7 +328,7 @@ contract PlonkVerifier {
       /// Checks if the proof is of the correct size
       /// @param actual_proof_size size of the proof (not the expected size)
       function check_proof_size(actual_proof_size) {
-        let expected_proof_size := add(0x300, mul(VK_NB_CUSTOM_GATES,0x60))
+        let expected_proof_size := add({{ hex (add 0x300 (mul (len .HExtra) 0x40)) }}, mul(VK_NB_CUSTOM_GATES,0x60))
         if iszero(eq(actual_proof_size, expected_proof_size)) {
          error_proof_size() 
         }
@@ -391,6 +400,7 @@ contract PlonkVerifier {
       /// * the word "gamma" in ascii, equal to [0x67,0x61,0x6d, 0x6d, 0x61] and encoded as a uint256.
       /// * the commitments to the permutation polynomials S1, S2, S3, where we concatenate the coordinates of those points
       /// * the commitments of Ql, Qr, Qm, Qo, Qk
+      /// * the commitments of the selectors Qcp, then of the selectors Qg of the custom gates
       /// * the public inputs
       /// * the commitments of the wires related to the custom gates (commitments_wires_commit_api)
       /// * commitments to L, R, O (proof_<l,r,o>_com_<x,y>)
@@ -428,8 +438,18 @@ contract PlonkVerifier {
         mstore(add(mPtr, {{ hex (add 544 (mul $index 64)) }}), VK_QCP_{{ $index }}_X)
         mstore(add(mPtr, {{ hex (add 576 (mul $index 64)) }}), VK_QCP_{{ $index }}_Y)
         {{ end }}
+        {{ range $index, $element := .Vk.Qg}}
+        mstore(add(mPtr, {{ hex (add (add 544 (mul (len $.Vk.CommitmentConstraintIndexes) 64)) (mul $index 64)) }}), VK_QG_{{ $index }}_COM_X)
+        mstore(add(mPtr, {{ hex (add (add 576 (mul (len $.Vk.CommitmentConstraintIndexes) 64)) (mul $index 64)) }}), VK_QG_{{ $index }}_COM_Y)
+        {{ end }}
+        {{- $terms := add (mul (add (len .Vk.CommitmentConstraintIndexes) (len .Vk.Qg)) 64) 544 }}
+        {{- range $index, $term := .GateTerms }}
+        // coefficient and exponents A + 2⁸B + 2¹⁶C of the term {{ $index }} of the custom gates
+        mstore(add(mPtr, {{ hex (add $terms (mul $index 64)) }}), {{ frstr (index $term 0) }})
+        mstore(add(mPtr, {{ hex (add $terms (add (mul $index 64) 32)) }}), {{ frstr (index $term 1) }})
+        {{- end }}
         // public inputs
-        let _mPtr := add(mPtr, {{ hex (add (mul (len .Vk.CommitmentConstraintIndexes) 64) 544) }})
+        let _mPtr := add(mPtr, {{ hex (add $terms (mul (len .GateTerms) 64)) }})
         let size_pi_in_bytes := mul(nb_pi, 0x20)
         calldatacopy(_mPtr, pi, size_pi_in_bytes)
         _mPtr := add(_mPtr, size_pi_in_bytes)
@@ -443,10 +463,18 @@ contract PlonkVerifier {
         // sizegamma(=0x5) + 11*64(=0x2c0)
         // + nb_public_inputs*0x20
         // + nb_custom gates*0x40
+        // + nb_gate_selectors*0x40
+        // + nb_gate_terms*0x40
         let size := add(0x2c5, size_pi_in_bytes)
         {{ if (gt (len .Vk.CommitmentConstraintIndexes) 0 )}}
         size := add(size, mul(VK_NB_CUSTOM_GATES, 0x40))
         {{ end -}}
+        {{ if (gt (len .Vk.Qg) 0 )}}
+        size := add(size, {{ hex (mul (len .Vk.Qg) 64) }})
+        {{ end -}}
+        {{ if (gt (len .GateTerms) 0 )}}
+        size := add(size, {{ hex (mul (len .GateTerms) 64) }})
+        {{ end -}}
         let l_success := staticcall(gas(), 0x2, add(mPtr, 0x1b), size, mPtr, 0x20) //0x1b -> 000.."gamma"
         if iszero(l_success) {
           error_verify()
@@ -526,8 +554,8 @@ contract PlonkVerifier {
         // zeta
         mstore(mPtr, FS_ZETA) // "zeta"
         mstore(add(mPtr, 0x20), alpha_not_reduced)
-        calldatacopy(add(mPtr, 0x40), add(aproof, PROOF_H_0_X), 0xc0)
-        let l_success := staticcall(gas(), 0x2, add(mPtr, 0x1c), 0xe4, mPtr, 0x20)
+        calldatacopy(add(mPtr, 0x40), add(aproof, PROOF_H_0_X), {{ hex (add 0xc0 (mul (len .HExtra) 0x40)) }})
+        let l_success := staticcall(gas(), 0x2, add(mPtr, 0x1c), {{ hex (add 0xe4 (mul (len .HExtra) 0x40)) }}, mPtr, 0x20)
         if iszero(l_success) {
           error_verify()
         }
@@ -1079,6 +1107,32 @@ contract PlonkVerifier {
         }
         {{ end }}
 
+        {{ if (gt (len .Vk.CustomGates) 0 )}}
+        {
+          let l := calldataload(add(aproof, PROOF_L_AT_ZETA))
+          let r := calldataload(add(aproof, PROOF_R_AT_ZETA))
+          let o := calldataload(add(aproof, PROOF_O_AT_ZETA))
+          let gate_at_zeta, term
+          {{ range $index, $gate := .Vk.CustomGates }}
+          // Gᵢ(L(ζ), R(ζ), O(ζ))[Qgᵢ], i = {{ $index }}
+          gate_at_zeta := 0
+          {{ range $j, $e := $gate.Exponents -}}
+          term := {{ frstr (index $gate.Coefficients $j) }}
+          {{ range repeat (index $e 0) }}term := mulmod(term, l, R_MOD)
+          {{ end -}}
+          {{ range repeat (index $e 1) }}term := mulmod(term, r, R_MOD)
+          {{ end -}}
+          {{ range repeat (index $e 2) }}term := mulmod(term, o, R_MOD)
+          {{ end -}}
+          gate_at_zeta := addmod(gate_at_zeta, term, R_MOD)
+          {{ end -}}
+          mstore(mPtr, VK_QG_{{ $index }}_COM_X)
+          mstore(add(mPtr, 0x20), VK_QG_{{ $index }}_COM_Y)
+          point_acc_mul(add(state, STATE_LINEARISED_POLYNOMIAL_X), mPtr, gate_at_zeta, add(mPtr, 0x40))
+          {{ end }}
+        }
+        {{ end }}
+
         mstore(mPtr, VK_S3_COM_X)
         mstore(add(mPtr, 0x20), VK_S3_COM_Y)
         point_acc_mul(add(state, STATE_LINEARISED_POLYNOMIAL_X), mPtr, s1, add(mPtr, 0x40))
@@ -1095,7 +1149,7 @@ contract PlonkVerifier {
       }
 
       /// @notice Compute the commitment to the linearized polynomial equal to
-      ///	L(ζ)[Qₗ]+r(ζ)[Qᵣ]+R(ζ)L(ζ)[Qₘ]+O(ζ)[Qₒ]+[Qₖ]+Σᵢqc'ᵢ(ζ)[BsbCommitmentᵢ] +
+      ///	L(ζ)[Qₗ]+r(ζ)[Qᵣ]+R(ζ)L(ζ)[Qₘ]+O(ζ)[Qₒ]+[Qₖ]+Σᵢqc'ᵢ(ζ)[BsbCommitmentᵢ]+Σⱼgateⱼ(L(ζ), R(ζ), O(ζ))[Qgⱼ] +
       ///	α*( Z(μζ)(L(ζ)+β*S₁(ζ)+γ)*(R(ζ)+β*S₂(ζ)+γ)[S₃]-[Z](L(ζ)+β*id_{1}(ζ)+γ)*(R(ζ)+β*id_{2}(ζ)+γ)*(O(ζ)+β*id_{3}(ζ)+γ) ) +
       ///	α²*L₁(ζ)[Z] - Z_{H}(ζ)*(([H₀] + ζᵐ⁺²*[H₁] + ζ²⁽ᵐ⁺²⁾*[H₂])
       /// where
@@ -1149,7 +1203,7 @@ contract PlonkVerifier {
         compute_commitment_linearised_polynomial_ec(aproof, s1, s2)
       }
 
-      /// @notice compute -z_h(ζ)*([H₁] + ζᵐ⁺²[H₂] + ζ²⁽ᵐ⁺²⁾[H₃]) and store the result at
+      /// @notice compute -z_h(ζ)*([H₁] + ζᵐ⁺²[H₂] + ζ²⁽ᵐ⁺²⁾[H₃] + ..) and store the result at
       /// state + state_folded_h
       /// @param aproof pointer to the proof
       function fold_h(aproof) {
@@ -1161,6 +1215,13 @@ contract PlonkVerifier {
         point_add_calldata(add(state, STATE_FOLDED_H_X), add(state, STATE_FOLDED_H_X), add(aproof, PROOF_H_1_X), mPtr)
         point_mul(add(state, STATE_FOLDED_H_X), add(state, STATE_FOLDED_H_X), zeta_power_n_plus_two, mPtr)
         point_add_calldata(add(state, STATE_FOLDED_H_X), add(state, STATE_FOLDED_H_X), add(aproof, PROOF_H_0_X), mPtr)
+        {{- if .HExtra }}
+        let zeta_power := mulmod(zeta_power_n_plus_two, zeta_power_n_plus_two, R_MOD)
+        {{- range $i := .HExtra }}
+        zeta_power := mulmod(zeta_power, zeta_power_n_plus_two, R_MOD)
+        point_acc_mul_calldata(add(state, STATE_FOLDED_H_X), add(aproof, PROOF_H_{{ $i }}_X), zeta_power, mPtr)
+        {{- end }}
+        {{- end }}
           point_mul(add(state, STATE_FOLDED_H_X), add(state, STATE_FOLDED_H_X), mload(add(state, STATE_ZETA_POWER_N_MINUS_ONE)), mPtr)
         let folded_h_y := mload(add(state, STATE_FOLDED_H_Y))
         folded_h_y := sub(P_MOD, folded_h_y)
@@ -1362,6 +1423,14 @@ func (proof *Proof) MarshalSolidity() []byte {
 		tmp64 = proof.H[i].RawBytes()
 		res = append(res, tmp64[:]...)
 	}
+
+	// uint256 h_3_x;
+	// uint256 h_3_y;
+	// ..
+	for i := range proof.HExtra {
+		tmp64 = proof.HExtra[i].RawBytes()
+		res = append(res, tmp64[:]...)
+	}
 	var tmp32 [32]byte
 
 	// uint256 l_at_zeta;
@@ -68,7 +68,7 @@ const (
 	id_S3
 	id_ID
 	id_LOne
-	id_Qci // [ .. , Qc_i, Pi_i, ...]
+	id_Qci // [ .. , Qc_i, Pi_i, ..., Qg_j, ..., W_k, Qw_k, Sw_k, ...]
 )
 
 // blinding factors
@@ -77,7 +77,7 @@ const (
 	id_Br
 	id_Bo
 	id_Bz
-	nb_blinding_polynomials
+	nb_blinding_polynomials // followed by the blinding polynomials of the advice wires
 )
 
 // blinding orders (-1 to deactivate)
@@ -86,6 +86,7 @@ const (
 	order_blinding_R = 1
 	order_blinding_O = 1
 	order_blinding_Z = 2
+	order_blinding_W = 1
 )
 
 type Proof struct {
@@ -99,9 +100,15 @@ type Proof struct {
 	// Commitments to h1, h2, h3 such that h = h1 + Xⁿ⁺²*h2 + X²⁽ⁿ⁺²⁾*h3 is the quotient polynomial
 	H [3]kzg.Digest
 
+	// Commitments to the solution vectors of the advice wires, for a
+	// constraint system with more than 3 wires, and to the additional chunks
+	// h4, .. of the quotient polynomial, for more than 3 wires or custom gates
+	// of degree more than 3.
+	W, HExtra []kzg.Digest
+
 	Bsb22Commitments []kzg.Digest
 
-	// Batch opening proof of linearizedPolynomial, l, r, o, s1, s2, qCPrime
+	// Batch opening proof of linearizedPolynomial, l, r, o, s1, s2, qCPrime, w, sw
 	BatchedProof kzg.BatchOpeningProof
 
 	// Opening proof of Z at zeta*mu
@@ -109,6 +116,13 @@ type Proof struct {
 }
 
 func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (*Proof, error) {
+	return prove(spr, pk, preprocess(spr), fullWitness, opts...)
+}
+
+// prove generates the proof with the preprocessed data of the constraint
+// system. The prover modifies the trace in Lagrange form, and only reads it in
+// canonical form, with its evaluations on the cosets.
+func prove(spr *cs.SparseR1CS, pk *ProvingKey, pre preprocessed, fullWitness witness.Witness, opts ...backend.ProverOption) (*Proof, error) {
 
 	log := logger.Logger().With().
 		Str("curve", spr.CurveID().String()).
@@ -124,8 +138,8 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness witness.Witness, opts
 	start := time.Now()
 
 	// init instance
-	g, ctx := errgroup.WithContext(context.Background())
-	instance, err := newInstance(ctx, spr, pk, fullWitness, &opt)
+	g, ctx := errgroup.WithContext(opt.Context)
+	instance, err := newInstance(ctx, spr, pk, pre, fullWitness, &opt)
 	if err != nil {
 		return nil, fmt.Errorf("new instance: %w", err)
 	}
@@ -158,6 +172,10 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness witness.Witness, opts
 	g.Go(instance.batchOpening)
 
 	if err := g.Wait(); err != nil {
+		if ctxErr := opt.Context.Err(); ctxErr != nil {
+			// the tasks return errContextDone once the context is done
+			return nil, ctxErr
+		}
 		return nil, err
 	}
 
@@ -210,10 +228,12 @@ type instance struct {
 
 	domain0, domain1 *fft.Domain
 
-	trace *Trace
+	trace  *Trace
+	qk     *iop.Polynomial     // canonical qk, nil if the trace is in Lagrange form
+	cosets [][]*iop.Polynomial // evaluations of the selectors on the cosets, or nil
 }
 
-func newInstance(ctx context.Context, spr *cs.SparseR1CS, pk *ProvingKey, fullWitness witness.Witness, opts *backend.ProverConfig) (*instance, error) {
+func newInstance(ctx context.Context, spr *cs.SparseR1CS, pk *ProvingKey, pre preprocessed, fullWitness witness.Witness, opts *backend.ProverConfig) (*instance, error) {
 	if opts.HashToFieldFn == nil {
 		opts.HashToFieldFn = hash_to_field.New([]byte("BSB22-Plonk"))
 	}
@@ -224,7 +244,7 @@ func newInstance(ctx context.Context, spr *cs.SparseR1CS, pk *ProvingKey, fullWi
 		spr:                    spr,
 		opt:                    opts,
 		fullWitness:            fullWitness,
-		bp:                     make([]*iop.Polynomial, nb_blinding_polynomials),
+		bp:                     make([]*iop.Polynomial, nb_blinding_polynomials+len(pre.trace.Qw)),
 		fs:                     fiatshamir.NewTranscript(opts.ChallengeHash, "gamma", "beta", "alpha", "zeta"),
 		kzgFoldingHash:         opts.KZGFoldingHash,
 		htfFunc:                opts.HashToFieldFn,
@@ -237,35 +257,55 @@ func newInstance(ctx context.Context, spr *cs.SparseR1CS, pk *ProvingKey, fullWi
 		chZOpening:             make(chan struct{}, 1),
 		chLinearizedPolynomial: make(chan struct{}, 1),
 		chRestoreLRO:           make(chan struct{}, 1),
+		domain0:                pre.domain0,
+		domain1:                pre.domain1,
+		trace:                  pre.trace,
+		qk:                     pre.qk,
+		cosets:                 pre.cosets,
 	}
 	s.initBSB22Commitments()
-	s.x = make([]*iop.Polynomial, id_Qci+2*len(s.commitmentInfo))
-
-	// init fft domains
-	nbConstraints := spr.GetNbConstraints()
-	sizeSystem := uint64(nbConstraints + len(spr.Public)) // len(spr.Public) is for the placeholder constraints
-	s.domain0 = fft.NewDomain(sizeSystem)
-
-	// h, the quotient polynomial is of degree 3(n+1)+2, so it's in a 3(n+2) dim vector space,
-	// the domain is the next power of 2 superior to 3(n+2). 4*domainNum is enough in all cases
-	// except when n<6.
-	if sizeSystem < 6 {
-		s.domain1 = fft.NewDomain(8*sizeSystem, fft.WithoutPrecompute())
-	} else {
-		s.domain1 = fft.NewDomain(4*sizeSystem, fft.WithoutPrecompute())
-	}
-
-	// build trace
-	s.trace = NewTrace(spr, s.domain0)
+	s.proof.W = make([]kzg.Digest, len(s.trace.Qw))
+	s.proof.HExtra = make([]kzg.Digest, pk.Vk.nbQuotientChunks()-3)
+	s.x = make([]*iop.Polynomial, s.idW(len(s.trace.Qw)))
 
 	return &s, nil
 }
 
+// idQg returns the index in x of the selector of the i-th custom gate.
+func (s *instance) idQg(i int) int {
+	return id_Qci + 2*len(s.commitmentInfo) + i
+}
+
+// idW returns the index in x of the i-th advice wire. It is followed by its
+// selector and its permutation polynomial.
+func (s *instance) idW(i int) int {
+	return s.idQg(len(s.trace.Qg)) + 3*i
+}
+
+// traceIDs returns the indexes in x of the selectors and the permutation, in
+// the order of (*Trace).selectors.
+func (s *instance) traceIDs() []int {
+	res := []int{id_Ql, id_Qr, id_Qm, id_Qo, id_S1, id_S2, id_S3}
+	for i := range s.trace.Qcp {
+		res = append(res, id_Qci+2*i)
+	}
+	for i := range s.trace.Qg {
+		res = append(res, s.idQg(i))
+	}
+	for i := range s.trace.Qw {
+		res = append(res, s.idW(i)+1, s.idW(i)+2)
+	}
+	return res
+}
+
 func (s *instance) initBlindingPolynomials() error {
 	s.bp[id_Bl] = getRandomPolynomial(order_blinding_L)
 	s.bp[id_Br] = getRandomPolynomial(order_blinding_R)
 	s.bp[id_Bo] = getRandomPolynomial(order_blinding_O)
 	s.bp[id_Bz] = getRandomPolynomial(order_blinding_Z)
+	for i := nb_blinding_polynomials; i < len(s.bp); i++ {
+		s.bp[i] = getRandomPolynomial(order_blinding_W)
+	}
 	close(s.chbp)
 	return nil
 }
@@ -319,10 +359,14 @@ func (s *instance) bsb22Hint(_ *big.Int, ins, outs []*big.Int) error {
 	return nil
 }
 
-// solveConstraints computes the evaluation of the polynomials L, R, O
-// and sets x[id_L], x[id_R], x[id_O] in canonical form
+// solveConstraints computes the evaluation of the polynomials L, R, O and of
+// the advice wires and sets x[id_L], x[id_R], x[id_O], x[idW(i)] in Lagrange
+// form
 func (s *instance) solveConstraints() error {
-	_solution, err := s.spr.Solve(s.fullWitness, s.opt.SolverOpts...)
+	endSolve := s.opt.Progress.Start(backend.PhaseSolve)
+	solverOpts := append(s.opt.SolverOpts, solver.WithContext(s.ctx))
+	_solution, err := s.spr.Solve(s.fullWitness, solverOpts...)
+	endSolve()
 	if err != nil {
 		return err
 	}
@@ -342,6 +386,10 @@ func (s *instance) solveConstraints() error {
 	}()
 
 	s.x[id_O] = iop.NewPolynomial(&evaluationODomainSmall, iop.Form{Basis: iop.Lagrange, Layout: iop.Regular})
+	for i := range solution.Extra {
+		evaluationWDomainSmall := []fr.Element(solution.Extra[i])
+		s.x[s.idW(i)] = iop.NewPolynomial(&evaluationWDomainSmall, iop.Form{Basis: iop.Lagrange, Layout: iop.Regular})
+	}
 
 	wg.Wait()
 
@@ -388,6 +436,7 @@ func (s *instance) commitToLRO() error {
 		return errContextDone
 	case <-s.chbp:
 	}
+	defer s.opt.Progress.Start(backend.PhaseCommit)()
 
 	g := new(errgroup.Group)
 
@@ -406,6 +455,14 @@ func (s *instance) commitToLRO() error {
 		return
 	})
 
+	for i := range s.proof.W {
+		i := i
+		g.Go(func() (err error) {
+			s.proof.W[i], err = s.commitToPolyAndBlinding(s.x[s.idW(i)], s.bp[nb_blinding_polynomials+i])
+			return
+		})
+	}
+
 	return g.Wait()
 }
 
@@ -427,7 +484,7 @@ func (s *instance) deriveGammaAndBeta() error {
 	case <-s.chLRO:
 	}
 
-	gamma, err := deriveRandomness(s.fs, "gamma", &s.proof.LRO[0], &s.proof.LRO[1], &s.proof.LRO[2])
+	gamma, err := deriveRandomness(s.fs, "gamma", wireCommitments(s.proof)...)
 	if err != nil {
 		return err
 	}
@@ -471,7 +528,7 @@ func (s *instance) deriveAlpha() (err error) {
 }
 
 func (s *instance) deriveZeta() (err error) {
-	s.zeta, err = deriveRandomness(s.fs, "zeta", &s.proof.H[0], &s.proof.H[1], &s.proof.H[2])
+	s.zeta, err = deriveRandomness(s.fs, "zeta", quotientCommitments(s.proof)...)
 	return
 }
 
@@ -488,6 +545,13 @@ func (s *instance) computeQuotient() (err error) {
 	for i := 0; i < len(s.commitmentInfo); i++ {
 		s.x[id_Qci+2*i] = s.trace.Qcp[i]
 	}
+	for i := range s.trace.Qg {
+		s.x[s.idQg(i)] = s.trace.Qg[i]
+	}
+	for i := range s.trace.Qw {
+		s.x[s.idW(i)+1] = s.trace.Qw[i]
+		s.x[s.idW(i)+2] = s.trace.Sw[i]
+	}
 
 	n := s.domain0.Cardinality
 	lone := make([]fr.Element, n)
@@ -515,6 +579,7 @@ func (s *instance) computeQuotient() (err error) {
 	if err = s.deriveAlpha(); err != nil {
 		return err
 	}
+	defer s.opt.Progress.Start(backend.PhaseQuotient)()
 
 	// TODO complete waste of memory find another way to do that
 	identity := make([]fr.Element, n)
@@ -533,9 +598,12 @@ func (s *instance) computeQuotient() (err error) {
 	if err != nil {
 		return err
 	}
+	if s.ctx.Err() != nil {
+		return errContextDone
+	}
 
 	// commit to h
-	if err := commitToQuotient(s.h1(), s.h2(), s.h3(), s.proof, s.pk.Kzg); err != nil {
+	if err := commitToQuotient(s.h1(), s.h2(), s.h3(), s.hExtra(), s.proof, s.pk.Kzg); err != nil {
 		return err
 	}
 
@@ -565,12 +633,16 @@ func (s *instance) buildRatioCopyConstraint() (err error) {
 
 	// TODO @gbotrel having iop.BuildRatioCopyConstraint return something
 	// with capacity = len() + 4 would avoid extra alloc / copy during openZ
+	entries := []*iop.Polynomial{
+		s.x[id_L],
+		s.x[id_R],
+		s.x[id_O],
+	}
+	for i := range s.trace.Qw {
+		entries = append(entries, s.x[s.idW(i)])
+	}
 	s.x[id_Z], err = iop.BuildRatioCopyConstraint(
-		[]*iop.Polynomial{
-			s.x[id_L],
-			s.x[id_R],
-			s.x[id_O],
-		},
+		entries,
 		s.trace.S,
 		s.beta,
 		s.gamma,
@@ -624,6 +696,17 @@ func (s *instance) h3() []fr.Element {
 	return h3
 }
 
+// hExtra returns the chunks h4, .. of the quotient for the advice wires and
+// the custom gates of higher degree.
+func (s *instance) hExtra() [][]fr.Element {
+	n := s.domain0.Cardinality + 2
+	res := make([][]fr.Element, len(s.proof.HExtra))
+	for i := range res {
+		res[i] = s.h.Coefficients()[uint64(3+i)*n : uint64(4+i)*n]
+	}
+	return res
+}
+
 func (s *instance) computeLinearizedPolynomial() error {
 
 	// wait for H to be committed and zeta to be derived (or ctx.Done())
@@ -634,9 +717,17 @@ func (s *instance) computeLinearizedPolynomial() error {
 	}
 
 	qcpzeta := make([]fr.Element, len(s.commitmentInfo))
+	bwzeta := make([]fr.Element, len(s.trace.Qw))
 	var blzeta, brzeta, bozeta fr.Element
 	var wg sync.WaitGroup
-	wg.Add(3 + len(s.commitmentInfo))
+	wg.Add(3 + len(s.commitmentInfo) + len(bwzeta))
+
+	for i := range bwzeta {
+		go func(i int) {
+			bwzeta[i] = evaluateBlinded(s.x[s.idW(i)], s.bp[nb_blinding_polynomials+i], s.zeta)
+			wg.Done()
+		}(i)
+	}
 
 	for i := 0; i < len(s.commitmentInfo); i++ {
 		go func(i int) {
@@ -680,6 +771,7 @@ func (s *instance) computeLinearizedPolynomial() error {
 		s.zeta,
 		bzuzeta,
 		qcpzeta,
+		bwzeta,
 		s.blindedZ,
 		coefficients(s.cCommitments),
 		s.pk,
@@ -709,10 +801,15 @@ func (s *instance) batchOpening() error {
 		return errContextDone
 	case <-s.chLinearizedPolynomial:
 	}
+	defer s.opt.Progress.Start(backend.PhaseOpening)()
 
 	polysQcp := coefficients(s.trace.Qcp)
-	polysToOpen := make([][]fr.Element, 6+len(polysQcp))
+	polysToOpen := make([][]fr.Element, 6+len(polysQcp), 6+len(polysQcp)+2*len(s.trace.Qw))
 	copy(polysToOpen[6:], polysQcp)
+	for i := range s.trace.Qw {
+		polysToOpen = append(polysToOpen, getBlindedCoefficients(s.x[s.idW(i)], s.bp[nb_blinding_polynomials+i]))
+	}
+	polysToOpen = append(polysToOpen, coefficients(s.trace.Sw)...)
 
 	polysToOpen[0] = s.linearizedPolynomial
 	polysToOpen[1] = getBlindedCoefficients(s.x[id_L], s.bp[id_Bl])
@@ -721,8 +818,10 @@ func (s *instance) batchOpening() error {
 	polysToOpen[4] = s.trace.S1.Coefficients()
 	polysToOpen[5] = s.trace.S2.Coefficients()
 
-	digestsToOpen := make([]curve.G1Affine, len(s.pk.Vk.Qcp)+6)
+	digestsToOpen := make([]curve.G1Affine, len(s.pk.Vk.Qcp)+6, len(s.pk.Vk.Qcp)+6+2*len(s.proof.W))
 	copy(digestsToOpen[6:], s.pk.Vk.Qcp)
+	digestsToOpen = append(digestsToOpen, s.proof.W...)
+	digestsToOpen = append(digestsToOpen, s.pk.Vk.Sw...)
 
 	digestsToOpen[0] = s.linearizedPolynomialDigest
 	digestsToOpen[1] = s.proof.LRO[0]
@@ -772,7 +871,11 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 	case <-s.chQk:
 	}
 
-	nbBsbGates := (len(s.x) - id_Qci + 1) >> 1
+	nbBsbGates := len(s.commitmentInfo)
+	customGates := s.pk.Vk.CustomGates
+	idQg := s.idQg(0)
+	nbAdvice := len(s.trace.Qw)
+	idW := s.idW(0)
 
 	gateConstraint := func(u ...fr.Element) fr.Element {
 
@@ -789,6 +892,15 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 			tmp.Mul(&u[id_Qci+2*i], &u[id_Qci+2*i+1])
 			ic.Add(&ic, &tmp)
 		}
+		for i := range customGates {
+			tmp = customGates[i].evaluate(u[id_L], u[id_R], u[id_O])
+			tmp.Mul(&tmp, &u[idQg+i])
+			ic.Add(&ic, &tmp)
+		}
+		for i := 0; i < nbAdvice; i++ {
+			tmp.Mul(&u[idW+3*i+1], &u[idW+3*i])
+			ic.Add(&ic, &tmp)
+		}
 
 		return ic
 	}
@@ -797,6 +909,16 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 	cs.Set(&s.domain1.FrMultiplicativeGen)
 	css.Square(&cs)
 
+	// u³, u⁴, .. shift the advice wires in the identity permutation
+	csw := make([]fr.Element, nbAdvice)
+	for i := range csw {
+		if i == 0 {
+			csw[i].Mul(&css, &cs)
+		} else {
+			csw[i].Mul(&csw[i-1], &cs)
+		}
+	}
+
 	orderingConstraint := func(u ...fr.Element) fr.Element {
 		gamma := s.gamma
 
@@ -812,6 +934,13 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 		c.Add(&u[id_S3], &u[id_O]).Add(&c, &gamma)
 		l.Mul(&a, &b).Mul(&l, &c).Mul(&l, &u[id_ZS])
 
+		for i := 0; i < nbAdvice; i++ {
+			a.Mul(&u[id_ID], &csw[i]).Add(&a, &u[idW+3*i]).Add(&a, &gamma)
+			r.Mul(&r, &a)
+			a.Add(&u[idW+3*i+2], &u[idW+3*i]).Add(&a, &gamma)
+			l.Mul(&l, &a)
+		}
+
 		l.Sub(&l, &r)
 
 		return l
@@ -852,10 +981,13 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 	var wgBuf sync.WaitGroup
 
 	allConstraints := func(i int, u ...fr.Element) fr.Element {
-		// scale S1, S2, S3 by β
+		// scale S1, S2, S3, Sw by β
 		u[id_S1].Mul(&u[id_S1], &s.beta)
 		u[id_S2].Mul(&u[id_S2], &s.beta)
 		u[id_S3].Mul(&u[id_S3], &s.beta)
+		for j := 0; j < nbAdvice; j++ {
+			u[idW+3*j+2].Mul(&u[idW+3*j+2], &s.beta)
+		}
 
 		// blind L, R, O, Z, ZS
 		var y fr.Element
@@ -867,6 +999,10 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 		u[id_O].Add(&u[id_O], &y)
 		y = s.bp[id_Bz].Evaluate(twiddles0[i])
 		u[id_Z].Add(&u[id_Z], &y)
+		for j := 0; j < nbAdvice; j++ {
+			y = s.bp[nb_blinding_polynomials+j].Evaluate(twiddles0[i])
+			u[idW+3*j].Add(&u[idW+3*j], &y)
+		}
 
 		// ZS is shifted by 1; need to get correct twiddle
 		y = s.bp[id_Bz].Evaluate(twiddles0[(i+1)%int(n)])
@@ -890,7 +1026,24 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 	m := uint64(s.domain1.Cardinality)
 	mm := uint64(64 - bits.TrailingZeros64(m))
 
+	// the polynomials are moved from coset to coset, but the selectors and
+	// the permutation if their evaluations on the cosets are precomputed
+	traceIDs := s.traceIDs()
+	moved := make([]*iop.Polynomial, len(s.x))
+	copy(moved, s.x)
+	if s.cosets != nil {
+		for _, id := range traceIDs {
+			moved[id] = nil
+		}
+	}
+	x := make([]*iop.Polynomial, len(s.x))
+	copy(x, s.x)
+
 	for i := 0; i < rho; i++ {
+		if s.ctx.Err() != nil {
+			wgBuf.Wait()
+			return nil, errContextDone
+		}
 
 		coset.Mul(&coset, &shifters[i])
 		tmp.Exp(coset, bn).Sub(&tmp, &one)
@@ -918,10 +1071,10 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 
 		// we do **a lot** of FFT here, but on the small domain.
 		// note that for all the polynomials in the proving key
-		// (Ql, Qr, Qm, Qo, S1, S2, S3, Qcp, Qc) and ID, LOne
-		// we could pre-compute theses rho*2 FFTs and store them
+		// (Ql, Qr, Qm, Qo, S1, S2, S3, Qcp, Qc, Qg, Qw, Sw)
+		// a PreparedProver pre-computes theses rho*2 FFTs and stores them
 		// at the cost of a huge memory footprint.
-		batchApply(s.x, func(p *iop.Polynomial) {
+		batchApply(moved, func(p *iop.Polynomial) {
 			nbTasks := calculateNbTasks(len(s.x)-1) * 2
 			// shift polynomials to be in the correct coset
 			p.ToCanonical(s.domain0, nbTasks)
@@ -945,12 +1098,18 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 			p.ToLagrange(s.domain0, nbTasks).ToRegular()
 		})
 
+		if s.cosets != nil {
+			for j, id := range traceIDs {
+				x[id] = s.cosets[i][j]
+			}
+		}
+
 		wgBuf.Wait()
 		if _, err := iop.Evaluate(
 			allConstraints,
 			buf,
 			iop.Form{Basis: iop.Lagrange, Layout: iop.Regular},
-			s.x...,
+			x...,
 		); err != nil {
 			return nil, err
 		}
@@ -975,10 +1134,9 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 
 	// scale everything back
 	go func() {
-		s.x[id_ID] = nil
-		s.x[id_LOne] = nil
-		s.x[id_ZS] = nil
-		s.x[id_Qk] = nil
+		for _, id := range []int{id_ID, id_LOne, id_ZS, id_Qk} {
+			s.x[id], moved[id] = nil, nil
+		}
 
 		var cs fr.Element
 		cs.Set(&shifters[0])
@@ -987,10 +1145,7 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 		}
 		cs.Inverse(&cs)
 
-		batchApply(s.x, func(p *iop.Polynomial) {
-			if p == nil {
-				return
-			}
+		batchApply(moved, func(p *iop.Polynomial) {
 			p.ToCanonical(s.domain0, 8).ToRegular()
 			scalePowers(p, cs)
 		})
@@ -1020,11 +1175,12 @@ func calculateNbTasks(n int) int {
 	return nbTasks
 }
 
-// batchApply executes fn on all polynomials in x except x[id_ZS] in parallel.
+// batchApply executes fn on all non nil polynomials in x except x[id_ZS] in
+// parallel.
 func batchApply(x []*iop.Polynomial, fn func(*iop.Polynomial)) {
 	var wg sync.WaitGroup
 	for i := 0; i < len(x); i++ {
-		if i == id_ZS {
+		if i == id_ZS || x[i] == nil {
 			continue
 		}
 		wg.Add(1)
@@ -1129,7 +1285,7 @@ func coefficients(p []*iop.Polynomial) [][]fr.Element {
 	return res
 }
 
-func commitToQuotient(h1, h2, h3 []fr.Element, proof *Proof, kzgPk kzg.ProvingKey) error {
+func commitToQuotient(h1, h2, h3 []fr.Element, hExtra [][]fr.Element, proof *Proof, kzgPk kzg.ProvingKey) error {
 	g := new(errgroup.Group)
 
 	g.Go(func() (err error) {
@@ -1147,6 +1303,14 @@ func commitToQuotient(h1, h2, h3 []fr.Element, proof *Proof, kzgPk kzg.ProvingKe
 		return
 	})
 
+	for i := range hExtra {
+		i := i
+		g.Go(func() (err error) {
+			proof.HExtra[i], err = kzg.Commit(hExtra[i], kzgPk)
+			return
+		})
+	}
+
 	return g.Wait()
 }
 
@@ -1211,28 +1375,38 @@ func evaluateXnMinusOneDomainBigCoset(domains [2]*fft.Domain) []fr.Element {
 // innerComputeLinearizedPoly computes the linearized polynomial in canonical basis.
 // The purpose is to commit and open all in one ql, qr, qm, qo, qk.
 // * lZeta, rZeta, oZeta are the evaluation of l, r, o at zeta
+// * wZeta are the evaluations of the advice wires at zeta
 // * z is the permutation polynomial, zu is Z(μX), the shifted version of Z
-// * pk is the proving key: the linearized polynomial is a linear combination of ql, qr, qm, qo, qk.
+// * pk is the proving key: the linearized polynomial is a linear combination of ql, qr, qm, qo, qk, qg, qw.
 //
 // The Linearized polynomial is:
 //
 // α²*L₁(ζ)*Z(X)
-// + α*( (l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*(β*s3(X))*Z(μζ) - Z(X)*(l(ζ)+β*id1(ζ)+γ)*(r(ζ)+β*id2(ζ)+γ)*(o(ζ)+β*id3(ζ)+γ))
-// + l(ζ)*Ql(X) + l(ζ)r(ζ)*Qm(X) + r(ζ)*Qr(X) + o(ζ)*Qo(X) + Qk(X) + ∑ᵢQcp_(ζ)Pi_(X)
-// - Z_{H}(ζ)*((H₀(X) + ζᵐ⁺²*H₁(X) + ζ²⁽ᵐ⁺²⁾*H₂(X))
-func (s *instance) innerComputeLinearizedPoly(lZeta, rZeta, oZeta, alpha, beta, gamma, zeta, zu fr.Element, qcpZeta, blindedZCanonical []fr.Element, pi2Canonical [][]fr.Element, pk *ProvingKey) []fr.Element {
+// + α*( (l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*∏ₖ(wₖ(ζ)+β*swₖ(ζ)+γ)*(β*s3(X))*Z(μζ) - Z(X)*(l(ζ)+β*id1(ζ)+γ)*(r(ζ)+β*id2(ζ)+γ)*(o(ζ)+β*id3(ζ)+γ)*∏ₖ(wₖ(ζ)+β*idₖ(ζ)+γ))
+// + l(ζ)*Ql(X) + l(ζ)r(ζ)*Qm(X) + r(ζ)*Qr(X) + o(ζ)*Qo(X) + Qk(X) + ∑ᵢQcp_(ζ)Pi_(X) + ∑ⱼGⱼ(l(ζ), r(ζ), o(ζ))Qgⱼ(X) + ∑ₖwₖ(ζ)Qwₖ(X)
+// - Z_{H}(ζ)*((H₀(X) + ζᵐ⁺²*H₁(X) + ζ²⁽ᵐ⁺²⁾*H₂(X) + ..)
+// where the Gⱼ are the custom gates and the wₖ are the advice wires.
+func (s *instance) innerComputeLinearizedPoly(lZeta, rZeta, oZeta, alpha, beta, gamma, zeta, zu fr.Element, qcpZeta, wZeta, blindedZCanonical []fr.Element, pi2Canonical [][]fr.Element, pk *ProvingKey) []fr.Element {
 
 	// l(ζ)r(ζ)
 	var rl fr.Element
 	rl.Mul(&rZeta, &lZeta)
 
+	// Gⱼ(l(ζ), r(ζ), o(ζ))
+	gZeta := make([]fr.Element, len(pk.Vk.CustomGates))
+	for j := range gZeta {
+		gZeta[j] = pk.Vk.CustomGates[j].evaluate(lZeta, rZeta, oZeta)
+	}
+
 	// s1 =  α*(l(ζ)+β*s1(β)+γ)*(r(ζ)+β*s2(β)+γ)*β*Z(μζ)
 	// s2 = -α*(l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ)
+	// , multiplied respectively by ∏ₖ(wₖ(ζ)+β*swₖ(ζ)+γ) and ∏ₖ(wₖ(ζ)+β*uᵏ⁺³*ζ+γ)
+	// for the advice wires.
 	// the linearised polynomial is
 	// α²*L₁(ζ)*Z(X) +
 	// s1*s3(X)+s2*Z(X) + l(ζ)*Ql(X) +
-	// l(ζ)r(ζ)*Qm(X) + r(ζ)*Qr(X) + o(ζ)*Qo(X) + Qk(X) + ∑ᵢQcp_(ζ)Pi_(X) -
-	// Z_{H}(ζ)*((H₀(X) + ζᵐ⁺²*H₁(X) + ζ²⁽ᵐ⁺²⁾*H₂(X))
+	// l(ζ)r(ζ)*Qm(X) + r(ζ)*Qr(X) + o(ζ)*Qo(X) + Qk(X) + ∑ᵢQcp_(ζ)Pi_(X) +
+	// ∑ⱼGⱼ(l(ζ), r(ζ), o(ζ))Qgⱼ(X) + ∑ₖwₖ(ζ)Qwₖ(X) - Z_{H}(ζ)*((H₀(X) + ζᵐ⁺²*H₁(X) + ζ²⁽ᵐ⁺²⁾*H₂(X) + ..)
 	var s1, s2 fr.Element
 	chS1 := make(chan struct{}, 1)
 	go func() {
@@ -1245,6 +1419,11 @@ func (s *instance) innerComputeLinearizedPoly(lZeta, rZeta, oZeta, alpha, beta,
 	tmp.Mul(&tmp, &beta).Add(&tmp, &rZeta).Add(&tmp, &gamma) // (r(ζ)+β*s2(ζ)+γ)
 	<-chS1
 	s1.Mul(&s1, &tmp).Mul(&s1, &zu).Mul(&s1, &beta).Mul(&s1, &alpha) // (l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*β*Z(μζ)*α
+	for k := range wZeta {
+		tmp = s.trace.Sw[k].Evaluate(zeta)                          // swₖ(ζ)
+		tmp.Mul(&tmp, &beta).Add(&tmp, &wZeta[k]).Add(&tmp, &gamma) // (wₖ(ζ)+β*swₖ(ζ)+γ)
+		s1.Mul(&s1, &tmp)
+	}
 
 	var uzeta, uuzeta fr.Element
 	uzeta.Mul(&zeta, &pk.Vk.CosetShift)
@@ -1255,6 +1434,11 @@ func (s *instance) innerComputeLinearizedPoly(lZeta, rZeta, oZeta, alpha, beta,
 	s2.Mul(&s2, &tmp)                                           // (l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)
 	tmp.Mul(&beta, &uuzeta).Add(&tmp, &oZeta).Add(&tmp, &gamma) // (o(ζ)+β*u²*ζ+γ)
 	s2.Mul(&s2, &tmp)                                           // (l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ)
+	for k := range wZeta {
+		uuzeta.Mul(&uuzeta, &pk.Vk.CosetShift)                         // uᵏ⁺³*ζ
+		tmp.Mul(&beta, &uuzeta).Add(&tmp, &wZeta[k]).Add(&tmp, &gamma) // (wₖ(ζ)+β*uᵏ⁺³*ζ+γ)
+		s2.Mul(&s2, &tmp)
+	}
 	s2.Neg(&s2).Mul(&s2, &alpha)
 
 	// Z_h(ζ), ζⁿ⁺², L₁(ζ)*α²*Z
@@ -1274,12 +1458,16 @@ func (s *instance) innerComputeLinearizedPoly(lZeta, rZeta, oZeta, alpha, beta,
 
 	s3canonical := s.trace.S3.Coefficients()
 
-	s.trace.Qk.ToCanonical(s.domain0).ToRegular()
+	qk := s.qk
+	if qk == nil {
+		qk = s.trace.Qk.ToCanonical(s.domain0).ToRegular()
+	}
 
 	// the hi are all of the same length
 	h1 := s.h1()
 	h2 := s.h2()
 	h3 := s.h3()
+	hExtra := s.hExtra()
 
 	// at this stage we have
 	// s1 =  α*(l(ζ)+β*s1(β)+γ)*(r(ζ)+β*s2(β)+γ)*β*Z(μζ)
@@ -1290,7 +1478,9 @@ func (s *instance) innerComputeLinearizedPoly(lZeta, rZeta, oZeta, alpha, beta,
 		cqr := s.trace.Qr.Coefficients()
 		cqm := s.trace.Qm.Coefficients()
 		cqo := s.trace.Qo.Coefficients()
-		cqk := s.trace.Qk.Coefficients()
+		cqk := qk.Coefficients()
+		cqg := coefficients(s.trace.Qg)
+		cqw := coefficients(s.trace.Qw)
 
 		var t, t0, t1 fr.Element
 
@@ -1314,13 +1504,26 @@ func (s *instance) innerComputeLinearizedPoly(lZeta, rZeta, oZeta, alpha, beta,
 					t0.Mul(&pi2Canonical[j][i], &qcpZeta[j])
 					t.Add(&t, &t0)
 				}
+				for j := range gZeta { // linPol += ∑ⱼGⱼ(l(ζ), r(ζ), o(ζ))Qgⱼ(X)
+					t0.Mul(&cqg[j][i], &gZeta[j])
+					t.Add(&t, &t0)
+				}
+				for k := range wZeta { // linPol += ∑ₖwₖ(ζ)Qwₖ(X)
+					t0.Mul(&cqw[k][i], &wZeta[k])
+					t.Add(&t, &t0)
+				}
 			}
 
 			t0.Mul(&blindedZCanonical[i], &alphaSquareLagrangeOne) // α²L₁(ζ)Z(X)
 			blindedZCanonical[i].Add(&t, &t0)                      // linPol += α²L₁(ζ)Z(X)
 
 			if i < len(h1) {
-				t.Mul(&h3[i], &zetaNPlusTwo).
+				t.SetZero()
+				for k := len(hExtra) - 1; k >= 0; k-- {
+					t.Add(&t, &hExtra[k][i]).Mul(&t, &zetaNPlusTwo)
+				}
+				t.Add(&t, &h3[i]).
+					Mul(&t, &zetaNPlusTwo).
 					Add(&t, &h2[i]).
 					Mul(&t, &zetaNPlusTwo).
 					Add(&t, &h1[i])
@@ -20,6 +20,7 @@
 package plonk
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -102,23 +103,26 @@ type VerifyingKey interface {
 // The kzg SRS must be provided in canonical and lagrange form.
 // For test purposes, see test/unsafekzg package. With an existing SRS generated through MPC in canonical form,
 // gnark-crypto offers the ToLagrangeG1 method to convert it to lagrange form.
-func Setup(ccs constraint.ConstraintSystem, srs, srsLagrange kzg.SRS) (ProvingKey, VerifyingKey, error) {
+//
+// The context and the progress callback of the setup can be set with
+// [backend.WithSetupContext] and [backend.WithSetupProgress].
+func Setup(ccs constraint.ConstraintSystem, srs, srsLagrange kzg.SRS, opts ...backend.SetupOption) (ProvingKey, VerifyingKey, error) {
 
 	switch tccs := ccs.(type) {
 	case *cs_bn254.SparseR1CS:
-		return plonk_bn254.Setup(tccs, *srs.(*kzg_bn254.SRS), *srsLagrange.(*kzg_bn254.SRS))
+		return plonk_bn254.Setup(tccs, *srs.(*kzg_bn254.SRS), *srsLagrange.(*kzg_bn254.SRS), opts...)
 	case *cs_bls12381.SparseR1CS:
-		return plonk_bls12381.Setup(tccs, *srs.(*kzg_bls12381.SRS), *srsLagrange.(*kzg_bls12381.SRS))
+		return plonk_bls12381.Setup(tccs, *srs.(*kzg_bls12381.SRS), *srsLagrange.(*kzg_bls12381.SRS), opts...)
 	case *cs_bls12377.SparseR1CS:
-		return plonk_bls12377.Setup(tccs, *srs.(*kzg_bls12377.SRS), *srsLagrange.(*kzg_bls12377.SRS))
+		return plonk_bls12377.Setup(tccs, *srs.(*kzg_bls12377.SRS), *srsLagrange.(*kzg_bls12377.SRS), opts...)
 	case *cs_bw6761.SparseR1CS:
-		return plonk_bw6761.Setup(tccs, *srs.(*kzg_bw6761.SRS), *srsLagrange.(*kzg_bw6761.SRS))
+		return plonk_bw6761.Setup(tccs, *srs.(*kzg_bw6761.SRS), *srsLagrange.(*kzg_bw6761.SRS), opts...)
 	case *cs_bls24317.SparseR1CS:
-		return plonk_bls24317.Setup(tccs, *srs.(*kzg_bls24317.SRS), *srsLagrange.(*kzg_bls24317.SRS))
+		return plonk_bls24317.Setup(tccs, *srs.(*kzg_bls24317.SRS), *srsLagrange.(*kzg_bls24317.SRS), opts...)
 	case *cs_bls24315.SparseR1CS:
-		return plonk_bls24315.Setup(tccs, *srs.(*kzg_bls24315.SRS), *srsLagrange.(*kzg_bls24315.SRS))
+		return plonk_bls24315.Setup(tccs, *srs.(*kzg_bls24315.SRS), *srsLagrange.(*kzg_bls24315.SRS), opts...)
 	case *cs_bw6633.SparseR1CS:
-		return plonk_bw6633.Setup(tccs, *srs.(*kzg_bw6633.SRS), *srsLagrange.(*kzg_bw6633.SRS))
+		return plonk_bw6633.Setup(tccs, *srs.(*kzg_bw6633.SRS), *srsLagrange.(*kzg_bw6633.SRS), opts...)
 	default:
 		panic("unrecognized SparseR1CS curve type")
 	}
@@ -160,6 +164,95 @@ func Prove(ccs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.W
 	}
 }
 
+// PreparedProver represents a constraint system and a PLONK ProvingKey prepared
+// with Prepare to generate many proofs.
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type PreparedProver interface {
+	io.WriterTo
+	io.ReaderFrom
+	gnarkio.UnsafeReaderFrom
+	CurveID() ecc.ID
+}
+
+// Prepare computes once the data of the prover which only depends on the
+// constraint system and the proving key, to generate many proofs with
+// ProvePrepared. The proofs may be generated concurrently. The prepared prover
+// holds the constraint system and the key, and can be written to disk and read
+// back in a PreparedProver returned by NewPreparedProver.
+func Prepare(ccs constraint.ConstraintSystem, pk ProvingKey) (PreparedProver, error) {
+	switch tccs := ccs.(type) {
+	case *cs_bn254.SparseR1CS:
+		return prepared(plonk_bn254.Prepare(tccs, pk.(*plonk_bn254.ProvingKey)))
+	case *cs_bls12377.SparseR1CS:
+		return prepared(plonk_bls12377.Prepare(tccs, pk.(*plonk_bls12377.ProvingKey)))
+	case *cs_bls12381.SparseR1CS:
+		return prepared(plonk_bls12381.Prepare(tccs, pk.(*plonk_bls12381.ProvingKey)))
+	case *cs_bw6761.SparseR1CS:
+		return prepared(plonk_bw6761.Prepare(tccs, pk.(*plonk_bw6761.ProvingKey)))
+	case *cs_bls24317.SparseR1CS:
+		return prepared(plonk_bls24317.Prepare(tccs, pk.(*plonk_bls24317.ProvingKey)))
+	case *cs_bls24315.SparseR1CS:
+		return prepared(plonk_bls24315.Prepare(tccs, pk.(*plonk_bls24315.ProvingKey)))
+	case *cs_bw6633.SparseR1CS:
+		return prepared(plonk_bw6633.Prepare(tccs, pk.(*plonk_bw6633.ProvingKey)))
+	default:
+		panic("unrecognized SparseR1CS curve type")
+	}
+}
+
+func prepared[P PreparedProver](p P, err error) (PreparedProver, error) {
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ProvePrepared generates a proof with a prepared prover. See Prove.
+func ProvePrepared(p PreparedProver, fullWitness witness.Witness, opts ...backend.ProverOption) (Proof, error) {
+	switch tp := p.(type) {
+	case *plonk_bn254.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	case *plonk_bls12377.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	case *plonk_bls12381.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	case *plonk_bw6761.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	case *plonk_bls24317.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	case *plonk_bls24315.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	case *plonk_bw6633.PreparedProver:
+		return tp.Prove(fullWitness, opts...)
+	default:
+		panic("unrecognized prepared prover curve type")
+	}
+}
+
+// NewPreparedProver instantiates a curve-typed PreparedProver and returns an
+// interface. This function exists for serialization purposes.
+func NewPreparedProver(curveID ecc.ID) PreparedProver {
+	switch curveID {
+	case ecc.BN254:
+		return &plonk_bn254.PreparedProver{}
+	case ecc.BLS12_377:
+		return &plonk_bls12377.PreparedProver{}
+	case ecc.BLS12_381:
+		return &plonk_bls12381.PreparedProver{}
+	case ecc.BW6_761:
+		return &plonk_bw6761.PreparedProver{}
+	case ecc.BLS24_317:
+		return &plonk_bls24317.PreparedProver{}
+	case ecc.BLS24_315:
+		return &plonk_bls24315.PreparedProver{}
+	case ecc.BW6_633:
+		return &plonk_bw6633.PreparedProver{}
+	default:
+		panic("not implemented")
+	}
+}
+
 // Verify verifies a PLONK proof, from the proof, preprocessed public data, and public witness.
 func Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness, opts ...backend.VerifierOption) error {
 
@@ -219,6 +312,67 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness, opts ..
 	}
 }
 
+// BatchVerify verifies the proofs with the given VerifyingKey and public
+// witnesses, where publicWitnesses[i] is the public witness of proofs[i]. The
+// KZG openings of all the proofs are checked with a single batch opening.
+//
+// If some proofs are invalid, the returned error is a
+// [backend.BatchVerificationError] reporting the index of the invalid proofs.
+func BatchVerify(proofs []Proof, vk VerifyingKey, publicWitnesses []witness.Witness, opts ...backend.VerifierOption) error {
+	switch _vk := vk.(type) {
+	case *plonk_bls12377.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*plonk_bls12377.Proof, w []fr_bls12377.Vector) error {
+			return plonk_bls12377.BatchVerify(p, _vk, w, opts...)
+		})
+	case *plonk_bls12381.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*plonk_bls12381.Proof, w []fr_bls12381.Vector) error {
+			return plonk_bls12381.BatchVerify(p, _vk, w, opts...)
+		})
+	case *plonk_bn254.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*plonk_bn254.Proof, w []fr_bn254.Vector) error {
+			return plonk_bn254.BatchVerify(p, _vk, w, opts...)
+		})
+	case *plonk_bw6761.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*plonk_bw6761.Proof, w []fr_bw6761.Vector) error {
+			return plonk_bw6761.BatchVerify(p, _vk, w, opts...)
+		})
+	case *plonk_bls24317.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*plonk_bls24317.Proof, w []fr_bls24317.Vector) error {
+			return plonk_bls24317.BatchVerify(p, _vk, w, opts...)
+		})
+	case *plonk_bls24315.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*plonk_bls24315.Proof, w []fr_bls24315.Vector) error {
+			return plonk_bls24315.BatchVerify(p, _vk, w, opts...)
+		})
+	case *plonk_bw6633.VerifyingKey:
+		return batchVerify(proofs, publicWitnesses, func(p []*plonk_bw6633.Proof, w []fr_bw6633.Vector) error {
+			return plonk_bw6633.BatchVerify(p, _vk, w, opts...)
+		})
+	default:
+		panic("unrecognized verifying key type")
+	}
+}
+
+// batchVerify converts the proofs and public witnesses to the curve-typed
+// ones expected by verify.
+func batchVerify[P any, V any](proofs []Proof, publicWitnesses []witness.Witness, verify func([]P, []V) error) error {
+	if len(proofs) != len(publicWitnesses) {
+		return fmt.Errorf("got %d proofs and %d public witnesses", len(proofs), len(publicWitnesses))
+	}
+	_proofs := make([]P, len(proofs))
+	w := make([]V, len(publicWitnesses))
+	for i := range proofs {
+		var ok bool
+		if _proofs[i], ok = proofs[i].(P); !ok {
+			return fmt.Errorf("proof %d: unexpected proof type %T", i, proofs[i])
+		}
+		if w[i], ok = publicWitnesses[i].Vector().(V); !ok {
+			return fmt.Errorf("public witness %d: %w", i, witness.ErrInvalidWitness)
+		}
+	}
+	return verify(_proofs, w)
+}
+
 // NewCS instantiate a concrete curved-typed SparseR1CS and return a ConstraintSystem interface
 // This method exists for (de)serialization purposes
 func NewCS(curveID ecc.ID) constraint.ConstraintSystem {
@@ -2,8 +2,11 @@ package plonk_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 	"testing"
 
 	"github.com/consensys/gnark"
@@ -11,6 +14,7 @@ import (
 	"github.com/consensys/gnark-crypto/kzg"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/scs"
@@ -194,6 +198,382 @@ func TestCustomKZGFoldingHash(t *testing.T) {
 	}
 }
 
+func TestBatchVerify(t *testing.T) {
+	const nbProofs = 4
+	assert := test.NewAssert(t)
+	for _, curve := range getCurves() {
+		assert.Run(func(assert *test.Assert) {
+			ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilder, &batchCircuit{})
+			assert.NoError(err)
+			srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+			assert.NoError(err)
+
+			proofs := make([]plonk.Proof, nbProofs)
+			publicWitnesses := make([]witness.Witness, nbProofs)
+			for i := range proofs {
+				w, err := frontend.NewWitness(&batchCircuit{X: i + 2, Y: (i + 2) * (i + 2)}, curve.ScalarField())
+				assert.NoError(err)
+				proofs[i], err = plonk.Prove(ccs, pk, w)
+				assert.NoError(err)
+				publicWitnesses[i], err = w.Public()
+				assert.NoError(err)
+			}
+			assert.NoError(plonk.BatchVerify(proofs, vk, publicWitnesses))
+
+			// swapping two public witnesses invalidates both proofs
+			wrong := append([]witness.Witness{}, publicWitnesses...)
+			wrong[1], wrong[3] = wrong[3], wrong[1]
+			err = plonk.BatchVerify(proofs, vk, wrong)
+			var batchErr *backend.BatchVerificationError
+			assert.True(errors.As(err, &batchErr))
+			assert.Equal([]int{1, 3}, batchErr.Failed)
+		}, curve.String())
+	}
+}
+
+func TestContextAndProgress(t *testing.T) {
+	const curve = ecc.BN254
+	assert := test.NewAssert(t)
+
+	ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilder, &batchCircuit{})
+	assert.NoError(err)
+	srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&batchCircuit{X: 2, Y: 4}, curve.ScalarField())
+	assert.NoError(err)
+
+	var mu sync.Mutex
+	events := make(map[backend.Phase][]bool)
+	progress := func(e backend.ProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events[e.Phase] = append(events[e.Phase], e.Done)
+	}
+
+	pk, _, err := plonk.Setup(ccs, srs, srsLagrange, backend.WithSetupProgress(progress))
+	assert.NoError(err)
+	assert.Equal(map[backend.Phase][]bool{
+		backend.PhasePreprocess: {false, true},
+		backend.PhaseCommit:     {false, true},
+	}, events)
+
+	events = make(map[backend.Phase][]bool)
+	_, err = plonk.Prove(ccs, pk, w, backend.WithProgress(progress))
+	assert.NoError(err)
+	assert.Equal(map[backend.Phase][]bool{
+		backend.PhaseSolve:    {false, true},
+		backend.PhaseCommit:   {false, true},
+		backend.PhaseQuotient: {false, true},
+		backend.PhaseOpening:  {false, true},
+	}, events)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = plonk.Prove(ccs, pk, w, backend.WithContext(ctx))
+	assert.ErrorIs(err, context.Canceled)
+	_, _, err = plonk.Setup(ccs, srs, srsLagrange, backend.WithSetupContext(ctx))
+	assert.ErrorIs(err, context.Canceled)
+
+	// cancel the prover at the start of a phase: the phase ends with the
+	// error of the context
+	for _, phase := range []backend.Phase{backend.PhaseSolve, backend.PhaseCommit, backend.PhaseQuotient} {
+		assert.Run(func(assert *test.Assert) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			events = make(map[backend.Phase][]bool)
+			progress := func(e backend.ProgressEvent) {
+				if e.Phase == phase && !e.Done {
+					cancel()
+				}
+				progress(e)
+			}
+			_, err := plonk.Prove(ccs, pk, w, backend.WithContext(ctx), backend.WithProgress(progress))
+			assert.ErrorIs(err, context.Canceled)
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal([]bool{false, true}, events[phase])
+			assert.NotContains(events, backend.PhaseOpening)
+		}, string(phase))
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	const curve = ecc.BN254
+	assert := test.NewAssert(t)
+
+	ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilder, &batchCircuit{})
+	assert.NoError(err)
+	srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+	assert.NoError(err)
+	pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+	assert.NoError(err)
+
+	prepared, err := plonk.Prepare(ccs, pk)
+	assert.NoError(err)
+
+	// write and read back the prepared prover
+	var buf bytes.Buffer
+	_, err = prepared.WriteTo(&buf)
+	assert.NoError(err)
+	read := plonk.NewPreparedProver(curve)
+	_, err = read.ReadFrom(&buf)
+	assert.NoError(err)
+
+	// generate proofs concurrently
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p := prepared
+			if i%2 == 1 {
+				p = read
+			}
+			w, err := frontend.NewWitness(&batchCircuit{X: i + 2, Y: (i + 2) * (i + 2)}, curve.ScalarField())
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			proof, err := plonk.ProvePrepared(p, w)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			publicWitness, err := w.Public()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = plonk.Verify(proof, vk, publicWitness)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(err)
+	}
+}
+
+func TestCustomGates(t *testing.T) {
+	for _, curve := range getCurves() {
+		t.Run(curve.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilder, &customGateCircuit{})
+			assert.NoError(err)
+			srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+			assert.NoError(err)
+
+			// write and read back the verifying key
+			var buf bytes.Buffer
+			_, err = vk.WriteTo(&buf)
+			assert.NoError(err)
+			readVk := plonk.NewVerifyingKey(curve)
+			_, err = readVk.ReadFrom(&buf)
+			assert.NoError(err)
+
+			assert.NoError(test.IsSolved(&customGateCircuit{}, &customGateCircuit{X: 3, Y: 6, Z: 106, W: 2}, curve.ScalarField()))
+			assert.Error(test.IsSolved(&customGateCircuit{}, &customGateCircuit{X: 3, Y: 7, Z: 145, W: 2}, curve.ScalarField()))
+
+			w, err := frontend.NewWitness(&customGateCircuit{X: 3, Y: 6, Z: 106, W: 2}, curve.ScalarField())
+			assert.NoError(err)
+			proof, err := plonk.Prove(ccs, pk, w)
+			assert.NoError(err)
+			publicWitness, err := w.Public()
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proof, vk, publicWitness))
+			assert.NoError(plonk.Verify(proof, readVk, publicWitness))
+
+			// wrong public input
+			w, err = frontend.NewWitness(&customGateCircuit{X: 3, Y: 6, Z: 107, W: 2}, curve.ScalarField())
+			assert.NoError(err)
+			publicWitness, err = w.Public()
+			assert.NoError(err)
+			assert.Error(plonk.Verify(proof, vk, publicWitness))
+
+			// unsatisfied custom gate
+			w, err = frontend.NewWitness(&customGateCircuit{X: 3, Y: 7, Z: 145, W: 2}, curve.ScalarField())
+			assert.NoError(err)
+			_, err = plonk.Prove(ccs, pk, w)
+			assert.Error(err)
+		})
+	}
+}
+
+func TestCustomGateDegree(t *testing.T) {
+	assignment := sboxCircuit{X: 2}
+	wrong := sboxCircuit{X: 2}
+	var x, y big.Int
+	x.SetInt64(2)
+	for _, k := range sboxRoundConstants {
+		x.Add(&x, big.NewInt(int64(k)))
+		x.Exp(&x, big.NewInt(5), nil)
+	}
+	for _, curve := range getCurves() {
+		t.Run(curve.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			y.Mod(&x, curve.ScalarField())
+			assignment.Y = new(big.Int).Set(&y)
+			wrong.Y = new(big.Int).Add(&y, big.NewInt(1))
+
+			ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilder, &sboxCircuit{})
+			assert.NoError(err)
+			// each round is a constraint of the custom gate
+			nbGates := 0
+			for _, c := range ccs.(constraint.SparseR1CS).GetSparseR1Cs() {
+				if c.QG != constraint.CoeffIdZero {
+					nbGates++
+				}
+			}
+			assert.Equal(len(sboxRoundConstants), nbGates)
+			srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+			assert.NoError(err)
+
+			w, err := frontend.NewWitness(&assignment, curve.ScalarField())
+			assert.NoError(err)
+			proof, err := plonk.Prove(ccs, pk, w)
+			assert.NoError(err)
+			publicWitness, err := w.Public()
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proof, vk, publicWitness))
+
+			// write and read back the proof, with the additional chunks of the
+			// quotient
+			var buf bytes.Buffer
+			_, err = proof.WriteTo(&buf)
+			assert.NoError(err)
+			readProof := plonk.NewProof(curve)
+			_, err = readProof.ReadFrom(&buf)
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(readProof, vk, publicWitness))
+
+			// prove with a prepared prover
+			prepared, err := plonk.Prepare(ccs, pk)
+			assert.NoError(err)
+			proof, err = plonk.ProvePrepared(prepared, w)
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proof, vk, publicWitness))
+
+			// wrong public input
+			w, err = frontend.NewWitness(&wrong, curve.ScalarField())
+			assert.NoError(err)
+			publicWitness, err = w.Public()
+			assert.NoError(err)
+			assert.Error(plonk.Verify(proof, vk, publicWitness))
+			_, err = plonk.Prove(ccs, pk, w)
+			assert.Error(err)
+		})
+	}
+}
+
+func TestCustomGateSolidity(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &sboxCircuit{})
+	assert.NoError(err)
+	srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+	assert.NoError(err)
+	_, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+	assert.NoError(err)
+
+	var buf bytes.Buffer
+	assert.NoError(vk.ExportSolidity(&buf))
+	contract := buf.String()
+	assert.Contains(contract, "VK_QG_0_COM_X")
+	// the quotient of the degree 5 gate has additional chunks
+	assert.Contains(contract, "PROOF_H_3_X")
+	assert.Contains(contract, "term 0 of the custom gates")
+}
+
+func TestWideConstraints(t *testing.T) {
+	nbConstraints := make(map[int]int)
+	for _, nbWires := range []int{3, 4, 5} {
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilderWithOptions(scs.WithNbWires(nbWires)), &wideCircuit{})
+		require.NoError(t, err)
+		nbConstraints[nbWires] = ccs.GetNbConstraints()
+	}
+	require.Less(t, nbConstraints[4], nbConstraints[3])
+	require.Less(t, nbConstraints[5], nbConstraints[4])
+
+	var assignment, wrong wideCircuit
+	for i := range assignment.X {
+		assignment.X[i] = i + 1
+		wrong.X[i] = i + 1
+	}
+	assignment.Y = 285
+	wrong.Y = 286
+
+	for _, nbWires := range []int{4, 5} {
+		for _, curve := range getCurves() {
+			t.Run(fmt.Sprintf("%s/%d", curve, nbWires), func(t *testing.T) {
+				assert := require.New(t)
+
+				ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilderWithOptions(scs.WithNbWires(nbWires)), &wideCircuit{})
+				assert.NoError(err)
+				srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+				assert.NoError(err)
+				pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+				assert.NoError(err)
+
+				// write and read back the verifying key
+				var buf bytes.Buffer
+				_, err = vk.WriteTo(&buf)
+				assert.NoError(err)
+				readVk := plonk.NewVerifyingKey(curve)
+				_, err = readVk.ReadFrom(&buf)
+				assert.NoError(err)
+
+				w, err := frontend.NewWitness(&assignment, curve.ScalarField())
+				assert.NoError(err)
+				proof, err := plonk.Prove(ccs, pk, w)
+				assert.NoError(err)
+				publicWitness, err := w.Public()
+				assert.NoError(err)
+				assert.NoError(plonk.Verify(proof, vk, publicWitness))
+				assert.NoError(plonk.Verify(proof, readVk, publicWitness))
+
+				// write and read back the proof
+				buf.Reset()
+				_, err = proof.WriteTo(&buf)
+				assert.NoError(err)
+				readProof := plonk.NewProof(curve)
+				_, err = readProof.ReadFrom(&buf)
+				assert.NoError(err)
+				assert.NoError(plonk.Verify(readProof, vk, publicWitness))
+
+				// prove with a prepared prover written and read back
+				prepared, err := plonk.Prepare(ccs, pk)
+				assert.NoError(err)
+				buf.Reset()
+				_, err = prepared.WriteTo(&buf)
+				assert.NoError(err)
+				readPrepared := plonk.NewPreparedProver(curve)
+				_, err = readPrepared.ReadFrom(&buf)
+				assert.NoError(err)
+				proof, err = plonk.ProvePrepared(readPrepared, w)
+				assert.NoError(err)
+				assert.NoError(plonk.Verify(proof, vk, publicWitness))
+
+				// wrong public input
+				w, err = frontend.NewWitness(&wrong, curve.ScalarField())
+				assert.NoError(err)
+				publicWitness, err = w.Public()
+				assert.NoError(err)
+				assert.Error(plonk.Verify(proof, vk, publicWitness))
+				_, err = plonk.Prove(ccs, pk, w)
+				assert.Error(err)
+			})
+		}
+	}
+}
+
 func BenchmarkSetup(b *testing.B) {
 	for _, curve := range getCurves() {
 		b.Run(curve.String(), func(b *testing.B) {
@@ -320,6 +700,89 @@ func (c *smallCircuit) Define(api frontend.API) error {
 	return nil
 }
 
+type batchCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *batchCircuit) Define(api frontend.API) error {
+	cmt, err := api.(frontend.Committer).Commit(c.X)
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	api.AssertIsDifferent(cmt, c.Y)
+	return nil
+}
+
+type customGateCircuit struct {
+	X, Y, W frontend.Variable
+	Z       frontend.Variable `gnark:",public"`
+}
+
+func (c *customGateCircuit) Define(api frontend.API) error {
+	cg, ok := api.(frontend.CustomGateAPI)
+	if !ok {
+		return errors.New("custom gates not supported")
+	}
+	sumsq, err := cg.DefineCustomGate("sumsq", frontend.GateTerm{Coeff: 1, A: 2}, frontend.GateTerm{Coeff: 1, B: 2})
+	if err != nil {
+		return err
+	}
+	mulo, err := cg.DefineCustomGate("mulo", frontend.GateTerm{Coeff: 1, A: 1, C: 1})
+	if err != nil {
+		return err
+	}
+	// (3x)² + (y - 1)² == z
+	api.AssertIsEqual(cg.EvaluateCustomGate(sumsq, api.Mul(c.X, 3), api.Sub(c.Y, 1), 1), c.Z)
+	// x⋅w - y == 0
+	cg.AddCustomGateConstraint(mulo, c.X, c.Y, c.W, 1, 0, -1, 0, 0, 0)
+	return nil
+}
+
+// sboxCircuit applies rounds of the x⁵ S-box of Poseidon with a custom gate of
+// degree 5.
+type sboxCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+var sboxRoundConstants = []int{1, 2, 3}
+
+func (c *sboxCircuit) Define(api frontend.API) error {
+	cg, ok := api.(frontend.CustomGateAPI)
+	if !ok {
+		return errors.New("custom gates not supported")
+	}
+	sbox, err := cg.DefineCustomGate("sbox", frontend.GateTerm{Coeff: 1, A: 5})
+	if err != nil {
+		return err
+	}
+	x := c.X
+	for _, k := range sboxRoundConstants {
+		// (x + k)⁵, the S-box doesn't read the second wire
+		x = cg.EvaluateCustomGate(sbox, api.Add(x, k), c.X, 1)
+	}
+	api.AssertIsEqual(x, c.Y)
+	return nil
+}
+
+type wideCircuit struct {
+	X [9]frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *wideCircuit) Define(api frontend.API) error {
+	terms := make([]frontend.Variable, len(c.X))
+	for i := range c.X {
+		terms[i] = api.Mul(c.X[i], i+1)
+	}
+	// ∑(i+1)xᵢ⋅x₀ == y
+	sum := api.Add(terms[0], terms[1], terms[2:]...)
+	api.AssertIsEqual(api.Mul(sum, c.X[0]), c.Y)
+	return nil
+}
+
 type constantHash struct{}
 
 func (h constantHash) Write(p []byte) (n int, err error) { return len(p), nil }
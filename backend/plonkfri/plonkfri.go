@@ -0,0 +1,188 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plonkfri implements PLONK Zero Knowledge Proof system, with FRI as
+// commitment scheme.
+//
+// Contrary to [github.com/consensys/gnark/backend/plonk], the setup is
+// transparent: no structured reference string is needed.
+//
+// # See also
+//
+// https://eprint.iacr.org/2019/953
+// https://eccc.weizmann.ac.il/report/2017/134/
+package plonkfri
+
+import (
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+
+	cs_bls12377 "github.com/consensys/gnark/constraint/bls12-377"
+	cs_bls12381 "github.com/consensys/gnark/constraint/bls12-381"
+	cs_bls24315 "github.com/consensys/gnark/constraint/bls24-315"
+	cs_bls24317 "github.com/consensys/gnark/constraint/bls24-317"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
+	cs_bw6633 "github.com/consensys/gnark/constraint/bw6-633"
+	cs_bw6761 "github.com/consensys/gnark/constraint/bw6-761"
+
+	plonkfri_bls12377 "github.com/consensys/gnark/backend/plonkfri/bls12-377"
+	plonkfri_bls12381 "github.com/consensys/gnark/backend/plonkfri/bls12-381"
+	plonkfri_bls24315 "github.com/consensys/gnark/backend/plonkfri/bls24-315"
+	plonkfri_bls24317 "github.com/consensys/gnark/backend/plonkfri/bls24-317"
+	plonkfri_bn254 "github.com/consensys/gnark/backend/plonkfri/bn254"
+	plonkfri_bw6633 "github.com/consensys/gnark/backend/plonkfri/bw6-633"
+	plonkfri_bw6761 "github.com/consensys/gnark/backend/plonkfri/bw6-761"
+
+	fr_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	fr_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	fr_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+	fr_bls24317 "github.com/consensys/gnark-crypto/ecc/bls24-317/fr"
+	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	fr_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+	fr_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr"
+)
+
+// Proof represents a PLONK-FRI proof generated by plonkfri.Prove
+//
+// it's underlying implementation is curve specific (see gnark/backend/plonkfri)
+type Proof interface{}
+
+// ProvingKey represents a PLONK-FRI ProvingKey
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/backend/plonkfri)
+type ProvingKey interface {
+	VerifyingKey() interface{}
+}
+
+// VerifyingKey represents a PLONK-FRI VerifyingKey
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/backend/plonkfri)
+type VerifyingKey interface {
+	NbPublicWitness() int
+}
+
+// Setup prepares the public data associated to a circuit + public inputs.
+// Contrary to PLONK with KZG, no SRS is needed.
+func Setup(ccs constraint.ConstraintSystem) (ProvingKey, VerifyingKey, error) {
+
+	switch tccs := ccs.(type) {
+	case *cs_bn254.SparseR1CS:
+		return plonkfri_bn254.Setup(tccs)
+	case *cs_bls12381.SparseR1CS:
+		return plonkfri_bls12381.Setup(tccs)
+	case *cs_bls12377.SparseR1CS:
+		return plonkfri_bls12377.Setup(tccs)
+	case *cs_bw6761.SparseR1CS:
+		return plonkfri_bw6761.Setup(tccs)
+	case *cs_bw6633.SparseR1CS:
+		return plonkfri_bw6633.Setup(tccs)
+	case *cs_bls24317.SparseR1CS:
+		return plonkfri_bls24317.Setup(tccs)
+	case *cs_bls24315.SparseR1CS:
+		return plonkfri_bls24315.Setup(tccs)
+	default:
+		panic("unrecognized SparseR1CS curve type")
+	}
+
+}
+
+// Prove generates PLONK-FRI proof from a circuit, associated preprocessed public data, and the witness
+func Prove(ccs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (Proof, error) {
+
+	switch tccs := ccs.(type) {
+	case *cs_bn254.SparseR1CS:
+		return plonkfri_bn254.Prove(tccs, pk.(*plonkfri_bn254.ProvingKey), fullWitness, opts...)
+
+	case *cs_bls12381.SparseR1CS:
+		return plonkfri_bls12381.Prove(tccs, pk.(*plonkfri_bls12381.ProvingKey), fullWitness, opts...)
+
+	case *cs_bls12377.SparseR1CS:
+		return plonkfri_bls12377.Prove(tccs, pk.(*plonkfri_bls12377.ProvingKey), fullWitness, opts...)
+
+	case *cs_bw6761.SparseR1CS:
+		return plonkfri_bw6761.Prove(tccs, pk.(*plonkfri_bw6761.ProvingKey), fullWitness, opts...)
+
+	case *cs_bw6633.SparseR1CS:
+		return plonkfri_bw6633.Prove(tccs, pk.(*plonkfri_bw6633.ProvingKey), fullWitness, opts...)
+
+	case *cs_bls24317.SparseR1CS:
+		return plonkfri_bls24317.Prove(tccs, pk.(*plonkfri_bls24317.ProvingKey), fullWitness, opts...)
+
+	case *cs_bls24315.SparseR1CS:
+		return plonkfri_bls24315.Prove(tccs, pk.(*plonkfri_bls24315.ProvingKey), fullWitness, opts...)
+
+	default:
+		panic("unrecognized SparseR1CS curve type")
+	}
+}
+
+// Verify verifies a PLONK-FRI proof, from the proof, preprocessed public data, and public witness.
+func Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness, opts ...backend.VerifierOption) error {
+
+	switch _proof := proof.(type) {
+
+	case *plonkfri_bn254.Proof:
+		w, ok := publicWitness.Vector().(fr_bn254.Vector)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bn254.Verify(_proof, vk.(*plonkfri_bn254.VerifyingKey), w, opts...)
+
+	case *plonkfri_bls12381.Proof:
+		w, ok := publicWitness.Vector().(fr_bls12381.Vector)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bls12381.Verify(_proof, vk.(*plonkfri_bls12381.VerifyingKey), w, opts...)
+
+	case *plonkfri_bls12377.Proof:
+		w, ok := publicWitness.Vector().(fr_bls12377.Vector)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bls12377.Verify(_proof, vk.(*plonkfri_bls12377.VerifyingKey), w, opts...)
+
+	case *plonkfri_bw6761.Proof:
+		w, ok := publicWitness.Vector().(fr_bw6761.Vector)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bw6761.Verify(_proof, vk.(*plonkfri_bw6761.VerifyingKey), w, opts...)
+
+	case *plonkfri_bw6633.Proof:
+		w, ok := publicWitness.Vector().(fr_bw6633.Vector)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bw6633.Verify(_proof, vk.(*plonkfri_bw6633.VerifyingKey), w, opts...)
+
+	case *plonkfri_bls24317.Proof:
+		w, ok := publicWitness.Vector().(fr_bls24317.Vector)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bls24317.Verify(_proof, vk.(*plonkfri_bls24317.VerifyingKey), w, opts...)
+
+	case *plonkfri_bls24315.Proof:
+		w, ok := publicWitness.Vector().(fr_bls24315.Vector)
+		if !ok {
+			return witness.ErrInvalidWitness
+		}
+		return plonkfri_bls24315.Verify(_proof, vk.(*plonkfri_bls24315.VerifyingKey), w, opts...)
+
+	default:
+		panic("unrecognized proof type")
+	}
+}
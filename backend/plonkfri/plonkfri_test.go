@@ -0,0 +1,77 @@
+package plonkfri_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/backend/plonkfri"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+type refCircuit struct {
+	nbConstraints int
+	X             frontend.Variable
+	Y             frontend.Variable `gnark:",public"`
+}
+
+func (circuit *refCircuit) Define(api frontend.API) error {
+	for i := 0; i < circuit.nbConstraints; i++ {
+		circuit.X = api.Mul(circuit.X, circuit.X)
+	}
+	api.AssertIsEqual(circuit.X, circuit.Y)
+	return nil
+}
+
+func TestProver(t *testing.T) {
+	for _, curve := range gnark.Curves() {
+		curve := curve
+		t.Run(curve.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			const nbConstraints = 10
+			ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilder, &refCircuit{nbConstraints: nbConstraints})
+			assert.NoError(err)
+
+			var good refCircuit
+			good.X = 2
+			// compute expected Y
+			y := new(big.Int).SetUint64(2)
+			for i := 0; i < nbConstraints; i++ {
+				y.Mul(y, y).Mod(y, curve.ScalarField())
+			}
+			good.Y = y
+
+			fullWitness, err := frontend.NewWitness(&good, curve.ScalarField())
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+
+			pk, vk, err := plonkfri.Setup(ccs)
+			assert.NoError(err)
+
+			proof, err := plonkfri.Prove(ccs, pk, fullWitness)
+			assert.NoError(err)
+
+			err = plonkfri.Verify(proof, vk, publicWitness)
+			assert.NoError(err)
+
+			// a proof must not verify against a different public input
+			var bad refCircuit
+			bad.X = 0
+			bad.Y = 1
+			badPublic, err := frontend.NewWitness(&bad, curve.ScalarField(), frontend.PublicOnly())
+			assert.NoError(err)
+			err = plonkfri.Verify(proof, vk, badPublic)
+			assert.Error(err)
+
+			// the prover must fail on an invalid witness
+			badFull, err := frontend.NewWitness(&bad, curve.ScalarField())
+			assert.NoError(err)
+			_, err = plonkfri.Prove(ccs, pk, badFull)
+			assert.Error(err)
+		})
+	}
+}
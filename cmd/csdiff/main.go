@@ -0,0 +1,102 @@
+// csdiff compares two serialized constraint systems and prints their
+// differences, see constraint.Diff.
+//
+//	go run github.com/consensys/gnark/cmd/csdiff -curve bn254 -backend groth16 old.r1cs new.r1cs
+//
+// It exits with status 1 if the systems differ, so that a CI job fails on an
+// unintended change of a circuit, and 2 on error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+)
+
+var (
+	fCurve   = flag.String("curve", "bn254", "curve of the constraint systems")
+	fBackend = flag.String("backend", "groth16", "backend of the constraint systems: groth16 (R1CS) or plonk (SparseR1CS)")
+	fVerbose = flag.Bool("v", false, "print the removed and added constraints")
+)
+
+func main() {
+	log.SetFlags(0)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] a b\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	a, err := read(flag.Arg(0))
+	if err != nil {
+		log.Println(err)
+		os.Exit(2)
+	}
+	b, err := read(flag.Arg(1))
+	if err != nil {
+		log.Println(err)
+		os.Exit(2)
+	}
+
+	d, err := constraint.Diff(a, b)
+	if err != nil {
+		log.Println(err)
+		os.Exit(2)
+	}
+	fmt.Println(d)
+	if *fVerbose {
+		for _, l := range d.Constraints {
+			fmt.Println()
+			location := l.Location
+			if location == "" {
+				location = "(no debug information)"
+			}
+			fmt.Println(location)
+			for _, c := range l.Removed {
+				fmt.Println("-", c)
+			}
+			for _, c := range l.Added {
+				fmt.Println("+", c)
+			}
+		}
+	}
+	if !d.Empty() {
+		os.Exit(1)
+	}
+}
+
+func read(path string) (constraint.ConstraintSystem, error) {
+	curve, err := ecc.IDFromString(*fCurve)
+	if err != nil {
+		return nil, err
+	}
+	var cs constraint.ConstraintSystem
+	switch *fBackend {
+	case "groth16":
+		cs = groth16.NewCS(curve)
+	case "plonk":
+		cs = plonk.NewCS(curve)
+	default:
+		return nil, fmt.Errorf("unknown backend %q", *fBackend)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := cs.ReadFrom(f); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return cs, nil
+}
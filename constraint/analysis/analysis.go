@@ -0,0 +1,794 @@
+// Package analysis implements static analyses of constraint systems.
+//
+// Analyze reports the outputs of the hints (see frontend.Compiler.NewHint)
+// which the constraints don't fix uniquely: the prover computes them off
+// circuit, and a cheating prover may choose other values satisfying the
+// constraints.
+package analysis
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend/cs"
+	"github.com/consensys/gnark/internal/embedded"
+	"github.com/rs/zerolog"
+)
+
+// Kind is the kind of a Finding.
+type Kind uint8
+
+const (
+	// Unconstrained wires appear in no constraint.
+	Unconstrained Kind = iota
+
+	// Underdetermined wires appear in constraints which don't fix their value.
+	Underdetermined
+
+	// Finite wires are fixed by the constraints up to finitely many values, as
+	// the roots of a polynomial; for example a boolean which is not part of a
+	// binary decomposition, or a square root.
+	Finite
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Unconstrained:
+		return "unconstrained"
+	case Underdetermined:
+		return "underdetermined"
+	case Finite:
+		return "finitely determined"
+	default:
+		return fmt.Sprintf("Kind(%d)", uint8(k))
+	}
+}
+
+// Finding reports a hint output whose value is not fixed by the inputs.
+type Finding struct {
+	Wire int
+	Name string // name of the wire, see constraint.Resolver
+	Hint string // name of the hint computing the wire
+	Kind Kind
+
+	// Confirmed is set if the constraint system is solved with another value
+	// of the wire, for the witness given with WithWitness.
+	Confirmed bool
+
+	// Locations are the source locations of the constraints using the wire,
+	// for the constraints with debug information (see the debug build tag).
+	Locations []string
+}
+
+func (f Finding) String() string {
+	var sbb strings.Builder
+	sbb.WriteString(f.Name)
+	sbb.WriteString(" (output of ")
+	sbb.WriteString(f.Hint)
+	sbb.WriteString(") is ")
+	sbb.WriteString(f.Kind.String())
+	if f.Confirmed {
+		sbb.WriteString(", confirmed")
+	}
+	for _, l := range f.Locations {
+		sbb.WriteString("\n\t")
+		sbb.WriteString(l)
+	}
+	return sbb.String()
+}
+
+// Report is the result of Analyze.
+type Report struct {
+	Findings []Finding
+
+	// Assumed lists the hints and the blueprints whose outputs are assumed to
+	// be fixed by their inputs, as the commitments and the lookups.
+	Assumed []string
+}
+
+func (r *Report) String() string {
+	if len(r.Findings) == 0 {
+		return "no findings"
+	}
+	var sbb strings.Builder
+	for i, f := range r.Findings {
+		if i != 0 {
+			sbb.WriteByte('\n')
+		}
+		sbb.WriteString(f.String())
+	}
+	return sbb.String()
+}
+
+// Option configures Analyze.
+type Option func(*config) error
+
+type config struct {
+	witness    witness.Witness
+	solverOpts []solver.Option
+}
+
+// WithWitness confirms the findings by randomized solving: the constraint
+// system is solved with the witness, replacing the value of the wire of the
+// finding by another one (its opposite, 1 minus it, its successor or a random
+// value). The finding is confirmed if the constraints are satisfied; the
+// wires which can only change together with other wires are not confirmed. The
+// solver options are used for the solving, for example to provide the hints.
+func WithWitness(w witness.Witness, opts ...solver.Option) Option {
+	return func(c *config) error {
+		if w == nil {
+			return errors.New("nil witness")
+		}
+		c.witness = w
+		c.solverOpts = opts
+		return nil
+	}
+}
+
+// Analyze runs a dataflow analysis over the instructions of the constraint
+// system, and reports the hint outputs whose value the constraints don't fix,
+// sorted by wire.
+//
+// Starting from the inputs, a wire is fixed by a constraint in which all the
+// other wires are fixed, if the constraint is affine in it. A constraint
+// quadratic in it fixes it up to two values; the values of booleans are then
+// fixed by a linear constraint on them with coefficients distinct powers of
+// two, as in a binary decomposition shorter than the field.
+//
+// The analysis is conservative, it may report values which are fixed by a
+// more complex argument, for example by a comparison or by a random linear
+// combination. WithWitness confirms the findings.
+func Analyze(ccs constraint.ConstraintSystem, opts ...Option) (*Report, error) {
+	var cfg config
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, err
+		}
+	}
+	s, err := embedded.Get[constraint.System](ccs)
+	if err != nil {
+		return nil, err
+	}
+
+	a := newAnalyzer(ccs, s)
+	a.propagate()
+	a.findings()
+
+	if cfg.witness != nil {
+		if err := a.confirm(&cfg); err != nil {
+			return nil, err
+		}
+	}
+	return &a.report, nil
+}
+
+// state of the value of a wire, as far as the analysis knows.
+type state uint8
+
+const (
+	unknown  state = iota
+	finite         // root of a polynomial
+	boolean        // 0 or 1
+	symbolic       // affine in boolean wires
+	determined
+)
+
+type hintCall struct {
+	id         solver.HintID
+	start, end uint32
+	call       int // index among the calls to the same hint, when solving sequentially
+}
+
+type constraintInfo struct {
+	r1c    *constraint.R1C
+	sparse *constraint.SparseR1C
+	id     int
+	wires  []uint32 // the distinct wires of the constraint which aren't inputs
+	done   bool
+}
+
+type analyzer struct {
+	cs       constraint.ConstraintSystem
+	system   *constraint.System
+	nbInputs int
+
+	states      []state
+	sym         map[uint32]expansion // expression of the symbolic wires
+	constraints []*constraintInfo
+	uses        [][]int // constraints using each wire
+	hints       []hintCall
+	hintOf      []int // hint computing each wire, -1 if none
+	queue       []int
+
+	report Report
+}
+
+func newAnalyzer(ccs constraint.ConstraintSystem, system *constraint.System) *analyzer {
+	nbInputs := system.GetNbPublicVariables() + system.GetNbSecretVariables()
+	nbWires := nbInputs + system.NbInternalVariables
+	a := &analyzer{
+		cs:       ccs,
+		system:   system,
+		nbInputs: nbInputs,
+		states:   make([]state, nbWires),
+		sym:      make(map[uint32]expansion),
+		uses:     make([][]int, nbWires),
+		hintOf:   make([]int, nbWires),
+	}
+	for i := range a.hintOf {
+		a.hintOf[i] = -1
+	}
+	for i := 0; i < nbInputs; i++ {
+		a.states[i] = determined
+	}
+
+	// the commitments and the GKR sub-circuits are computed by hints from
+	// their inputs.
+	assumedHints := map[solver.HintID]bool{solver.GetHintID(cs.Bsb22CommitmentComputePlaceholder): true}
+	if system.GkrInfo.Is() {
+		assumedHints[system.GkrInfo.SolveHintID] = true
+		assumedHints[system.GkrInfo.ProveHintID] = true
+	}
+	assumed := make(map[string]bool)
+
+	hintOfInstruction := make(map[int]int)
+	for i, pi := range system.Instructions {
+		inst := pi.Unpack(system)
+		switch b := system.Blueprints[pi.BlueprintID].(type) {
+		case constraint.BlueprintHint:
+			var h constraint.HintMapping
+			b.DecompressHint(&h, inst)
+			if assumedHints[h.HintID] {
+				assumed[system.MHintsDependencies[h.HintID]] = true
+				for w := h.OutputRange.Start; w < h.OutputRange.End; w++ {
+					a.states[w] = determined
+				}
+				continue
+			}
+			hintOfInstruction[i] = len(a.hints)
+			for w := h.OutputRange.Start; w < h.OutputRange.End; w++ {
+				a.hintOf[w] = len(a.hints)
+			}
+			a.hints = append(a.hints, hintCall{id: h.HintID, start: h.OutputRange.Start, end: h.OutputRange.End})
+		case constraint.BlueprintR1C:
+			c := &constraintInfo{r1c: new(constraint.R1C), id: int(pi.ConstraintOffset)}
+			b.DecompressR1C(c.r1c, inst)
+			a.addConstraint(c)
+		case constraint.BlueprintSparseR1C:
+			c := &constraintInfo{sparse: new(constraint.SparseR1C), id: int(pi.ConstraintOffset)}
+			b.DecompressSparseR1C(c.sparse, inst)
+			a.addConstraint(c)
+		default:
+			// the outputs of the other blueprints (e.g. lookups) are assumed
+			// to be fixed by their inputs.
+			assumed[fmt.Sprintf("%T", b)] = true
+			for j := 0; j < b.NbOutputs(inst); j++ {
+				a.states[int(pi.WireOffset)+j] = determined
+			}
+		}
+	}
+	for name := range assumed {
+		a.report.Assumed = append(a.report.Assumed, name)
+	}
+	sort.Strings(a.report.Assumed)
+
+	// the order of the hint calls when the solver runs sequentially
+	calls := make(map[solver.HintID]int)
+	for _, level := range system.Levels {
+		for _, i := range level {
+			if h, ok := hintOfInstruction[int(i)]; ok {
+				a.hints[h].call = calls[a.hints[h].id]
+				calls[a.hints[h].id]++
+			}
+		}
+	}
+
+	return a
+}
+
+func (a *analyzer) addConstraint(c *constraintInfo) {
+	cID := len(a.constraints)
+	a.constraints = append(a.constraints, c)
+	a.wires(c, func(w uint32) {
+		if int(w) < a.nbInputs {
+			return
+		}
+		if n := len(a.uses[w]); n != 0 && a.uses[w][n-1] == cID {
+			return
+		}
+		for _, v := range c.wires {
+			if v == w {
+				return
+			}
+		}
+		c.wires = append(c.wires, w)
+		a.uses[w] = append(a.uses[w], cID)
+	})
+}
+
+// wires calls f on each wire of the constraint with a non-zero coefficient.
+func (a *analyzer) wires(c *constraintInfo, f func(w uint32)) {
+	if c.r1c != nil {
+		for _, l := range [...]constraint.LinearExpression{c.r1c.L, c.r1c.R, c.r1c.O} {
+			for _, t := range l {
+				if !t.IsConstant() && t.CID != constraint.CoeffIdZero {
+					f(t.VID)
+				}
+			}
+		}
+		return
+	}
+	s := c.sparse
+	for _, t := range linearTerms(s) {
+		if t.CID != constraint.CoeffIdZero {
+			f(t.VID)
+		}
+	}
+	if s.QM != constraint.CoeffIdZero || s.QG != constraint.CoeffIdZero {
+		f(s.XA)
+		f(s.XB)
+	}
+	if s.QG != constraint.CoeffIdZero {
+		f(s.XC)
+	}
+}
+
+// linearTerms returns the terms qL⋅xa, qR⋅xb, qO⋅xc, qD⋅xd and qE⋅xe of c.
+func linearTerms(c *constraint.SparseR1C) [5]constraint.Term {
+	return [5]constraint.Term{{CID: c.QL, VID: c.XA}, {CID: c.QR, VID: c.XB}, {CID: c.QO, VID: c.XC}, {CID: c.QD, VID: c.XD}, {CID: c.QE, VID: c.XE}}
+}
+
+// propagate computes the states of the wires, until no constraint changes them.
+func (a *analyzer) propagate() {
+	a.queue = a.queue[:0]
+	for i := range a.constraints {
+		a.queue = append(a.queue, i)
+	}
+	for len(a.queue) != 0 {
+		i := a.queue[len(a.queue)-1]
+		a.queue = a.queue[:len(a.queue)-1]
+		if !a.constraints[i].done {
+			a.evaluate(a.constraints[i])
+		}
+	}
+}
+
+// set updates the state of a wire, and schedules the constraints using it.
+func (a *analyzer) set(w uint32, s state) {
+	if a.states[w] == s || a.states[w] == determined {
+		return
+	}
+	a.states[w] = s
+	if s == determined {
+		delete(a.sym, w)
+	}
+	for _, c := range a.uses[w] {
+		if !a.constraints[c].done {
+			a.queue = append(a.queue, c)
+		}
+	}
+}
+
+// evaluate applies the rules of the analysis to the constraint.
+func (a *analyzer) evaluate(c *constraintInfo) {
+	var unknowns, others []uint32
+	for _, w := range c.wires {
+		switch a.states[w] {
+		case unknown:
+			unknowns = append(unknowns, w)
+		case determined:
+		default:
+			others = append(others, w)
+		}
+	}
+
+	switch {
+	case len(unknowns) == 0 && len(others) == 0:
+		c.done = true
+
+	case len(unknowns) == 1 && len(others) == 0:
+		// the constraint fixes the last wire, or its possible values
+		switch deg, isBoolean := a.degree(c, unknowns[0]); {
+		case deg == 1:
+			a.set(unknowns[0], determined)
+		case isBoolean:
+			a.set(unknowns[0], boolean)
+		default:
+			a.set(unknowns[0], finite)
+		}
+		c.done = true
+
+	case len(unknowns) == 1:
+		// the last wire is an affine function of the booleans
+		coeffs, ok := a.coefficients(c)
+		if !ok || a.states[unknowns[0]] != unknown {
+			return
+		}
+		e, ok := a.expand(coeffs, unknowns[0])
+		if !ok {
+			return
+		}
+		a.sym[unknowns[0]] = e
+		a.set(unknowns[0], symbolic)
+
+	case len(unknowns) == 0:
+		// the constraint fixes the booleans if it's injective in them
+		if coeffs, ok := a.coefficients(c); ok {
+			if e, ok := a.expand(coeffs, ^uint32(0)); ok && a.injective(e) {
+				for _, w := range e.wires {
+					a.set(w, determined)
+				}
+				for _, w := range others {
+					a.set(w, determined)
+				}
+				c.done = true
+				return
+			}
+		}
+		if len(others) == 1 {
+			if deg, _ := a.degree(c, others[0]); deg == 1 {
+				a.set(others[0], determined)
+				c.done = true
+			}
+		}
+	}
+}
+
+// degree returns the degree of the constraint in w, assuming the other wires
+// are fixed, and whether its roots are 0 and 1 if it's quadratic. The custom
+// gates are assumed affine in each wire, as the solver requires.
+func (a *analyzer) degree(c *constraintInfo, w uint32) (deg int, isBoolean bool) {
+	if c.r1c != nil {
+		in := func(l constraint.LinearExpression) bool {
+			for _, t := range l {
+				if t.VID == w && t.CID != constraint.CoeffIdZero {
+					return true
+				}
+			}
+			return false
+		}
+		if !in(c.r1c.L) || !in(c.r1c.R) {
+			return 1, false
+		}
+		// (l1⋅w + l0)⋅(r1⋅w + r0) == o1⋅w + o0
+		l1, l0, okL := a.split(c.r1c.L, w)
+		r1, r0, okR := a.split(c.r1c.R, w)
+		o1, o0, okO := a.split(c.r1c.O, w)
+		if !okL || !okR || !okO {
+			return 2, false
+		}
+		c2 := a.cs.Mul(l1, r1)
+		c1 := a.cs.Sub(a.cs.Add(a.cs.Mul(l1, r0), a.cs.Mul(l0, r1)), o1)
+		c0 := a.cs.Sub(a.cs.Mul(l0, r0), o0)
+		c1 = a.cs.Add(c2, c1)
+		return 2, c0.IsZero() && c1.IsZero()
+	}
+
+	s := c.sparse
+	if s.QM == constraint.CoeffIdZero || s.XA != w || s.XB != w {
+		return 1, false
+	}
+	// qM⋅w² + (qL + qR)⋅w + qC == 0, with no other wire
+	var c1 constraint.Element
+	for _, t := range linearTerms(s) {
+		if t.CID == constraint.CoeffIdZero {
+			continue
+		}
+		if t.VID != w {
+			return 2, false
+		}
+		c1 = a.cs.Add(c1, a.cs.GetCoefficient(int(t.CID)))
+	}
+	if s.QG != constraint.CoeffIdZero || s.QC != constraint.CoeffIdZero {
+		return 2, false
+	}
+	c1 = a.cs.Add(a.cs.GetCoefficient(int(s.QM)), c1)
+	return 2, c1.IsZero()
+}
+
+// split returns the coefficient of w in l and its constant part, if l has no
+// other wire than w and the constant wire of a R1CS.
+func (a *analyzer) split(l constraint.LinearExpression, w uint32) (k1, k0 constraint.Element, ok bool) {
+	for _, t := range l {
+		switch t.VID {
+		case w:
+			k1 = a.cs.Add(k1, a.cs.GetCoefficient(int(t.CID)))
+		case 0:
+			k0 = a.cs.Add(k0, a.cs.GetCoefficient(int(t.CID)))
+		default:
+			return k1, k0, false
+		}
+	}
+	return k1, k0, true
+}
+
+// coefficients returns the coefficients of the wires which are not determined
+// in the constraint, up to a common factor, if it's affine in them with
+// constant coefficients.
+func (a *analyzer) coefficients(c *constraintInfo) (map[uint32]constraint.Element, bool) {
+	coeffs := make(map[uint32]constraint.Element)
+	add := func(l constraint.LinearExpression, k constraint.Element) {
+		for _, t := range l {
+			if !t.IsConstant() && a.states[t.VID] != determined {
+				coeffs[t.VID] = a.cs.Add(coeffs[t.VID], a.cs.Mul(k, a.cs.GetCoefficient(int(t.CID))))
+			}
+		}
+	}
+	fixed := func(l constraint.LinearExpression) bool {
+		for _, t := range l {
+			if !t.IsConstant() && a.states[t.VID] != determined {
+				return false
+			}
+		}
+		return true
+	}
+	minusOne := a.cs.Neg(a.cs.One())
+
+	if c.r1c != nil {
+		l, r, o := c.r1c.L, c.r1c.R, c.r1c.O
+		if !fixed(l) {
+			l, r = r, l
+		}
+		switch {
+		case !fixed(l):
+			return nil, false
+		case fixed(r):
+			add(o, minusOne)
+		default:
+			// L⋅R == O, with L fixed: the coefficients of R are scaled by the
+			// value of L, which must be constant if O isn't fixed.
+			k, isConstant := a.constant(l)
+			if !isConstant && !fixed(o) {
+				return nil, false
+			}
+			if !isConstant {
+				k = a.cs.One()
+			}
+			add(r, k)
+			add(o, minusOne)
+		}
+		return coeffs, true
+	}
+
+	s := c.sparse
+	if s.QG != constraint.CoeffIdZero && (a.states[s.XA] != determined || a.states[s.XB] != determined || a.states[s.XC] != determined) {
+		return nil, false
+	}
+	if s.QM != constraint.CoeffIdZero && (a.states[s.XA] != determined || a.states[s.XB] != determined) {
+		return nil, false
+	}
+	for _, t := range linearTerms(s) {
+		if t.CID != constraint.CoeffIdZero {
+			add(constraint.LinearExpression{t}, a.cs.One())
+		}
+	}
+	return coeffs, true
+}
+
+// constant returns the value of l if it only references the constant wire of a R1CS.
+func (a *analyzer) constant(l constraint.LinearExpression) (constraint.Element, bool) {
+	var k constraint.Element
+	for _, t := range l {
+		if t.VID != 0 {
+			return k, false
+		}
+		k = a.cs.Add(k, a.cs.GetCoefficient(int(t.CID)))
+	}
+	return k, true
+}
+
+// expansion is an affine combination of boolean wires, without its constant part.
+type expansion struct {
+	wires  []uint32
+	coeffs []constraint.Element
+}
+
+// expand returns the combination of the booleans equal to -(Σ coeffs[w]⋅w)/coeffs[u],
+// with the symbolic wires expanded; u is ignored if it's not in coeffs. It
+// fails if a wire isn't boolean or symbolic.
+func (a *analyzer) expand(coeffs map[uint32]constraint.Element, u uint32) (expansion, bool) {
+	var e expansion
+	index := make(map[uint32]int)
+	add := func(w uint32, k constraint.Element) {
+		if i, ok := index[w]; ok {
+			e.coeffs[i] = a.cs.Add(e.coeffs[i], k)
+			return
+		}
+		index[w] = len(e.wires)
+		e.wires = append(e.wires, w)
+		e.coeffs = append(e.coeffs, k)
+	}
+
+	scale := a.cs.One()
+	if ku, ok := coeffs[u]; ok {
+		inv, ok := a.cs.Inverse(ku)
+		if !ok {
+			return e, false
+		}
+		scale = a.cs.Neg(inv)
+	}
+
+	// iterate in a deterministic order
+	wires := make([]uint32, 0, len(coeffs))
+	for w := range coeffs {
+		wires = append(wires, w)
+	}
+	sort.Slice(wires, func(i, j int) bool { return wires[i] < wires[j] })
+	for _, w := range wires {
+		if w == u {
+			continue
+		}
+		k := a.cs.Mul(scale, coeffs[w])
+		switch a.states[w] {
+		case boolean:
+			add(w, k)
+		case symbolic:
+			s := a.sym[w]
+			for i, b := range s.wires {
+				if a.states[b] != determined {
+					add(b, a.cs.Mul(k, s.coeffs[i]))
+				}
+			}
+		default:
+			return e, false
+		}
+	}
+
+	// remove the cancelled terms
+	n := 0
+	for i := range e.wires {
+		if !e.coeffs[i].IsZero() {
+			e.wires[n], e.coeffs[n] = e.wires[i], e.coeffs[i]
+			n++
+		}
+	}
+	e.wires, e.coeffs = e.wires[:n], e.coeffs[:n]
+	return e, n != 0
+}
+
+// injective returns true if Σ e.coeffs[i]⋅e.wires[i] determines the booleans,
+// which holds if the coefficients are, up to a common factor, distinct powers
+// of two whose sum is smaller than the modulus.
+func (a *analyzer) injective(e expansion) bool {
+	inv, ok := a.cs.Inverse(e.coeffs[0])
+	if !ok {
+		return false
+	}
+	exponents := make([]int, len(e.coeffs))
+	for i := range e.coeffs {
+		r := a.cs.Mul(e.coeffs[i], inv)
+		if k, ok := log2(a.cs.ToBigInt(r)); ok {
+			exponents[i] = k
+		} else if r, ok = a.cs.Inverse(r); !ok {
+			return false
+		} else if k, ok = log2(a.cs.ToBigInt(r)); ok {
+			exponents[i] = -k
+		} else {
+			return false
+		}
+	}
+	sort.Ints(exponents)
+	for i := 1; i < len(exponents); i++ {
+		if exponents[i] == exponents[i-1] {
+			return false
+		}
+	}
+	return exponents[len(exponents)-1]-exponents[0] <= a.cs.FieldBitLen()-2
+}
+
+// log2 returns k if v == 2ᵏ.
+func log2(v *big.Int) (int, bool) {
+	if v.Sign() <= 0 {
+		return 0, false
+	}
+	k := v.BitLen() - 1
+	return k, v.TrailingZeroBits() == uint(k)
+}
+
+// findings reports the hint outputs which aren't determined.
+func (a *analyzer) findings() {
+	for w := range a.states {
+		if a.hintOf[w] < 0 || a.states[w] == determined {
+			continue
+		}
+		f := Finding{
+			Wire: w,
+			Name: a.cs.VariableToString(w),
+			Hint: a.system.MHintsDependencies[a.hints[a.hintOf[w]].id],
+			Kind: Finite,
+		}
+		switch {
+		case len(a.uses[w]) == 0:
+			f.Kind = Unconstrained
+		case a.states[w] == unknown:
+			f.Kind = Underdetermined
+		}
+		f.Locations = a.locations(w)
+		a.report.Findings = append(a.report.Findings, f)
+	}
+}
+
+// locations returns the source locations of the constraints using w.
+func (a *analyzer) locations(w int) []string {
+	var locations []string
+	seen := make(map[string]bool)
+	st := &a.system.SymbolTable
+	for _, c := range a.uses[w] {
+		dID, ok := a.system.MDebug[a.constraints[c].id]
+		if !ok {
+			continue
+		}
+		for _, lID := range a.system.DebugInfo[dID].Stack {
+			location := st.Locations[lID]
+			function := st.Functions[location.FunctionID]
+			l := fmt.Sprintf("%s:%d %s", function.Filename, location.Line, function.Name)
+			if !seen[l] {
+				seen[l] = true
+				locations = append(locations, l)
+			}
+		}
+	}
+	return locations
+}
+
+// confirm solves the system with other values of the wires of the findings.
+func (a *analyzer) confirm(cfg *config) error {
+	if err := a.cs.IsSolved(cfg.witness, cfg.solverOpts...); err != nil {
+		return fmt.Errorf("the witness doesn't solve the constraint system: %w", err)
+	}
+	solverCfg, err := solver.NewConfig(cfg.solverOpts...)
+	if err != nil {
+		return err
+	}
+	modulus := a.cs.Field()
+	alternatives := []func(v *big.Int) *big.Int{
+		func(v *big.Int) *big.Int { return new(big.Int).Neg(v) },
+		func(v *big.Int) *big.Int { return new(big.Int).Sub(big.NewInt(1), v) },
+		func(v *big.Int) *big.Int { return new(big.Int).Add(v, big.NewInt(1)) },
+		func(v *big.Int) *big.Int { r, _ := rand.Int(rand.Reader, modulus); return r },
+	}
+
+	for i := range a.report.Findings {
+		f := &a.report.Findings[i]
+		h := a.hints[a.hintOf[f.Wire]]
+		hint, ok := solverCfg.HintFunctions[h.id]
+		if !ok {
+			continue
+		}
+		output := f.Wire - int(h.start)
+		for _, alternative := range alternatives {
+			var lock sync.Mutex
+			calls, changed := 0, false
+			override := func(mod *big.Int, inputs, outputs []*big.Int) error {
+				if err := hint(mod, inputs, outputs); err != nil {
+					return err
+				}
+				lock.Lock()
+				defer lock.Unlock()
+				if calls == h.call {
+					v := alternative(outputs[output])
+					v.Mod(v, mod)
+					changed = v.Cmp(outputs[output]) != 0
+					outputs[output].Set(v)
+				}
+				calls++
+				return nil
+			}
+			opts := append(cfg.solverOpts[:len(cfg.solverOpts):len(cfg.solverOpts)],
+				solver.OverrideHint(h.id, override), solver.WithNbTasks(1), solver.WithLogger(zerolog.Nop()))
+			if err := a.cs.IsSolved(cfg.witness, opts...); err == nil && changed {
+				f.Confirmed = true
+				break
+			}
+		}
+	}
+	return nil
+}
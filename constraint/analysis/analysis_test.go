@@ -0,0 +1,126 @@
+package analysis_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint/analysis"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+)
+
+func sqrtHint(mod *big.Int, inputs, outputs []*big.Int) error {
+	if outputs[0].ModSqrt(inputs[0], mod) == nil {
+		return errors.New("no square root")
+	}
+	return nil
+}
+
+// splitHint returns x-1 and 1.
+func splitHint(_ *big.Int, inputs, outputs []*big.Int) error {
+	outputs[0].Sub(inputs[0], big.NewInt(1))
+	outputs[1].SetUint64(1)
+	return nil
+}
+
+func bitHint(_ *big.Int, inputs, outputs []*big.Int) error {
+	outputs[0].SetUint64(uint64(inputs[0].Bit(0)))
+	return nil
+}
+
+type soundCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *soundCircuit) Define(api frontend.API) error {
+	bits := api.ToBinary(c.X, 8)
+	api.AssertIsEqual(api.FromBinary(bits...), c.X)
+	api.AssertIsEqual(api.Mul(api.Inverse(c.X), c.X), 1)
+	api.AssertIsEqual(api.IsZero(api.Sub(c.X, c.Y)), 1)
+	api.AssertIsEqual(api.Div(c.Y, c.X), api.Add(bits[0], bits[1]))
+	return nil
+}
+
+type unsoundCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *unsoundCircuit) Define(api frontend.API) error {
+	r, err := api.Compiler().NewHint(sqrtHint, 1, c.X)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(api.Mul(r[0], r[0]), c.X)
+
+	s, err := api.Compiler().NewHint(splitHint, 2, c.Y)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(api.Add(s[0], s[1]), c.Y)
+
+	b, err := api.Compiler().NewHint(bitHint, 1, c.Y)
+	if err != nil {
+		return err
+	}
+	api.AssertIsBoolean(b[0])
+
+	_, err = api.Compiler().NewHint(sqrtHint, 1, c.Y)
+	return err
+}
+
+func TestAnalyze(t *testing.T) {
+	solver.RegisterHint(sqrtHint, splitHint, bitHint)
+	assert := test.NewAssert(t)
+	field := ecc.BN254.ScalarField()
+
+	for _, b := range []struct {
+		name       string
+		newBuilder frontend.NewBuilder
+	}{{"r1cs", r1cs.NewBuilder}, {"scs", scs.NewBuilder}} {
+		assert.Run(func(assert *test.Assert) {
+			ccs, err := frontend.Compile(field, b.newBuilder, &soundCircuit{})
+			assert.NoError(err)
+			w, err := frontend.NewWitness(&soundCircuit{X: 9, Y: 9}, field)
+			assert.NoError(err)
+			report, err := analysis.Analyze(ccs, analysis.WithWitness(w))
+			assert.NoError(err)
+			assert.Empty(report.Findings, report)
+
+			ccs, err = frontend.Compile(field, b.newBuilder, &unsoundCircuit{})
+			assert.NoError(err)
+			w, err = frontend.NewWitness(&unsoundCircuit{X: 9, Y: 4}, field)
+			assert.NoError(err)
+			report, err = analysis.Analyze(ccs, analysis.WithWitness(w))
+			assert.NoError(err)
+
+			kinds := make(map[analysis.Kind]int)
+			confirmed := 0
+			for _, f := range report.Findings {
+				kinds[f.Kind]++
+				if f.Confirmed {
+					confirmed++
+				}
+			}
+			// the square root and the boolean, the outputs of splitHint, and the
+			// unused square root
+			assert.Equal(map[analysis.Kind]int{analysis.Finite: 2, analysis.Underdetermined: 2, analysis.Unconstrained: 1}, kinds, report)
+			// the outputs of splitHint can only be changed together
+			assert.Equal(3, confirmed, report)
+
+			// the witness must solve the system
+			w, err = frontend.NewWitness(&soundCircuit{X: 9, Y: 8}, field)
+			assert.NoError(err)
+			ccs, err = frontend.Compile(field, b.newBuilder, &soundCircuit{})
+			assert.NoError(err)
+			_, err = analysis.Analyze(ccs, analysis.WithWitness(w))
+			assert.Error(err)
+		}, b.name)
+	}
+}
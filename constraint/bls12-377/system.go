@@ -17,6 +17,8 @@
 package cs
 
 import (
+	"encoding/binary"
+	"errors"
 	"io"
 	"time"
 
@@ -106,8 +108,8 @@ func (cs *system) Solve(witness witness.Witness, opts ...csolver.Option) (any, e
 	} else {
 		// sparse R1CS
 		var res SparseR1CSSolution
-		// query l, r, o in Lagrange basis, not blinded
-		res.L, res.R, res.O = evaluateLROSmallDomain(cs, solver.values)
+		// query l, r, o and the advice wires in Lagrange basis, not blinded
+		res.L, res.R, res.O, res.Extra = evaluateLROSmallDomain(cs, solver.values)
 
 		return &res, nil
 	}
@@ -167,10 +169,11 @@ func (cs *system) GetSparseR1Cs() []constraint.SparseR1C {
 	return toReturn
 }
 
-// evaluateLROSmallDomain extracts the solver l, r, o, and returns it in lagrange form.
+// evaluateLROSmallDomain extracts the solver l, r, o and the advice wires
+// (if the system has more than 3 wires), and returns them in lagrange form.
 // solver = [ public | secret | internal ]
 // TODO @gbotrel refactor; this seems to be a small util function for plonk
-func evaluateLROSmallDomain(cs *system, solution []fr.Element) ([]fr.Element, []fr.Element, []fr.Element) {
+func evaluateLROSmallDomain(cs *system, solution []fr.Element) ([]fr.Element, []fr.Element, []fr.Element, []fr.Vector) {
 
 	//s := int(pk.Domain[0].Cardinality)
 	s := cs.GetNbConstraints() + len(cs.Public) // len(spr.Public) is for the placeholder constraints
@@ -180,12 +183,19 @@ func evaluateLROSmallDomain(cs *system, solution []fr.Element) ([]fr.Element, []
 	l = make([]fr.Element, s, s+4) // +4 to leave room for the blinding in plonk
 	r = make([]fr.Element, s, s+4)
 	o = make([]fr.Element, s, s+4)
+	extra := make([]fr.Vector, cs.GetNbWires()-3)
+	for i := range extra {
+		extra[i] = make([]fr.Element, s, s+4)
+	}
 	s0 := solution[0]
 
 	for i := 0; i < len(cs.Public); i++ { // placeholders
 		l[i] = solution[i]
 		r[i] = s0
 		o[i] = s0
+		for _, e := range extra {
+			e[i] = s0
+		}
 	}
 	offset := len(cs.Public)
 	nbConstraints := cs.GetNbConstraints()
@@ -200,6 +210,10 @@ func evaluateLROSmallDomain(cs *system, solution []fr.Element) ([]fr.Element, []
 			l[offset+j] = solution[sparseR1C.XA]
 			r[offset+j] = solution[sparseR1C.XB]
 			o[offset+j] = solution[sparseR1C.XC]
+			advice := [...]uint32{sparseR1C.XD, sparseR1C.XE}
+			for i := range extra {
+				extra[i][offset+j] = solution[advice[i]]
+			}
 			j++
 		}
 	}
@@ -210,9 +224,12 @@ func evaluateLROSmallDomain(cs *system, solution []fr.Element) ([]fr.Element, []
 		l[offset+i] = s0
 		r[offset+i] = s0
 		o[offset+i] = s0
+		for _, e := range extra {
+			e[offset+i] = s0
+		}
 	}
 
-	return l, r, o
+	return l, r, o, extra
 
 }
 
@@ -266,6 +283,10 @@ func (t *R1CSSolution) ReadFrom(r io.Reader) (int64, error) {
 // SparseR1CSSolution represent a valid assignment to all the variables in the constraint system.
 type SparseR1CSSolution struct {
 	L, R, O fr.Vector
+
+	// Extra are the values of the advice wires xd, xe of the constraints, for
+	// a system with more than 3 wires.
+	Extra []fr.Vector
 }
 
 func (t *SparseR1CSSolution) WriteTo(w io.Writer) (int64, error) {
@@ -280,8 +301,21 @@ func (t *SparseR1CSSolution) WriteTo(w io.Writer) (int64, error) {
 	}
 	a, err = t.O.WriteTo(w)
 	n += a
-	return n, err
-
+	if err != nil {
+		return n, err
+	}
+	if err = binary.Write(w, binary.BigEndian, uint32(len(t.Extra))); err != nil {
+		return n, err
+	}
+	n += 4
+	for _, e := range t.Extra {
+		a, err = e.WriteTo(w)
+		n += a
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
 }
 
 func (t *SparseR1CSSolution) ReadFrom(r io.Reader) (int64, error) {
@@ -296,7 +330,26 @@ func (t *SparseR1CSSolution) ReadFrom(r io.Reader) (int64, error) {
 	}
 	a, err = t.O.ReadFrom(r)
 	n += a
-	return n, err
+	if err != nil {
+		return n, err
+	}
+	var nbExtra uint32
+	if err = binary.Read(r, binary.BigEndian, &nbExtra); err != nil {
+		return n, err
+	}
+	n += 4
+	if nbExtra > constraint.MaxNbWires-3 {
+		return n, errors.New("invalid number of advice wires")
+	}
+	t.Extra = make([]fr.Vector, nbExtra)
+	for i := range t.Extra {
+		a, err = t.Extra[i].ReadFrom(r)
+		n += a
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
 }
 
 func (s *system) AddGkr(gkr constraint.GkrInfo) error {
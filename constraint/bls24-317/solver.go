@@ -17,6 +17,7 @@
 package cs
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
@@ -49,6 +50,7 @@ type solver struct {
 	// used to out api.Println
 	logger  zerolog.Logger
 	nbTasks int
+	ctx     context.Context
 
 	a, b, c fr.Vector // R1CS solver will compute the a,b,c matrices
 
@@ -104,6 +106,7 @@ func newSolver(cs *system, witness fr.Vector, opts ...csolver.Option) (*solver,
 		mHintsFunctions: hintFunctions,
 		logger:          opt.Logger,
 		nbTasks:         opt.NbTasks,
+		ctx:             opt.Context,
 		q:               cs.Field(),
 	}
 
@@ -468,7 +471,13 @@ func (solver *solver) run() error {
 	var scratch scratch
 
 	// for each level, we push the tasks
+	done := solver.ctx.Done()
 	for _, level := range solver.Levels {
+		select {
+		case <-done:
+			return solver.ctx.Err()
+		default:
+		}
 
 		// max CPU to use
 		maxCPU := float64(len(level)) / minWorkPerCPU
@@ -0,0 +1,230 @@
+package constraint
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxCustomGateDegree is the maximal degree of a CustomGate in the wires of a
+// constraint. The quotient of the PLONK prover is split in as many chunks as
+// the maximal degree of the gates, if it exceeds the number of wires, so that
+// the proofs grow with the degree of the gates.
+const MaxCustomGateDegree = 8
+
+// CustomGate is a gate of a SparseR1CS in addition to the standard gate
+// qL⋅xa + qR⋅xb + qO⋅xc + qM⋅(xaxb) + qC. It is a polynomial gate(xa, xb, xc)
+// in the wires of a constraint, of degree at most MaxCustomGateDegree, with
+// its own selector qG. A constraint using the gate encodes
+//
+//	qL⋅xa + qR⋅xb + qO⋅xc + qM⋅(xaxb) + qC + qG⋅gate(xa, xb, xc) == 0
+//
+// The optimizer doesn't support the custom gates: Optimize returns
+// ErrOptimizationUnsupported for a system using them.
+type CustomGate struct {
+	Name  string
+	Terms []GateTerm
+}
+
+// GateTerm is a monomial coeff⋅xaᴬ⋅xbᴮ⋅xcᶜ of a CustomGate, where coeff is the
+// coefficient of id CID.
+type GateTerm struct {
+	CID     uint32
+	A, B, C uint8
+}
+
+// Degree returns the degree of the term.
+func (t GateTerm) Degree() int {
+	return int(t.A) + int(t.B) + int(t.C)
+}
+
+// Degree returns the degree of the gate.
+func (g *CustomGate) Degree() int {
+	res := 0
+	for _, t := range g.Terms {
+		res = max(res, t.Degree())
+	}
+	return res
+}
+
+// String formats the gate as name(xa, xb, xc) = coeff⋅xaᴬxbᴮxcᶜ + ...
+func (g *CustomGate) String(r Resolver) string {
+	sbb := NewStringBuilder(r)
+	sbb.WriteString(g.Name)
+	sbb.WriteString("(xa, xb, xc) = ")
+	for i, t := range g.Terms {
+		if i > 0 {
+			sbb.WriteString(" + ")
+		}
+		sbb.WriteString(sbb.CoeffToString(int(t.CID)))
+		for _, w := range [...]struct {
+			name     string
+			exponent uint8
+		}{{"xa", t.A}, {"xb", t.B}, {"xc", t.C}} {
+			for j := uint8(0); j < w.exponent; j++ {
+				sbb.WriteString("⋅")
+				sbb.WriteString(w.name)
+			}
+		}
+	}
+	return sbb.String()
+}
+
+// AddCustomGate registers the gate and returns the id of the blueprint of the
+// constraints using it, to be given to AddSparseR1C with the selector of the
+// gate in SparseR1C.QG.
+func (system *System) AddCustomGate(g CustomGate) (BlueprintID, error) {
+	if system.Type != SystemSparseR1CS {
+		return 0, errors.New("custom gates are only supported by SparseR1CS")
+	}
+	if len(g.Terms) == 0 {
+		return 0, fmt.Errorf("custom gate %q has no term", g.Name)
+	}
+	if d := g.Degree(); d > MaxCustomGateDegree {
+		return 0, fmt.Errorf("custom gate %q has degree %d, the maximal degree is %d", g.Name, d, MaxCustomGateDegree)
+	}
+	bID := system.AddBlueprint(&BlueprintCustomGate{
+		ID:   uint32(len(system.CustomGates)),
+		Gate: g,
+	})
+	system.CustomGates = append(system.CustomGates, bID)
+	return bID, nil
+}
+
+// GetCustomGates returns the custom gates of the system. A constraint with
+// SparseR1C.Gate == i uses the i-th gate.
+func (system *System) GetCustomGates() []CustomGate {
+	res := make([]CustomGate, len(system.CustomGates))
+	for i, bID := range system.CustomGates {
+		res[i] = system.Blueprints[bID].(*BlueprintCustomGate).Gate
+	}
+	return res
+}
+
+// BlueprintCustomGate implements Blueprint, BlueprintSolvable and BlueprintSparseR1C.
+// Encodes
+//
+//	qL⋅xa + qR⋅xb + qO⋅xc + qM⋅(xaxb) + qC + qG⋅gate(xa, xb, xc) == 0
+//
+// where gate is the custom gate of the blueprint.
+type BlueprintCustomGate struct {
+	// ID is the index of the gate in the custom gates of the system.
+	ID   uint32
+	Gate CustomGate
+}
+
+func (b *BlueprintCustomGate) CalldataSize() int {
+	return 9
+}
+func (b *BlueprintCustomGate) NbConstraints() int {
+	return 1
+}
+func (b *BlueprintCustomGate) NbOutputs(inst Instruction) int {
+	return 0
+}
+
+func (b *BlueprintCustomGate) UpdateInstructionTree(inst Instruction, tree InstructionTree) Level {
+	return updateInstructionTree(inst.Calldata[0:3], tree)
+}
+
+func (b *BlueprintCustomGate) CompressSparseR1C(c *SparseR1C, to *[]uint32) {
+	*to = append(*to, c.XA, c.XB, c.XC, c.QL, c.QR, c.QO, c.QM, c.QC, c.QG)
+}
+
+func (b *BlueprintCustomGate) DecompressSparseR1C(c *SparseR1C, inst Instruction) {
+	c.Clear()
+	c.XA = inst.Calldata[0]
+	c.XB = inst.Calldata[1]
+	c.XC = inst.Calldata[2]
+	c.QL = inst.Calldata[3]
+	c.QR = inst.Calldata[4]
+	c.QO = inst.Calldata[5]
+	c.QM = inst.Calldata[6]
+	c.QC = inst.Calldata[7]
+	c.QG = inst.Calldata[8]
+	c.Gate = b.ID
+}
+
+// Solve solves the wire of the constraint which is not solved yet, if the
+// constraint is affine in this wire. If all the wires are solved, it checks
+// that the constraint holds.
+func (b *BlueprintCustomGate) Solve(s Solver, inst Instruction) error {
+	var c SparseR1C
+	b.DecompressSparseR1C(&c, inst)
+
+	wires := []uint32{c.XA, c.XB, c.XC}
+	return solveAffine(s, wires, func(x []Element) Element {
+		return b.evaluate(s, &c, [3]Element(x))
+	}, func(v Element) error {
+		return fmt.Errorf("qL⋅xa + qR⋅xb + qO⋅xc + qM⋅(xaxb) + qC + qG⋅%s(xa, xb, xc) != 0 → %s != 0", b.Gate.Name, s.String(v))
+	})
+}
+
+// solveAffine solves the wire of a constraint f(x) == 0 which is not solved
+// yet, where x are the values of the wires, if f is affine in this wire. If all
+// the wires are solved, it returns errNotZero(f(x)) if f(x) != 0.
+func solveAffine(s Solver, wires []uint32, f func(x []Element) Element, errNotZero func(Element) error) error {
+	x := make([]Element, len(wires))
+	unsolved, isUnsolved := uint32(0), false
+	for i, w := range wires {
+		if s.IsSolved(w) {
+			x[i] = s.GetValue(CoeffIdOne, w)
+		} else {
+			unsolved, isUnsolved = w, true
+		}
+	}
+
+	if !isUnsolved {
+		if v := f(x); !v.IsZero() {
+			return errNotZero(v)
+		}
+		return nil
+	}
+
+	// the constraint is a polynomial f(t) = f₀ + f₁⋅t + f₂⋅t² in the value t of
+	// the unsolved wire; we get its coefficients from f(0), f(1) and f(-1).
+	at := func(t Element) Element {
+		for i, w := range wires {
+			if w == unsolved {
+				x[i] = t
+			}
+		}
+		return f(x)
+	}
+	one := s.One()
+	f0 := at(Element{})
+	f1 := at(one)
+	fm1 := at(s.Neg(one))
+
+	// f(1) + f(-1) - 2f(0) = 2f₂
+	if f2 := s.Sub(s.Add(f1, fm1), s.Add(f0, f0)); !f2.IsZero() {
+		return errors.New("the constraint is not affine in the unsolved wire")
+	}
+	// f₁ = f(1) - f(0)
+	den, ok := s.Inverse(s.Sub(f1, f0))
+	if !ok {
+		return errDivideByZero
+	}
+	s.SetValue(unsolved, s.Neg(s.Mul(f0, den)))
+	return nil
+}
+
+// evaluate returns the value of the constraint at xa, xb, xc = x[0], x[1], x[2].
+func (b *BlueprintCustomGate) evaluate(s Solver, c *SparseR1C, x [3]Element) Element {
+	res := s.Mul(s.GetCoeff(c.QL), x[0])
+	res = s.Add(res, s.Mul(s.GetCoeff(c.QR), x[1]))
+	res = s.Add(res, s.Mul(s.GetCoeff(c.QO), x[2]))
+	res = s.Add(res, s.Mul(s.GetCoeff(c.QM), s.Mul(x[0], x[1])))
+	res = s.Add(res, s.GetCoeff(c.QC))
+
+	var gate Element
+	for _, t := range b.Gate.Terms {
+		m := s.GetCoeff(t.CID)
+		for i, e := range [3]uint8{t.A, t.B, t.C} {
+			for j := uint8(0); j < e; j++ {
+				m = s.Mul(m, x[i])
+			}
+		}
+		gate = s.Add(gate, m)
+	}
+	return s.Add(res, s.Mul(s.GetCoeff(c.QG), gate))
+}
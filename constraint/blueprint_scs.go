@@ -290,6 +290,97 @@ func (b *BlueprintSparseR1CBool) DecompressSparseR1C(c *SparseR1C, inst Instruct
 	c.QM = inst.Calldata[2]
 }
 
+// BlueprintSparseR1CWide implements Blueprint, BlueprintSolvable and BlueprintSparseR1C.
+// Encodes
+//
+//	qL⋅xa + qR⋅xb + qO⋅xc + qD⋅xd + qE⋅xe + qM⋅(xaxb) + qC == 0
+//
+// where xd, xe are the advice wires of a system with more than 3 wires. With 4
+// wires, xe and qE are 0.
+type BlueprintSparseR1CWide struct{}
+
+func (b *BlueprintSparseR1CWide) CalldataSize() int {
+	return 12
+}
+func (b *BlueprintSparseR1CWide) NbConstraints() int {
+	return 1
+}
+func (b *BlueprintSparseR1CWide) NbOutputs(inst Instruction) int {
+	return 0
+}
+
+func (b *BlueprintSparseR1CWide) UpdateInstructionTree(inst Instruction, tree InstructionTree) Level {
+	return updateInstructionTree(inst.Calldata[0:5], tree)
+}
+
+func (b *BlueprintSparseR1CWide) CompressSparseR1C(c *SparseR1C, to *[]uint32) {
+	*to = append(*to, c.XA, c.XB, c.XC, c.XD, c.XE, c.QL, c.QR, c.QO, c.QD, c.QE, c.QM, c.QC)
+}
+
+func (b *BlueprintSparseR1CWide) DecompressSparseR1C(c *SparseR1C, inst Instruction) {
+	c.Clear()
+	c.XA = inst.Calldata[0]
+	c.XB = inst.Calldata[1]
+	c.XC = inst.Calldata[2]
+	c.XD = inst.Calldata[3]
+	c.XE = inst.Calldata[4]
+	c.QL = inst.Calldata[5]
+	c.QR = inst.Calldata[6]
+	c.QO = inst.Calldata[7]
+	c.QD = inst.Calldata[8]
+	c.QE = inst.Calldata[9]
+	c.QM = inst.Calldata[10]
+	c.QC = inst.Calldata[11]
+}
+
+// Solve solves the wire of the constraint which is not solved yet. If all the
+// wires are solved, it checks that the constraint holds.
+func (b *BlueprintSparseR1CWide) Solve(s Solver, inst Instruction) error {
+	var c SparseR1C
+	b.DecompressSparseR1C(&c, inst)
+
+	wires := [...]uint32{c.XA, c.XB, c.XC, c.XD, c.XE}
+	coeffs := [...]uint32{c.QL, c.QR, c.QO, c.QD, c.QE}
+
+	// the constraint is k + q⋅u == 0 where u is the value of the unsolved
+	// wire, if any; a wire may appear several times.
+	unsolved, isUnsolved := uint32(0), false
+	k := s.GetCoeff(c.QC)
+	var q Element
+	for i, w := range wires {
+		if !s.IsSolved(w) {
+			unsolved, isUnsolved = w, true
+			q = s.Add(q, s.GetCoeff(coeffs[i]))
+			continue
+		}
+		k = s.Add(k, s.GetValue(coeffs[i], w))
+	}
+	aSolved, bSolved := s.IsSolved(c.XA), s.IsSolved(c.XB)
+	switch {
+	case aSolved && bSolved:
+		k = s.Add(k, s.Mul(s.GetValue(c.QM, c.XA), s.GetValue(CoeffIdOne, c.XB)))
+	case aSolved:
+		q = s.Add(q, s.GetValue(c.QM, c.XA))
+	case bSolved:
+		q = s.Add(q, s.GetValue(c.QM, c.XB))
+	case c.QM != CoeffIdZero:
+		return errors.New("qM⋅(xaxb) is not affine in the unsolved wire")
+	}
+
+	if !isUnsolved {
+		if !k.IsZero() {
+			return fmt.Errorf("qL⋅xa + qR⋅xb + qO⋅xc + qD⋅xd + qE⋅xe + qM⋅(xaxb) + qC != 0 → %s != 0", s.String(k))
+		}
+		return nil
+	}
+	q, ok := s.Inverse(q)
+	if !ok {
+		return errDivideByZero
+	}
+	s.SetValue(unsolved, s.Neg(s.Mul(k, q)))
+	return nil
+}
+
 func updateInstructionTree(wires []uint32, tree InstructionTree) Level {
 	// constraint has at most one unsolved wire.
 	var outputWire uint32
@@ -17,6 +17,7 @@
 package cs
 
 import (
+	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -99,3 +100,14 @@ func (cs *system) ReadFrom(r io.Reader) (int64, error) {
 
 	return int64(totalLen) + 4*8, nil
 }
+
+// Digest returns the SHA-256 digest of the canonical serialization of the
+// system (see constraint.System.WriteCanonical) and of its coefficients.
+func (cs *system) Digest() ([]byte, error) {
+	h := sha256.New()
+	if err := cs.System.WriteCanonical(h); err != nil {
+		return nil, err
+	}
+	h.Write(cs.CoeffTable.toBytes())
+	return h.Sum(nil), nil
+}
@@ -126,6 +126,13 @@ type System struct {
 	CommitmentInfo Commitments
 	GkrInfo        GkrInfo
 
+	// blueprints of the custom gates, by index of the gate
+	CustomGates []BlueprintID
+
+	// number of wires of the constraints of a SparseR1CS, 0 for the standard 3
+	// wires xa, xb, xc. See SparseR1CS.SetNbWires.
+	NbWires int
+
 	genericHint BlueprintID
 }
 
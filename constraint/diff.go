@@ -0,0 +1,542 @@
+package constraint
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark/internal/embedded"
+)
+
+// SystemDiff lists the differences between two constraint systems, see Diff.
+type SystemDiff struct {
+	// Header lists the differences of type, field, size and custom gates.
+	Header []string
+
+	// Public and Secret list the input variables whose name changed, by
+	// index in the witness.
+	Public, Secret []VariableDiff
+
+	// Constraints lists the constraints removed from the first system and
+	// added in the second one, by source location.
+	Constraints []LocationDiff
+
+	// Commitments lists the differences of the commitments.
+	Commitments []string
+
+	// Blueprints lists the blueprints whose number of instructions changed.
+	Blueprints []string
+}
+
+// VariableDiff is an input variable whose name changed; A or B is empty if
+// the variable doesn't exist in the corresponding system.
+type VariableDiff struct {
+	Index int
+	A, B  string
+}
+
+// LocationDiff lists the constraints removed and added at a source location,
+// formatted with the Resolver of their system.
+type LocationDiff struct {
+	// Location is the innermost frame outside of the frontend of the stack
+	// recorded in the symbol table, empty for the constraints without debug
+	// information.
+	Location       string
+	Removed, Added []string
+}
+
+// Empty returns true if the systems are equivalent.
+func (d *SystemDiff) Empty() bool {
+	return len(d.Header) == 0 && len(d.Public) == 0 && len(d.Secret) == 0 &&
+		len(d.Constraints) == 0 && len(d.Commitments) == 0 && len(d.Blueprints) == 0
+}
+
+func (d *SystemDiff) String() string {
+	if d.Empty() {
+		return "no differences"
+	}
+	var sbb strings.Builder
+	for _, h := range d.Header {
+		sbb.WriteString(h)
+		sbb.WriteByte('\n')
+	}
+	for _, v := range [...]struct {
+		name  string
+		diffs []VariableDiff
+	}{{"public", d.Public}, {"secret", d.Secret}} {
+		for _, vd := range v.diffs {
+			fmt.Fprintf(&sbb, "%s variable %d: %q -> %q\n", v.name, vd.Index, vd.A, vd.B)
+		}
+	}
+	for _, c := range d.Commitments {
+		sbb.WriteString(c)
+		sbb.WriteByte('\n')
+	}
+	for _, b := range d.Blueprints {
+		sbb.WriteString(b)
+		sbb.WriteByte('\n')
+	}
+	for _, l := range d.Constraints {
+		location := l.Location
+		if location == "" {
+			location = "(no debug information)"
+		}
+		fmt.Fprintf(&sbb, "%s: -%d +%d constraints\n", location, len(l.Removed), len(l.Added))
+	}
+	return strings.TrimSuffix(sbb.String(), "\n")
+}
+
+// Diff aligns two constraint systems and returns their differences.
+//
+// An input wire is identified by its visibility and its index in the
+// witness, so that renamed inputs are only reported in Public and Secret.
+// The constraints are compared independently of the indexes of the internal
+// wires, which change with any added constraint: an internal wire is
+// identified by the instruction which first references it (a hint or the
+// constraint solving it), recursively. Two constraints are then equal if
+// they have the same coefficients on the same wires; the alignment is
+// robust to reordered instructions and to inserted or removed constraints.
+// The outputs of the blueprints which are neither constraints nor hints (e.g.
+// lookups) are identified by their rank among the instructions of the
+// blueprint.
+//
+// The systems are compared if they have the same type and field, otherwise
+// only the header differs.
+func Diff(a, b ConstraintSystem) (*SystemDiff, error) {
+	sa, err := embedded.Get[System](a)
+	if err != nil {
+		return nil, err
+	}
+	sb, err := embedded.Get[System](b)
+	if err != nil {
+		return nil, err
+	}
+	da, db := newDiffSystem(a, sa), newDiffSystem(b, sb)
+
+	var d SystemDiff
+	differ := func(name string, va, vb any) {
+		if va != vb {
+			d.Header = append(d.Header, fmt.Sprintf("%s: %v -> %v", name, va, vb))
+		}
+	}
+	differ("type", da.system.typeName(), db.system.typeName())
+	differ("field", a.Field().String(), b.Field().String())
+	if len(d.Header) != 0 {
+		return &d, nil
+	}
+	if da.system.Type == SystemSparseR1CS {
+		differ("wires per constraint", da.system.GetNbWires(), db.system.GetNbWires())
+	}
+	differ("constraints", a.GetNbConstraints(), b.GetNbConstraints())
+	differ("internal variables", a.GetNbInternalVariables(), b.GetNbInternalVariables())
+	differ("custom gates", strings.Join(da.customGates(), ", "), strings.Join(db.customGates(), ", "))
+
+	d.Public = diffVariables(da.system.Public, db.system.Public)
+	d.Secret = diffVariables(da.system.Secret, db.system.Secret)
+
+	da.align()
+	db.align()
+	d.Constraints = diffConstraints(da, db)
+	d.Commitments = diffCommitments(da, db)
+	d.Blueprints = diffBlueprints(da, db)
+
+	return &d, nil
+}
+
+func (system *System) typeName() string {
+	switch system.Type {
+	case SystemR1CS:
+		return "R1CS"
+	case SystemSparseR1CS:
+		return "SparseR1CS"
+	default:
+		return "unknown"
+	}
+}
+
+func diffVariables(a, b []string) []VariableDiff {
+	var res []VariableDiff
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var va, vb string
+		if i < len(a) {
+			va = a[i]
+		}
+		if i < len(b) {
+			vb = b[i]
+		}
+		if va != vb {
+			res = append(res, VariableDiff{Index: i, A: va, B: vb})
+		}
+	}
+	return res
+}
+
+func diffConstraints(a, b *diffSystem) []LocationDiff {
+	locations := make(map[string]*LocationDiff)
+	location := func(l string) *LocationDiff {
+		if ld, ok := locations[l]; ok {
+			return ld
+		}
+		ld := &LocationDiff{Location: l}
+		locations[l] = ld
+		return ld
+	}
+
+	// multiset difference of the keys
+	unmatched := func(x, y *diffSystem, f func(ld *LocationDiff, c string)) {
+		count := make(map[uint64]int, len(y.keys))
+		for _, k := range y.keys {
+			count[k]++
+		}
+		for cID, k := range x.keys {
+			if count[k] > 0 {
+				count[k]--
+				continue
+			}
+			f(location(x.location(cID)), x.constraintString(cID))
+		}
+	}
+	unmatched(a, b, func(ld *LocationDiff, c string) { ld.Removed = append(ld.Removed, c) })
+	unmatched(b, a, func(ld *LocationDiff, c string) { ld.Added = append(ld.Added, c) })
+
+	res := make([]LocationDiff, 0, len(locations))
+	for _, ld := range locations {
+		res = append(res, *ld)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Location < res[j].Location })
+	return res
+}
+
+func diffCommitments(a, b *diffSystem) []string {
+	var res []string
+	differ := func(i int, what string, la, lb []uint64) {
+		if len(la) != len(lb) {
+			res = append(res, fmt.Sprintf("commitment %d: %d -> %d %s", i, len(la), len(lb), what))
+			return
+		}
+		for j := range la {
+			if la[j] != lb[j] {
+				res = append(res, fmt.Sprintf("commitment %d: %s changed", i, what))
+				return
+			}
+		}
+	}
+
+	switch ca := a.system.CommitmentInfo.(type) {
+	case Groth16Commitments:
+		cb, _ := b.system.CommitmentInfo.(Groth16Commitments)
+		if len(ca) != len(cb) {
+			res = append(res, fmt.Sprintf("commitments: %d -> %d", len(ca), len(cb)))
+		}
+		for i := 0; i < min(len(ca), len(cb)); i++ {
+			if ca[i].NbPublicCommitted != cb[i].NbPublicCommitted {
+				res = append(res, fmt.Sprintf("commitment %d: %d -> %d public committed wires", i, ca[i].NbPublicCommitted, cb[i].NbPublicCommitted))
+			}
+			differ(i, "committed wires", a.wireLabels(ca[i].PublicAndCommitmentCommitted), b.wireLabels(cb[i].PublicAndCommitmentCommitted))
+			differ(i, "private committed wires", a.wireLabels(ca[i].PrivateCommitted), b.wireLabels(cb[i].PrivateCommitted))
+			differ(i, "commitment wire", a.wireLabels([]int{ca[i].CommitmentIndex}), b.wireLabels([]int{cb[i].CommitmentIndex}))
+		}
+	case PlonkCommitments:
+		cb, _ := b.system.CommitmentInfo.(PlonkCommitments)
+		if len(ca) != len(cb) {
+			res = append(res, fmt.Sprintf("commitments: %d -> %d", len(ca), len(cb)))
+		}
+		for i := 0; i < min(len(ca), len(cb)); i++ {
+			differ(i, "committed constraints", a.constraintLabels(ca[i].Committed), b.constraintLabels(cb[i].Committed))
+			differ(i, "commitment constraint", a.constraintLabels([]int{ca[i].CommitmentIndex}), b.constraintLabels([]int{cb[i].CommitmentIndex}))
+		}
+	}
+	return res
+}
+
+func diffBlueprints(a, b *diffSystem) []string {
+	names := make([]string, 0, len(a.blueprints))
+	for name := range a.blueprints {
+		names = append(names, name)
+	}
+	for name := range b.blueprints {
+		if _, ok := a.blueprints[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var res []string
+	for _, name := range names {
+		if a.blueprints[name] != b.blueprints[name] {
+			res = append(res, fmt.Sprintf("%s: %d -> %d instructions", name, a.blueprints[name], b.blueprints[name]))
+		}
+	}
+	return res
+}
+
+// diffSystem labels the wires and the constraints of a system independently
+// of the indexes of the internal wires.
+type diffSystem struct {
+	cs     ConstraintSystem
+	system *System
+
+	labels     []uint64       // labels of the wires, 0 if not labelled yet
+	keys       []uint64       // labels of the constraints, by constraint ID
+	sources    []int          // instruction of each constraint
+	blueprints map[string]int // number of instructions by blueprint name
+
+	fresh []uint32 // wires first referenced by the current instruction
+}
+
+func newDiffSystem(cs ConstraintSystem, system *System) *diffSystem {
+	return &diffSystem{
+		cs:         cs,
+		system:     system,
+		blueprints: make(map[string]int),
+	}
+}
+
+func (d *diffSystem) customGates() []string {
+	res := make([]string, len(d.system.CustomGates))
+	for i, id := range d.system.CustomGates {
+		res[i] = blueprintName(d.system.Blueprints[id])
+	}
+	return res
+}
+
+// blueprintName identifies a blueprint between systems.
+func blueprintName(b Blueprint) string {
+	if g, ok := b.(*BlueprintCustomGate); ok {
+		return "custom gate " + strconv.Quote(g.Gate.Name)
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", b), "*")
+}
+
+// align computes the labels of the wires and the constraints, in the order
+// of the instructions.
+func (d *diffSystem) align() {
+	nbInputs := len(d.system.Public) + len(d.system.Secret)
+	d.labels = make([]uint64, nbInputs+d.system.NbInternalVariables)
+	// the names of the inputs are compared separately, see SystemDiff.Public
+	for i := range d.system.Public {
+		d.labels[i] = newDiffHasher().string("public").word(uint64(i)).sum()
+	}
+	for i := range d.system.Secret {
+		d.labels[len(d.system.Public)+i] = newDiffHasher().string("secret").word(uint64(i)).sum()
+	}
+	d.keys = make([]uint64, 0, d.system.NbConstraints)
+	d.sources = make([]int, 0, d.system.NbConstraints)
+
+	ranks := make(map[BlueprintID]int)
+	var (
+		r1c    R1C
+		sparse SparseR1C
+		hint   HintMapping
+	)
+	for i, pi := range d.system.Instructions {
+		inst := pi.Unpack(d.system)
+		blueprint := d.system.Blueprints[pi.BlueprintID]
+		name := blueprintName(blueprint)
+		d.blueprints[name]++
+		d.fresh = d.fresh[:0]
+
+		h := newDiffHasher().string(name)
+		var outputs, nbOutputs int
+		switch b := blueprint.(type) {
+		case BlueprintR1C:
+			b.DecompressR1C(&r1c, inst)
+			// L⋅R == R⋅L
+			l, r := d.expression(r1c.L), d.expression(r1c.R)
+			h.word(min(l, r)).word(max(l, r)).word(d.expression(r1c.O))
+		case BlueprintSparseR1C:
+			b.DecompressSparseR1C(&sparse, inst)
+			d.hashSparseR1C(h, &sparse)
+		case BlueprintHint:
+			b.DecompressHint(&hint, inst)
+			hintName, ok := d.system.MHintsDependencies[hint.HintID]
+			if !ok {
+				hintName = strconv.FormatUint(uint64(hint.HintID), 10)
+			}
+			h.string(hintName)
+			for _, in := range hint.Inputs {
+				h.word(d.expression(in))
+			}
+			outputs, nbOutputs = int(hint.OutputRange.Start), int(hint.OutputRange.End-hint.OutputRange.Start)
+		default:
+			// the calldata can't be interpreted, the instruction is identified
+			// by its rank.
+			h.word(uint64(ranks[pi.BlueprintID]))
+			ranks[pi.BlueprintID]++
+			outputs, nbOutputs = int(inst.WireOffset), blueprint.NbOutputs(inst)
+		}
+		key := h.sum()
+
+		// the wires first referenced by a constraint are the ones it solves
+		for j, w := range d.fresh {
+			d.labels[w] = newDiffHasher().word(key).word(uint64(j)).sum()
+		}
+		for j := 0; j < nbOutputs; j++ {
+			d.labels[outputs+j] = newDiffHasher().word(key).string("output").word(uint64(j)).sum()
+		}
+		for j := 0; j < blueprint.NbConstraints(); j++ {
+			if j != 0 {
+				key = newDiffHasher().word(key).word(uint64(j)).sum()
+			}
+			d.keys = append(d.keys, key)
+			d.sources = append(d.sources, i)
+		}
+	}
+}
+
+// label returns the label of a wire, or a placeholder for the wires first
+// referenced by the current instruction.
+func (d *diffSystem) label(w uint32) uint64 {
+	if w == math.MaxUint32 {
+		return 1
+	}
+	if l := d.labels[w]; l != 0 {
+		return l
+	}
+	for j, f := range d.fresh {
+		if f == w {
+			return uint64(j) + 2
+		}
+	}
+	d.fresh = append(d.fresh, w)
+	return uint64(len(d.fresh)) + 1
+}
+
+// term hashes a term, independently of the index of its coefficient.
+func (d *diffSystem) term(cID, vID uint32) uint64 {
+	e := d.cs.GetCoefficient(int(cID))
+	return newDiffHasher().word(d.label(vID)).element(&e).sum()
+}
+
+// expression hashes a linear expression, independently of the order of its
+// terms.
+func (d *diffSystem) expression(l LinearExpression) uint64 {
+	terms := make([]uint64, 0, len(l))
+	for _, t := range l {
+		if t.CID != CoeffIdZero {
+			terms = append(terms, d.term(t.CID, t.VID))
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool { return terms[i] < terms[j] })
+	h := newDiffHasher()
+	for _, t := range terms {
+		h.word(t)
+	}
+	return h.sum()
+}
+
+func (d *diffSystem) hashSparseR1C(h *diffHasher, c *SparseR1C) {
+	var linear LinearExpression
+	for _, t := range [...]Term{{CID: c.QL, VID: c.XA}, {CID: c.QR, VID: c.XB}, {CID: c.QO, VID: c.XC}, {CID: c.QD, VID: c.XD}, {CID: c.QE, VID: c.XE}} {
+		if t.CID != CoeffIdZero {
+			linear = append(linear, t)
+		}
+	}
+	h.word(d.expression(linear))
+	if c.QM != CoeffIdZero {
+		qM := d.cs.GetCoefficient(int(c.QM))
+		xa, xb := d.label(c.XA), d.label(c.XB)
+		h.string("qM").element(&qM).word(min(xa, xb)).word(max(xa, xb))
+	}
+	qC := d.cs.GetCoefficient(int(c.QC))
+	h.string("qC").element(&qC)
+	if c.QG != CoeffIdZero {
+		// the gate is not symmetric in its wires
+		qG := d.cs.GetCoefficient(int(c.QG))
+		h.string("qG").element(&qG).word(d.label(c.XA)).word(d.label(c.XB)).word(d.label(c.XC))
+	}
+	h.word(uint64(c.Commitment))
+}
+
+// location returns the innermost source location of a constraint outside of
+// the frontend, where the API was called.
+func (d *diffSystem) location(cID int) string {
+	dID, ok := d.system.MDebug[cID]
+	if !ok || len(d.system.DebugInfo[dID].Stack) == 0 {
+		return ""
+	}
+	st := &d.system.SymbolTable
+	stack := d.system.DebugInfo[dID].Stack
+	lID := stack[0]
+	for _, l := range stack {
+		if f := st.Functions[st.Locations[l].FunctionID]; !strings.Contains(f.Filename, "gnark/frontend/") {
+			lID = l
+			break
+		}
+	}
+	location := st.Locations[lID]
+	function := st.Functions[location.FunctionID]
+	return fmt.Sprintf("%s:%d %s", function.Filename, location.Line, function.Name)
+}
+
+func (d *diffSystem) constraintString(cID int) string {
+	pi := d.system.Instructions[d.sources[cID]]
+	inst := pi.Unpack(d.system)
+	switch b := d.system.Blueprints[pi.BlueprintID].(type) {
+	case BlueprintR1C:
+		var c R1C
+		b.DecompressR1C(&c, inst)
+		return c.String(d.cs)
+	case BlueprintSparseR1C:
+		var c SparseR1C
+		b.DecompressSparseR1C(&c, inst)
+		return c.String(d.cs)
+	default:
+		return fmt.Sprintf("constraint %d of %s", cID, blueprintName(b))
+	}
+}
+
+// wireLabels returns the labels of wires, to compare them between systems.
+func (d *diffSystem) wireLabels(wires []int) []uint64 {
+	res := make([]uint64, len(wires))
+	for i, w := range wires {
+		res[i] = d.labels[w]
+	}
+	return res
+}
+
+// constraintLabels returns the labels of constraints, to compare them between
+// systems.
+func (d *diffSystem) constraintLabels(constraints []int) []uint64 {
+	res := make([]uint64, len(constraints))
+	for i, c := range constraints {
+		res[i] = d.keys[c]
+	}
+	return res
+}
+
+type diffHasher struct {
+	h hash.Hash64
+}
+
+func newDiffHasher() *diffHasher {
+	return &diffHasher{h: fnv.New64a()}
+}
+
+func (h *diffHasher) word(v uint64) *diffHasher {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	h.h.Write(b[:])
+	return h
+}
+
+func (h *diffHasher) string(s string) *diffHasher {
+	h.word(uint64(len(s)))
+	h.h.Write([]byte(s))
+	return h
+}
+
+func (h *diffHasher) element(e *Element) *diffHasher {
+	b := e.Bytes()
+	h.h.Write(b[:])
+	return h
+}
+
+func (h *diffHasher) sum() uint64 {
+	return h.h.Sum64()
+}
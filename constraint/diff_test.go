@@ -0,0 +1,90 @@
+package constraint_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+)
+
+type diffCircuit struct {
+	X, Y  frontend.Variable
+	Z     frontend.Variable `gnark:",public"`
+	extra bool
+}
+
+func (c *diffCircuit) Define(api frontend.API) error {
+	if c.extra {
+		// shifts the internal wires of the following constraints
+		api.AssertIsDifferent(api.Mul(c.X, c.X), 0)
+	}
+	bits := api.ToBinary(c.X, 8)
+	acc := api.Mul(api.FromBinary(bits...), c.Y)
+	acc = api.Add(acc, api.Inverse(c.Y))
+	api.AssertIsEqual(acc, c.Z)
+	return nil
+}
+
+type renamedCircuit struct {
+	X, Y frontend.Variable
+	W    frontend.Variable `gnark:",public"`
+}
+
+func (c *renamedCircuit) Define(api frontend.API) error {
+	return (&diffCircuit{X: c.X, Y: c.Y, Z: c.W}).Define(api)
+}
+
+func TestDiff(t *testing.T) {
+	assert := test.NewAssert(t)
+	field := ecc.BN254.ScalarField()
+
+	for _, b := range []struct {
+		name       string
+		newBuilder frontend.NewBuilder
+	}{{"r1cs", r1cs.NewBuilder}, {"scs", scs.NewBuilder}} {
+		assert.Run(func(assert *test.Assert) {
+			compile := func(circuit frontend.Circuit) constraint.ConstraintSystem {
+				ccs, err := frontend.Compile(field, b.newBuilder, circuit)
+				assert.NoError(err)
+				return ccs
+			}
+			reference := compile(&diffCircuit{})
+
+			d, err := constraint.Diff(reference, compile(&diffCircuit{}))
+			assert.NoError(err)
+			assert.True(d.Empty(), d)
+
+			extra := compile(&diffCircuit{extra: true})
+			nbAdded := extra.GetNbConstraints() - reference.GetNbConstraints()
+			d, err = constraint.Diff(reference, extra)
+			assert.NoError(err)
+			assert.False(d.Empty())
+			added, removed := 0, 0
+			for _, l := range d.Constraints {
+				added += len(l.Added)
+				removed += len(l.Removed)
+			}
+			assert.Equal(nbAdded, added, d)
+			assert.Equal(0, removed, d)
+
+			// the renamed input is only reported as such
+			d, err = constraint.Diff(reference, compile(&renamedCircuit{}))
+			assert.NoError(err)
+			assert.Equal([]constraint.VariableDiff{{Index: reference.GetNbPublicVariables() - 1, A: "Z", B: "W"}}, d.Public, d)
+			assert.Empty(d.Secret, d)
+			assert.Empty(d.Constraints, d)
+		}, b.name)
+	}
+
+	ra, err := frontend.Compile(field, r1cs.NewBuilder, &diffCircuit{})
+	assert.NoError(err)
+	sa, err := frontend.Compile(field, scs.NewBuilder, &diffCircuit{})
+	assert.NoError(err)
+	d, err := constraint.Diff(ra, sa)
+	assert.NoError(err)
+	assert.Len(d.Header, 1)
+}
@@ -0,0 +1,99 @@
+package constraint
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// digestDomain separates the digests of the constraint systems from other
+// hashes; it changes if the canonical serialization changes.
+const digestDomain = "gnark/constraint.System/digest/v1"
+
+// WriteCanonical writes a canonical serialization of the system which
+// identifies the circuit, for the Digest method of the curve-typed systems
+// which append their coefficients. It covers the field, the type, the
+// number of inputs and of wires, the blueprints, the instructions and their
+// calldata, the commitments and the GKR sub-circuits; it ignores the names of
+// the inputs, the debug information, the logs and the gnark version, and the
+// maps are encoded sorted.
+func (system *System) WriteCanonical(w io.Writer) error {
+	// nil and empty slices are equal after a round-trip
+	opts := cbor.CoreDetEncOptions()
+	opts.NilContainers = cbor.NilContainerAsEmpty
+	enc, err := opts.EncModeWithTags(getTagSet())
+	if err != nil {
+		return err
+	}
+	var buf []byte
+	writeUint64 := func(v uint64) {
+		buf = binary.LittleEndian.AppendUint64(buf, v)
+	}
+	writeBytes := func(b []byte) {
+		writeUint64(uint64(len(b)))
+		buf = append(buf, b...)
+	}
+	writeValue := func(v any) error {
+		b, err := enc.Marshal(v)
+		if err != nil {
+			return err
+		}
+		writeBytes(b)
+		return nil
+	}
+	flush := func() error {
+		_, err := w.Write(buf)
+		buf = buf[:0]
+		return err
+	}
+
+	writeBytes([]byte(digestDomain))
+	writeBytes([]byte(system.ScalarField))
+	writeUint64(uint64(system.Type))
+	writeUint64(uint64(system.GetNbWires()))
+	writeUint64(uint64(len(system.Public)))
+	writeUint64(uint64(len(system.Secret)))
+	writeUint64(uint64(system.NbInternalVariables))
+	writeUint64(uint64(system.NbConstraints))
+
+	writeUint64(uint64(len(system.Blueprints)))
+	for _, b := range system.Blueprints {
+		if err := writeValue(b); err != nil {
+			return err
+		}
+	}
+	writeUint64(uint64(len(system.CustomGates)))
+	for _, id := range system.CustomGates {
+		writeUint64(uint64(id))
+	}
+	if err := writeValue(system.CommitmentInfo); err != nil {
+		return err
+	}
+	if err := writeValue(system.GkrInfo); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	// the instructions, with their calldata, by blocks
+	const blockSize = 1 << 12
+	writeUint64(uint64(len(system.Instructions)))
+	for i, pi := range system.Instructions {
+		inst := pi.Unpack(system)
+		writeUint64(uint64(pi.BlueprintID))
+		writeUint64(uint64(pi.ConstraintOffset))
+		writeUint64(uint64(pi.WireOffset))
+		writeUint64(uint64(len(inst.Calldata)))
+		for _, v := range inst.Calldata {
+			buf = binary.LittleEndian.AppendUint32(buf, v)
+		}
+		if i%blockSize == blockSize-1 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
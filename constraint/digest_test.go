@@ -0,0 +1,57 @@
+package constraint_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+)
+
+func TestDigest(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, tc := range []struct {
+		name       string
+		newBuilder frontend.NewBuilder
+		newCS      func(ecc.ID) constraint.ConstraintSystem
+	}{
+		{"r1cs", r1cs.NewBuilder, groth16.NewCS},
+		{"scs", scs.NewBuilder, plonk.NewCS},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			digest := func(circuit frontend.Circuit) []byte {
+				ccs, err := frontend.Compile(ecc.BN254.ScalarField(), tc.newBuilder, circuit)
+				assert.NoError(err)
+				d, err := ccs.Digest()
+				assert.NoError(err)
+
+				// round-trip
+				var buf bytes.Buffer
+				_, err = ccs.WriteTo(&buf)
+				assert.NoError(err)
+				read := tc.newCS(ecc.BN254)
+				_, err = read.ReadFrom(&buf)
+				assert.NoError(err)
+				rd, err := read.Digest()
+				assert.NoError(err)
+				assert.Equal(d, rd)
+
+				return d
+			}
+
+			reference := digest(&diffCircuit{})
+			assert.Len(reference, 32)
+			assert.Equal(reference, digest(&diffCircuit{}))
+			// the names of the inputs are not part of the circuit
+			assert.Equal(reference, digest(&renamedCircuit{}))
+			assert.NotEqual(reference, digest(&diffCircuit{extra: true}))
+		}, tc.name)
+	}
+}
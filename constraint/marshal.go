@@ -358,6 +358,8 @@ func getTagSet() cbor.TagSet {
 	addType(reflect.TypeOf(BlueprintLookupHint{}))
 	addType(reflect.TypeOf(Groth16Commitments{}))
 	addType(reflect.TypeOf(PlonkCommitments{}))
+	addType(reflect.TypeOf(BlueprintCustomGate{}))
+	addType(reflect.TypeOf(BlueprintSparseR1CWide{}))
 
 	return ts
 }
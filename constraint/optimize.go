@@ -0,0 +1,1031 @@
+package constraint
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/consensys/gnark/internal/embedded"
+)
+
+// Optimization is a pass of Optimize.
+type Optimization uint8
+
+const (
+	// EliminateDeadWires removes the constraints computing an internal wire
+	// which is not used by any other constraint, the hints whose outputs are
+	// not used, and the wires which are not referenced anymore.
+	EliminateDeadWires Optimization = iota
+
+	// EliminateCommonSubexpressions removes the duplicated constraints and hint
+	// calls: a hint called again with the same inputs, or a constraint
+	// computing a wire with the same expression as a previous one, is replaced
+	// by the first one. The constraints left trivially satisfied are removed.
+	EliminateCommonSubexpressions
+
+	// SubstituteLinearConstraints removes the linear constraints of a R1CS
+	// computing a wire, substituting the wire with its linear expression in
+	// the other constraints.
+	SubstituteLinearConstraints
+
+	// MergeAdditions merges the constraints of a SparseR1CS computing a sum
+	// into the constraint using it, when the result fits in one constraint
+	// (see SparseR1CS.SetNbWires).
+	MergeAdditions
+)
+
+func (o Optimization) String() string {
+	switch o {
+	case EliminateDeadWires:
+		return "EliminateDeadWires"
+	case EliminateCommonSubexpressions:
+		return "EliminateCommonSubexpressions"
+	case SubstituteLinearConstraints:
+		return "SubstituteLinearConstraints"
+	case MergeAdditions:
+		return "MergeAdditions"
+	default:
+		return fmt.Sprintf("Optimization(%d)", uint8(o))
+	}
+}
+
+// ErrOptimizationUnsupported is returned by Optimize when the constraint system
+// uses features the optimizer can't reason about. The system is left unchanged.
+var ErrOptimizationUnsupported = errors.New("constraint system not supported by the optimizer")
+
+// OptimizationReport reports the size of a constraint system before and after Optimize.
+type OptimizationReport struct {
+	NbConstraintsBefore, NbConstraintsAfter             int
+	NbInternalVariablesBefore, NbInternalVariablesAfter int
+}
+
+func (r OptimizationReport) String() string {
+	return fmt.Sprintf("nbConstraints: %d → %d, nbInternalVariables: %d → %d",
+		r.NbConstraintsBefore, r.NbConstraintsAfter, r.NbInternalVariablesBefore, r.NbInternalVariablesAfter)
+}
+
+// Optimize rewrites the constraint system in place with the given
+// optimizations, all of them if none is given. The passes run in the order
+// SubstituteLinearConstraints and MergeAdditions, then
+// EliminateCommonSubexpressions, then EliminateDeadWires; the passes which
+// don't apply to the type of the system are ignored. The internal wires are
+// renumbered; the inputs, and the wires referenced by the logs and the debug
+// information, are kept.
+//
+// maxExpressionLen bounds the length of the linear expressions resulting from
+// SubstituteLinearConstraints, 0 for no bound.
+//
+// Optimize returns an error wrapping ErrOptimizationUnsupported, leaving the
+// system unchanged, if it has commitments, GKR sub-circuits, or instructions
+// other than the hints and the constraints of the standard blueprints, such as
+// the constraints of custom gates.
+func Optimize(cs ConstraintSystem, maxExpressionLen int, optimizations ...Optimization) (OptimizationReport, error) {
+	system, err := embedded.Get[System](cs)
+	if err != nil {
+		return OptimizationReport{}, fmt.Errorf("%w: %w", ErrOptimizationUnsupported, err)
+	}
+	report := OptimizationReport{
+		NbConstraintsBefore:       system.NbConstraints,
+		NbInternalVariablesBefore: system.NbInternalVariables,
+	}
+
+	if len(optimizations) == 0 {
+		optimizations = []Optimization{EliminateDeadWires, EliminateCommonSubexpressions, SubstituteLinearConstraints, MergeAdditions}
+	}
+	var dead, cse, substitute, merge bool
+	for _, opt := range optimizations {
+		switch opt {
+		case EliminateDeadWires:
+			dead = true
+		case EliminateCommonSubexpressions:
+			cse = true
+		case SubstituteLinearConstraints:
+			substitute = system.Type == SystemR1CS
+		case MergeAdditions:
+			merge = system.Type == SystemSparseR1CS
+		default:
+			return report, fmt.Errorf("unknown optimization %s", opt)
+		}
+	}
+
+	o, err := newOptimizer(cs, system, maxExpressionLen)
+	if err != nil {
+		return report, err
+	}
+	if cse || substitute || merge {
+		o.simplify(cse, substitute, merge)
+	}
+	o.resolveLogs()
+	if dead {
+		o.eliminateDeadWires()
+	}
+	o.rebuild()
+
+	report.NbConstraintsAfter = system.NbConstraints
+	report.NbInternalVariablesAfter = system.NbInternalVariables
+	return report, nil
+}
+
+type optKind uint8
+
+const (
+	optHint optKind = iota
+	optR1C
+	optSparseR1C
+)
+
+// optInstruction is a decoded instruction of a system being optimized.
+type optInstruction struct {
+	kind    optKind
+	bID     BlueprintID
+	hint    HintMapping
+	r1c     R1C
+	sparse  SparseR1C
+	debugID int // index in System.DebugInfo, -1 if none
+
+	// rewritten is set when the sparse constraint doesn't fit its original
+	// blueprint anymore.
+	rewritten bool
+	removed   bool
+}
+
+type optimizer struct {
+	cs               ConstraintSystem
+	system           *System
+	insts            []optInstruction
+	nbInputs         int
+	nbWires          int
+	maxExpressionLen int
+
+	// sub maps the wires removed by the simplifications to their expression
+	// in the remaining wires.
+	sub map[uint32]LinearExpression
+
+	// logPinned marks the wires referenced by the logs; debugWires lists the
+	// wires referenced by each debug information entry.
+	logPinned  []bool
+	debugWires [][]uint32
+}
+
+func newOptimizer(cs ConstraintSystem, system *System, maxExpressionLen int) (*optimizer, error) {
+	if system.CommitmentInfo != nil && len(system.CommitmentInfo.CommitmentIndexes()) != 0 {
+		return nil, fmt.Errorf("%w: commitments", ErrOptimizationUnsupported)
+	}
+	if system.GkrInfo.Is() {
+		return nil, fmt.Errorf("%w: GKR", ErrOptimizationUnsupported)
+	}
+
+	o := &optimizer{
+		cs:               cs,
+		system:           system,
+		insts:            make([]optInstruction, len(system.Instructions)),
+		nbInputs:         system.GetNbPublicVariables() + system.GetNbSecretVariables(),
+		maxExpressionLen: maxExpressionLen,
+		sub:              make(map[uint32]LinearExpression),
+	}
+	o.nbWires = o.nbInputs + system.NbInternalVariables
+
+	for i, pi := range system.Instructions {
+		inst := pi.Unpack(system)
+		oi := &o.insts[i]
+		oi.bID = pi.BlueprintID
+		oi.debugID = -1
+		switch b := system.Blueprints[pi.BlueprintID].(type) {
+		case *BlueprintGenericHint:
+			oi.kind = optHint
+			b.DecompressHint(&oi.hint, inst)
+		case *BlueprintGenericR1C:
+			oi.kind = optR1C
+			b.DecompressR1C(&oi.r1c, inst)
+		case *BlueprintGenericSparseR1C, *BlueprintSparseR1CMul, *BlueprintSparseR1CAdd, *BlueprintSparseR1CBool, *BlueprintSparseR1CWide:
+			oi.kind = optSparseR1C
+			b.(BlueprintSparseR1C).DecompressSparseR1C(&oi.sparse, inst)
+		default:
+			return nil, fmt.Errorf("%w: blueprint %T", ErrOptimizationUnsupported, b)
+		}
+		if oi.kind != optHint {
+			if id, ok := system.MDebug[int(pi.ConstraintOffset)]; ok {
+				oi.debugID = id
+			}
+		}
+	}
+
+	o.logPinned = make([]bool, o.nbWires)
+	for _, l := range system.Logs {
+		for _, e := range l.ToResolve {
+			for _, t := range e {
+				if !t.IsConstant() {
+					o.logPinned[t.VID] = true
+				}
+			}
+		}
+	}
+	o.debugWires = make([][]uint32, len(system.DebugInfo))
+	o.setDebugWires()
+
+	return o, nil
+}
+
+// setDebugWires lists the wires referenced by each debug information entry.
+func (o *optimizer) setDebugWires() {
+	for i, d := range o.system.DebugInfo {
+		o.debugWires[i] = o.debugWires[i][:0]
+		for _, e := range d.ToResolve {
+			for _, t := range e {
+				if !t.IsConstant() {
+					o.debugWires[i] = append(o.debugWires[i], t.VID)
+				}
+			}
+		}
+	}
+}
+
+// wires calls f on each occurrence of a wire in the instruction; the outputs
+// of the hints and the wires with a zero coefficient in a sparse constraint
+// are ignored.
+func (o *optimizer) wires(inst *optInstruction, f func(w uint32)) {
+	visit := func(l LinearExpression) {
+		for _, t := range l {
+			if !t.IsConstant() {
+				f(t.VID)
+			}
+		}
+	}
+	switch inst.kind {
+	case optHint:
+		for _, in := range inst.hint.Inputs {
+			visit(in)
+		}
+	case optR1C:
+		visit(inst.r1c.L)
+		visit(inst.r1c.R)
+		visit(inst.r1c.O)
+	case optSparseR1C:
+		for _, t := range linearTerms(&inst.sparse) {
+			if t.CID != CoeffIdZero {
+				f(t.VID)
+			}
+		}
+		if inst.sparse.QM != CoeffIdZero {
+			f(inst.sparse.XA)
+			f(inst.sparse.XB)
+		}
+	}
+}
+
+// linearTerms returns the terms qL⋅xa, qR⋅xb, qO⋅xc, qD⋅xd and qE⋅xe of c.
+func linearTerms(c *SparseR1C) [5]Term {
+	return [5]Term{{c.QL, c.XA}, {c.QR, c.XB}, {c.QO, c.XC}, {c.QD, c.XD}, {c.QE, c.XE}}
+}
+
+// definedWire returns the wire the constraint solves, if it's an internal wire
+// appearing once, linearly, in the constraint and nowhere before.
+func (o *optimizer) definedWire(inst *optInstruction, seen []bool) (uint32, bool) {
+	if inst.kind == optHint {
+		return 0, false
+	}
+	var w uint32
+	nb, distinct := 0, true
+	o.wires(inst, func(v uint32) {
+		if seen[v] {
+			return
+		}
+		if nb > 0 && v != w {
+			distinct = false
+		}
+		w = v
+		nb++
+	})
+	if nb != 1 || !distinct {
+		return 0, false
+	}
+	if inst.kind == optR1C {
+		for _, t := range inst.r1c.O {
+			if t.VID == w {
+				return w, true
+			}
+		}
+		return 0, false
+	}
+	if inst.sparse.QM != CoeffIdZero && (inst.sparse.XA == w || inst.sparse.XB == w) {
+		return 0, false
+	}
+	return w, true
+}
+
+// uses returns for each wire the instructions referencing it.
+func (o *optimizer) uses() [][]int {
+	uses := make([][]int, o.nbWires)
+	for i := range o.insts {
+		o.wires(&o.insts[i], func(w uint32) {
+			if n := len(uses[w]); n == 0 || uses[w][n-1] != i {
+				uses[w] = append(uses[w], i)
+			}
+		})
+	}
+	return uses
+}
+
+// simplify runs the passes which rewrite the instructions in order: each
+// instruction is first rewritten with the substitutions of the previous ones,
+// then it is removed if it's redundant or can be substituted.
+func (o *optimizer) simplify(cse, substitute, merge bool) {
+	seen := make([]bool, o.nbWires)
+	for i := 0; i < o.nbInputs; i++ {
+		seen[i] = true
+	}
+	var uses [][]int
+	var growth []int
+	if substitute || merge {
+		uses = o.uses()
+		growth = make([]int, len(o.insts))
+	}
+	debugUses := o.debugUses()
+
+	hints := make(map[string]uint32)       // key of a hint call → its first output
+	constraints := make(map[string]bool)   // keys of the constraints
+	definitions := make(map[string]uint32) // key of a constraint without its defined wire → defined wire
+
+	for i := range o.insts {
+		inst := &o.insts[i]
+		o.resolve(inst)
+
+		if inst.kind == optHint {
+			o.wires(inst, func(w uint32) { seen[w] = true })
+			h := &inst.hint
+			if cse {
+				key := o.hintKey(h)
+				if first, ok := hints[key]; ok {
+					for j := uint32(0); j < h.OutputRange.End-h.OutputRange.Start; j++ {
+						o.sub[h.OutputRange.Start+j] = LinearExpression{{CID: CoeffIdOne, VID: first + j}}
+					}
+					inst.removed = true
+					continue
+				}
+				hints[key] = h.OutputRange.Start
+			}
+			for w := h.OutputRange.Start; w < h.OutputRange.End; w++ {
+				seen[w] = true
+			}
+			continue
+		}
+
+		w, defines := o.definedWire(inst, seen)
+		o.wires(inst, func(v uint32) { seen[v] = true })
+
+		if defines && substitute && o.substitute(i, w, uses[w], growth) {
+			inst.removed = true
+			continue
+		}
+		if defines && merge && o.mergeInto(i, w, uses[w], debugUses) {
+			inst.removed = true
+			continue
+		}
+		if !cse {
+			continue
+		}
+		key := o.key(inst, math.MaxUint32)
+		if constraints[key] || o.trivial(inst) {
+			inst.removed = true
+			continue
+		}
+		if defines {
+			dKey := o.key(inst, w)
+			if first, ok := definitions[dKey]; ok {
+				o.sub[w] = LinearExpression{{CID: CoeffIdOne, VID: first}}
+				inst.removed = true
+				continue
+			}
+			definitions[dKey] = w
+		}
+		constraints[key] = true
+	}
+}
+
+// resolve rewrites the instruction with the substitutions.
+func (o *optimizer) resolve(inst *optInstruction) {
+	if len(o.sub) == 0 {
+		return
+	}
+	switch inst.kind {
+	case optHint:
+		for i := range inst.hint.Inputs {
+			inst.hint.Inputs[i] = o.resolveExpression(inst.hint.Inputs[i])
+		}
+	case optR1C:
+		inst.r1c.L = o.resolveExpression(inst.r1c.L)
+		inst.r1c.R = o.resolveExpression(inst.r1c.R)
+		inst.r1c.O = o.resolveExpression(inst.r1c.O)
+	case optSparseR1C:
+		// the substitutions of a SparseR1CS are aliases.
+		c := &inst.sparse
+		for _, x := range [...]*uint32{&c.XA, &c.XB, &c.XC, &c.XD, &c.XE} {
+			if s, ok := o.sub[*x]; ok {
+				*x = s[0].VID
+			}
+		}
+	}
+}
+
+// resolveExpression returns l with the substituted wires replaced by their
+// expression, l itself if it has none.
+func (o *optimizer) resolveExpression(l LinearExpression) LinearExpression {
+	substituted := false
+	for _, t := range l {
+		if _, ok := o.sub[t.VID]; ok && !t.IsConstant() {
+			substituted = true
+			break
+		}
+	}
+	if !substituted {
+		return l
+	}
+	f := o.newForm()
+	for _, t := range l {
+		s, ok := o.sub[t.VID]
+		if !ok || t.IsConstant() {
+			f.add(t.VID, o.cs.GetCoefficient(int(t.CID)))
+			continue
+		}
+		c := o.cs.GetCoefficient(int(t.CID))
+		for _, st := range s {
+			f.add(st.VID, o.cs.Mul(c, o.cs.GetCoefficient(int(st.CID))))
+		}
+	}
+	return f.expression()
+}
+
+// resolveLogs rewrites the logs and the debug information with the substitutions.
+func (o *optimizer) resolveLogs() {
+	if len(o.sub) == 0 {
+		return
+	}
+	for _, entries := range [...][]LogEntry{o.system.Logs, o.system.DebugInfo} {
+		for i := range entries {
+			for j := range entries[i].ToResolve {
+				entries[i].ToResolve[j] = o.resolveExpression(entries[i].ToResolve[j])
+			}
+		}
+	}
+	for i := range o.logPinned {
+		o.logPinned[i] = false
+	}
+	for _, l := range o.system.Logs {
+		for _, e := range l.ToResolve {
+			for _, t := range e {
+				if !t.IsConstant() {
+					o.logPinned[t.VID] = true
+				}
+			}
+		}
+	}
+	o.setDebugWires()
+}
+
+// debugUses counts for each wire the constraints whose debug information
+// references it.
+func (o *optimizer) debugUses() []int {
+	uses := make([]int, o.nbWires)
+	for i := range o.insts {
+		if !o.insts[i].removed && o.insts[i].debugID >= 0 {
+			for _, w := range o.debugWires[o.insts[i].debugID] {
+				uses[w]++
+			}
+		}
+	}
+	return uses
+}
+
+// pinned returns true if the wire can't be removed along with the i-th
+// instruction, because of the logs or the debug information of the others.
+func (o *optimizer) pinned(i int, w uint32, debugUses []int) bool {
+	if o.logPinned[w] {
+		return true
+	}
+	n := debugUses[w]
+	if id := o.insts[i].debugID; id >= 0 {
+		for _, v := range o.debugWires[id] {
+			if v == w {
+				n--
+				break
+			}
+		}
+	}
+	return n > 0
+}
+
+// substitute removes the i-th constraint of a R1CS if it's linear, by
+// substituting w with its expression in the instructions using it.
+func (o *optimizer) substitute(i int, w uint32, uses []int, growth []int) bool {
+	r1c := &o.insts[i].r1c
+
+	// L⋅R == O is linear if L or R is constant, on the wire 0 of the R1CS.
+	var f form
+	if k, ok := o.constant(r1c.L); ok {
+		f = o.scaledForm(r1c.R, k)
+	} else if k, ok := o.constant(r1c.R); ok {
+		f = o.scaledForm(r1c.L, k)
+	} else {
+		return false
+	}
+	for _, t := range r1c.O {
+		f.add(t.VID, o.cs.Neg(o.cs.GetCoefficient(int(t.CID))))
+	}
+
+	// f == 0, and w = -(f - fw⋅w)/fw
+	j, ok := f.index[w]
+	if !ok || f.coeffs[j].IsZero() {
+		return false
+	}
+	inv, ok := o.cs.Inverse(f.coeffs[j])
+	if !ok {
+		return false
+	}
+	inv = o.cs.Neg(inv)
+	f.coeffs[j] = Element{}
+	for k := range f.coeffs {
+		f.coeffs[k] = o.cs.Mul(f.coeffs[k], inv)
+	}
+	expression := f.expression()
+
+	if o.maxExpressionLen > 0 {
+		for _, u := range uses {
+			if u != i && o.maxLen(&o.insts[u])+growth[u]+len(expression)-1 > o.maxExpressionLen {
+				return false
+			}
+		}
+	}
+	for _, u := range uses {
+		growth[u] += len(expression) - 1
+	}
+	o.sub[w] = expression
+	return true
+}
+
+// trivial returns true if the constraint holds for any value of its wires,
+// as the ones left by the aliasing of duplicated hint outputs.
+func (o *optimizer) trivial(inst *optInstruction) bool {
+	isZero := func(f form) bool {
+		for _, c := range f.coeffs {
+			if !c.IsZero() {
+				return false
+			}
+		}
+		return true
+	}
+	if inst.kind == optR1C {
+		one := o.cs.One()
+		l, r := o.scaledForm(inst.r1c.L, one), o.scaledForm(inst.r1c.R, one)
+		return (isZero(l) || isZero(r)) && isZero(o.scaledForm(inst.r1c.O, one))
+	}
+	return inst.sparse.QM == CoeffIdZero && isZero(o.sparseForm(&inst.sparse))
+}
+
+// constant returns the value of l if it only references the wire 0.
+func (o *optimizer) constant(l LinearExpression) (Element, bool) {
+	var k Element
+	for _, t := range l {
+		if t.VID != 0 {
+			return Element{}, false
+		}
+		k = o.cs.Add(k, o.cs.GetCoefficient(int(t.CID)))
+	}
+	return k, true
+}
+
+// maxLen returns the length of the longest linear expression of the instruction.
+func (o *optimizer) maxLen(inst *optInstruction) int {
+	n := 0
+	switch inst.kind {
+	case optHint:
+		for _, in := range inst.hint.Inputs {
+			n = max(n, len(in))
+		}
+	case optR1C:
+		n = max(len(inst.r1c.L), len(inst.r1c.R), len(inst.r1c.O))
+	}
+	return n
+}
+
+// mergeInto removes the i-th constraint of a SparseR1CS if it's linear and its
+// result w is only used linearly by another constraint, by substituting w in it.
+func (o *optimizer) mergeInto(i int, w uint32, uses []int, debugUses []int) bool {
+	ci := &o.insts[i].sparse
+	if ci.QM != CoeffIdZero || len(uses) != 2 || uses[0] != i || o.pinned(i, w, debugUses) {
+		return false
+	}
+	j := uses[1]
+	if o.insts[j].kind != optSparseR1C {
+		return false
+	}
+	cj := &o.insts[j].sparse
+	if cj.QM != CoeffIdZero && (cj.XA == w || cj.XB == w) {
+		return false
+	}
+
+	fi, fj := o.sparseForm(ci), o.sparseForm(cj)
+	qi, qj := fi.coeffs[fi.index[w]], fj.coeffs[fj.index[w]]
+	inv, ok := o.cs.Inverse(qi)
+	if !ok {
+		return false
+	}
+	r := o.cs.Neg(o.cs.Mul(qj, inv))
+	for k, v := range fi.vids {
+		fj.add(v, o.cs.Mul(r, fi.coeffs[k]))
+	}
+
+	// lay out the terms, the wires of qM⋅(xaxb) first
+	var c SparseR1C
+	slots := make([]Term, 0, MaxNbWires)
+	take := func(v uint32) Term {
+		t := Term{CID: CoeffIdZero, VID: v}
+		if k, ok := fj.index[v]; ok {
+			t.CID = o.cs.AddCoeff(fj.coeffs[k])
+			fj.coeffs[k] = Element{}
+		}
+		return t
+	}
+	if cj.QM != CoeffIdZero {
+		c.QM = cj.QM
+		slots = append(slots, take(cj.XA), take(cj.XB))
+	}
+	c.QC = CoeffIdZero
+	if k, ok := fj.index[math.MaxUint32]; ok {
+		c.QC = o.cs.AddCoeff(fj.coeffs[k])
+		fj.coeffs[k] = Element{}
+	}
+	for k, v := range fj.vids {
+		if !fj.coeffs[k].IsZero() {
+			slots = append(slots, Term{CID: o.cs.AddCoeff(fj.coeffs[k]), VID: v})
+		}
+	}
+	if len(slots) > o.system.GetNbWires() {
+		return false
+	}
+	slots = append(slots, make([]Term, MaxNbWires-len(slots))...)
+	c.QL, c.XA = slots[0].CID, slots[0].VID
+	c.QR, c.XB = slots[1].CID, slots[1].VID
+	c.QO, c.XC = slots[2].CID, slots[2].VID
+	c.QD, c.XD = slots[3].CID, slots[3].VID
+	c.QE, c.XE = slots[4].CID, slots[4].VID
+
+	*cj = c
+	o.insts[j].rewritten = true
+	return true
+}
+
+// sparseForm returns the linear part of c, with qC on the constant wire.
+func (o *optimizer) sparseForm(c *SparseR1C) form {
+	f := o.newForm()
+	for _, t := range linearTerms(c) {
+		if t.CID != CoeffIdZero {
+			f.add(t.VID, o.cs.GetCoefficient(int(t.CID)))
+		}
+	}
+	f.add(math.MaxUint32, o.cs.GetCoefficient(int(c.QC)))
+	return f
+}
+
+// scaledForm returns k⋅l.
+func (o *optimizer) scaledForm(l LinearExpression, k Element) form {
+	f := o.newForm()
+	for _, t := range l {
+		f.add(t.VID, o.cs.Mul(k, o.cs.GetCoefficient(int(t.CID))))
+	}
+	return f
+}
+
+// eliminateDeadWires removes the instructions whose results are not used,
+// until there are none.
+func (o *optimizer) eliminateDeadWires() {
+	counts := make([]int, o.nbWires)
+	defined := make([]int64, len(o.insts))
+	seen := make([]bool, o.nbWires)
+	for i := 0; i < o.nbInputs; i++ {
+		seen[i] = true
+	}
+	for i := range o.insts {
+		inst := &o.insts[i]
+		defined[i] = -1
+		if inst.removed {
+			continue
+		}
+		if w, ok := o.definedWire(inst, seen); ok {
+			defined[i] = int64(w)
+		}
+		o.wires(inst, func(w uint32) {
+			counts[w]++
+			seen[w] = true
+		})
+		if inst.kind == optHint {
+			for w := inst.hint.OutputRange.Start; w < inst.hint.OutputRange.End; w++ {
+				seen[w] = true
+			}
+		}
+	}
+	debugUses := o.debugUses()
+
+	remove := func(i int) {
+		inst := &o.insts[i]
+		inst.removed = true
+		o.wires(inst, func(w uint32) { counts[w]-- })
+		if inst.debugID >= 0 {
+			for _, w := range o.debugWires[inst.debugID] {
+				debugUses[w]--
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for i := len(o.insts) - 1; i >= 0; i-- {
+			inst := &o.insts[i]
+			if inst.removed {
+				continue
+			}
+			if inst.kind == optHint {
+				used := false
+				for w := inst.hint.OutputRange.Start; w < inst.hint.OutputRange.End && !used; w++ {
+					used = counts[w] != 0 || o.logPinned[w] || debugUses[w] != 0
+				}
+				if !used {
+					remove(i)
+					changed = true
+				}
+				continue
+			}
+			w := defined[i]
+			if w < 0 || counts[w] != 1 || o.pinned(i, uint32(w), debugUses) {
+				continue
+			}
+			// keep the last constraint on an input
+			lastInput := false
+			o.wires(inst, func(v uint32) { counts[v]-- })
+			o.wires(inst, func(v uint32) { lastInput = lastInput || (int(v) < o.nbInputs && counts[v] == 0) })
+			o.wires(inst, func(v uint32) { counts[v]++ })
+			if !lastInput {
+				remove(i)
+				changed = true
+			}
+		}
+	}
+}
+
+// rebuild replaces the instructions of the system with the remaining ones,
+// renumbering the internal wires.
+func (o *optimizer) rebuild() {
+	system := o.system
+
+	// the wires which are still referenced
+	keep := make([]bool, o.nbWires)
+	if o.nbWires > 0 {
+		keep[0] = true
+	}
+	referencedDebug := make([]bool, len(system.DebugInfo))
+	for i := range o.insts {
+		inst := &o.insts[i]
+		if inst.removed {
+			continue
+		}
+		o.wires(inst, func(w uint32) { keep[w] = true })
+		if inst.kind == optHint {
+			for w := inst.hint.OutputRange.Start; w < inst.hint.OutputRange.End; w++ {
+				keep[w] = true
+			}
+		}
+		if inst.debugID >= 0 {
+			referencedDebug[inst.debugID] = true
+			for _, w := range o.debugWires[inst.debugID] {
+				keep[w] = true
+			}
+		}
+	}
+	for w, pinned := range o.logPinned {
+		keep[w] = keep[w] || pinned
+	}
+
+	newID := make([]uint32, o.nbWires)
+	nbInternal := 0
+	for w := range newID {
+		if w < o.nbInputs {
+			newID[w] = uint32(w)
+		} else if keep[w] {
+			newID[w] = uint32(o.nbInputs + nbInternal)
+			nbInternal++
+		}
+	}
+	remap := func(l LinearExpression) LinearExpression {
+		r := make(LinearExpression, len(l))
+		for i, t := range l {
+			r[i] = t
+			if !t.IsConstant() {
+				r[i].VID = newID[t.VID]
+			}
+		}
+		return r
+	}
+
+	system.Instructions = make([]PackedInstruction, 0, len(system.Instructions))
+	system.CallData = make([]uint32, 0, len(system.CallData))
+	system.NbConstraints = 0
+	system.NbInternalVariables = 0
+	system.lbWireLevel = make([]Level, 0, nbInternal)
+	system.Levels = nil
+	system.MDebug = make(map[int]int)
+	for i := 0; i < nbInternal; i++ {
+		system.AddInternalVariable()
+	}
+
+	var genericSparse, wideSparse BlueprintID = math.MaxUint32, math.MaxUint32
+	sparseBlueprint := func(c *SparseR1C) BlueprintID {
+		if c.QD == CoeffIdZero && c.QE == CoeffIdZero {
+			if genericSparse == math.MaxUint32 {
+				genericSparse = system.findBlueprint(func(b Blueprint) bool { _, ok := b.(*BlueprintGenericSparseR1C); return ok }, &BlueprintGenericSparseR1C{})
+			}
+			return genericSparse
+		}
+		if wideSparse == math.MaxUint32 {
+			wideSparse = system.findBlueprint(func(b Blueprint) bool { _, ok := b.(*BlueprintSparseR1CWide); return ok }, &BlueprintSparseR1CWide{})
+		}
+		return wideSparse
+	}
+
+	var calldata []uint32
+	for i := range o.insts {
+		inst := &o.insts[i]
+		if inst.removed {
+			continue
+		}
+		calldata = calldata[:0]
+		switch inst.kind {
+		case optHint:
+			h := inst.hint
+			h.Inputs = make([]LinearExpression, len(inst.hint.Inputs))
+			for j, in := range inst.hint.Inputs {
+				h.Inputs[j] = remap(in)
+			}
+			h.OutputRange.Start = newID[inst.hint.OutputRange.Start]
+			h.OutputRange.End = newID[inst.hint.OutputRange.End-1] + 1
+			system.Blueprints[inst.bID].(BlueprintHint).CompressHint(h, &calldata)
+		case optR1C:
+			c := R1C{L: remap(inst.r1c.L), R: remap(inst.r1c.R), O: remap(inst.r1c.O)}
+			system.Blueprints[inst.bID].(BlueprintR1C).CompressR1C(&c, &calldata)
+		case optSparseR1C:
+			c := inst.sparse
+			for _, x := range [...]struct {
+				w *uint32
+				q uint32
+			}{{&c.XA, c.QL | c.QM}, {&c.XB, c.QR | c.QM}, {&c.XC, c.QO}, {&c.XD, c.QD}, {&c.XE, c.QE}} {
+				if x.q == CoeffIdZero {
+					*x.w = 0
+				} else {
+					*x.w = newID[*x.w]
+				}
+			}
+			if inst.rewritten {
+				inst.bID = sparseBlueprint(&c)
+			}
+			system.Blueprints[inst.bID].(BlueprintSparseR1C).CompressSparseR1C(&c, &calldata)
+		}
+		if inst.debugID >= 0 {
+			system.MDebug[system.NbConstraints] = inst.debugID
+		}
+		system.AddInstruction(inst.bID, calldata)
+	}
+
+	for i := range system.Logs {
+		for j, e := range system.Logs[i].ToResolve {
+			system.Logs[i].ToResolve[j] = remap(e)
+		}
+	}
+	for i := range system.DebugInfo {
+		if !referencedDebug[i] {
+			// the wires of the debug information of the removed constraints may be gone.
+			system.DebugInfo[i].ToResolve = nil
+			continue
+		}
+		for j, e := range system.DebugInfo[i].ToResolve {
+			system.DebugInfo[i].ToResolve[j] = remap(e)
+		}
+	}
+}
+
+// findBlueprint returns the id of the first blueprint matching, adding b if none does.
+func (system *System) findBlueprint(match func(Blueprint) bool, b Blueprint) BlueprintID {
+	for i, bp := range system.Blueprints {
+		if match(bp) {
+			return BlueprintID(i)
+		}
+	}
+	return system.AddBlueprint(b)
+}
+
+// hintKey identifies a hint call by its function and inputs.
+func (o *optimizer) hintKey(h *HintMapping) string {
+	key := binary.LittleEndian.AppendUint32(nil, uint32(h.HintID))
+	key = binary.LittleEndian.AppendUint32(key, h.OutputRange.End-h.OutputRange.Start)
+	for _, in := range h.Inputs {
+		key = appendExpressionKey(key, in, math.MaxUint32)
+	}
+	return string(key)
+}
+
+// key identifies a constraint up to the order of its terms; the wire w, if
+// any, is replaced by a placeholder.
+func (o *optimizer) key(inst *optInstruction, w uint32) string {
+	var key []byte
+	if inst.kind == optR1C {
+		l := appendExpressionKey(nil, inst.r1c.L, w)
+		r := appendExpressionKey(nil, inst.r1c.R, w)
+		if string(r) < string(l) {
+			l, r = r, l
+		}
+		key = append(key, 'r')
+		key = append(key, l...)
+		key = append(key, r...)
+		key = appendExpressionKey(key, inst.r1c.O, w)
+		return string(key)
+	}
+
+	c := &inst.sparse
+	var l LinearExpression
+	for _, t := range linearTerms(c) {
+		if t.CID != CoeffIdZero {
+			l = append(l, t)
+		}
+	}
+	key = append(key, 's')
+	key = appendExpressionKey(key, l, w)
+	key = binary.LittleEndian.AppendUint32(key, c.QM)
+	if c.QM != CoeffIdZero {
+		a, b := c.XA, c.XB
+		if b < a {
+			a, b = b, a
+		}
+		key = binary.LittleEndian.AppendUint32(key, a)
+		key = binary.LittleEndian.AppendUint32(key, b)
+	}
+	key = binary.LittleEndian.AppendUint32(key, c.QC)
+	return string(key)
+}
+
+// appendExpressionKey appends the terms of l sorted by wire, with w replaced
+// by a placeholder.
+func appendExpressionKey(key []byte, l LinearExpression, w uint32) []byte {
+	const placeholder = math.MaxUint32 - 1
+	terms := make(LinearExpression, len(l))
+	copy(terms, l)
+	for i := range terms {
+		if terms[i].VID == w {
+			terms[i].VID = placeholder
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].VID != terms[j].VID {
+			return terms[i].VID < terms[j].VID
+		}
+		return terms[i].CID < terms[j].CID
+	})
+	key = binary.LittleEndian.AppendUint32(key, uint32(len(terms)))
+	for _, t := range terms {
+		key = binary.LittleEndian.AppendUint32(key, t.VID)
+		key = binary.LittleEndian.AppendUint32(key, t.CID)
+	}
+	return key
+}
+
+// form is a linear combination of wires with explicit coefficients, in the
+// order the wires were added.
+type form struct {
+	cs     ConstraintSystem
+	vids   []uint32
+	coeffs []Element
+	index  map[uint32]int
+}
+
+func (o *optimizer) newForm() form {
+	return form{cs: o.cs, index: make(map[uint32]int)}
+}
+
+func (f *form) add(vid uint32, c Element) {
+	if i, ok := f.index[vid]; ok {
+		f.coeffs[i] = f.cs.Add(f.coeffs[i], c)
+		return
+	}
+	f.index[vid] = len(f.vids)
+	f.vids = append(f.vids, vid)
+	f.coeffs = append(f.coeffs, c)
+}
+
+// expression returns the linear expression of the terms with a non-zero coefficient.
+func (f *form) expression() LinearExpression {
+	l := make(LinearExpression, 0, len(f.vids))
+	for i, vid := range f.vids {
+		if !f.coeffs[i].IsZero() {
+			l = append(l, Term{CID: f.cs.AddCoeff(f.coeffs[i]), VID: vid})
+		}
+	}
+	return l
+}
@@ -0,0 +1,94 @@
+package constraint_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+type redundantCircuit struct {
+	X, Y frontend.Variable
+	Z    frontend.Variable `gnark:",public"`
+}
+
+func (c *redundantCircuit) Define(api frontend.API) error {
+	// duplicated products and hint calls
+	a := api.Mul(c.X, c.Y)
+	b := api.Mul(c.X, c.Y)
+	h1, err := api.Compiler().NewHint(idHint, 1, c.X)
+	if err != nil {
+		return err
+	}
+	h2, err := api.Compiler().NewHint(idHint, 1, c.X)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(h1[0], h2[0])
+
+	// unused results
+	_ = api.Mul(a, c.Y)
+	_ = api.Inverse(api.Add(c.X, 1))
+
+	// chain of additions
+	acc := api.Add(a, b)
+	for i := 0; i < 6; i++ {
+		acc = api.Add(acc, c.Y, i)
+	}
+	acc = api.Mul(acc, h1[0])
+	api.AssertIsEqual(acc, c.Z)
+	return nil
+}
+
+func TestOptimize(t *testing.T) {
+	solver.RegisterHint(idHint)
+	assert := require.New(t)
+
+	// z = (2xy + 6y + 15)⋅x
+	valid := redundantCircuit{X: 3, Y: 5, Z: 3 * (2*15 + 6*5 + 15)}
+	invalid := redundantCircuit{X: 3, Y: 5, Z: 3*(2*15+6*5+15) + 1}
+	field := ecc.BN254.ScalarField()
+	validWitness, err := frontend.NewWitness(&valid, field)
+	assert.NoError(err)
+	invalidWitness, err := frontend.NewWitness(&invalid, field)
+	assert.NoError(err)
+
+	for name, newBuilder := range map[string]frontend.NewBuilder{
+		"r1cs":         r1cs.NewBuilder,
+		"scs":          scs.NewBuilder,
+		"scs/nbWires5": scs.NewBuilderWithOptions(scs.WithNbWires(5)),
+	} {
+		// the compression of the long linear expressions of a R1CS adds linear constraints
+		compile := func(opts ...frontend.CompileOption) constraint.ConstraintSystem {
+			ccs, err := frontend.Compile(field, newBuilder, &redundantCircuit{}, append(opts, frontend.WithCompressThreshold(4))...)
+			assert.NoError(err, name)
+			return ccs
+		}
+		reference := compile()
+
+		for _, optimizations := range [][]constraint.Optimization{
+			{constraint.EliminateDeadWires},
+			{constraint.EliminateCommonSubexpressions},
+			{constraint.SubstituteLinearConstraints, constraint.MergeAdditions},
+		} {
+			ccs := compile()
+			report, err := constraint.Optimize(ccs, 0, optimizations...)
+			assert.NoError(err, name)
+			assert.Equal(reference.GetNbConstraints(), report.NbConstraintsBefore)
+			assert.Equal(ccs.GetNbConstraints(), report.NbConstraintsAfter)
+			assert.Less(ccs.GetNbConstraints(), reference.GetNbConstraints(), "%s %v", name, optimizations)
+			assert.NoError(ccs.IsSolved(validWitness), "%s %v", name, optimizations)
+			assert.Error(ccs.IsSolved(invalidWitness), "%s %v", name, optimizations)
+		}
+
+		ccs := compile(frontend.WithOptimizations())
+		assert.Less(ccs.GetNbConstraints(), reference.GetNbConstraints(), name)
+		assert.NoError(ccs.IsSolved(validWitness), name)
+		assert.Error(ccs.IsSolved(invalidWitness), name)
+	}
+}
@@ -14,6 +14,12 @@
 
 package constraint
 
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
 type SparseR1CS interface {
 	ConstraintSystem
 
@@ -27,6 +33,49 @@ type SparseR1CS interface {
 
 	// GetSparseR1CIterator returns an SparseR1CIterator to iterate on the SparseR1C constraints of the system.
 	GetSparseR1CIterator() SparseR1CIterator
+
+	// AddCustomGate registers a custom gate and returns the id of the blueprint
+	// of the constraints using it.
+	AddCustomGate(g CustomGate) (BlueprintID, error)
+
+	// GetCustomGates returns the custom gates of the system.
+	GetCustomGates() []CustomGate
+
+	// SetNbWires sets the number of wires of the constraints, between 3 and
+	// MaxNbWires. It must be called before any constraint is added.
+	SetNbWires(nbWires int) error
+
+	// GetNbWires returns the number of wires of the constraints.
+	GetNbWires() int
+}
+
+// MaxNbWires is the maximal number of wires of a SparseR1C: xa, xb, xc and the
+// advice wires xd, xe.
+const MaxNbWires = 5
+
+// SetNbWires sets the number of wires of the constraints of the system. With
+// more than 3 wires, the constraints may use the advice wires xd, xe of
+// SparseR1C with the blueprint BlueprintSparseR1CWide.
+func (system *System) SetNbWires(nbWires int) error {
+	if system.Type != SystemSparseR1CS {
+		return errors.New("the number of wires can only be set for a SparseR1CS")
+	}
+	if nbWires < 3 || nbWires > MaxNbWires {
+		return fmt.Errorf("invalid number of wires %d, must be between 3 and %d", nbWires, MaxNbWires)
+	}
+	if system.NbConstraints != 0 {
+		return errors.New("the number of wires must be set before adding constraints")
+	}
+	system.NbWires = nbWires
+	return nil
+}
+
+// GetNbWires returns the number of wires of the constraints of the system.
+func (system *System) GetNbWires() int {
+	if system.NbWires == 0 {
+		return 3
+	}
+	return system.NbWires
 }
 
 // SparseR1CIterator facilitates iterating through SparseR1C constraints.
@@ -139,18 +188,27 @@ const (
 )
 
 // SparseR1C represent a PlonK-ish constraint
-// qL⋅xa + qR⋅xb + qO⋅xc + qM⋅(xaxb) + qC -committed?*Bsb22Commitments-commitment?*commitmentValue == 0
+// qL⋅xa + qR⋅xb + qO⋅xc + qD⋅xd + qE⋅xe + qM⋅(xaxb) + qC + qG⋅gate(xa, xb, xc) -committed?*Bsb22Commitments-commitment?*commitmentValue == 0
 type SparseR1C struct {
 	XA, XB, XC         uint32
 	QL, QR, QO, QM, QC uint32
 	Commitment         CommitmentConstraint
+
+	// XD, XE are the advice wires of the systems with more than 3 wires (see
+	// SparseR1CS.SetNbWires), with coefficients QD, QE.
+	XD, XE, QD, QE uint32
+
+	// QG is the selector of the custom gate of index Gate (see CustomGate),
+	// CoeffIdZero if the constraint doesn't use a custom gate.
+	QG, Gate uint32
 }
 
 func (c *SparseR1C) Clear() {
 	*c = SparseR1C{}
 }
 
-// String formats the constraint as qL⋅xa + qR⋅xb + qO⋅xc + qM⋅(xaxb) + qC == 0
+// String formats the constraint as qL⋅xa + qR⋅xb + qO⋅xc + qM⋅(xaxb) + qC == 0,
+// with the terms of the advice wires and of the custom gate if they are set.
 func (c *SparseR1C) String(r Resolver) string {
 	sbb := NewStringBuilder(r)
 	sbb.WriteTerm(Term{CID: c.QL, VID: c.XA})
@@ -158,6 +216,12 @@ func (c *SparseR1C) String(r Resolver) string {
 	sbb.WriteTerm(Term{CID: c.QR, VID: c.XB})
 	sbb.WriteString(" + ")
 	sbb.WriteTerm(Term{CID: c.QO, VID: c.XC})
+	for _, t := range [...]Term{{CID: c.QD, VID: c.XD}, {CID: c.QE, VID: c.XE}} {
+		if sbb.CoeffToString(int(t.CID)) != "0" {
+			sbb.WriteString(" + ")
+			sbb.WriteTerm(t)
+		}
+	}
 	if qM := sbb.CoeffToString(int(c.QM)); qM != "0" {
 		xa := sbb.VariableToString(int(c.XA))
 		xb := sbb.VariableToString(int(c.XB))
@@ -171,6 +235,19 @@ func (c *SparseR1C) String(r Resolver) string {
 	}
 	sbb.WriteString(" + ")
 	sbb.WriteString(r.CoeffToString(int(c.QC)))
+	if qG := sbb.CoeffToString(int(c.QG)); qG != "0" {
+		sbb.WriteString(" + ")
+		sbb.WriteString(qG)
+		sbb.WriteString("⋅gate")
+		sbb.WriteString(strconv.Itoa(int(c.Gate)))
+		sbb.WriteString("(")
+		sbb.WriteString(sbb.VariableToString(int(c.XA)))
+		sbb.WriteString(", ")
+		sbb.WriteString(sbb.VariableToString(int(c.XB)))
+		sbb.WriteString(", ")
+		sbb.WriteString(sbb.VariableToString(int(c.XC)))
+		sbb.WriteByte(')')
+	}
 	sbb.WriteString(" == 0")
 	return sbb.String()
 }
@@ -77,6 +77,12 @@ type ConstraintSystem interface {
 
 	GetInstruction(int) Instruction
 
+	// Digest returns a SHA-256 fingerprint of the circuit, computed over a
+	// canonical serialization of the system (see System.WriteCanonical) and of
+	// its coefficients. It is stable across WriteTo/ReadFrom round-trips and
+	// machines, and independent of the debug information.
+	Digest() ([]byte, error)
+
 	GetCoefficient(i int) Element
 }
 
@@ -164,3 +164,34 @@ type PlonkAPI interface {
 	// AddPlonkConstraint asserts qL.a + qR.b + qM.ab + qO.o + qC
 	AddPlonkConstraint(a, b, o Variable, qL, qR, qO, qM, qC int)
 }
+
+// GateTerm is a monomial Coeff.aᴬ.bᴮ.oᶜ of a custom gate, where Coeff is a
+// constant (integer, *big.Int, string, ...).
+type GateTerm struct {
+	Coeff   any
+	A, B, C uint8
+}
+
+// CustomGateAPI defines custom gates in PLONK constraint systems, in addition
+// to the gate of [PlonkAPI]. A custom gate is a polynomial gate(a, b, o) of
+// degree at most [constraint.MaxCustomGateDegree] in the three wires of a
+// constraint, with its own selector. The PLONK proofs have one more chunk of
+// the quotient for each degree above the number of wires.
+//
+// The operands a constraint doesn't read may be constant. If an operand it
+// reads is constant, the constraint is expressed with the standard gates.
+//
+// A constraint system with custom gates is not optimized by
+// [WithOptimizations], it is compiled unoptimized with a warning.
+type CustomGateAPI interface {
+	// DefineCustomGate defines the custom gate gate(a, b, o) = ∑ Coeff.aᴬ.bᴮ.oᶜ
+	// and returns its id.
+	DefineCustomGate(name string, terms ...GateTerm) (int, error)
+
+	// EvaluateCustomGate returns res = qG.gate(a, b), where gate doesn't
+	// depend on o.
+	EvaluateCustomGate(gate int, a, b Variable, qG int) Variable
+
+	// AddCustomGateConstraint asserts qG.gate(a, b, o) + qL.a + qR.b + qM.ab + qO.o + qC == 0
+	AddCustomGateConstraint(gate int, a, b, o Variable, qG, qL, qR, qO, qM, qC int)
+}
@@ -51,6 +51,7 @@ func Compile(field *big.Int, newBuilder NewBuilder, circuit Circuit, opts ...Com
 		log.Err(err).Msg("instantiating builder")
 		return nil, fmt.Errorf("new compiler: %w", err)
 	}
+	setComponentContext(builder, field, newBuilder, opt)
 
 	// parse the circuit builds a schema of the circuit
 	// and call circuit.Define() method to initialize a list of constraints in the compiler
@@ -61,7 +62,24 @@ func Compile(field *big.Int, newBuilder NewBuilder, circuit Circuit, opts ...Com
 	}
 
 	// compile the circuit into its final form
-	return builder.Compile()
+	ccs, err := builder.Compile()
+	if err != nil || !opt.Optimize {
+		return ccs, err
+	}
+
+	report, err := constraint.Optimize(ccs, opt.CompressThreshold, opt.Optimizations...)
+	if errors.Is(err, constraint.ErrOptimizationUnsupported) {
+		log.Warn().Err(err).Msg("skipping constraint system optimization")
+		return ccs, nil
+	}
+	if err != nil {
+		log.Err(err).Msg("optimizing constraint system")
+		return nil, fmt.Errorf("optimize: %w", err)
+	}
+	log.Info().Int("nbConstraintsBefore", report.NbConstraintsBefore).Int("nbConstraints", report.NbConstraintsAfter).
+		Int("nbInternalVariablesBefore", report.NbInternalVariablesBefore).Int("nbInternalVariables", report.NbInternalVariablesAfter).
+		Msg("optimized constraint system")
+	return ccs, nil
 }
 
 func parseCircuit(builder Builder, circuit Circuit) (err error) {
@@ -154,6 +172,9 @@ type CompileConfig struct {
 	Capacity                  int
 	IgnoreUnconstrainedInputs bool
 	CompressThreshold         int
+	Optimize                  bool
+	Optimizations             []constraint.Optimization
+	ComponentCacheDir         string
 }
 
 // WithCapacity is a compile option that specifies the estimated capacity needed
@@ -203,6 +224,23 @@ func WithCompressThreshold(threshold int) CompileOption {
 	}
 }
 
+// WithOptimizations is a compile option which runs the given optimization
+// passes over the compiled constraint system, all of them if none is given
+// (see [constraint.Optimize]). The passes eliminate the dead wires and the
+// duplicated constraints and hint calls, substitute the linear constraints of
+// a R1CS and merge the chains of additions of a SparseR1CS.
+//
+// The number of constraints before and after the optimization is logged. If
+// the constraint system uses features the optimizer doesn't support, such as
+// commitments or custom gates, it is returned unoptimized with a warning.
+func WithOptimizations(optimizations ...constraint.Optimization) CompileOption {
+	return func(opt *CompileConfig) error {
+		opt.Optimize = true
+		opt.Optimizations = optimizations
+		return nil
+	}
+}
+
 var tVariable reflect.Type
 
 func init() {
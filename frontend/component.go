@@ -0,0 +1,683 @@
+package frontend
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend/schema"
+	"github.com/consensys/gnark/internal/embedded"
+	"github.com/consensys/gnark/internal/kvstore"
+	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/logger"
+)
+
+// Component is an independent sub-circuit, compiled once and instantiated for
+// several inputs (see Instantiate), for the circuits repeating a sub-circuit,
+// as the verification of many signatures.
+//
+// A component is identified by its type and the values of its fields, which
+// are its parameters (e.g. the number of bits of its inputs): the components
+// with the same type and field values must define the same constraints. The
+// fields must then be plain values (booleans, numbers, strings, and arrays and
+// structs of them), otherwise the component must implement KeyedComponent.
+type Component interface {
+	// Define defines the constraints of the component on its inputs and
+	// returns its outputs. The inputs are variables, even if the values
+	// given to Instantiate are constants.
+	Define(api API, inputs []Variable) ([]Variable, error)
+}
+
+// KeyedComponent is a Component identified by a key rather than by the values
+// of its fields, for the components with pointers, slices, maps or interfaces
+// among their parameters.
+type KeyedComponent interface {
+	Component
+
+	// Key identifies the component among the components of its type: the
+	// components with the same type and key must define the same
+	// constraints. The key must not depend on the run (e.g. on addresses).
+	Key() string
+}
+
+// Instantiate adds the constraints of the component on the inputs and returns
+// its outputs; see InstantiateAll.
+func Instantiate(api API, c Component, inputs ...Variable) ([]Variable, error) {
+	outputs, err := InstantiateAll(api, c, [][]Variable{inputs})
+	if err != nil {
+		return nil, err
+	}
+	return outputs[0], nil
+}
+
+// InstantiateAll adds the constraints of an instance of the component for
+// each list of inputs, and returns the outputs of the instances.
+//
+// The component is compiled once, as a template constraint system whose
+// secret inputs are the inputs of the component. Its instructions are then
+// copied in the constraint system of the circuit for each instance, renaming
+// the wires; the instances are relocated in parallel. WithComponentCache
+// caches the templates on disk between runs.
+//
+// The inputs which are not a single wire cost a constraint each. The
+// components can't use commitments (and hence the range checks of
+// std/rangecheck with a commitment), custom gates, GKR or lookups, and their
+// debug information and logs are not copied. With the APIs which don't build a
+// constraint system, as the test engine, the component is defined for each
+// instance.
+func InstantiateAll(api API, c Component, inputs [][]Variable) ([][]Variable, error) {
+	b, ok := api.Compiler().(componentBuilder)
+	var ctx *componentContext
+	if ok {
+		ctx, _ = b.GetKeyValue(componentContextKey{}).(*componentContext)
+	}
+	if ctx == nil {
+		outputs := make([][]Variable, len(inputs))
+		for i := range inputs {
+			var err error
+			if outputs[i], err = c.Define(api, inputs[i]); err != nil {
+				return nil, err
+			}
+		}
+		return outputs, nil
+	}
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+	for i := range inputs {
+		if len(inputs[i]) != len(inputs[0]) {
+			return nil, fmt.Errorf("instance %d has %d inputs, expected %d", i, len(inputs[i]), len(inputs[0]))
+		}
+	}
+
+	t, err := ctx.template(b, c, len(inputs[0]))
+	if err != nil {
+		return nil, fmt.Errorf("component %T: %w", c, err)
+	}
+	return t.instantiate(api, b, inputs)
+}
+
+// WithComponentCache is a compile option which caches the compiled components
+// (see Component) in the directory dir between runs. The entries are keyed by
+// the component, the type of the constraint system, the compile options and
+// the digest of the executable, so that they are invalidated when the program
+// changes.
+func WithComponentCache(dir string) CompileOption {
+	return func(opt *CompileConfig) error {
+		opt.ComponentCacheDir = dir
+		return nil
+	}
+}
+
+// componentBuilder is implemented by the builders of the constraint systems,
+// which give access to their constraint system to instantiate the components.
+type componentBuilder interface {
+	Compiler
+	kvstore.Store
+	ConstraintSystem() constraint.ConstraintSystem
+}
+
+type componentContextKey struct{}
+
+// componentContext is stored in the builders by Compile, to compile the
+// templates with the same builder and options.
+type componentContext struct {
+	field      *big.Int
+	newBuilder NewBuilder
+	config     CompileConfig
+	templates  map[string]*componentTemplate
+}
+
+func setComponentContext(builder Builder, field *big.Int, newBuilder NewBuilder, config CompileConfig) {
+	if kv, ok := builder.(kvstore.Store); ok {
+		kv.SetKeyValue(componentContextKey{}, &componentContext{
+			field:      field,
+			newBuilder: newBuilder,
+			config:     config,
+			templates:  make(map[string]*componentTemplate),
+		})
+	}
+}
+
+// template returns the template of the component, compiled or read from the
+// cache.
+func (ctx *componentContext) template(b componentBuilder, c Component, nbInputs int) (*componentTemplate, error) {
+	key, err := componentKey(c)
+	if err != nil {
+		return nil, err
+	}
+	key = fmt.Sprintf("%s/%d", key, nbInputs)
+	if t, ok := ctx.templates[key]; ok {
+		return t, nil
+	}
+
+	log := logger.Logger()
+	var path string
+	if ctx.config.ComponentCacheDir != "" {
+		if path, err = ctx.cachePath(b, key); err != nil {
+			log.Warn().Err(err).Msg("can't cache the component")
+		} else if t, err := readComponentTemplate(path, b.ConstraintSystem()); err == nil {
+			log.Debug().Str("component", key).Msg("read component from the cache")
+			ctx.templates[key] = t
+			return t, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			log.Warn().Err(err).Str("path", path).Msg("reading the component cache")
+		}
+	}
+
+	t, err := ctx.compile(c, nbInputs)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Str("component", key).Int("nbConstraints", t.cs.GetNbConstraints()).Msg("compiled component")
+	if path != "" {
+		if err := t.write(path); err != nil {
+			log.Warn().Err(err).Str("path", path).Msg("writing the component cache")
+		}
+	}
+	ctx.templates[key] = t
+	return t, nil
+}
+
+// componentKey returns the key of the component, its type qualified by the
+// package path and the values of its fields or its Key.
+func componentKey(c Component) (string, error) {
+	// %T only qualifies the type by the package name, not its path
+	t := reflect.TypeOf(c)
+	name := ""
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		name = "*"
+	}
+	name += t.PkgPath() + "." + t.Name()
+
+	if k, ok := c.(KeyedComponent); ok {
+		return name + "/" + k.Key(), nil
+	}
+	if !isPlainValue(t) {
+		return "", errors.New("the fields are not plain values, the component must implement KeyedComponent")
+	}
+	// %#v formats the fields without their String methods
+	return fmt.Sprintf("%s/%#v", name, c), nil
+}
+
+// isPlainValue returns true if the values of type t are formatted
+// independently of the run, i.e. t has no pointers, slices, maps, interfaces,
+// functions or channels.
+func isPlainValue(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return isPlainValue(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !isPlainValue(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// compile compiles the component with the builder of the circuit.
+func (ctx *componentContext) compile(c Component, nbInputs int) (t *componentTemplate, err error) {
+	config := ctx.config
+	config.Capacity = 0
+	config.IgnoreUnconstrainedInputs = true
+	config.Optimize = false
+	builder, err := ctx.newBuilder(ctx.field, config)
+	if err != nil {
+		return nil, err
+	}
+	// the components may instantiate other components
+	if kv, ok := builder.(kvstore.Store); ok {
+		kv.SetKeyValue(componentContextKey{}, ctx)
+	}
+
+	inputs := make([]Variable, nbInputs)
+	for i := range inputs {
+		name := fmt.Sprintf("input%d", i)
+		inputs[i] = builder.SecretVariable(schema.LeafInfo{Visibility: schema.Secret, FullName: func() string { return name }})
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	outputs, err := c.Define(builder, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("define: %w", err)
+	}
+	if err = callDeferred(builder); err != nil {
+		return nil, fmt.Errorf("deferred: %w", err)
+	}
+
+	canonical := make([]constraint.LinearExpression, len(outputs))
+	for i, o := range outputs {
+		switch v := builder.ToCanonicalVariable(o).(type) {
+		case constraint.LinearExpression:
+			canonical[i] = append(constraint.LinearExpression(nil), v...)
+		case constraint.Term:
+			canonical[i] = constraint.LinearExpression{v}
+		default:
+			return nil, fmt.Errorf("unsupported output %T", v)
+		}
+	}
+
+	ccs, err := builder.Compile()
+	if err != nil {
+		return nil, err
+	}
+	return newComponentTemplate(ccs, canonical)
+}
+
+// cachePath returns the path of the cache entry of the component.
+func (ctx *componentContext) cachePath(b componentBuilder, key string) (string, error) {
+	executable, err := executableDigest()
+	if err != nil {
+		return "", err
+	}
+	cs := b.ConstraintSystem()
+	system, err := embedded.Get[constraint.System](cs)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(executable)
+	fmt.Fprintf(h, "%s\x00%x\x00%d\x00%d\x00%d", key, ctx.field, system.Type, system.GetNbWires(), ctx.config.CompressThreshold)
+	return filepath.Join(ctx.config.ComponentCacheDir, hex.EncodeToString(h.Sum(nil))+".component"), nil
+}
+
+// executableDigest returns the SHA-256 digest of the running program.
+var executableDigest = sync.OnceValues(func() ([]byte, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+})
+
+// componentTemplate is a compiled component. The inputs of the component are
+// the secret variables of the constraint system, and its public variables
+// are the constant wire of a R1CS.
+type componentTemplate struct {
+	cs      constraint.ConstraintSystem
+	system  *constraint.System
+	outputs []constraint.LinearExpression
+
+	nbPublic, nbInputs int
+	instructions       []componentInstruction
+}
+
+// componentInstruction is a decompressed instruction of a template.
+type componentInstruction struct {
+	blueprint   constraint.BlueprintID
+	r1c         *constraint.R1C
+	sparse      *constraint.SparseR1C
+	hint        *constraint.HintMapping
+	calldataEnd int // end of the calldata of the instruction in the calldata of an instance
+}
+
+func newComponentTemplate(cs constraint.ConstraintSystem, outputs []constraint.LinearExpression) (*componentTemplate, error) {
+	system, err := embedded.Get[constraint.System](cs)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(system.CommitmentInfo.CommitmentIndexes()) != 0:
+		return nil, errors.New("commitments are not supported in components")
+	case system.GkrInfo.Is():
+		return nil, errors.New("GKR is not supported in components")
+	case len(system.CustomGates) != 0:
+		return nil, errors.New("custom gates are not supported in components")
+	}
+
+	t := &componentTemplate{
+		cs:           cs,
+		system:       system,
+		outputs:      outputs,
+		nbPublic:     system.GetNbPublicVariables(),
+		nbInputs:     system.GetNbPublicVariables() + system.GetNbSecretVariables(),
+		instructions: make([]componentInstruction, len(system.Instructions)),
+	}
+	calldataEnd := 0
+	for i, pi := range system.Instructions {
+		inst := pi.Unpack(system)
+		ci := &t.instructions[i]
+		ci.blueprint = pi.BlueprintID
+		switch b := system.Blueprints[pi.BlueprintID].(type) {
+		case constraint.BlueprintR1C:
+			ci.r1c = new(constraint.R1C)
+			b.DecompressR1C(ci.r1c, inst)
+		case constraint.BlueprintSparseR1C:
+			ci.sparse = new(constraint.SparseR1C)
+			b.DecompressSparseR1C(ci.sparse, inst)
+		case constraint.BlueprintHint:
+			ci.hint = new(constraint.HintMapping)
+			b.DecompressHint(ci.hint, inst)
+		default:
+			return nil, fmt.Errorf("blueprint %T is not supported in components", b)
+		}
+		if system.Blueprints[pi.BlueprintID].NbOutputs(inst) != 0 {
+			return nil, fmt.Errorf("blueprint %T is not supported in components", system.Blueprints[pi.BlueprintID])
+		}
+		calldataEnd += len(inst.Calldata)
+		ci.calldataEnd = calldataEnd
+	}
+	return t, nil
+}
+
+type componentRelocationKey struct {
+	t *componentTemplate
+}
+
+// componentRelocation maps the blueprints and the coefficients of a template
+// to the ones of the constraint system of a circuit.
+type componentRelocation struct {
+	blueprints   map[constraint.BlueprintID]constraint.BlueprintID
+	coefficients []uint32
+}
+
+func (t *componentTemplate) relocation(b componentBuilder) (*componentRelocation, error) {
+	if r, ok := b.GetKeyValue(componentRelocationKey{t}).(*componentRelocation); ok {
+		return r, nil
+	}
+	cs := b.ConstraintSystem()
+	system, err := embedded.Get[constraint.System](cs)
+	if err != nil {
+		return nil, err
+	}
+
+	for id, name := range t.system.MHintsDependencies {
+		if registered, ok := system.MHintsDependencies[id]; ok && registered != name {
+			return nil, fmt.Errorf("hint %s registered with the same id as %s", name, registered)
+		}
+		system.MHintsDependencies[id] = name
+	}
+
+	r := &componentRelocation{
+		blueprints:   make(map[constraint.BlueprintID]constraint.BlueprintID),
+		coefficients: make([]uint32, t.cs.GetNbCoefficients()),
+	}
+	for i := range r.coefficients {
+		r.coefficients[i] = cs.AddCoeff(t.cs.GetCoefficient(i))
+	}
+	for _, ci := range t.instructions {
+		if _, ok := r.blueprints[ci.blueprint]; ok {
+			continue
+		}
+		// reuse the blueprints of the circuit; the supported blueprints are
+		// stateless.
+		blueprint := t.system.Blueprints[ci.blueprint]
+		id := constraint.BlueprintID(math.MaxUint32)
+		for j, other := range system.Blueprints {
+			if reflect.DeepEqual(blueprint, other) {
+				id = constraint.BlueprintID(j)
+				break
+			}
+		}
+		if id == math.MaxUint32 {
+			id = cs.AddBlueprint(blueprint)
+		}
+		r.blueprints[ci.blueprint] = id
+	}
+
+	b.SetKeyValue(componentRelocationKey{t}, r)
+	return r, nil
+}
+
+// instantiate adds the instances of the template to the constraint system of
+// the circuit.
+func (t *componentTemplate) instantiate(api API, b componentBuilder, inputs [][]Variable) ([][]Variable, error) {
+	r, err := t.relocation(b)
+	if err != nil {
+		return nil, err
+	}
+	cs := b.ConstraintSystem()
+
+	// the wires of the inputs of the template in each instance
+	wires := make([][]uint32, len(inputs))
+	for k := range inputs {
+		wires[k] = make([]uint32, t.nbInputs)
+		for i := 0; i < t.nbPublic; i++ {
+			wires[k][i] = uint32(i)
+		}
+		for i, v := range inputs[k] {
+			if wires[k][t.nbPublic+i], err = componentInputWire(api, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// the internal wires of the instances are allocated contiguously
+	nbInternal := t.system.NbInternalVariables
+	base := cs.GetNbPublicVariables() + cs.GetNbSecretVariables() + cs.GetNbInternalVariables()
+	for i := 0; i < len(inputs)*nbInternal; i++ {
+		cs.AddInternalVariable()
+	}
+	wire := func(k int) func(w uint32) uint32 {
+		offset := uint32(base+k*nbInternal) - uint32(t.nbInputs)
+		return func(w uint32) uint32 {
+			switch {
+			case w == math.MaxUint32:
+				return w
+			case int(w) < t.nbInputs:
+				return wires[k][w]
+			default:
+				return w + offset
+			}
+		}
+	}
+
+	// relocate the instances in parallel, by batches to bound the memory
+	batchSize := 4 * runtime.NumCPU()
+	calldata := make([][]uint32, min(batchSize, len(inputs)))
+	for start := 0; start < len(inputs); start += batchSize {
+		end := min(start+batchSize, len(inputs))
+		utils.Parallelize(end-start, func(from, to int) {
+			for k := from; k < to; k++ {
+				calldata[k] = t.relocate(r, wire(start+k), calldata[k][:0])
+			}
+		})
+		for k := 0; k < end-start; k++ {
+			previous := 0
+			for _, ci := range t.instructions {
+				cs.AddInstruction(r.blueprints[ci.blueprint], calldata[k][previous:ci.calldataEnd])
+				previous = ci.calldataEnd
+			}
+		}
+	}
+
+	outputs := make([][]Variable, len(inputs))
+	for k := range inputs {
+		outputs[k] = make([]Variable, len(t.outputs))
+		w := wire(k)
+		for i, l := range t.outputs {
+			outputs[k][i] = t.output(api, l, w)
+		}
+	}
+	return outputs, nil
+}
+
+// relocate appends the calldata of the instructions of an instance to buf.
+func (t *componentTemplate) relocate(r *componentRelocation, wire func(uint32) uint32, buf []uint32) []uint32 {
+	term := func(tt constraint.Term) constraint.Term {
+		return constraint.Term{CID: r.coefficients[tt.CID], VID: wire(tt.VID)}
+	}
+	expression := func(l constraint.LinearExpression) constraint.LinearExpression {
+		res := make(constraint.LinearExpression, len(l))
+		for i := range l {
+			res[i] = term(l[i])
+		}
+		return res
+	}
+
+	for _, ci := range t.instructions {
+		switch b := t.system.Blueprints[ci.blueprint].(type) {
+		case constraint.BlueprintR1C:
+			c := constraint.R1C{L: expression(ci.r1c.L), R: expression(ci.r1c.R), O: expression(ci.r1c.O)}
+			b.CompressR1C(&c, &buf)
+		case constraint.BlueprintSparseR1C:
+			c := *ci.sparse
+			c.XA, c.XB, c.XC, c.XD, c.XE = wire(c.XA), wire(c.XB), wire(c.XC), wire(c.XD), wire(c.XE)
+			for _, q := range [...]*uint32{&c.QL, &c.QR, &c.QO, &c.QM, &c.QC, &c.QD, &c.QE, &c.QG} {
+				*q = r.coefficients[*q]
+			}
+			b.CompressSparseR1C(&c, &buf)
+		case constraint.BlueprintHint:
+			h := constraint.HintMapping{HintID: ci.hint.HintID, Inputs: make([]constraint.LinearExpression, len(ci.hint.Inputs))}
+			for i, l := range ci.hint.Inputs {
+				h.Inputs[i] = expression(l)
+			}
+			// the outputs are contiguous internal wires
+			h.OutputRange.Start = wire(ci.hint.OutputRange.Start)
+			h.OutputRange.End = h.OutputRange.Start + ci.hint.OutputRange.End - ci.hint.OutputRange.Start
+			b.CompressHint(h, &buf)
+		}
+	}
+	return buf
+}
+
+// output returns the variable of an output of an instance.
+func (t *componentTemplate) output(api API, l constraint.LinearExpression, wire func(uint32) uint32) Variable {
+	var res Variable = 0
+	for _, tt := range l {
+		c := t.cs.ToBigInt(t.cs.GetCoefficient(int(tt.CID)))
+		if tt.IsConstant() {
+			res = api.Add(res, c)
+			continue
+		}
+		v := api.Compiler().InternalVariable(wire(tt.VID))
+		if c.Cmp(big.NewInt(1)) != 0 {
+			v = api.Mul(v, c)
+		}
+		res = api.Add(res, v)
+	}
+	return res
+}
+
+// componentInputWire returns the wire of an input of an instance, creating
+// one if the input is not a single wire.
+func componentInputWire(api API, v Variable) (uint32, error) {
+	var t constraint.Term
+	switch c := api.Compiler().ToCanonicalVariable(v).(type) {
+	case constraint.LinearExpression:
+		if len(c) == 1 {
+			t = c[0]
+		}
+	case constraint.Term:
+		t = c
+	}
+	if t.CID == constraint.CoeffIdOne && !t.IsConstant() {
+		return t.VID, nil
+	}
+
+	w, err := api.Compiler().NewHint(componentInputHint, 1, v)
+	if err != nil {
+		return 0, err
+	}
+	api.AssertIsEqual(w[0], v)
+	return componentInputWire(api, w[0])
+}
+
+// componentInputHint copies its input, as the wire of an input of a component.
+func componentInputHint(_ *big.Int, inputs, outputs []*big.Int) error {
+	outputs[0].Set(inputs[0])
+	return nil
+}
+
+func init() {
+	solver.RegisterHint(componentInputHint)
+}
+
+// write writes the template in the cache file path.
+func (t *componentTemplate) write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = t.cs.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	buf := binary.LittleEndian.AppendUint32(nil, uint32(len(t.outputs)))
+	for _, l := range t.outputs {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(l)))
+		for _, tt := range l {
+			buf = binary.LittleEndian.AppendUint32(buf, tt.CID)
+			buf = binary.LittleEndian.AppendUint32(buf, tt.VID)
+		}
+	}
+	if _, err = f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	// the cache entries are replaced atomically
+	return os.Rename(f.Name(), path)
+}
+
+// readComponentTemplate reads a template from the cache file path, in a
+// constraint system of the same type as cs.
+func readComponentTemplate(path string, cs constraint.ConstraintSystem) (*componentTemplate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ccs := reflect.New(reflect.TypeOf(cs).Elem()).Interface().(constraint.ConstraintSystem)
+	if _, err = ccs.ReadFrom(f); err != nil {
+		return nil, err
+	}
+	var n uint32
+	if err = binary.Read(f, binary.LittleEndian, &n); err != nil {
+		return nil, err
+	}
+	outputs := make([]constraint.LinearExpression, n)
+	for i := range outputs {
+		if err = binary.Read(f, binary.LittleEndian, &n); err != nil {
+			return nil, err
+		}
+		outputs[i] = make(constraint.LinearExpression, n)
+		if err = binary.Read(f, binary.LittleEndian, outputs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return newComponentTemplate(ccs, outputs)
+}
@@ -0,0 +1,206 @@
+package frontend_test
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+)
+
+// cubeComponent returns x³+y+Offset and x/y.
+type cubeComponent struct {
+	Offset int
+}
+
+func (c cubeComponent) Define(api frontend.API, inputs []frontend.Variable) ([]frontend.Variable, error) {
+	x, y := inputs[0], inputs[1]
+	return []frontend.Variable{
+		api.Add(api.Mul(x, x, x), y, c.Offset),
+		api.Div(x, y),
+	}, nil
+}
+
+// pointerComponent is cubeComponent with a parameter formatted as an address.
+type pointerComponent struct {
+	Offset *int
+}
+
+func (c pointerComponent) Define(api frontend.API, inputs []frontend.Variable) ([]frontend.Variable, error) {
+	return cubeComponent{Offset: *c.Offset}.Define(api, inputs)
+}
+
+// keyedComponent is pointerComponent identified by the value of its offset.
+type keyedComponent struct {
+	pointerComponent
+}
+
+func (c keyedComponent) Key() string {
+	return strconv.Itoa(*c.Offset)
+}
+
+type componentCircuit struct {
+	X, Y   [8]frontend.Variable
+	Out    [8][2]frontend.Variable `gnark:",public"`
+	inline bool
+}
+
+func (c *componentCircuit) Define(api frontend.API) error {
+	component := cubeComponent{Offset: 5}
+	inputs := make([][]frontend.Variable, len(c.X))
+	for i := range c.X {
+		// constant and linear inputs are materialized
+		y := c.Y[i]
+		if i == 1 {
+			y = api.Add(c.Y[i], 1)
+		}
+		inputs[i] = []frontend.Variable{c.X[i], y}
+	}
+	inputs[2][0] = 3
+
+	var outputs [][]frontend.Variable
+	if c.inline {
+		outputs = make([][]frontend.Variable, len(inputs))
+		for i := range inputs {
+			var err error
+			if outputs[i], err = component.Define(api, inputs[i]); err != nil {
+				return err
+			}
+		}
+	} else {
+		var err error
+		if outputs, err = frontend.InstantiateAll(api, component, inputs); err != nil {
+			return err
+		}
+	}
+
+	for i := range outputs {
+		for j := range outputs[i] {
+			api.AssertIsEqual(outputs[i][j], c.Out[i][j])
+		}
+	}
+	return nil
+}
+
+// componentAssignment returns the assignment of componentCircuit, with the
+// outputs x³+y+5 and x/y of each instance.
+func componentAssignment(field *big.Int) *componentCircuit {
+	var c componentCircuit
+	for i := range c.X {
+		x, y := big.NewInt(int64(i+2)), big.NewInt(int64(i+1))
+		c.X[i], c.Y[i] = new(big.Int).Set(x), new(big.Int).Set(y)
+		if i == 1 {
+			y.Add(y, big.NewInt(1))
+		}
+		if i == 2 {
+			x.SetInt64(3)
+		}
+		cube := new(big.Int).Exp(x, big.NewInt(3), nil)
+		c.Out[i][0] = cube.Add(cube, y).Add(cube, big.NewInt(5))
+		div := new(big.Int).ModInverse(y, field)
+		c.Out[i][1] = div.Mul(div, x).Mod(div, field)
+	}
+	return &c
+}
+
+func TestComponent(t *testing.T) {
+	assert := test.NewAssert(t)
+	field := ecc.BN254.ScalarField()
+
+	assert.NoError(test.IsSolved(&componentCircuit{}, componentAssignment(field), field))
+
+	for _, b := range []struct {
+		name       string
+		newBuilder frontend.NewBuilder
+	}{{"r1cs", r1cs.NewBuilder}, {"scs", scs.NewBuilder}} {
+		assert.Run(func(assert *test.Assert) {
+			dir := t.TempDir()
+			ccs, err := frontend.Compile(field, b.newBuilder, &componentCircuit{}, frontend.WithComponentCache(dir))
+			assert.NoError(err)
+			inline, err := frontend.Compile(field, b.newBuilder, &componentCircuit{inline: true})
+			assert.NoError(err)
+
+			// each output of each instance is the output of the inline definition
+			valid := componentAssignment(field)
+			w, err := frontend.NewWitness(valid, field)
+			assert.NoError(err)
+			assert.NoError(ccs.IsSolved(w))
+			assert.NoError(inline.IsSolved(w))
+			for i := range valid.Out {
+				for j := range valid.Out[i] {
+					wrong := componentAssignment(field)
+					wrong.Out[i][j] = new(big.Int).Add(wrong.Out[i][j].(*big.Int), big.NewInt(1))
+					w, err := frontend.NewWitness(wrong, field)
+					assert.NoError(err)
+					assert.Error(ccs.IsSolved(w), "instance %d, output %d", i, j)
+					assert.Error(inline.IsSolved(w), "instance %d, output %d", i, j)
+				}
+			}
+
+			// the second compilation reads the component from the cache: the
+			// entry, written after each compilation of the component, is not
+			// replaced
+			entries, err := os.ReadDir(dir)
+			assert.NoError(err)
+			assert.Len(entries, 1)
+			path := filepath.Join(dir, entries[0].Name())
+			past := time.Now().Add(-time.Hour).Truncate(time.Second)
+			assert.NoError(os.Chtimes(path, past, past))
+			cached, err := frontend.Compile(field, b.newBuilder, &componentCircuit{}, frontend.WithComponentCache(dir))
+			assert.NoError(err)
+			info, err := os.Stat(path)
+			assert.NoError(err)
+			assert.True(info.ModTime().Equal(past), "the cache entry was rewritten")
+			d1, err := ccs.Digest()
+			assert.NoError(err)
+			d2, err := cached.Digest()
+			assert.NoError(err)
+			assert.Equal(d1, d2)
+		}, b.name)
+	}
+}
+
+type componentKeyCircuit struct {
+	X, Y      frontend.Variable
+	Out       frontend.Variable `gnark:",public"`
+	component frontend.Component
+}
+
+func (c *componentKeyCircuit) Define(api frontend.API) error {
+	outputs, err := frontend.Instantiate(api, c.component, c.X, c.Y)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(outputs[0], c.Out)
+	return nil
+}
+
+func TestComponentKey(t *testing.T) {
+	assert := test.NewAssert(t)
+	field := ecc.BN254.ScalarField()
+	offset := 5
+
+	for _, b := range []struct {
+		name       string
+		newBuilder frontend.NewBuilder
+	}{{"r1cs", r1cs.NewBuilder}, {"scs", scs.NewBuilder}} {
+		assert.Run(func(assert *test.Assert) {
+			// the key of a pointer would be its address
+			_, err := frontend.Compile(field, b.newBuilder, &componentKeyCircuit{component: pointerComponent{Offset: &offset}})
+			assert.ErrorContains(err, "must implement KeyedComponent")
+
+			ccs, err := frontend.Compile(field, b.newBuilder, &componentKeyCircuit{component: keyedComponent{pointerComponent{Offset: &offset}}})
+			assert.NoError(err)
+			w, err := frontend.NewWitness(&componentKeyCircuit{X: 2, Y: 3, Out: 16}, field)
+			assert.NoError(err)
+			assert.NoError(ccs.IsSolved(w))
+		}, b.name)
+	}
+}
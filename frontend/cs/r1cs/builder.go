@@ -270,6 +270,11 @@ func init() {
 	tVariable = reflect.ValueOf(struct{ A frontend.Variable }{}).FieldByName("A").Type()
 }
 
+// ConstraintSystem returns the constraint system being built.
+func (builder *builder) ConstraintSystem() constraint.ConstraintSystem {
+	return builder.cs
+}
+
 // Compile constructs a rank-1 constraint system
 func (builder *builder) Compile() (constraint.ConstraintSystem, error) {
 	// TODO if already compiled, return builder.cs object
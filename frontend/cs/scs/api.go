@@ -719,6 +719,159 @@ func (builder *builder) AddPlonkConstraint(a, b, o frontend.Variable, qL, qR, qO
 	})
 }
 
+// DefineCustomGate defines the custom gate gate(a, b, o) = ∑ Coeff.aᴬ.bᴮ.oᶜ
+// and returns its id.
+func (builder *builder) DefineCustomGate(name string, terms ...frontend.GateTerm) (int, error) {
+	gate := constraint.CustomGate{
+		Name:  name,
+		Terms: make([]constraint.GateTerm, len(terms)),
+	}
+	for i, t := range terms {
+		gate.Terms[i] = constraint.GateTerm{
+			CID: builder.cs.AddCoeff(builder.cs.FromInterface(t.Coeff)),
+			A:   t.A,
+			B:   t.B,
+			C:   t.C,
+		}
+	}
+	bID, err := builder.cs.AddCustomGate(gate)
+	if err != nil {
+		return 0, err
+	}
+	builder.customGates = append(builder.customGates, customGate{bID: bID, terms: terms})
+	return len(builder.customGates) - 1, nil
+}
+
+// EvaluateCustomGate returns res = qG.gate(a, b), where gate doesn't depend on o.
+func (builder *builder) EvaluateCustomGate(gate int, a, b frontend.Variable, qG int) frontend.Variable {
+	g := builder.customGates[gate]
+	for _, t := range g.terms {
+		if t.C != 0 {
+			panic(fmt.Sprintf("custom gate %d depends on o", gate))
+		}
+	}
+
+	usesA, usesB, _ := g.uses()
+	wires, ok := builder.customGateWires([]frontend.Variable{a, b}, []bool{usesA, usesB})
+	if !ok {
+		return builder.Mul(builder.evaluateCustomGate(g, a, b, 0), qG)
+	}
+
+	res := builder.newInternalVariable()
+	builder.addCustomGateConstraint(g.bID, sparseR1C{
+		xa: wires[0].VID,
+		xb: wires[1].VID,
+		xc: res.VID,
+		qO: builder.tMinusOne,
+	}, builder.cs.FromInterface(qG))
+	return res
+}
+
+// AddCustomGateConstraint asserts qG.gate(a, b, o) + qL.a + qR.b + qM.ab + qO.o + qC == 0
+func (builder *builder) AddCustomGateConstraint(gate int, a, b, o frontend.Variable, qG, qL, qR, qO, qM, qC int) {
+	g := builder.customGates[gate]
+
+	usesA, usesB, usesO := g.uses()
+	wires, ok := builder.customGateWires([]frontend.Variable{a, b, o}, []bool{
+		usesA || qL != 0 || qM != 0,
+		usesB || qR != 0 || qM != 0,
+		usesO || qO != 0,
+	})
+	if !ok {
+		builder.AssertIsEqual(
+			builder.Add(
+				builder.Mul(builder.evaluateCustomGate(g, a, b, o), qG),
+				builder.Mul(a, qL),
+				builder.Mul(b, qR),
+				builder.Mul(a, b, qM),
+				builder.Mul(o, qO),
+				qC,
+			),
+			0,
+		)
+		return
+	}
+
+	builder.addCustomGateConstraint(g.bID, sparseR1C{
+		xa: wires[0].VID,
+		xb: wires[1].VID,
+		xc: wires[2].VID,
+		qL: builder.cs.FromInterface(qL),
+		qR: builder.cs.FromInterface(qR),
+		qO: builder.cs.FromInterface(qO),
+		qM: builder.cs.FromInterface(qM),
+		qC: builder.cs.FromInterface(qC),
+	}, builder.cs.FromInterface(qG))
+}
+
+// evaluateCustomGate returns gate(a, b, o) computed with the API.
+func (builder *builder) evaluateCustomGate(g customGate, a, b, o frontend.Variable) frontend.Variable {
+	monomials := make([]frontend.Variable, len(g.terms))
+	for i, t := range g.terms {
+		var factors []frontend.Variable
+		for j := uint8(0); j < t.A; j++ {
+			factors = append(factors, a)
+		}
+		for j := uint8(0); j < t.B; j++ {
+			factors = append(factors, b)
+		}
+		for j := uint8(0); j < t.C; j++ {
+			factors = append(factors, o)
+		}
+		monomials[i] = builder.Mul(t.Coeff, 1, factors...)
+	}
+	return builder.Add(0, 0, monomials...)
+}
+
+// customGateWires returns the wires of the operands of a custom gate
+// constraint, with coefficient 1. An operand which the constraint doesn't read
+// may be constant: its wire is then the wire of another operand. ok is false if
+// an operand read by the constraint is constant, or if all the operands are.
+func (builder *builder) customGateWires(operands []frontend.Variable, read []bool) (wires []expr.Term, ok bool) {
+	constant := make([]bool, len(operands))
+	dummy := -1
+	for i, v := range operands {
+		if _, constant[i] = builder.constantValue(v); !constant[i] {
+			dummy = i
+		} else if read[i] {
+			return nil, false
+		}
+	}
+	if dummy == -1 {
+		return nil, false
+	}
+
+	wires = make([]expr.Term, len(operands))
+	for i, v := range operands {
+		if !constant[i] {
+			wires[i] = builder.customGateWire(v.(expr.Term))
+		}
+	}
+	for i := range operands {
+		if constant[i] {
+			wires[i] = wires[dummy]
+		}
+	}
+	return wires, true
+}
+
+// customGateWire returns a term with coefficient 1 equal to t: the selector of
+// a custom gate can't absorb the coefficients of its wires.
+func (builder *builder) customGateWire(t expr.Term) expr.Term {
+	if builder.cs.IsOne(t.Coeff) {
+		return t
+	}
+	res := builder.newInternalVariable()
+	builder.addPlonkConstraint(sparseR1C{
+		xa: t.VID,
+		xb: t.VID,
+		xc: res.VID,
+		qL: t.Coeff,
+		qO: builder.tMinusOne,
+	})
+	return res
+}
+
 func filterConstants(v []frontend.Variable) []frontend.Variable {
 	res := make([]frontend.Variable, 0, len(v))
 	for _, vI := range v {
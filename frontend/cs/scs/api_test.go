@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/scs"
 	"github.com/consensys/gnark/test"
@@ -50,6 +51,43 @@ func TestDuplicateAdd(t *testing.T) {
 	assert.NoError(err, "solving failed")
 }
 
+type circuitDupWideAdd struct {
+	A, B, C, D frontend.Variable
+	R          frontend.Variable
+}
+
+func (c *circuitDupWideAdd) Define(api frontend.API) error {
+
+	f := api.Add(c.A, c.B, c.C, c.D) // 1 constraint
+	g := api.Add(c.A, c.B, c.C, c.D) // no constraints
+
+	api.AssertIsEqual(f, c.R) // 1 constraint
+	api.AssertIsEqual(g, c.R) // 1 constraint
+
+	return nil
+}
+
+func TestDuplicateWideAdd(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilderWithOptions(scs.WithNbWires(5)), &circuitDupWideAdd{})
+	assert.NoError(err)
+
+	assert.Equal(3, ccs.GetNbConstraints(), "comparing expected number of constraints")
+
+	w, err := frontend.NewWitness(&circuitDupWideAdd{
+		A: 1,
+		B: 2,
+		C: 3,
+		D: 4,
+		R: 10,
+	}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	_, err = ccs.Solve(w)
+	assert.NoError(err, "solving failed")
+}
+
 type circuitDupMul struct {
 	A, B   frontend.Variable
 	R1, R2 frontend.Variable
@@ -257,3 +295,41 @@ func TestSubSameNoConstraint(t *testing.T) {
 		t.Fatal("expected 0 constraints")
 	}
 }
+
+type customGateConstantCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (c *customGateConstantCircuit) Define(api frontend.API) error {
+	cg := api.(frontend.CustomGateAPI)
+	sbox, err := cg.DefineCustomGate("sbox", frontend.GateTerm{Coeff: 1, A: 5})
+	if err != nil {
+		return err
+	}
+	// the gate doesn't read b and o, which may be constant
+	api.AssertIsEqual(cg.EvaluateCustomGate(sbox, c.X, 0, 1), c.Y)
+	cg.AddCustomGateConstraint(sbox, c.X, 0, 0, 1, 0, 0, 0, 0, -32)
+	// the constraint reads o, it falls back to the standard gates
+	cg.AddCustomGateConstraint(sbox, c.X, 0, 32, 1, 0, 0, -1, 0, 0)
+	return nil
+}
+
+func TestCustomGateConstantOperand(t *testing.T) {
+	assert := test.NewAssert(t)
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &customGateConstantCircuit{})
+	assert.NoError(err)
+	nbGates := 0
+	for _, c := range ccs.(constraint.SparseR1CS).GetSparseR1Cs() {
+		if c.QG != constraint.CoeffIdZero {
+			nbGates++
+		}
+	}
+	assert.Equal(2, nbGates)
+
+	w, err := frontend.NewWitness(&customGateConstantCircuit{X: 2, Y: 32}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	assert.NoError(ccs.IsSolved(w))
+	w, err = frontend.NewWitness(&customGateConstantCircuit{X: 2, Y: 33}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	assert.Error(ccs.IsSolved(w))
+}
@@ -49,6 +49,43 @@ func NewBuilder(field *big.Int, config frontend.CompileConfig) (frontend.Builder
 	return newBuilder(field, config), nil
 }
 
+// BuilderOption configures the builder returned by NewBuilderWithOptions.
+type BuilderOption func(*builder) error
+
+// WithNbWires sets the number of wires of the constraints, between 3 and
+// constraint.MaxNbWires. With more than 3 wires, the builder packs the sums of
+// many terms in fewer constraints, using the advice wires of the constraints.
+//
+// The width is capped at 5: a constraint.SparseR1C has the two advice wires XD
+// and XE only, in fixed fields, and the backends commit to as many wires.
+func WithNbWires(nbWires int) BuilderOption {
+	return func(b *builder) error {
+		if err := b.cs.SetNbWires(nbWires); err != nil {
+			return err
+		}
+		if nbWires > 3 {
+			b.wideGate = b.cs.AddBlueprint(&constraint.BlueprintSparseR1CWide{})
+		}
+		return nil
+	}
+}
+
+// NewBuilderWithOptions returns a frontend.NewBuilder of SparseR1CS configured
+// with the options, to be given to frontend.Compile:
+//
+//	frontend.Compile(field, scs.NewBuilderWithOptions(scs.WithNbWires(5)), &circuit)
+func NewBuilderWithOptions(opts ...BuilderOption) frontend.NewBuilder {
+	return func(field *big.Int, config frontend.CompileConfig) (frontend.Builder, error) {
+		b := newBuilder(field, config)
+		for _, opt := range opts {
+			if err := opt(b); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+}
+
 type builder struct {
 	cs     constraint.SparseR1CS
 	config frontend.CompileConfig
@@ -65,12 +102,23 @@ type builder struct {
 	// see addConstraintExist(...)
 	mAddInstructions map[uint64]int
 
+	// same thing for the additions of the wide gate
+	// see wideAddConstraintExist(...)
+	mWideAddInstructions map[uint64]int
+
 	// frequently used coefficients
 	tOne, tMinusOne constraint.Element
 
 	genericGate                constraint.BlueprintID
 	mulGate, addGate, boolGate constraint.BlueprintID
 
+	// blueprint of the constraints with advice wires, if the constraints have
+	// more than 3 wires
+	wideGate constraint.BlueprintID
+
+	// custom gates, by id
+	customGates []customGate
+
 	// used to avoid repeated allocations
 	bufL expr.LinearExpression
 	bufH []constraint.LinearExpression
@@ -80,12 +128,13 @@ type builder struct {
 // we may want to add build tags to tune that
 func newBuilder(field *big.Int, config frontend.CompileConfig) *builder {
 	b := builder{
-		mtBooleans:       make(map[expr.Term]struct{}),
-		mMulInstructions: make(map[uint64]int, config.Capacity/2),
-		mAddInstructions: make(map[uint64]int, config.Capacity/2),
-		config:           config,
-		Store:            kvstore.New(),
-		bufL:             make(expr.LinearExpression, 20),
+		mtBooleans:           make(map[expr.Term]struct{}),
+		mMulInstructions:     make(map[uint64]int, config.Capacity/2),
+		mAddInstructions:     make(map[uint64]int, config.Capacity/2),
+		mWideAddInstructions: make(map[uint64]int),
+		config:               config,
+		Store:                kvstore.New(),
+		bufL:                 make(expr.LinearExpression, 20),
 	}
 	// init hint buffer.
 	_ = b.hintBuffer(256)
@@ -142,6 +191,22 @@ type sparseR1C struct {
 	commitment         constraint.CommitmentConstraint
 }
 
+// customGate is a custom gate defined with DefineCustomGate.
+type customGate struct {
+	bID   constraint.BlueprintID
+	terms []frontend.GateTerm
+}
+
+// uses returns whether the terms of the gate read a, b and o.
+func (g *customGate) uses() (a, b, o bool) {
+	for _, t := range g.terms {
+		a = a || t.A != 0
+		b = b || t.B != 0
+		o = o || t.C != 0
+	}
+	return
+}
+
 // a * b == c
 func (builder *builder) addMulGate(a, b, c expr.Term) {
 	qM := builder.cs.Mul(a.Coeff, b.Coeff)
@@ -173,6 +238,27 @@ func (builder *builder) addAddGate(a, b expr.Term, xc uint32, k constraint.Eleme
 	}, builder.addGate)
 }
 
+// acc + ∑ terms + k == xc, with the terms but the first on the advice wires
+// newWideAddConstraint returns the constraint acc + terms[0] + .. + k == xc of
+// the wide gate, where terms has at most one term per advice wire plus one.
+func (builder *builder) newWideAddConstraint(acc expr.Term, terms expr.LinearExpression, xc uint32, k constraint.Element) constraint.SparseR1C {
+	c := constraint.SparseR1C{
+		XA: uint32(acc.VID),
+		XB: uint32(terms[0].VID),
+		XC: xc,
+		QL: builder.cs.AddCoeff(acc.Coeff),
+		QR: builder.cs.AddCoeff(terms[0].Coeff),
+		QO: constraint.CoeffIdMinusOne,
+		QC: builder.cs.AddCoeff(k),
+	}
+	advice := [...]struct{ x, q *uint32 }{{&c.XD, &c.QD}, {&c.XE, &c.QE}}
+	for i, t := range terms[1:] {
+		*advice[i].x = uint32(t.VID)
+		*advice[i].q = builder.cs.AddCoeff(t.Coeff)
+	}
+	return c
+}
+
 func (builder *builder) addBoolGate(c sparseR1C, debugInfo ...constraint.DebugInfo) {
 	QL := builder.cs.AddCoeff(c.qL)
 	QM := builder.cs.AddCoeff(c.qM)
@@ -222,6 +308,23 @@ func (builder *builder) addPlonkConstraint(c sparseR1C, debugInfo ...constraint.
 	}
 }
 
+// addCustomGateConstraint adds a sparseR1C with the custom gate of blueprint bID
+// to the underlying constraint system:
+// qL⋅xa + qR⋅xb + qO⋅xc + qM⋅(xaxb) + qC + qG⋅gate(xa, xb, xc) == 0
+func (builder *builder) addCustomGateConstraint(bID constraint.BlueprintID, c sparseR1C, qG constraint.Element) {
+	builder.cs.AddSparseR1C(constraint.SparseR1C{
+		XA: uint32(c.xa),
+		XB: uint32(c.xb),
+		XC: uint32(c.xc),
+		QL: builder.cs.AddCoeff(c.qL),
+		QR: builder.cs.AddCoeff(c.qR),
+		QO: builder.cs.AddCoeff(c.qO),
+		QM: builder.cs.AddCoeff(c.qM),
+		QC: builder.cs.AddCoeff(c.qC),
+		QG: builder.cs.AddCoeff(qG),
+	}, bID)
+}
+
 // newInternalVariable creates a new wire, appends it on the list of wires of the circuit, sets
 // the wire's id to the number of wires, and returns it
 func (builder *builder) newInternalVariable() expr.Term {
@@ -291,6 +394,11 @@ func init() {
 	tVariable = reflect.ValueOf(struct{ A frontend.Variable }{}).FieldByName("A").Type()
 }
 
+// ConstraintSystem returns the constraint system being built.
+func (builder *builder) ConstraintSystem() constraint.ConstraintSystem {
+	return builder.cs
+}
+
 func (builder *builder) Compile() (constraint.ConstraintSystem, error) {
 	log := logger.Logger()
 	log.Info().
@@ -452,6 +560,20 @@ func (builder *builder) splitSum(acc expr.Term, r expr.LinearExpression, k *cons
 	if k != nil {
 		qC = *k
 	}
+
+	// with advice wires, a constraint adds up more terms:
+	// acc + r[0] + r[1] (+ r[2]) (+ k) == o
+	if nbAdvice := builder.cs.GetNbWires() - 3; nbAdvice > 0 && len(r) > 1 {
+		m := min(len(r), nbAdvice+1)
+		c := builder.newWideAddConstraint(acc, r[:m], 0, qC)
+		o, found := builder.wideAddConstraintExist(c)
+		if !found {
+			o = builder.newInternalVariable()
+			c.XC = uint32(o.VID)
+			builder.cs.AddSparseR1C(c, builder.wideGate)
+		}
+		return builder.splitSum(o, r[m:], nil)
+	}
 	o, found := builder.addConstraintExist(acc, r[0], qC)
 	if !found {
 		o = builder.newInternalVariable()
@@ -552,6 +674,43 @@ func (builder *builder) addConstraintExist(a, b expr.Term, k constraint.Element)
 	return expr.Term{}, false
 }
 
+// wideAddConstraintExist check if we recorded a constraint of the wide gate in
+// the form
+// qL*xa + qR*xb + qD*xd + qE*xe + qC - xc == 0
+// with the same wires and coefficients as c, whose xc is not set.
+//
+// if we find one, this function returns the xc wire.
+// if we don't, and no previous addition was recorded with xa, xb, xd and xe, add an entry in the map
+// (this assumes that the caller will add a constraint just after this call if it's not found!)
+//
+// limitations:
+// 1. as in addConstraintExist, we just store the first addition that occurred with the wires, and
+// the wires must be in the same order.
+func (builder *builder) wideAddConstraintExist(c constraint.SparseR1C) (expr.Term, bool) {
+	h := (uint64(c.XA) | uint64(c.XB)<<32) ^ (uint64(c.XD)|uint64(c.XE)<<32)*0x9e3779b97f4a7c15
+
+	if iID, ok := builder.mWideAddInstructions[h]; ok {
+		var recorded constraint.SparseR1C
+		inst := builder.cs.GetInstruction(iID)
+		// we know the blueprint we added it.
+		blueprint := constraint.BlueprintSparseR1CWide{}
+		blueprint.DecompressSparseR1C(&recorded, inst)
+
+		xc := recorded.XC
+		recorded.XC = c.XC
+		if recorded != c {
+			// another constraint hashes the same, or the coefficients differ; we
+			// will need an additional constraint
+			return expr.Term{}, false
+		}
+		return expr.NewTerm(int(xc), builder.tOne), true
+	}
+	// we are going to add this constraint, so we mark it.
+	// ! assumes the caller add an instruction immediately  after the call to this function
+	builder.mWideAddInstructions[h] = builder.cs.GetNbInstructions()
+	return expr.Term{}, false
+}
+
 // mulConstraintExist check if we recorded a constraint in the form
 // qM*xa*xb - xc == 0
 //
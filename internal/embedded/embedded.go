@@ -0,0 +1,26 @@
+// Package embedded gives the packages processing the constraint systems
+// independently of the curve access to the constraint.System they embed.
+package embedded
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Get returns the field of type T embedded in the struct pointed to by v, for
+// example the constraint.System embedded in the curve-typed constraint
+// systems. It doesn't import the types it resolves, so that package
+// constraint can use it too.
+func Get[T any](v any) (*T, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Kind() == reflect.Struct {
+		rv = rv.Elem()
+		target := reflect.TypeOf((*T)(nil)).Elem()
+		for i := 0; i < rv.NumField(); i++ {
+			if f := rv.Type().Field(i); f.Anonymous && f.Type == target {
+				return rv.Field(i).Addr().Interface().(*T), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%T doesn't embed a %s", v, reflect.TypeOf((*T)(nil)).Elem())
+}
@@ -102,9 +102,11 @@ func main() {
 			defer wg.Done()
 
 			var (
-				groth16Dir         = strings.Replace(d.RootPath, "{?}", "groth16", 1)
-				groth16MpcSetupDir = filepath.Join(groth16Dir, "mpcsetup")
-				plonkDir           = strings.Replace(d.RootPath, "{?}", "plonk", 1)
+				groth16Dir          = strings.Replace(d.RootPath, "{?}", "groth16", 1)
+				groth16MpcSetupDir  = filepath.Join(groth16Dir, "mpcsetup")
+				groth16AggregateDir = filepath.Join(groth16Dir, "aggregate")
+				plonkDir            = strings.Replace(d.RootPath, "{?}", "plonk", 1)
+				plonkFriDir         = strings.Replace(d.RootPath, "{?}", "plonkfri", 1)
 			)
 
 			if err := os.MkdirAll(groth16Dir, 0700); err != nil {
@@ -154,12 +156,18 @@ func main() {
 			if err := os.MkdirAll(plonkDir, 0700); err != nil {
 				panic(err)
 			}
+			if err := os.MkdirAll(plonkFriDir, 0700); err != nil {
+				panic(err)
+			}
 
 			entries = []bavard.Entry{
 				{File: filepath.Join(groth16Dir, "verify.go"), Templates: []string{"groth16/groth16.verify.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "prove.go"), Templates: []string{"groth16/groth16.prove.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "setup.go"), Templates: []string{"groth16/groth16.setup.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "marshal.go"), Templates: []string{"groth16/groth16.marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "stream.go"), Templates: []string{"groth16/groth16.stream.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "executor.go"), Templates: []string{"groth16/groth16.executor.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "prepare.go"), Templates: []string{"groth16/groth16.prepare.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "marshal_test.go"), Templates: []string{"groth16/tests/groth16.marshal.go.tmpl", importCurve}},
 			}
 			if err := bgen.Generate(d, "groth16", "./template/zkpschemes/", entries...); err != nil {
@@ -189,11 +197,27 @@ func main() {
 				panic(err) // TODO handle
 			}
 
+			// groth16 aggregation
+			if d.Curve == "BN254" || d.Curve == "BLS12-381" {
+				if err := os.MkdirAll(groth16AggregateDir, 0700); err != nil {
+					panic(err)
+				}
+				entries = []bavard.Entry{
+					{File: filepath.Join(groth16AggregateDir, "aggregate.go"), Templates: []string{"groth16/aggregate/aggregate.go.tmpl", importCurve}},
+					{File: filepath.Join(groth16AggregateDir, "verify.go"), Templates: []string{"groth16/aggregate/verify.go.tmpl", importCurve}},
+					{File: filepath.Join(groth16AggregateDir, "marshal.go"), Templates: []string{"groth16/aggregate/marshal.go.tmpl", importCurve}},
+				}
+				if err := bgen.Generate(d, "aggregate", "./template/zkpschemes/", entries...); err != nil {
+					panic(err)
+				}
+			}
+
 			// plonk
 			entries = []bavard.Entry{
 				{File: filepath.Join(plonkDir, "verify.go"), Templates: []string{"plonk/plonk.verify.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "prove.go"), Templates: []string{"plonk/plonk.prove.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "setup.go"), Templates: []string{"plonk/plonk.setup.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkDir, "prepare.go"), Templates: []string{"plonk/plonk.prepare.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "marshal.go"), Templates: []string{"plonk/plonk.marshal.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "marshal_test.go"), Templates: []string{"plonk/tests/marshal.go.tmpl", importCurve}},
 			}
@@ -201,6 +225,16 @@ func main() {
 				panic(err)
 			}
 
+			// plonkfri
+			entries = []bavard.Entry{
+				{File: filepath.Join(plonkFriDir, "verify.go"), Templates: []string{"plonkfri/plonk.verify.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkFriDir, "prove.go"), Templates: []string{"plonkfri/plonk.prove.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkFriDir, "setup.go"), Templates: []string{"plonkfri/plonk.setup.go.tmpl", importCurve}},
+			}
+			if err := bgen.Generate(d, "plonkfri", "./template/zkpschemes/", entries...); err != nil {
+				panic(err)
+			}
+
 		}(d)
 
 	}
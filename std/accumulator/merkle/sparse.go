@@ -0,0 +1,133 @@
+package merkle
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/math/bits"
+)
+
+// SparseProof is an authentication path in a sparse Merkle tree. The tree has
+// 2^depth leaves, where the leaf at position key stores the value for the key
+// and depth is the number of siblings in the path. Non-empty leaves are hashed
+// as H(key, value) and empty leaves and the subtrees containing only empty
+// leaves have the empty hashes E₀ = 0 and Eᵢ₊₁ = H(Eᵢ, Eᵢ).
+//
+// The proof is the same for the membership and non-membership of a key, and
+// for the trees before and after updating the leaf at the key. Use [SparseTree]
+// to build the proofs out-circuit and [NewSparseProof] to assign them.
+type SparseProof struct {
+	// Siblings are the sibling nodes along the path from the leaf (index 0) to
+	// the root.
+	Siblings []frontend.Variable
+}
+
+// NewSparseProof returns the assignment of the proof from the siblings
+// computed by [SparseTree.Prove].
+func NewSparseProof(siblings [][]byte) SparseProof {
+	res := SparseProof{Siblings: make([]frontend.Variable, len(siblings))}
+	for i := range siblings {
+		res.Siblings[i] = siblings[i]
+	}
+	return res
+}
+
+// sparseLeafSum returns the hash of the non-empty leaf storing value at key.
+func sparseLeafSum(h hash.FieldHasher, key, value frontend.Variable) frontend.Variable {
+
+	h.Reset()
+	h.Write(key, value)
+	res := h.Sum()
+
+	return res
+}
+
+// path returns the binary decomposition of key, which defines the position of
+// the leaf in the tree. The decomposition is unique also when the depth is
+// larger than the bitlength of the native field.
+func (p *SparseProof) path(api frontend.API, key frontend.Variable) []frontend.Variable {
+	return bits.ToBinary(api, key, bits.WithNbDigits(len(p.Siblings)))
+}
+
+// roots returns the roots of the trees containing the leaf hashes at the
+// position path. The roots share the siblings and the path, which allows to
+// compute the tree root before and after an update.
+func (p *SparseProof) roots(api frontend.API, h hash.FieldHasher, path []frontend.Variable, leaves ...frontend.Variable) []frontend.Variable {
+	sums := make([]frontend.Variable, len(leaves))
+	copy(sums, leaves)
+	for i := range p.Siblings { // the size of the loop is fixed -> one circuit per depth
+		for j := range sums {
+			d1 := api.Select(path[i], p.Siblings[i], sums[j])
+			d2 := api.Select(path[i], sums[j], p.Siblings[i])
+			sums[j] = nodeSum(api, h, d1, d2)
+		}
+	}
+	return sums
+}
+
+// VerifyMembership asserts that the tree with the given root stores value at
+// key.
+func (p *SparseProof) VerifyMembership(api frontend.API, h hash.FieldHasher, root, key, value frontend.Variable) {
+	path := p.path(api, key)
+	leaf := sparseLeafSum(h, key, value)
+	sums := p.roots(api, h, path, leaf)
+	api.AssertIsEqual(sums[0], root)
+}
+
+// VerifyNonMembership asserts that the leaf at key is empty in the tree with
+// the given root.
+func (p *SparseProof) VerifyNonMembership(api frontend.API, h hash.FieldHasher, root, key frontend.Variable) {
+	path := p.path(api, key)
+	sums := p.roots(api, h, path, 0)
+	api.AssertIsEqual(sums[0], root)
+}
+
+// Update asserts that the tree with the root oldRoot stores oldValue at key
+// and returns the root of the tree where the value at key is replaced by
+// newValue. Both roots are computed with the same path.
+func (p *SparseProof) Update(api frontend.API, h hash.FieldHasher, oldRoot, key, oldValue, newValue frontend.Variable) frontend.Variable {
+	path := p.path(api, key)
+	oldLeaf := sparseLeafSum(h, key, oldValue)
+	newLeaf := sparseLeafSum(h, key, newValue)
+	sums := p.roots(api, h, path, oldLeaf, newLeaf)
+	api.AssertIsEqual(sums[0], oldRoot)
+	return sums[1]
+}
+
+// Insert asserts that the leaf at key is empty in the tree with the root
+// oldRoot and returns the root of the tree where value is stored at key. Both
+// roots are computed with the same path.
+func (p *SparseProof) Insert(api frontend.API, h hash.FieldHasher, oldRoot, key, value frontend.Variable) frontend.Variable {
+	path := p.path(api, key)
+	newLeaf := sparseLeafSum(h, key, value)
+	sums := p.roots(api, h, path, 0, newLeaf)
+	api.AssertIsEqual(sums[0], oldRoot)
+	return sums[1]
+}
+
+// Delete asserts that the tree with the root oldRoot stores oldValue at key
+// and returns the root of the tree where the leaf at key is emptied. Both
+// roots are computed with the same path.
+func (p *SparseProof) Delete(api frontend.API, h hash.FieldHasher, oldRoot, key, oldValue frontend.Variable) frontend.Variable {
+	path := p.path(api, key)
+	oldLeaf := sparseLeafSum(h, key, oldValue)
+	sums := p.roots(api, h, path, oldLeaf, 0)
+	api.AssertIsEqual(sums[0], oldRoot)
+	return sums[1]
+}
+
+// BatchInsert inserts the values at the corresponding keys sequentially into
+// the tree with the given root and returns the root of the resulting tree. The
+// proof at index i is for the tree after the first i insertions, as built by
+// calling [SparseTree.Prove] and [SparseTree.Set] alternately. It asserts that
+// all keys are distinct and not stored in the initial tree.
+func BatchInsert(api frontend.API, h hash.FieldHasher, root frontend.Variable, keys, values []frontend.Variable, proofs []SparseProof) (frontend.Variable, error) {
+	if len(keys) != len(values) || len(keys) != len(proofs) {
+		return nil, fmt.Errorf("mismatching number of keys (%d), values (%d) and proofs (%d)", len(keys), len(values), len(proofs))
+	}
+	for i := range proofs {
+		root = proofs[i].Insert(api, h, root, keys[i], values[i])
+	}
+	return root, nil
+}
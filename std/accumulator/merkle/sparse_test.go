@@ -0,0 +1,224 @@
+package merkle
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+)
+
+const sparseDepth = 256
+
+type sparseMembershipCircuit struct {
+	Root, Key, Value frontend.Variable
+	Proof            SparseProof
+	nonMembership    bool
+}
+
+func (c *sparseMembershipCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	if c.nonMembership {
+		c.Proof.VerifyNonMembership(api, &h, c.Root, c.Key)
+	} else {
+		c.Proof.VerifyMembership(api, &h, c.Root, c.Key, c.Value)
+	}
+	return nil
+}
+
+type sparseUpdateCircuit struct {
+	OldRoot, NewRoot        frontend.Variable
+	Key, OldValue, NewValue frontend.Variable
+	Proof                   SparseProof
+}
+
+func (c *sparseUpdateCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	newRoot := c.Proof.Update(api, &h, c.OldRoot, c.Key, c.OldValue, c.NewValue)
+	api.AssertIsEqual(newRoot, c.NewRoot)
+	return nil
+}
+
+type sparseBatchInsertCircuit struct {
+	OldRoot, NewRoot frontend.Variable
+	Keys, Values     []frontend.Variable
+	Proofs           []SparseProof
+}
+
+func (c *sparseBatchInsertCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	newRoot, err := BatchInsert(api, &h, c.OldRoot, c.Keys, c.Values, c.Proofs)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(newRoot, c.NewRoot)
+	return nil
+}
+
+func sparseCircuitProof() SparseProof {
+	return SparseProof{Siblings: make([]frontend.Variable, sparseDepth)}
+}
+
+func randomSparseTree(assert *test.Assert, nbLeaves int) (*SparseTree, []*big.Int, []*big.Int) {
+	tree, err := NewSparseTree(hash.MIMC_BN254.New(), sparseDepth)
+	assert.NoError(err)
+	keys := make([]*big.Int, nbLeaves)
+	values := make([]*big.Int, nbLeaves)
+	for i := range keys {
+		keys[i], err = rand.Int(rand.Reader, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		values[i], err = rand.Int(rand.Reader, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		assert.NoError(tree.Set(keys[i], values[i]))
+	}
+	return tree, keys, values
+}
+
+func TestSparseTreeNative(t *testing.T) {
+	assert := test.NewAssert(t)
+	tree, keys, values := randomSparseTree(assert, 8)
+	for i := range keys {
+		siblings, err := tree.Prove(keys[i])
+		assert.NoError(err)
+		assert.NoError(VerifySparseProof(hash.MIMC_BN254.New(), tree.Root(), keys[i], values[i], siblings))
+		assert.Error(VerifySparseProof(hash.MIMC_BN254.New(), tree.Root(), keys[i], nil, siblings))
+	}
+	// deleting all leaves returns to the empty tree
+	empty, err := NewSparseTree(hash.MIMC_BN254.New(), sparseDepth)
+	assert.NoError(err)
+	for i := range keys {
+		assert.NoError(tree.Delete(keys[i]))
+	}
+	assert.Equal(empty.Root(), tree.Root())
+	for i := range tree.nodes {
+		assert.Empty(tree.nodes[i])
+	}
+	_, err = tree.Prove(new(big.Int).Lsh(big.NewInt(1), sparseDepth))
+	assert.Error(err)
+}
+
+func TestSparseMembership(t *testing.T) {
+	assert := test.NewAssert(t)
+	tree, keys, values := randomSparseTree(assert, 4)
+	siblings, err := tree.Prove(keys[1])
+	assert.NoError(err)
+
+	circuit := sparseMembershipCircuit{Proof: sparseCircuitProof()}
+	witness := sparseMembershipCircuit{
+		Root:  tree.Root(),
+		Key:   keys[1],
+		Value: values[1],
+		Proof: NewSparseProof(siblings),
+	}
+	assert.CheckCircuit(&circuit, test.WithValidAssignment(&witness), test.WithCurves(ecc.BN254), test.NoProverChecks())
+
+	wrong := witness
+	wrong.Value = new(big.Int).Add(values[1], big.NewInt(1))
+	assert.Error(test.IsSolved(&circuit, &wrong, ecc.BN254.ScalarField()))
+}
+
+func TestSparseNonMembership(t *testing.T) {
+	assert := test.NewAssert(t)
+	tree, keys, _ := randomSparseTree(assert, 4)
+	key, err := rand.Int(rand.Reader, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	siblings, err := tree.Prove(key)
+	assert.NoError(err)
+
+	circuit := sparseMembershipCircuit{Proof: sparseCircuitProof(), nonMembership: true}
+	witness := sparseMembershipCircuit{
+		Root:  tree.Root(),
+		Key:   key,
+		Value: 0,
+		Proof: NewSparseProof(siblings),
+	}
+	assert.CheckCircuit(&circuit, test.WithValidAssignment(&witness), test.WithCurves(ecc.BN254), test.NoProverChecks())
+
+	// existing key is not empty
+	siblings, err = tree.Prove(keys[2])
+	assert.NoError(err)
+	wrong := sparseMembershipCircuit{
+		Root:  tree.Root(),
+		Key:   keys[2],
+		Value: 0,
+		Proof: NewSparseProof(siblings),
+	}
+	assert.Error(test.IsSolved(&circuit, &wrong, ecc.BN254.ScalarField()))
+}
+
+func TestSparseUpdate(t *testing.T) {
+	assert := test.NewAssert(t)
+	tree, keys, values := randomSparseTree(assert, 4)
+	newValue, err := rand.Int(rand.Reader, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	oldRoot := tree.Root()
+	siblings, err := tree.Prove(keys[3])
+	assert.NoError(err)
+	assert.NoError(tree.Set(keys[3], newValue))
+
+	circuit := sparseUpdateCircuit{Proof: sparseCircuitProof()}
+	witness := sparseUpdateCircuit{
+		OldRoot:  oldRoot,
+		NewRoot:  tree.Root(),
+		Key:      keys[3],
+		OldValue: values[3],
+		NewValue: newValue,
+		Proof:    NewSparseProof(siblings),
+	}
+	assert.CheckCircuit(&circuit, test.WithValidAssignment(&witness), test.WithCurves(ecc.BN254), test.NoProverChecks())
+
+	wrong := witness
+	wrong.OldValue = newValue
+	assert.Error(test.IsSolved(&circuit, &wrong, ecc.BN254.ScalarField()))
+}
+
+func TestSparseBatchInsert(t *testing.T) {
+	assert := test.NewAssert(t)
+	const nbInserts = 3
+	tree, _, _ := randomSparseTree(assert, 4)
+
+	circuit := sparseBatchInsertCircuit{
+		Keys:   make([]frontend.Variable, nbInserts),
+		Values: make([]frontend.Variable, nbInserts),
+		Proofs: make([]SparseProof, nbInserts),
+	}
+	witness := sparseBatchInsertCircuit{
+		OldRoot: tree.Root(),
+		Keys:    make([]frontend.Variable, nbInserts),
+		Values:  make([]frontend.Variable, nbInserts),
+		Proofs:  make([]SparseProof, nbInserts),
+	}
+	for i := 0; i < nbInserts; i++ {
+		circuit.Proofs[i] = sparseCircuitProof()
+		key, err := rand.Int(rand.Reader, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		value, err := rand.Int(rand.Reader, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		siblings, err := tree.Prove(key)
+		assert.NoError(err)
+		assert.NoError(tree.Set(key, value))
+		witness.Keys[i] = key
+		witness.Values[i] = value
+		witness.Proofs[i] = NewSparseProof(siblings)
+	}
+	witness.NewRoot = tree.Root()
+	assert.CheckCircuit(&circuit, test.WithValidAssignment(&witness), test.WithCurves(ecc.BN254), test.NoProverChecks())
+
+	// inserting the same key twice must fail
+	wrong := witness
+	wrong.Keys = []frontend.Variable{witness.Keys[0], witness.Keys[0], witness.Keys[2]}
+	assert.Error(test.IsSolved(&circuit, &wrong, ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,212 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+)
+
+// SparseTree is the out-circuit sparse Merkle tree corresponding to
+// [SparseProof]. It stores only the non-empty nodes and is used to build the
+// witnesses for the in-circuit proofs.
+//
+// The hash function must correspond to the in-circuit field hasher, for
+// example the MiMC hash of gnark-crypto over the native field for
+// [github.com/consensys/gnark/std/hash/mimc]. The nodes are encoded as
+// big-endian byte slices of the block size of the hash function. The tree is
+// not safe for concurrent use.
+type SparseTree struct {
+	h     hash.Hash
+	depth int
+	// empty are the hashes of the subtrees with only empty leaves per level.
+	empty [][]byte
+	// nodes are the non-empty nodes per level, indexed by the position of the
+	// node in the level.
+	nodes []map[string][]byte
+}
+
+// NewSparseTree returns an empty sparse Merkle tree with 2^depth leaves using
+// the hash function h.
+func NewSparseTree(h hash.Hash, depth int) (*SparseTree, error) {
+	if depth <= 0 {
+		return nil, errors.New("depth must be positive")
+	}
+	t := &SparseTree{
+		h:     h,
+		depth: depth,
+		empty: make([][]byte, depth+1),
+		nodes: make([]map[string][]byte, depth+1),
+	}
+	t.empty[0] = make([]byte, h.BlockSize())
+	for i := 0; i < depth; i++ {
+		e, err := t.sum(t.empty[i], t.empty[i])
+		if err != nil {
+			return nil, fmt.Errorf("empty subtree at level %d: %w", i+1, err)
+		}
+		t.empty[i+1] = e
+		t.nodes[i] = make(map[string][]byte)
+	}
+	t.nodes[depth] = make(map[string][]byte)
+	return t, nil
+}
+
+// Depth returns the depth of the tree.
+func (t *SparseTree) Depth() int {
+	return t.depth
+}
+
+// Root returns the root of the tree.
+func (t *SparseTree) Root() []byte {
+	return t.node(t.depth, new(big.Int))
+}
+
+// Prove returns the siblings of the path to the leaf at key, from the leaf to
+// the root. The siblings are the same regardless whether the leaf is empty.
+func (t *SparseTree) Prove(key *big.Int) ([][]byte, error) {
+	if err := t.checkKey(key); err != nil {
+		return nil, err
+	}
+	siblings := make([][]byte, t.depth)
+	idx := new(big.Int).Set(key)
+	sib := new(big.Int)
+	for i := 0; i < t.depth; i++ {
+		sib.SetBit(idx, 0, 1-idx.Bit(0))
+		siblings[i] = t.node(i, sib)
+		idx.Rsh(idx, 1)
+	}
+	return siblings, nil
+}
+
+// Set stores value at key. The value has to be reduced modulo the field of the
+// hash function.
+func (t *SparseTree) Set(key, value *big.Int) error {
+	if err := t.checkKey(key); err != nil {
+		return err
+	}
+	if err := t.checkValue(value); err != nil {
+		return err
+	}
+	leaf, err := t.sum(t.element(key), t.element(value))
+	if err != nil {
+		return fmt.Errorf("leaf: %w", err)
+	}
+	return t.setLeaf(key, leaf)
+}
+
+// Delete empties the leaf at key.
+func (t *SparseTree) Delete(key *big.Int) error {
+	if err := t.checkKey(key); err != nil {
+		return err
+	}
+	return t.setLeaf(key, t.empty[0])
+}
+
+// VerifySparseProof returns nil if the tree with the given root stores value
+// at key, or if value is nil and the leaf at key is empty. The siblings are as
+// returned by [SparseTree.Prove].
+func VerifySparseProof(h hash.Hash, root []byte, key, value *big.Int, siblings [][]byte) error {
+	t := &SparseTree{h: h, depth: len(siblings)}
+	if err := t.checkKey(key); err != nil {
+		return err
+	}
+	sum := make([]byte, h.BlockSize())
+	if value != nil {
+		if err := t.checkValue(value); err != nil {
+			return err
+		}
+		var err error
+		if sum, err = t.sum(t.element(key), t.element(value)); err != nil {
+			return fmt.Errorf("leaf: %w", err)
+		}
+	}
+	for i := range siblings {
+		var err error
+		if key.Bit(i) == 0 {
+			sum, err = t.sum(sum, siblings[i])
+		} else {
+			sum, err = t.sum(siblings[i], sum)
+		}
+		if err != nil {
+			return fmt.Errorf("node at level %d: %w", i+1, err)
+		}
+	}
+	if !bytes.Equal(sum, root) {
+		return errors.New("root mismatch")
+	}
+	return nil
+}
+
+// setLeaf sets the leaf hash at key and recomputes the nodes on the path to
+// the root. Nodes equal to the empty subtree hash are not stored.
+func (t *SparseTree) setLeaf(key *big.Int, leaf []byte) error {
+	idx := new(big.Int).Set(key)
+	sib := new(big.Int)
+	sum := leaf
+	for i := 0; ; i++ {
+		t.setNode(i, idx, sum)
+		if i == t.depth {
+			return nil
+		}
+		sib.SetBit(idx, 0, 1-idx.Bit(0))
+		var err error
+		if idx.Bit(0) == 0 {
+			sum, err = t.sum(sum, t.node(i, sib))
+		} else {
+			sum, err = t.sum(t.node(i, sib), sum)
+		}
+		if err != nil {
+			return fmt.Errorf("node at level %d: %w", i+1, err)
+		}
+		idx.Rsh(idx, 1)
+	}
+}
+
+func (t *SparseTree) node(level int, idx *big.Int) []byte {
+	if n, ok := t.nodes[level][string(idx.Bytes())]; ok {
+		return n
+	}
+	return t.empty[level]
+}
+
+func (t *SparseTree) setNode(level int, idx *big.Int, n []byte) {
+	if bytes.Equal(n, t.empty[level]) {
+		delete(t.nodes[level], string(idx.Bytes()))
+		return
+	}
+	t.nodes[level][string(idx.Bytes())] = n
+}
+
+func (t *SparseTree) checkKey(key *big.Int) error {
+	if key.Sign() < 0 || key.BitLen() > t.depth || key.BitLen() > 8*t.h.BlockSize() {
+		return fmt.Errorf("key out of range for depth %d", t.depth)
+	}
+	return nil
+}
+
+func (t *SparseTree) checkValue(value *big.Int) error {
+	if value.Sign() < 0 || value.BitLen() > 8*t.h.BlockSize() {
+		return errors.New("value out of range")
+	}
+	return nil
+}
+
+// element returns the big-endian encoding of v on the block size of the hash
+// function.
+func (t *SparseTree) element(v *big.Int) []byte {
+	return v.FillBytes(make([]byte, t.h.BlockSize()))
+}
+
+// sum returns the hash of the concatenation of a and b. The hash function
+// returns an error when a or b are not reduced.
+func (t *SparseTree) sum(a, b []byte) ([]byte, error) {
+	t.h.Reset()
+	if _, err := t.h.Write(a); err != nil {
+		return nil, err
+	}
+	if _, err := t.h.Write(b); err != nil {
+		return nil, err
+	}
+	return t.h.Sum(nil), nil
+}
@@ -42,7 +42,8 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package merkle provides a ZKP-circuit function to verify merkle proofs.
+// Package merkle provides ZKP-circuit functions to verify merkle proofs in dense
+// and sparse Merkle trees.
 package merkle
 
 import (
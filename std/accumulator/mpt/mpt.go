@@ -0,0 +1,432 @@
+// Package mpt implements the verification of Merkle Patricia Trie proofs.
+//
+// Merkle Patricia Tries are used in Ethereum to commit to the accounts in the
+// state root and to the storage slots in the storage root of an account. A
+// proof is the list of RLP-encoded trie nodes on the path from the root to the
+// leaf storing the value, where every node is referenced in its parent by its
+// Keccak-256 hash or is embedded in its parent when its encoding is shorter
+// than 32 bytes.
+//
+// The verifier supports proofs of variable length up to the maximum depth of
+// the trie and nodes of variable length up to the maximum node size, both
+// fixed at circuit compile time. The maximum size of a branch node is
+// [MaxBranchNodeSize] bytes.
+//
+// Only inclusion proofs for keys of fixed length (as in the secure tries of
+// Ethereum, where the keys are Keccak-256 hashes) are supported.
+package mpt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/hash/sha3"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/selector"
+)
+
+const (
+	// HashLen is the length of the Keccak-256 node references.
+	HashLen = 32
+	// MaxBranchNodeSize is the maximum length of an RLP-encoded branch node
+	// with 16 hash references and an empty value.
+	MaxBranchNodeSize = 3 + 16*(HashLen+1) + 1
+	// nbBranchItems is the number of items in a branch node.
+	nbBranchItems = 17
+)
+
+// Proof is a Merkle Patricia Trie inclusion proof. Use [PlaceholderProof] to
+// define the proof in the circuit and [ValueOfProof] to assign it.
+type Proof struct {
+	// Nodes are the RLP-encoded nodes on the path from the root to the leaf,
+	// including the embedded nodes. Every node is padded to the maximum node
+	// size.
+	Nodes [][]uints.U8
+	// NodeLengths are the lengths of the encoded nodes.
+	NodeLengths []frontend.Variable
+	// Depth is the number of nodes in the proof. The nodes after Depth are
+	// ignored.
+	Depth frontend.Variable
+}
+
+// PlaceholderProof returns a placeholder proof for the circuit definition for
+// proofs of at most maxDepth nodes of at most maxNodeSize bytes.
+func PlaceholderProof(maxDepth, maxNodeSize int) Proof {
+	p := Proof{
+		Nodes:       make([][]uints.U8, maxDepth),
+		NodeLengths: make([]frontend.Variable, maxDepth),
+	}
+	for i := range p.Nodes {
+		p.Nodes[i] = make([]uints.U8, maxNodeSize)
+	}
+	return p
+}
+
+// ValueOfProof returns the assignment of the proof for key with the given
+// nodes, as returned by eth_getProof. The nodes embedded in their parents are
+// added to the proof as separate nodes.
+func ValueOfProof(key []byte, nodes [][]byte, maxDepth, maxNodeSize int) (Proof, error) {
+	expanded, err := expandProof(key, nodes)
+	if err != nil {
+		return Proof{}, fmt.Errorf("expand proof: %w", err)
+	}
+	if len(expanded) > maxDepth {
+		return Proof{}, fmt.Errorf("proof depth %d exceeds maximum %d", len(expanded), maxDepth)
+	}
+	p := PlaceholderProof(maxDepth, maxNodeSize)
+	p.Depth = len(expanded)
+	for i := range p.Nodes {
+		var node []byte
+		if i < len(expanded) {
+			node = expanded[i]
+		}
+		if len(node) > maxNodeSize {
+			return Proof{}, fmt.Errorf("node %d size %d exceeds maximum %d", i, len(node), maxNodeSize)
+		}
+		padded := make([]byte, maxNodeSize)
+		copy(padded, node)
+		p.Nodes[i] = uints.NewU8Array(padded)
+		p.NodeLengths[i] = len(node)
+	}
+	return p, nil
+}
+
+// Verifier verifies Merkle Patricia Trie proofs.
+type Verifier struct {
+	api      frontend.API
+	rchecker frontend.Rangechecker
+
+	// tables indexed by the first byte of an RLP item.
+	headerLen   *logderivlookup.Table // length of the header
+	shortLen    *logderivlookup.Table // payload length for the short forms
+	long1       *logderivlookup.Table // 1 when the payload length is encoded on 1 byte
+	long2       *logderivlookup.Table // 1 when the payload length is encoded on 2 bytes
+	isList      *logderivlookup.Table // 1 for lists
+	validHeader *logderivlookup.Table // 0 when the payload length is encoded on more than 2 bytes
+
+	// tables indexed by a byte.
+	hiNibble *logderivlookup.Table
+	loNibble *logderivlookup.Table
+	// tables indexed by the first byte of a hex-prefix encoded path.
+	isLeafPath *logderivlookup.Table
+	isOddPath  *logderivlookup.Table
+}
+
+// New returns a new [Verifier].
+func New(api frontend.API) (*Verifier, error) {
+	return &Verifier{
+		api:      api,
+		rchecker: rangecheck.New(api),
+		headerLen: newByteTable(api, func(b int) int {
+			switch {
+			case b < 0x80:
+				return 0
+			case b <= 0xb7:
+				return 1
+			case b < 0xc0:
+				return 1 + b - 0xb7
+			case b <= 0xf7:
+				return 1
+			default:
+				return 1 + b - 0xf7
+			}
+		}),
+		shortLen: newByteTable(api, func(b int) int {
+			switch {
+			case b < 0x80:
+				return 1
+			case b <= 0xb7:
+				return b - 0x80
+			case b < 0xc0:
+				return 0
+			case b <= 0xf7:
+				return b - 0xc0
+			default:
+				return 0
+			}
+		}),
+		long1:       newByteTable(api, func(b int) int { return boolToInt(b == 0xb8 || b == 0xf8) }),
+		long2:       newByteTable(api, func(b int) int { return boolToInt(b == 0xb9 || b == 0xf9) }),
+		isList:      newByteTable(api, func(b int) int { return boolToInt(b >= 0xc0) }),
+		validHeader: newByteTable(api, func(b int) int { return boolToInt(!(b > 0xb9 && b < 0xc0) && b <= 0xf9) }),
+		hiNibble:    newByteTable(api, func(b int) int { return b >> 4 }),
+		loNibble:    newByteTable(api, func(b int) int { return b & 0xf }),
+		isLeafPath:  newByteTable(api, func(b int) int { return (b >> 5) & 1 }),
+		isOddPath:   newByteTable(api, func(b int) int { return (b >> 4) & 1 }),
+	}, nil
+}
+
+// VerifyProof asserts that proof is a valid inclusion proof for key in the
+// trie with the given root, and returns the value stored at key. The value is
+// returned on maxValueLen bytes, where the bytes after valueLen are zero. It
+// asserts that the value is at most maxValueLen bytes.
+//
+// The value is the payload of the RLP string in the leaf. For the accounts
+// and the storage slots of Ethereum, it is itself RLP-encoded.
+func (v *Verifier) VerifyProof(root, key []uints.U8, proof Proof, maxValueLen int) (value []uints.U8, valueLen frontend.Variable, err error) {
+	api := v.api
+	maxDepth := len(proof.Nodes)
+	if len(root) != HashLen {
+		return nil, nil, fmt.Errorf("root must be %d bytes", HashLen)
+	}
+	if len(key) == 0 {
+		return nil, nil, errors.New("empty key")
+	}
+	if maxDepth == 0 || len(proof.NodeLengths) != maxDepth {
+		return nil, nil, errors.New("mismatching number of nodes and node lengths")
+	}
+	maxNodeSize := len(proof.Nodes[0])
+	if maxNodeSize < HashLen {
+		return nil, nil, fmt.Errorf("maximum node size must be at least %d", HashLen)
+	}
+	for i := range proof.Nodes {
+		if len(proof.Nodes[i]) != maxNodeSize {
+			return nil, nil, errors.New("nodes must be padded to the same size")
+		}
+	}
+	if maxValueLen <= 0 {
+		return nil, nil, errors.New("maximum value length must be positive")
+	}
+	nbNibbles := 2 * len(key)
+
+	// we store all nodes in a single table, where every node is followed by
+	// zero padding. The padding allows to read the references and paths at
+	// any offset in the node without going out of the table bounds for
+	// nodes which are not of the corresponding kind.
+	stride := maxNodeSize + HashLen + 1 + len(key) + 1
+	nodes := logderivlookup.New(api)
+	for i := range proof.Nodes {
+		for j := range proof.Nodes[i] {
+			v.rchecker.Check(proof.Nodes[i][j].Val, 8)
+			nodes.Insert(proof.Nodes[i][j].Val)
+		}
+		for j := maxNodeSize; j < stride; j++ {
+			nodes.Insert(0)
+		}
+	}
+	for j := 0; j < maxValueLen; j++ {
+		nodes.Insert(0)
+	}
+
+	// the nibbles of the key followed by zero padding, as the nibbles are
+	// read at any offset consumed+j for j < nbNibbles.
+	keyNibbles := logderivlookup.New(api)
+	for i := range key {
+		keyNibbles.Insert(v.hiNibble.Lookup(key[i].Val)[0])
+		keyNibbles.Insert(v.loNibble.Lookup(key[i].Val)[0])
+	}
+	for j := 0; j <= nbNibbles; j++ {
+		keyNibbles.Insert(0)
+	}
+
+	active := lessMask(api, maxDepth, proof.Depth)
+	api.AssertIsEqual(active[0], 1)
+
+	hashes := make([][]uints.U8, maxDepth)
+	for i := range proof.Nodes {
+		h, err := sha3.NewLegacyKeccak256(api)
+		if err != nil {
+			return nil, nil, fmt.Errorf("new keccak: %w", err)
+		}
+		fh, ok := h.(hash.BinaryFixedLengthHasher)
+		if !ok {
+			return nil, nil, fmt.Errorf("keccak doesn't support fixed length sum")
+		}
+		fh.Write(proof.Nodes[i])
+		hashes[i] = fh.FixedLengthSum(proof.NodeLengths[i])
+	}
+	for j := range root {
+		api.AssertIsEqual(hashes[0][j].Val, root[j].Val)
+	}
+
+	var consumed, valueOffset frontend.Variable = 0, 0
+	valueLen = 0
+	for i := 0; i < maxDepth; i++ {
+		base := i * stride
+		isLast := active[i]
+		if i+1 < maxDepth {
+			isLast = api.Sub(active[i], active[i+1])
+		}
+
+		// the node is a list of either 17 items (branch) or 2 items (leaf or
+		// extension).
+		list := v.decodeItem(nodes, base)
+		v.assertIf(active[i], list.valid, 1)
+		v.assertIf(active[i], list.isList, 1)
+		v.assertIf(active[i], list.end, api.Add(base, proof.NodeLengths[i]))
+		var items [nbBranchItems]rlpItem
+		items[0] = v.decodeItem(nodes, list.payloadOffset)
+		items[1] = v.decodeItem(nodes, items[0].end)
+		isShort := api.IsZero(api.Sub(items[1].end, list.end))
+		for k := 2; k < nbBranchItems; k++ {
+			// for leaves and extensions, we decode the node from the start
+			// not to read outside of the node.
+			offset := api.Select(isShort, base, items[k-1].end)
+			items[k] = v.decodeItem(nodes, offset)
+		}
+		isBranch := api.Sub(1, isShort)
+		for k := range items {
+			v.assertIf(active[i], items[k].valid, 1)
+		}
+		v.assertIf(api.Mul(active[i], isBranch), items[nbBranchItems-1].end, list.end)
+
+		// hex-prefix encoded path of the leaves and extensions
+		pathNibbles := make([]frontend.Variable, 2*(len(key)+1))
+		for k := 0; k <= len(key); k++ {
+			b := nodes.Lookup(api.Add(items[0].payloadOffset, k))[0]
+			pathNibbles[2*k] = v.hiNibble.Lookup(b)[0]
+			pathNibbles[2*k+1] = v.loNibble.Lookup(b)[0]
+		}
+		firstPathByte := nodes.Lookup(items[0].payloadOffset)[0]
+		isLeafPath := v.isLeafPath.Lookup(firstPathByte)[0]
+		isOdd := v.isOddPath.Lookup(firstPathByte)[0]
+		isShortActive := api.Mul(active[i], isShort)
+		v.assertIf(isShortActive, pathNibbles[0], api.Add(api.Mul(2, isLeafPath), isOdd))
+		isLeaf := api.Mul(isShort, isLeafPath)
+		pathLen := api.Add(api.Mul(2, api.Sub(items[0].payloadLen, 1)), isOdd)
+		pathMask := lessMask(api, nbNibbles, api.Mul(isShortActive, pathLen))
+		for j := 0; j < nbNibbles; j++ {
+			// the path starts at the second nibble for odd lengths and at the
+			// third nibble for even lengths.
+			nibble := api.Select(isOdd, pathNibbles[j+1], pathNibbles[j+2])
+			keyNibble := keyNibbles.Lookup(api.Add(consumed, j))[0]
+			api.AssertIsEqual(api.Mul(pathMask[j], api.Sub(nibble, keyNibble)), 0)
+		}
+
+		// only the last node is a leaf, which consumes the rest of the key.
+		v.assertIf(isLast, isLeaf, 1)
+		v.assertIf(api.Sub(active[i], isLast), isLeaf, 0)
+		v.assertIf(isLast, api.Add(consumed, pathLen), nbNibbles)
+		v.assertIf(isLast, items[1].isList, 0)
+		valueOffset = api.Add(valueOffset, api.Mul(isLast, items[1].payloadOffset))
+		valueLen = api.Add(valueLen, api.Mul(isLast, items[1].payloadLen))
+
+		// the reference to the next node is the item at the next nibble of the
+		// key for branches and the second item for extensions.
+		branchNibble := keyNibbles.Lookup(consumed)[0]
+		consumed = api.Add(consumed, api.Mul(active[i], api.Select(isShort, pathLen, 1)))
+		if i+1 == maxDepth {
+			break
+		}
+		var offsets, prefixes, ends, isLists [16]frontend.Variable
+		for k := range offsets {
+			offsets[k] = items[k].offset
+			prefixes[k] = items[k].prefix
+			ends[k] = items[k].end
+			isLists[k] = items[k].isList
+		}
+		ref := rlpItem{
+			offset: api.Select(isShort, items[1].offset, selector.Mux(api, branchNibble, offsets[:]...)),
+			prefix: api.Select(isShort, items[1].prefix, selector.Mux(api, branchNibble, prefixes[:]...)),
+			end:    api.Select(isShort, items[1].end, selector.Mux(api, branchNibble, ends[:]...)),
+			isList: api.Select(isShort, items[1].isList, selector.Mux(api, branchNibble, isLists[:]...)),
+		}
+		v.assertChildRef(nodes, ref, proof.Nodes[i+1], proof.NodeLengths[i+1], hashes[i+1], active[i+1])
+	}
+
+	valueMask := lessMask(api, maxValueLen, valueLen)
+	value = make([]uints.U8, maxValueLen)
+	for k := range value {
+		b := nodes.Lookup(api.Add(valueOffset, k))[0]
+		value[k] = uints.U8{Val: api.Mul(valueMask[k], b)}
+	}
+	return value, valueLen, nil
+}
+
+// assertChildRef asserts that when isActive is 1, the reference ref in the
+// parent node is either the hash of the child node or the child node itself
+// when it is embedded in the parent.
+func (v *Verifier) assertChildRef(nodes *logderivlookup.Table, ref rlpItem, child []uints.U8, childLen frontend.Variable, childHash []uints.U8, isActive frontend.Variable) {
+	api := v.api
+	isHash := api.IsZero(api.Sub(ref.prefix, 0x80+HashLen))
+	v.assertIf(isActive, api.Add(isHash, ref.isList), 1)
+
+	refBytes := make([]frontend.Variable, HashLen+1)
+	for k := range refBytes {
+		refBytes[k] = nodes.Lookup(api.Add(ref.offset, k))[0]
+	}
+	isActiveHash := api.Mul(isActive, isHash)
+	for k := 0; k < HashLen; k++ {
+		v.assertIf(isActiveHash, refBytes[k+1], childHash[k].Val)
+	}
+	isActiveEmbedded := api.Mul(isActive, ref.isList)
+	refLen := api.Sub(ref.end, ref.offset)
+	v.assertIf(isActiveEmbedded, refLen, childLen)
+	embeddedMask := lessMask(api, HashLen, api.Mul(isActiveEmbedded, refLen))
+	for k := 0; k < HashLen; k++ {
+		api.AssertIsEqual(api.Mul(embeddedMask[k], api.Sub(refBytes[k], child[k].Val)), 0)
+	}
+}
+
+// assertIf asserts that a == b when cond is 1.
+func (v *Verifier) assertIf(cond, a, b frontend.Variable) {
+	v.api.AssertIsEqual(v.api.Mul(cond, v.api.Sub(a, b)), 0)
+}
+
+// rlpItem is the decoded header of an RLP item.
+type rlpItem struct {
+	offset        frontend.Variable // offset of the first byte of the item
+	prefix        frontend.Variable // first byte of the item
+	payloadOffset frontend.Variable // offset of the first byte of the payload
+	payloadLen    frontend.Variable // length of the payload
+	end           frontend.Variable // offset of the first byte after the item
+	isList        frontend.Variable // 1 for lists, 0 for strings
+	valid         frontend.Variable // 0 when the payload length is encoded on more than 2 bytes
+}
+
+// decodeItem decodes the header of the RLP item at the given offset in tbl.
+func (v *Verifier) decodeItem(tbl *logderivlookup.Table, offset frontend.Variable) rlpItem {
+	api := v.api
+	bs := tbl.Lookup(offset, api.Add(offset, 1), api.Add(offset, 2))
+	prefix := bs[0]
+	long1 := v.long1.Lookup(prefix)[0]
+	long2 := v.long2.Lookup(prefix)[0]
+	payloadLen := api.Add(
+		v.shortLen.Lookup(prefix)[0],
+		api.Mul(long1, bs[1]),
+		api.Mul(long2, api.Add(api.Mul(bs[1], 256), bs[2])),
+	)
+	payloadOffset := api.Add(offset, v.headerLen.Lookup(prefix)[0])
+	return rlpItem{
+		offset:        offset,
+		prefix:        prefix,
+		payloadOffset: payloadOffset,
+		payloadLen:    payloadLen,
+		end:           api.Add(payloadOffset, payloadLen),
+		isList:        v.isList.Lookup(prefix)[0],
+		valid:         v.validHeader.Lookup(prefix)[0],
+	}
+}
+
+// lessMask returns a slice of n elements, where the element at i is 1 when
+// i < length and 0 otherwise. It asserts that 0 <= length <= n.
+func lessMask(api frontend.API, n int, length frontend.Variable) []frontend.Variable {
+	if n == 1 {
+		api.AssertIsBoolean(length)
+		return []frontend.Variable{length}
+	}
+	ones := make([]frontend.Variable, n)
+	for i := range ones {
+		ones[i] = 1
+	}
+	return selector.Partition(api, length, false, ones)
+}
+
+func newByteTable(api frontend.API, f func(b int) int) *logderivlookup.Table {
+	t := logderivlookup.New(api)
+	for b := 0; b < 256; b++ {
+		t.Insert(f(b))
+	}
+	return t
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,271 @@
+package mpt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"sort"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/sha3"
+)
+
+// the following is a minimal implementation of the Merkle Patricia Trie for
+// building the test proofs.
+
+func keccak(b []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+func rlpHeader(base byte, l int) []byte {
+	if l <= 55 {
+		return []byte{base + byte(l)}
+	}
+	var lb []byte
+	for ; l > 0; l >>= 8 {
+		lb = append([]byte{byte(l)}, lb...)
+	}
+	return append([]byte{base + 55 + byte(len(lb))}, lb...)
+}
+
+func rlpString(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+func rlpList(items ...[]byte) []byte {
+	payload := bytes.Join(items, nil)
+	return append(rlpHeader(0xc0, len(payload)), payload...)
+}
+
+func hexPrefix(nibbles []byte, isLeaf bool) []byte {
+	var flag byte
+	if isLeaf {
+		flag = 2
+	}
+	if len(nibbles)%2 == 1 {
+		res := []byte{(flag+1)<<4 | nibbles[0]}
+		for i := 1; i < len(nibbles); i += 2 {
+			res = append(res, nibbles[i]<<4|nibbles[i+1])
+		}
+		return res
+	}
+	res := []byte{flag << 4}
+	for i := 0; i < len(nibbles); i += 2 {
+		res = append(res, nibbles[i]<<4|nibbles[i+1])
+	}
+	return res
+}
+
+func nodeRef(node []byte) []byte {
+	if len(node) < HashLen {
+		return node
+	}
+	return rlpString(keccak(node))
+}
+
+type entry struct {
+	nibbles []byte
+	value   []byte
+}
+
+// buildNode returns the encoded node for the entries sharing the first depth
+// nibbles, and the proof for the key from this node as returned by
+// eth_getProof.
+func buildNode(entries []entry, depth int, key []byte) (node []byte, proof [][]byte) {
+	if len(entries) == 1 {
+		node = rlpList(rlpString(hexPrefix(entries[0].nibbles[depth:], true)), rlpString(entries[0].value))
+		return node, [][]byte{node}
+	}
+	common := 0
+	for ; depth+common < len(entries[0].nibbles); common++ {
+		n := entries[0].nibbles[depth+common]
+		same := true
+		for _, e := range entries {
+			same = same && e.nibbles[depth+common] == n
+		}
+		if !same {
+			break
+		}
+	}
+	if common > 0 {
+		child, childProof := buildNode(entries, depth+common, key)
+		node = rlpList(rlpString(hexPrefix(entries[0].nibbles[depth:depth+common], false)), nodeRef(child))
+		return node, withNode(node, child, childProof)
+	}
+	items := make([][]byte, 17)
+	for n := byte(0); n < 16; n++ {
+		var group []entry
+		for _, e := range entries {
+			if e.nibbles[depth] == n {
+				group = append(group, e)
+			}
+		}
+		items[n] = rlpString(nil)
+		if len(group) == 0 {
+			continue
+		}
+		child, childProof := buildNode(group, depth+1, key)
+		items[n] = nodeRef(child)
+		if key != nil && key[depth] == n {
+			proof = withNode(nil, child, childProof)
+		}
+	}
+	items[16] = rlpString(nil)
+	node = rlpList(items...)
+	return node, append([][]byte{node}, proof...)
+}
+
+// withNode prepends node to the proof of its child and omits the embedded
+// child as eth_getProof does.
+func withNode(node, child []byte, childProof [][]byte) [][]byte {
+	if len(child) < HashLen {
+		childProof = childProof[1:]
+	}
+	if node == nil {
+		return childProof
+	}
+	return append([][]byte{node}, childProof...)
+}
+
+// trieProof returns the root of the trie with the given entries and the proof
+// for key.
+func trieProof(entries []entry, key []byte) (root []byte, proof [][]byte) {
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].nibbles, entries[j].nibbles) < 0 })
+	var keyNibbles []byte
+	for _, b := range key {
+		keyNibbles = append(keyNibbles, b>>4, b&0xf)
+	}
+	node, proof := buildNode(entries, 0, keyNibbles)
+	return keccak(node), proof
+}
+
+func newEntry(key, value []byte) entry {
+	e := entry{value: value}
+	for _, b := range key {
+		e.nibbles = append(e.nibbles, b>>4, b&0xf)
+	}
+	return e
+}
+
+type mptCircuit struct {
+	Root        []uints.U8
+	Key         []uints.U8
+	Proof       Proof
+	Value       []uints.U8
+	ValueLen    frontend.Variable
+	maxValueLen int
+}
+
+func (c *mptCircuit) Define(api frontend.API) error {
+	v, err := New(api)
+	if err != nil {
+		return err
+	}
+	value, valueLen, err := v.VerifyProof(c.Root, c.Key, c.Proof, c.maxValueLen)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(valueLen, c.ValueLen)
+	for i := range c.Value {
+		api.AssertIsEqual(value[i].Val, c.Value[i].Val)
+	}
+	return nil
+}
+
+func TestVerifyProof(t *testing.T) {
+	const (
+		maxDepth    = 5
+		maxNodeSize = 300
+		maxValueLen = 80
+	)
+	assert := test.NewAssert(t)
+
+	var entries []entry
+	var keys [][]byte
+	for i := 0; i < 8; i++ {
+		key := make([]byte, 32)
+		_, err := rand.Read(key)
+		assert.NoError(err)
+		// account-like value
+		value := make([]byte, 70+i)
+		_, err = rand.Read(value)
+		assert.NoError(err)
+		entries = append(entries, newEntry(key, value))
+		keys = append(keys, key)
+	}
+	// keys differing only in the last nibble lead to an embedded branch with
+	// embedded leaves.
+	embedded := make([]byte, 32)
+	_, err := rand.Read(embedded)
+	assert.NoError(err)
+	embedded[31] = 0x10
+	entries = append(entries, newEntry(embedded, []byte{0x05}))
+	keys = append(keys, bytes.Clone(embedded))
+	embedded[31] = 0x11
+	entries = append(entries, newEntry(bytes.Clone(embedded), []byte{0x06}))
+
+	for _, tc := range []struct {
+		key        []byte
+		nbEmbedded int
+	}{
+		{keys[0], 0},
+		{keys[5], 0},
+		{keys[8], 2},
+	} {
+		key := tc.key
+		assert.Run(func(assert *test.Assert) {
+			root, nodes := trieProof(entries, key)
+			var value []byte
+			for _, e := range entries {
+				if bytes.Equal(e.nibbles, newEntry(key, nil).nibbles) {
+					value = e.value
+				}
+			}
+			proof, err := ValueOfProof(key, nodes, maxDepth, maxNodeSize)
+			assert.NoError(err)
+			assert.Equal(len(nodes)+tc.nbEmbedded, proof.Depth)
+			paddedValue := make([]byte, maxValueLen)
+			copy(paddedValue, value)
+
+			circuit := mptCircuit{
+				Root:        make([]uints.U8, HashLen),
+				Key:         make([]uints.U8, len(key)),
+				Proof:       PlaceholderProof(maxDepth, maxNodeSize),
+				Value:       make([]uints.U8, maxValueLen),
+				maxValueLen: maxValueLen,
+			}
+			witness := mptCircuit{
+				Root:     uints.NewU8Array(root),
+				Key:      uints.NewU8Array(key),
+				Proof:    proof,
+				Value:    uints.NewU8Array(paddedValue),
+				ValueLen: len(value),
+			}
+			err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+
+			// wrong value
+			paddedValue[0] ^= 1
+			witness.Value = uints.NewU8Array(paddedValue)
+			err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.Error(err)
+			paddedValue[0] ^= 1
+			witness.Value = uints.NewU8Array(paddedValue)
+
+			// wrong key
+			wrongKey := bytes.Clone(key)
+			wrongKey[31] ^= 0x02
+			witness.Key = uints.NewU8Array(wrongKey)
+			err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.Error(err)
+		})
+	}
+}
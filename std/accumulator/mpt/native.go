@@ -0,0 +1,130 @@
+package mpt
+
+import (
+	"errors"
+	"fmt"
+)
+
+// expandProof returns the nodes of the proof for key including the nodes
+// embedded in their parents, which are not returned separately by
+// eth_getProof.
+func expandProof(key []byte, nodes [][]byte) ([][]byte, error) {
+	nibbles := make([]byte, 0, 2*len(key))
+	for _, b := range key {
+		nibbles = append(nibbles, b>>4, b&0xf)
+	}
+	var res [][]byte
+	consumed := 0
+	for i := 0; i < len(nodes); i++ {
+		node := nodes[i]
+		for {
+			res = append(res, node)
+			items, err := decodeListItems(node)
+			if err != nil {
+				return nil, fmt.Errorf("node %d: %w", len(res)-1, err)
+			}
+			var ref []byte
+			switch len(items) {
+			case nbBranchItems:
+				if consumed >= len(nibbles) {
+					return nil, errors.New("key too short")
+				}
+				ref = items[nibbles[consumed]]
+				consumed++
+			case 2:
+				path, err := itemPayload(items[0])
+				if err != nil || len(path) == 0 {
+					return nil, errors.New("invalid path")
+				}
+				isLeaf := path[0]&0x20 != 0
+				pathNibbles := 2*len(path) - 2
+				if path[0]&0x10 != 0 {
+					pathNibbles++
+				}
+				consumed += pathNibbles
+				if isLeaf {
+					if i != len(nodes)-1 {
+						return nil, errors.New("leaf before the last node")
+					}
+					return res, nil
+				}
+				ref = items[1]
+			default:
+				return nil, fmt.Errorf("invalid number of items %d", len(items))
+			}
+			if len(ref) == 0 || ref[0] < 0xc0 {
+				// the child is referenced by its hash and is the next node
+				break
+			}
+			node = ref
+		}
+	}
+	return nil, errors.New("proof does not end with a leaf")
+}
+
+// decodeListItems returns the encoded items of the RLP list b.
+func decodeListItems(b []byte) ([][]byte, error) {
+	payloadOffset, payloadLen, isList, err := decodeHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	if !isList {
+		return nil, errors.New("not a list")
+	}
+	if payloadOffset+payloadLen != len(b) {
+		return nil, errors.New("invalid list length")
+	}
+	var items [][]byte
+	for rest := b[payloadOffset:]; len(rest) > 0; {
+		offset, l, _, err := decodeHeader(rest)
+		if err != nil {
+			return nil, err
+		}
+		if offset+l > len(rest) {
+			return nil, errors.New("item out of bounds")
+		}
+		items = append(items, rest[:offset+l])
+		rest = rest[offset+l:]
+	}
+	return items, nil
+}
+
+// itemPayload returns the payload of the encoded RLP string b.
+func itemPayload(b []byte) ([]byte, error) {
+	offset, l, isList, err := decodeHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	if isList || offset+l != len(b) {
+		return nil, errors.New("not a string")
+	}
+	return b[offset : offset+l], nil
+}
+
+// decodeHeader decodes the header of the RLP item b.
+func decodeHeader(b []byte) (payloadOffset, payloadLen int, isList bool, err error) {
+	if len(b) == 0 {
+		return 0, 0, false, errors.New("empty input")
+	}
+	var base byte
+	switch p := b[0]; {
+	case p < 0x80:
+		return 0, 1, false, nil
+	case p <= 0xb7:
+		return 1, int(p - 0x80), false, nil
+	case p < 0xc0:
+		base = 0xb7
+	case p <= 0xf7:
+		return 1, int(p - 0xc0), true, nil
+	default:
+		base, isList = 0xf7, true
+	}
+	lenOfLen := int(b[0] - base)
+	if lenOfLen > 2 || len(b) < 1+lenOfLen {
+		return 0, 0, false, errors.New("invalid length")
+	}
+	for _, c := range b[1 : 1+lenOfLen] {
+		payloadLen = payloadLen<<8 | int(c)
+	}
+	return 1 + lenOfLen, payloadLen, isList, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc"
 	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	fr_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/test"
 )
@@ -118,3 +119,86 @@ func TestScalarMulG2BySeedTestSolve(t *testing.T) {
 	err := test.IsSolved(&scalarMulG2BySeedCircuit{}, &witness, ecc.BN254.ScalarField())
 	assert.NoError(err)
 }
+
+type addUnifiedG2Circuit struct {
+	In1, In2 G2Affine
+	Res      G2Affine
+}
+
+func (c *addUnifiedG2Circuit) Define(api frontend.API) error {
+	g2 := NewG2(api)
+	res := g2.AddUnified(&c.In1, &c.In2)
+	g2.AssertIsEqual(res, &c.Res)
+	return nil
+}
+
+func TestAddUnifiedG2TestSolve(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, in1 := randomG1G2Affines()
+	_, in2 := randomG1G2Affines()
+	var infinity, neg1, res bls12381.G2Affine
+	neg1.Neg(&in1)
+	for _, tc := range []struct {
+		name     string
+		in1, in2 bls12381.G2Affine
+	}{
+		{"P+Q", in1, in2},
+		{"P+P", in1, in1},
+		{"P-P", in1, neg1},
+		{"O+Q", infinity, in2},
+		{"P+O", in1, infinity},
+		{"O+O", infinity, infinity},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			res.Add(&tc.in1, &tc.in2)
+			witness := addUnifiedG2Circuit{
+				In1: NewG2Affine(tc.in1),
+				In2: NewG2Affine(tc.in2),
+				Res: NewG2Affine(res),
+			}
+			err := test.IsSolved(&addUnifiedG2Circuit{}, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, tc.name)
+	}
+}
+
+type scalarMulG2Circuit struct {
+	In  G2Affine
+	S   Scalar
+	Res G2Affine
+}
+
+func (c *scalarMulG2Circuit) Define(api frontend.API) error {
+	g2 := NewG2(api)
+	res := g2.ScalarMul(&c.In, &c.S)
+	g2.AssertIsEqual(res, &c.Res)
+	return nil
+}
+
+func TestScalarMulG2TestSolve(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, in := randomG1G2Affines()
+	var infinity, res bls12381.G2Affine
+	var s fr_bls12381.Element
+	s.SetRandom()
+	for _, tc := range []struct {
+		name string
+		in   bls12381.G2Affine
+		s    fr_bls12381.Element
+	}{
+		{"[s]P", in, s},
+		{"[0]P", in, fr_bls12381.Element{}},
+		{"[s]O", infinity, s},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			res.ScalarMultiplication(&tc.in, tc.s.BigInt(new(big.Int)))
+			witness := scalarMulG2Circuit{
+				In:  NewG2Affine(tc.in),
+				S:   NewScalar(tc.s),
+				Res: NewG2Affine(res),
+			}
+			err := test.IsSolved(&scalarMulG2Circuit{}, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, tc.name)
+	}
+}
@@ -0,0 +1,92 @@
+package sw_bls12381
+
+import (
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+func init() {
+	solver.RegisterHint(GetHints()...)
+}
+
+// GetHints returns all hint functions used in the package.
+func GetHints() []solver.Hint {
+	return []solver.Hint{sswuSqrtG1Hint, sswuSqrtG2Hint}
+}
+
+// sswuSqrtG1Hint returns the square root y of gx1 if it is a square and of gx2
+// otherwise, such that sgn0(y) == sgn0(u).
+func sswuSqrtG1Hint(mod *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	return emulated.UnwrapHint(inputs, outputs, func(field *big.Int, inputs, outputs []*big.Int) error {
+		if len(inputs) != 3 {
+			return fmt.Errorf("expecting three inputs")
+		}
+		if len(outputs) != 1 {
+			return fmt.Errorf("expecting one output")
+		}
+		var gx1, gx2, u, y fp.Element
+		gx1.SetBigInt(inputs[0])
+		gx2.SetBigInt(inputs[1])
+		u.SetBigInt(inputs[2])
+		if y.Sqrt(&gx1) == nil {
+			if y.Sqrt(&gx2) == nil {
+				return fmt.Errorf("neither gx1 nor gx2 is a square")
+			}
+		}
+		if sgn0G1(&y) != sgn0G1(&u) {
+			y.Neg(&y)
+		}
+		y.BigInt(outputs[0])
+		return nil
+	})
+}
+
+// sswuSqrtG2Hint is the counterpart of [sswuSqrtG1Hint] over Fp2. The inputs
+// and the output are given coordinate-wise.
+func sswuSqrtG2Hint(mod *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	return emulated.UnwrapHint(inputs, outputs, func(field *big.Int, inputs, outputs []*big.Int) error {
+		if len(inputs) != 6 {
+			return fmt.Errorf("expecting six inputs")
+		}
+		if len(outputs) != 2 {
+			return fmt.Errorf("expecting two outputs")
+		}
+		var gx1, gx2, u, y bls12381.E2
+		gx1.A0.SetBigInt(inputs[0])
+		gx1.A1.SetBigInt(inputs[1])
+		gx2.A0.SetBigInt(inputs[2])
+		gx2.A1.SetBigInt(inputs[3])
+		u.A0.SetBigInt(inputs[4])
+		u.A1.SetBigInt(inputs[5])
+		switch {
+		case gx1.Legendre() != -1:
+			y.Sqrt(&gx1)
+		case gx2.Legendre() != -1:
+			y.Sqrt(&gx2)
+		default:
+			return fmt.Errorf("neither gx1 nor gx2 is a square")
+		}
+		if sgn0G2(&y) != sgn0G2(&u) {
+			y.Neg(&y)
+		}
+		y.A0.BigInt(outputs[0])
+		y.A1.BigInt(outputs[1])
+		return nil
+	})
+}
+
+func sgn0G1(z *fp.Element) uint {
+	return uint(z.Bits()[0] % 2)
+}
+
+func sgn0G2(z *bls12381.E2) uint {
+	if z.A0.IsZero() {
+		return sgn0G1(&z.A1)
+	}
+	return sgn0G1(&z.A0)
+}
@@ -0,0 +1,237 @@
+package sw_bls12381
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/hash/tofield"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// The constants of the simplified SWU map to the curve E2' isogenous to the
+// twist of BLS12-381 and of the 3-isogeny from E2' to E2, as defined in
+// [RFC 9380] Section 8.8.2 and Appendix E.3. The elements of Fp2 are given as
+// pairs (A0, A1) of coordinates.
+//
+// [RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380
+var (
+	// g2SSWUA is the coefficient A' = 240·u of the isogenous curve E2'.
+	g2SSWUA = [2]string{"0", "240"}
+	// g2SSWUB is the coefficient B' = 1012·(1+u) of the isogenous curve E2'.
+	g2SSWUB = [2]string{"1012", "1012"}
+	// g2SSWUZ is the non-square Z = -(2+u) of the simplified SWU map.
+	g2SSWUZ = [2]string{
+		"0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaa9",
+		"0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaaa",
+	}
+)
+
+// g2IsogenyXNum are the coefficients of the numerator of the x-coordinate of
+// the 3-isogeny, in increasing degree.
+var g2IsogenyXNum = [][2]string{
+	{"0x5c759507e8e333ebb5b7a9a47d7ed8532c52d39fd3a042a88b58423c50ae15d5c2638e343d9c71c6238aaaaaaaa97d6", "0x5c759507e8e333ebb5b7a9a47d7ed8532c52d39fd3a042a88b58423c50ae15d5c2638e343d9c71c6238aaaaaaaa97d6"},
+	{"0x0", "0x11560bf17baa99bc32126fced787c88f984f87adf7ae0c7f9a208c6b4f20a4181472aaa9cb8d555526a9ffffffffc71a"},
+	{"0x11560bf17baa99bc32126fced787c88f984f87adf7ae0c7f9a208c6b4f20a4181472aaa9cb8d555526a9ffffffffc71e", "0x8ab05f8bdd54cde190937e76bc3e447cc27c3d6fbd7063fcd104635a790520c0a395554e5c6aaaa9354ffffffffe38d"},
+	{"0x171d6541fa38ccfaed6dea691f5fb614cb14b4e7f4e810aa22d6108f142b85757098e38d0f671c7188e2aaaaaaaa5ed1", "0x0"},
+}
+
+// g2IsogenyXDen are the coefficients of the monic denominator of the
+// x-coordinate of the 3-isogeny, in increasing degree and omitting the
+// leading one.
+var g2IsogenyXDen = [][2]string{
+	{"0x0", "0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaa63"},
+	{"0xc", "0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaa9f"},
+}
+
+// g2IsogenyYNum are the coefficients of the numerator of the y-coordinate of
+// the 3-isogeny, in increasing degree.
+var g2IsogenyYNum = [][2]string{
+	{"0x1530477c7ab4113b59a4c18b076d11930f7da5d4a07f649bf54439d87d27e500fc8c25ebf8c92f6812cfc71c71c6d706", "0x1530477c7ab4113b59a4c18b076d11930f7da5d4a07f649bf54439d87d27e500fc8c25ebf8c92f6812cfc71c71c6d706"},
+	{"0x0", "0x5c759507e8e333ebb5b7a9a47d7ed8532c52d39fd3a042a88b58423c50ae15d5c2638e343d9c71c6238aaaaaaaa97be"},
+	{"0x11560bf17baa99bc32126fced787c88f984f87adf7ae0c7f9a208c6b4f20a4181472aaa9cb8d555526a9ffffffffc71c", "0x8ab05f8bdd54cde190937e76bc3e447cc27c3d6fbd7063fcd104635a790520c0a395554e5c6aaaa9354ffffffffe38f"},
+	{"0x124c9ad43b6cf79bfbf7043de3811ad0761b0f37a1e26286b0e977c69aa274524e79097a56dc4bd9e1b371c71c718b10", "0x0"},
+}
+
+// g2IsogenyYDen are the coefficients of the monic denominator of the
+// y-coordinate of the 3-isogeny, in increasing degree and omitting the
+// leading one.
+var g2IsogenyYDen = [][2]string{
+	{"0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffa8fb", "0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffa8fb"},
+	{"0x0", "0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffa9d3"},
+	{"0x12", "0x1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaa99"},
+}
+
+// MapToG2 maps the element u of Fp2 to a point in G2. It implements the
+// map_to_curve and clear_cofactor steps of the BLS12381G2_XMD:SHA-256_SSWU_RO_
+// suite of [RFC 9380], i.e. the simplified SWU map to the isogenous curve E2',
+// followed by the 3-isogeny to E2 and the cofactor clearing using the
+// endomorphism ψ of [BP17]. The result matches the native [bls12381.MapToG2]
+// and the MAP_FP2_TO_G2 precompile of [EIP-2537].
+//
+// ⚠️  The exceptional cases of the isogeny (zero denominator) and of the
+// incomplete additions in the cofactor clearing happen with negligible
+// probability and make the circuit unsatisfiable.
+//
+// [RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380
+// [BP17]: https://eprint.iacr.org/2017/419
+// [EIP-2537]: https://eips.ethereum.org/EIPS/eip-2537
+func (g2 *G2) MapToG2(u *fields_bls12381.E2) *G2Affine {
+	p := g2.mapToCurve2(u)
+	p = g2.isogeny(p)
+	return g2.clearCofactor(p)
+}
+
+// HashToG2 hashes the message msg to a point in G2 using the domain separation
+// tag dst. It implements the BLS12381G2_XMD:SHA-256_SSWU_RO_ suite of [RFC
+// 9380], i.e. the message is hashed to two elements of Fp2 with
+// expand_message_xmd and SHA-256, which are mapped to E2' and E2 as in
+// [G2.MapToG2] and added before clearing the cofactor. The result matches the
+// native [bls12381.HashToG2] and can be used for BLS signatures in the min-pk
+// setting.
+//
+// ⚠️  The exceptional cases of the incomplete addition of the two mapped points
+// happen with negligible probability and make the circuit unsatisfiable.
+//
+// [RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380
+func (g2 *G2) HashToG2(msg []uints.U8, dst []byte) (*G2Affine, error) {
+	u, err := tofield.Hash[BaseField](g2.api, msg, dst, 4)
+	if err != nil {
+		return nil, fmt.Errorf("hash to field: %w", err)
+	}
+	q0 := g2.isogeny(g2.mapToCurve2(&fields_bls12381.E2{A0: *u[0], A1: *u[1]}))
+	q1 := g2.isogeny(g2.mapToCurve2(&fields_bls12381.E2{A0: *u[2], A1: *u[3]}))
+	return g2.clearCofactor(g2.add(q0, q1)), nil
+}
+
+// mapToCurve2 implements the simplified SWU map to the curve E2' as in RFC 9380
+// Section 6.6.2.
+func (g2 *G2) mapToCurve2(u *fields_bls12381.E2) *G2Affine {
+	a := g2.constE2(g2SSWUA)
+	b := g2.constE2(g2SSWUB)
+	z := g2.constE2(g2SSWUZ)
+	one := g2.Ext2.One()
+
+	// tv1 = Z·u², tv2 = tv1² + tv1
+	tv1 := g2.Ext2.Mul(z, g2.Ext2.Square(u))
+	tv2 := g2.Ext2.Add(g2.Ext2.Square(tv1), tv1)
+	// x1 = B'·(tv2 + 1) / (A'·tv3), where tv3 = Z if tv2 == 0 and -tv2 otherwise
+	tv3 := g2.Ext2.Select(g2.Ext2.IsZero(tv2), z, g2.Ext2.Neg(tv2))
+	x1 := g2.Ext2.DivUnchecked(
+		g2.Ext2.Mul(b, g2.Ext2.Add(tv2, one)),
+		g2.Ext2.Mul(a, tv3),
+	)
+	// x2 = tv1·x1
+	x2 := g2.Ext2.Mul(tv1, x1)
+	// gx = x³ + A'·x + B'
+	gx := func(x *fields_bls12381.E2) *fields_bls12381.E2 {
+		res := g2.Ext2.Mul(x, g2.Ext2.Square(x))
+		res = g2.Ext2.Add(res, g2.Ext2.Mul(a, x))
+		return g2.Ext2.Add(res, b)
+	}
+	gx1 := gx(x1)
+	gx2 := gx(x2)
+
+	// As in the G1 case, exactly one of gx1 and gx2 is a square (or both are
+	// zero). The hint returns its square root y with sgn0(y) == sgn0(u) and
+	// we check that it is correct.
+	hint, err := g2.fp.NewHint(sswuSqrtG2Hint, 2, &gx1.A0, &gx1.A1, &gx2.A0, &gx2.A1, &u.A0, &u.A1)
+	if err != nil {
+		panic(err)
+	}
+	y := &fields_bls12381.E2{A0: *hint[0], A1: *hint[1]}
+	yy := g2.Ext2.Square(y)
+	isGx1 := g2.Ext2.IsZero(g2.Ext2.Sub(yy, gx1))
+	isGx2 := g2.Ext2.IsZero(g2.Ext2.Sub(yy, gx2))
+	g2.api.AssertIsEqual(g2.api.Or(isGx1, isGx2), 1)
+	x := g2.Ext2.Select(isGx1, x1, x2)
+	g2.api.AssertIsEqual(g2.sgn0(y), g2.sgn0(u))
+
+	return &G2Affine{
+		P: g2AffP{X: *x, Y: *y},
+	}
+}
+
+// isogeny maps the point p on E2' to E2 using the 3-isogeny.
+func (g2 *G2) isogeny(p *G2Affine) *G2Affine {
+	xNum := g2.evalPolynomial(g2IsogenyXNum, false, &p.P.X)
+	xDen := g2.evalPolynomial(g2IsogenyXDen, true, &p.P.X)
+	yNum := g2.evalPolynomial(g2IsogenyYNum, false, &p.P.X)
+	yDen := g2.evalPolynomial(g2IsogenyYDen, true, &p.P.X)
+	yNum = g2.Ext2.Mul(yNum, &p.P.Y)
+	return &G2Affine{
+		P: g2AffP{
+			X: *g2.Ext2.DivUnchecked(xNum, xDen),
+			Y: *g2.Ext2.DivUnchecked(yNum, yDen),
+		},
+	}
+}
+
+// clearCofactor computes [h_eff]p using the decomposition of [BP17] (Section
+// 4.1):
+//
+//	[x₀² - x₀ - 1]p + [x₀ - 1]ψ(p) + ψ²([2]p)
+//
+// where x₀ is the seed of the curve.
+//
+// [BP17]: https://eprint.iacr.org/2017/419
+func (g2 *G2) clearCofactor(p *G2Affine) *G2Affine {
+	// [x₀]p and [x₀²]p
+	xp := g2.scalarMulBySeed(p)
+	xxp := g2.scalarMulBySeed(xp)
+	// [x₀² - x₀ - 1]p
+	res := g2.sub(g2.sub(xxp, xp), p)
+	// ψ([x₀ - 1]p)
+	res = g2.add(res, g2.psi(g2.sub(xp, p)))
+	// ψ²([2]p) = (w·x, -y), where w is a primitive cube root of unity
+	p2 := g2.double(p)
+	p2 = &G2Affine{
+		P: g2AffP{
+			X: *g2.Ext2.MulByElement(&p2.P.X, g2.w),
+			Y: *g2.Ext2.Neg(&p2.P.Y),
+		},
+	}
+	return g2.add(res, p2)
+}
+
+// evalPolynomial evaluates at x the polynomial with the given coefficients in
+// increasing degree using Horner's method. If monic is set, then the
+// polynomial has an additional leading coefficient one.
+func (g2 *G2) evalPolynomial(coefficients [][2]string, monic bool, x *fields_bls12381.E2) *fields_bls12381.E2 {
+	res := g2.constE2(coefficients[len(coefficients)-1])
+	if monic {
+		res = g2.Ext2.Add(res, x)
+	}
+	for i := len(coefficients) - 2; i >= 0; i-- {
+		res = g2.Ext2.Mul(res, x)
+		res = g2.Ext2.Add(res, g2.constE2(coefficients[i]))
+	}
+	return res
+}
+
+// sgn0 returns the sign of the element x as defined in RFC 9380 Section 4.1,
+// i.e. sgn0(x.A0) OR (x.A0 == 0 AND sgn0(x.A1)).
+func (g2 *G2) sgn0(x *fields_bls12381.E2) frontend.Variable {
+	sgn0 := func(x *emulated.Element[BaseField]) frontend.Variable {
+		x = g2.fp.Reduce(x)
+		g2.fp.AssertIsInRange(x)
+		return g2.fp.ToBits(x)[0]
+	}
+	sign0 := sgn0(&x.A0)
+	zero0 := g2.fp.IsZero(&x.A0)
+	sign1 := sgn0(&x.A1)
+	// when x.A0 == 0 then sgn0(x.A0) == 0, so the OR is a sum.
+	return g2.api.Add(sign0, g2.api.Mul(zero0, sign1))
+}
+
+// constE2 returns the constant element of Fp2 with coordinates c.
+func (g2 *G2) constE2(c [2]string) *fields_bls12381.E2 {
+	a0, _ := new(big.Int).SetString(c[0], 0)
+	a1, _ := new(big.Int).SetString(c[1], 0)
+	return &fields_bls12381.E2{
+		A0: *g2.fp.NewElement(a0),
+		A1: *g2.fp.NewElement(a1),
+	}
+}
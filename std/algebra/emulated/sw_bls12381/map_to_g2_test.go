@@ -0,0 +1,80 @@
+package sw_bls12381
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+type mapToG2Circuit struct {
+	U   fields_bls12381.E2
+	Res G2Affine
+}
+
+func (c *mapToG2Circuit) Define(api frontend.API) error {
+	g2 := NewG2(api)
+	res := g2.MapToG2(&c.U)
+	g2.AssertIsEqual(res, &c.Res)
+	return nil
+}
+
+func TestMapToG2TestSolve(t *testing.T) {
+	assert := test.NewAssert(t)
+	var u bls12381.E2
+	for i := 0; i < 4; i++ {
+		switch i {
+		case 0:
+			// tv2 == 0 exceptional case of the SSWU map
+			u.SetZero()
+		default:
+			u.SetRandom()
+		}
+		res := bls12381.MapToG2(u)
+		witness := mapToG2Circuit{
+			U:   fields_bls12381.FromE2(&u),
+			Res: NewG2Affine(res),
+		}
+		err := test.IsSolved(&mapToG2Circuit{}, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+	}
+}
+
+type hashToG2Circuit struct {
+	Msg []uints.U8
+	Res G2Affine
+	dst []byte
+}
+
+func (c *hashToG2Circuit) Define(api frontend.API) error {
+	g2 := NewG2(api)
+	res, err := g2.HashToG2(c.Msg, c.dst)
+	if err != nil {
+		return err
+	}
+	g2.AssertIsEqual(res, &c.Res)
+	return nil
+}
+
+func TestHashToG2TestSolve(t *testing.T) {
+	assert := test.NewAssert(t)
+	dst := []byte("QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_RO_")
+	for _, msg := range []string{"", "abc"} {
+		res, err := bls12381.HashToG2([]byte(msg), dst)
+		assert.NoError(err)
+		circuit := hashToG2Circuit{
+			Msg: make([]uints.U8, len(msg)),
+			dst: dst,
+		}
+		witness := hashToG2Circuit{
+			Msg: uints.NewU8Array([]byte(msg)),
+			Res: NewG2Affine(res),
+		}
+		err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err, "msg %q", msg)
+	}
+}
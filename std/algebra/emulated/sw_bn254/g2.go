@@ -10,6 +10,8 @@ import (
 )
 
 type G2 struct {
+	api frontend.API
+	fp  *emulated.Field[BaseField]
 	*fields_bn254.Ext2
 	w    *emulated.Element[BaseField]
 	u, v *fields_bn254.E2
@@ -48,7 +50,13 @@ func NewG2(api frontend.API) *G2 {
 		A0: emulated.ValueOf[BaseField]("2821565182194536844548159561693502659359617185244120367078079554186484126554"),
 		A1: emulated.ValueOf[BaseField]("3505843767911556378687030309984248845540243509899259641013678093033130930403"),
 	}
+	fp, err := emulated.NewField[BaseField](api)
+	if err != nil {
+		panic(err)
+	}
 	return &G2{
+		api:  api,
+		fp:   fp,
 		Ext2: fields_bn254.NewExt2(api),
 		w:    &w,
 		u:    &u,
@@ -257,6 +265,69 @@ func (g2 G2) doubleAndAdd(p, q *G2Affine) *G2Affine {
 	}
 }
 
+// AddUnified adds p and q and returns it. It doesn't modify p nor q.
+//
+// ✅ p can be equal to q, and either or both can be (0,0).
+// (0,0) is not on the curve but we conventionally take it as the
+// neutral/infinity point.
+//
+// It uses the unified formulas of Brier and Joye ([[BriJoy02]] (Corollary 1)).
+//
+// [BriJoy02]: https://link.springer.com/content/pdf/10.1007/3-540-45664-3_24.pdf
+func (g2 *G2) AddUnified(p, q *G2Affine) *G2Affine {
+	// selector1 = 1 when p is (0,0) and 0 otherwise
+	selector1 := g2.api.And(g2.Ext2.IsZero(&p.P.X), g2.Ext2.IsZero(&p.P.Y))
+	// selector2 = 1 when q is (0,0) and 0 otherwise
+	selector2 := g2.api.And(g2.Ext2.IsZero(&q.P.X), g2.Ext2.IsZero(&q.P.Y))
+
+	// λ = ((p.x+q.x)² - p.x*q.x)/(p.y + q.y)
+	pxqx := g2.Ext2.Mul(&p.P.X, &q.P.X)
+	pxplusqx := g2.Ext2.Add(&p.P.X, &q.P.X)
+	num := g2.Ext2.Square(pxplusqx)
+	num = g2.Ext2.Sub(num, pxqx)
+	denum := g2.Ext2.Add(&p.P.Y, &q.P.Y)
+	// if p.y + q.y = 0, assign dummy 1 to denum and continue
+	selector3 := g2.Ext2.IsZero(denum)
+	denum = g2.Ext2.Select(selector3, g2.Ext2.One(), denum)
+	λ := g2.Ext2.DivUnchecked(num, denum)
+
+	// x = λ^2 - p.x - q.x
+	xr := g2.Ext2.Square(λ)
+	xr = g2.Ext2.Sub(xr, pxplusqx)
+
+	// y = λ(p.x - xr) - p.y
+	yr := g2.Ext2.Sub(&p.P.X, xr)
+	yr = g2.Ext2.Mul(yr, λ)
+	yr = g2.Ext2.Sub(yr, &p.P.Y)
+	result := &G2Affine{
+		P: g2AffP{X: *xr, Y: *yr},
+	}
+
+	zero := g2.Ext2.Zero()
+	infinity := &G2Affine{
+		P: g2AffP{X: *zero, Y: *zero},
+	}
+	// if p=(0,0) return q
+	result = g2.Select(selector1, q, result)
+	// if q=(0,0) return p
+	result = g2.Select(selector2, p, result)
+	// if p.y + q.y = 0, return (0, 0)
+	result = g2.Select(selector3, infinity, result)
+
+	return result
+}
+
+// Select selects between p and q given the selector b. If b == 1, then returns
+// p and q otherwise.
+func (g2 *G2) Select(b frontend.Variable, p, q *G2Affine) *G2Affine {
+	return &G2Affine{
+		P: g2AffP{
+			X: *g2.Ext2.Select(b, &p.P.X, &q.P.X),
+			Y: *g2.Ext2.Select(b, &p.P.Y, &q.P.Y),
+		},
+	}
+}
+
 // AssertIsEqual asserts that p and q are the same point.
 func (g2 *G2) AssertIsEqual(p, q *G2Affine) {
 	g2.Ext2.AssertIsEqual(&p.P.X, &q.P.X)
@@ -142,3 +142,45 @@ func TestEndomorphismG2TestSolve(t *testing.T) {
 	err := test.IsSolved(&endomorphismG2Circuit{}, &witness, ecc.BN254.ScalarField())
 	assert.NoError(err)
 }
+
+type addUnifiedG2Circuit struct {
+	In1, In2 G2Affine
+	Res      G2Affine
+}
+
+func (c *addUnifiedG2Circuit) Define(api frontend.API) error {
+	g2 := NewG2(api)
+	res := g2.AddUnified(&c.In1, &c.In2)
+	g2.AssertIsEqual(res, &c.Res)
+	return nil
+}
+
+func TestAddUnifiedG2TestSolve(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, in1 := randomG1G2Affines()
+	_, in2 := randomG1G2Affines()
+	var infinity, neg1, res bn254.G2Affine
+	neg1.Neg(&in1)
+	for _, tc := range []struct {
+		name     string
+		in1, in2 bn254.G2Affine
+	}{
+		{"P+Q", in1, in2},
+		{"P+P", in1, in1},
+		{"P-P", in1, neg1},
+		{"O+Q", infinity, in2},
+		{"P+O", in1, infinity},
+		{"O+O", infinity, infinity},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			res.Add(&tc.in1, &tc.in2)
+			witness := addUnifiedG2Circuit{
+				In1: NewG2Affine(tc.in1),
+				In2: NewG2Affine(tc.in2),
+				Res: NewG2Affine(res),
+			}
+			err := test.IsSolved(&addUnifiedG2Circuit{}, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, tc.name)
+	}
+}
@@ -1,9 +1,11 @@
 package sw_bn254
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
 	"github.com/consensys/gnark/constraint/solver"
 	"github.com/consensys/gnark/std/math/emulated"
 )
@@ -16,6 +18,8 @@ func init() {
 func GetHints() []solver.Hint {
 	return []solver.Hint{
 		millerLoopAndCheckFinalExpHint,
+		svdwSqrtG1Hint,
+		svdwSqrtG2Hint,
 	}
 }
 
@@ -162,3 +166,107 @@ func millerLoopAndCheckFinalExpHint(nativeMod *big.Int, nativeInputs, nativeOutp
 			return nil
 		})
 }
+
+// svdwSqrtG1Hint returns the square roots s1 and s2 of gx1 and gx2 if they are
+// squares and of -gx1 and -gx2 otherwise (-1 is a non-square as p ≡ 3 mod 4).
+// Additionally, it returns the square root y of the first square among gx1,
+// gx2 and gx3 such that sgn0(y) == sgn0(u).
+func svdwSqrtG1Hint(mod *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	return emulated.UnwrapHint(inputs, outputs, func(field *big.Int, inputs, outputs []*big.Int) error {
+		if len(inputs) != 4 {
+			return fmt.Errorf("expecting four inputs")
+		}
+		if len(outputs) != 3 {
+			return fmt.Errorf("expecting three outputs")
+		}
+		var gx [3]fp.Element
+		var u, y fp.Element
+		for i := range gx {
+			gx[i].SetBigInt(inputs[i])
+		}
+		u.SetBigInt(inputs[3])
+		for i := 0; i < 2; i++ {
+			var s, ngx fp.Element
+			if s.Sqrt(&gx[i]) == nil {
+				ngx.Neg(&gx[i])
+				if s.Sqrt(&ngx) == nil {
+					return fmt.Errorf("neither gx nor -gx is a square")
+				}
+			}
+			s.BigInt(outputs[i])
+		}
+		found := false
+		for i := range gx {
+			if y.Sqrt(&gx[i]) != nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("none of gx1, gx2 and gx3 is a square")
+		}
+		if sgn0G1(&y) != sgn0G1(&u) {
+			y.Neg(&y)
+		}
+		y.BigInt(outputs[2])
+		return nil
+	})
+}
+
+// svdwSqrtG2Hint is the counterpart of [svdwSqrtG1Hint] over Fp2, where the
+// non-square is ξ = 9+u instead of -1. The inputs and the outputs are given
+// coordinate-wise.
+func svdwSqrtG2Hint(mod *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	return emulated.UnwrapHint(inputs, outputs, func(field *big.Int, inputs, outputs []*big.Int) error {
+		if len(inputs) != 8 {
+			return fmt.Errorf("expecting eight inputs")
+		}
+		if len(outputs) != 6 {
+			return fmt.Errorf("expecting six outputs")
+		}
+		var gx [3]bn254.E2
+		var u, y bn254.E2
+		for i := range gx {
+			gx[i].A0.SetBigInt(inputs[2*i])
+			gx[i].A1.SetBigInt(inputs[2*i+1])
+		}
+		u.A0.SetBigInt(inputs[6])
+		u.A1.SetBigInt(inputs[7])
+		for i := 0; i < 2; i++ {
+			var s bn254.E2
+			if gx[i].Legendre() != -1 {
+				s.Sqrt(&gx[i])
+			} else {
+				s.MulByNonResidue(&gx[i])
+				s.Sqrt(&s)
+			}
+			s.A0.BigInt(outputs[2*i])
+			s.A1.BigInt(outputs[2*i+1])
+		}
+		i := 0
+		for i < len(gx) && gx[i].Legendre() == -1 {
+			i++
+		}
+		if i == len(gx) {
+			return fmt.Errorf("none of gx1, gx2 and gx3 is a square")
+		}
+		y.Sqrt(&gx[i])
+		if sgn0G2(&y) != sgn0G2(&u) {
+			y.Neg(&y)
+		}
+		y.A0.BigInt(outputs[4])
+		y.A1.BigInt(outputs[5])
+		return nil
+	})
+}
+
+func sgn0G1(z *fp.Element) uint {
+	return uint(z.Bits()[0] % 2)
+}
+
+func sgn0G2(z *bn254.E2) uint {
+	if z.A0.IsZero() {
+		return sgn0G1(&z.A1)
+	}
+	return sgn0G1(&z.A0)
+}
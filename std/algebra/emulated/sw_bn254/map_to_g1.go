@@ -0,0 +1,128 @@
+package sw_bn254
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/tofield"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// The constants of the Shallue-van de Woestijne map to BN254 G1 with Z = 1, as
+// defined in [RFC 9380] Section 6.6.1.
+//
+// [RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380
+const (
+	// g1SVDWZ is the element Z of the SvdW map.
+	g1SVDWZ = 1
+	// g1SVDWC1 is c1 = g(Z).
+	g1SVDWC1 = 4
+	// g1SVDWC2 is c2 = -Z/2.
+	g1SVDWC2 = "10944121435919637611123202872628637544348155578648911831344518947322613104291"
+	// g1SVDWC3 is c3 = sqrt(-g(Z)·(3Z²+4A)) with sgn0(c3) == 0.
+	g1SVDWC3 = "8815841940592487685674414971303048083897117035520822607866"
+	// g1SVDWC4 is c4 = -4g(Z)/(3Z²+4A).
+	g1SVDWC4 = "7296080957279758407415468581752425029565437052432607887563012631548408736189"
+	// g1B is the coefficient B of the curve y² = x³ + B.
+	g1B = 3
+)
+
+// MapToG1 maps the base field element u to a point in G1. It implements the
+// map_to_curve step of the BN254G1_XMD:SHA-256_SVDW_RO_ suite of [RFC 9380],
+// i.e. the Shallue-van de Woestijne map. As G1 has cofactor one, no cofactor
+// clearing is needed. The result matches the native [bn254.MapToG1].
+//
+// [RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380
+func (g1 *G1) MapToG1(u *emulated.Element[BaseField]) *G1Affine {
+	return g1.mapToCurve1(u)
+}
+
+// HashToG1 hashes the message msg to a point in G1 using the domain separation
+// tag dst. It implements the BN254G1_XMD:SHA-256_SVDW_RO_ suite of [RFC 9380],
+// i.e. the message is hashed to two base field elements with
+// expand_message_xmd and SHA-256, which are mapped to G1 as in [G1.MapToG1]
+// and added. The result matches the native [bn254.HashToG1].
+//
+// ⚠️  The exceptional cases of the incomplete addition of the two mapped points
+// happen with negligible probability and make the circuit unsatisfiable.
+//
+// [RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380
+func (g1 *G1) HashToG1(msg []uints.U8, dst []byte) (*G1Affine, error) {
+	u, err := tofield.Hash[BaseField](g1.api, msg, dst, 2)
+	if err != nil {
+		return nil, fmt.Errorf("hash to field: %w", err)
+	}
+	q0 := g1.mapToCurve1(u[0])
+	q1 := g1.mapToCurve1(u[1])
+	return g1.add(q0, q1), nil
+}
+
+// mapToCurve1 implements the Shallue-van de Woestijne map as in RFC 9380
+// Section 6.6.1.
+func (g1 *G1) mapToCurve1(u *emulated.Element[BaseField]) *G1Affine {
+	z := g1.curveF.NewElement(g1SVDWZ)
+	c1 := g1.curveF.NewElement(g1SVDWC1)
+	c2 := g1.curveF.NewElement(g1SVDWC2)
+	c3 := g1.curveF.NewElement(g1SVDWC3)
+	c4 := g1.curveF.NewElement(g1SVDWC4)
+	b := g1.curveF.NewElement(g1B)
+	one := g1.curveF.One()
+
+	// tv1 = c1·u², tv2 = 1 + tv1, tv1 = 1 - tv1
+	tv1 := g1.curveF.Mul(c1, g1.curveF.Mul(u, u))
+	tv2 := g1.curveF.Add(one, tv1)
+	tv1 = g1.curveF.Sub(one, tv1)
+	// tv3 = inv0(tv1·tv2)
+	tv3 := g1.curveF.Mul(tv1, tv2)
+	tv3IsZero := g1.curveF.IsZero(tv3)
+	tv3 = g1.curveF.Inverse(g1.curveF.Select(tv3IsZero, one, tv3))
+	tv3 = g1.curveF.Select(tv3IsZero, g1.curveF.Zero(), tv3)
+	// tv4 = u·tv1·tv3·c3, x1 = c2 - tv4, x2 = c2 + tv4
+	tv4 := g1.curveF.Mul(g1.curveF.Mul(u, tv1), g1.curveF.Mul(tv3, c3))
+	x1 := g1.curveF.Sub(c2, tv4)
+	x2 := g1.curveF.Add(c2, tv4)
+	// x3 = c4·(tv2²·tv3)² + Z
+	x3 := g1.curveF.Mul(g1.curveF.Mul(tv2, tv2), tv3)
+	x3 = g1.curveF.Add(g1.curveF.Mul(c4, g1.curveF.Mul(x3, x3)), z)
+	// gx = x³ + B
+	gx := func(x *emulated.Element[BaseField]) *emulated.Element[BaseField] {
+		return g1.curveF.Add(g1.curveF.Mul(x, g1.curveF.Mul(x, x)), b)
+	}
+	gx1 := gx(x1)
+	gx2 := gx(x2)
+	gx3 := gx(x3)
+
+	// Unlike in the SSWU map, both gx1 and gx2 may be squares and the map
+	// takes the first square among gx1, gx2 and gx3. To prevent the prover
+	// from choosing, the hint returns the square roots s1 and s2 of either gxᵢ
+	// or -gxᵢ, which tell whether gxᵢ is a square. Finally, it returns the
+	// square root y of g(x) with sgn0(y) == sgn0(u).
+	hint, err := g1.curveF.NewHint(svdwSqrtG1Hint, 3, gx1, gx2, gx3, u)
+	if err != nil {
+		panic(err)
+	}
+	isSquare := func(gx, s *emulated.Element[BaseField]) frontend.Variable {
+		ss := g1.curveF.Mul(s, s)
+		res := g1.curveF.IsZero(g1.curveF.Sub(ss, gx))
+		g1.curveF.AssertIsEqual(ss, g1.curveF.Select(res, gx, g1.curveF.Neg(gx)))
+		return res
+	}
+	isGx1Square := isSquare(gx1, hint[0])
+	isGx2Square := isSquare(gx2, hint[1])
+	x := g1.curveF.Select(isGx2Square, x2, x3)
+	x = g1.curveF.Select(isGx1Square, x1, x)
+	y := hint[2]
+	g1.curveF.AssertIsEqual(g1.curveF.Mul(y, y), gx(x))
+	g1.api.AssertIsEqual(g1.sgn0(y), g1.sgn0(u))
+
+	return &G1Affine{X: *x, Y: *y}
+}
+
+// sgn0 returns the sign of the element x as defined in RFC 9380 Section 4.1,
+// i.e. the parity of its canonical representative.
+func (g1 *G1) sgn0(x *emulated.Element[BaseField]) frontend.Variable {
+	x = g1.curveF.Reduce(x)
+	g1.curveF.AssertIsInRange(x)
+	return g1.curveF.ToBits(x)[0]
+}
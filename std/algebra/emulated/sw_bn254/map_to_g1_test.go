@@ -0,0 +1,92 @@
+package sw_bn254
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	fp_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+type mapToG1Circuit struct {
+	U   emulated.Element[BaseField]
+	Res G1Affine
+}
+
+func (c *mapToG1Circuit) Define(api frontend.API) error {
+	g1, err := NewG1(api)
+	if err != nil {
+		return err
+	}
+	res := g1.MapToG1(&c.U)
+	g1.curveF.AssertIsEqual(&res.X, &c.Res.X)
+	g1.curveF.AssertIsEqual(&res.Y, &c.Res.Y)
+	return nil
+}
+
+func TestMapToG1TestSolve(t *testing.T) {
+	assert := test.NewAssert(t)
+	var u fp_bn254.Element
+	for i := 0; i < 4; i++ {
+		switch i {
+		case 0:
+			u.SetZero()
+		case 1:
+			// tv1·tv2 == 0 exceptional case of the SvdW map, as c1·u² == 1
+			u.SetUint64(2)
+			u.Inverse(&u)
+		default:
+			u.SetRandom()
+		}
+		res := bn254.MapToG1(u)
+		witness := mapToG1Circuit{
+			U:   emulated.ValueOf[BaseField](u),
+			Res: NewG1Affine(res),
+		}
+		err := test.IsSolved(&mapToG1Circuit{}, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+	}
+}
+
+type hashToG1Circuit struct {
+	Msg []uints.U8
+	Res G1Affine
+	dst []byte
+}
+
+func (c *hashToG1Circuit) Define(api frontend.API) error {
+	g1, err := NewG1(api)
+	if err != nil {
+		return err
+	}
+	res, err := g1.HashToG1(c.Msg, c.dst)
+	if err != nil {
+		return err
+	}
+	g1.curveF.AssertIsEqual(&res.X, &c.Res.X)
+	g1.curveF.AssertIsEqual(&res.Y, &c.Res.Y)
+	return nil
+}
+
+func TestHashToG1TestSolve(t *testing.T) {
+	assert := test.NewAssert(t)
+	dst := []byte("QUUX-V01-CS02-with-BN254G1_XMD:SHA-256_SVDW_RO_")
+	for _, msg := range []string{"", "abc"} {
+		res, err := bn254.HashToG1([]byte(msg), dst)
+		assert.NoError(err)
+		circuit := hashToG1Circuit{
+			Msg: make([]uints.U8, len(msg)),
+			dst: dst,
+		}
+		witness := hashToG1Circuit{
+			Msg: uints.NewU8Array([]byte(msg)),
+			Res: NewG1Affine(res),
+		}
+		err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err, "msg %q", msg)
+	}
+}
@@ -0,0 +1,180 @@
+package sw_bn254
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bn254"
+	"github.com/consensys/gnark/std/hash/tofield"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// The constants of the Shallue-van de Woestijne map to the twist of BN254 with
+// Z = 1, as defined in [RFC 9380] Section 6.6.1. The elements of Fp2 are given
+// as pairs (A0, A1) of coordinates.
+//
+// [RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380
+var (
+	// g2SVDWZ is the element Z of the SvdW map.
+	g2SVDWZ = [2]string{"1", "0"}
+	// g2SVDWC1 is c1 = g(Z).
+	g2SVDWC1 = [2]string{
+		"19485874751759354771024239261021720505790618469301721065564631296452457478374",
+		"266929791119991161246907387137283842545076965332900288569378510910307636690",
+	}
+	// g2SVDWC2 is c2 = -Z/2.
+	g2SVDWC2 = [2]string{"10944121435919637611123202872628637544348155578648911831344518947322613104291", "0"}
+	// g2SVDWC3 is c3 = sqrt(-g(Z)·(3Z²+4A)) with sgn0(c3) == 0.
+	g2SVDWC3 = [2]string{
+		"18992192239972082890849143911285057164064277369389217330423471574879236301292",
+		"21819008332247140148575583693947636719449476128975323941588917397607662637108",
+	}
+	// g2SVDWC4 is c4 = -4g(Z)/(3Z²+4A).
+	g2SVDWC4 = [2]string{
+		"10499238450719652342378357227399831140106360636427411350395554762472100376473",
+		"6940174569119770192419592065569379906172001098655407502803841283667998553941",
+	}
+	// g2B is the coefficient B = 3/(9+u) of the twist y² = x³ + B.
+	g2B = [2]string{
+		"19485874751759354771024239261021720505790618469301721065564631296452457478373",
+		"266929791119991161246907387137283842545076965332900288569378510910307636690",
+	}
+)
+
+// MapToG2 maps the element u of Fp2 to a point in G2. It implements the
+// map_to_curve and clear_cofactor steps of the BN254G2_XMD:SHA-256_SVDW_RO_
+// suite of [RFC 9380], i.e. the Shallue-van de Woestijne map to the twist
+// followed by the cofactor clearing using the endomorphism ψ of [FKR11]. The
+// result matches the native [bn254.MapToG2].
+//
+// ⚠️  The exceptional cases of the incomplete additions in the cofactor
+// clearing happen with negligible probability and make the circuit
+// unsatisfiable.
+//
+// [RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380
+// [FKR11]: https://cacr.uwaterloo.ca/techreports/2011/cacr2011-26.pdf
+func (g2 *G2) MapToG2(u *fields_bn254.E2) *G2Affine {
+	p := g2.mapToCurve2(u)
+	return g2.clearCofactor(p)
+}
+
+// HashToG2 hashes the message msg to a point in G2 using the domain separation
+// tag dst. It implements the BN254G2_XMD:SHA-256_SVDW_RO_ suite of [RFC 9380],
+// i.e. the message is hashed to two elements of Fp2 with expand_message_xmd
+// and SHA-256, which are mapped to the twist as in [G2.MapToG2] and added
+// before clearing the cofactor. The result matches the native
+// [bn254.HashToG2].
+//
+// ⚠️  The exceptional cases of the incomplete addition of the two mapped points
+// happen with negligible probability and make the circuit unsatisfiable.
+//
+// [RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380
+func (g2 *G2) HashToG2(msg []uints.U8, dst []byte) (*G2Affine, error) {
+	u, err := tofield.Hash[BaseField](g2.api, msg, dst, 4)
+	if err != nil {
+		return nil, fmt.Errorf("hash to field: %w", err)
+	}
+	q0 := g2.mapToCurve2(&fields_bn254.E2{A0: *u[0], A1: *u[1]})
+	q1 := g2.mapToCurve2(&fields_bn254.E2{A0: *u[2], A1: *u[3]})
+	return g2.clearCofactor(g2.add(q0, q1)), nil
+}
+
+// mapToCurve2 implements the Shallue-van de Woestijne map as in RFC 9380
+// Section 6.6.1.
+func (g2 *G2) mapToCurve2(u *fields_bn254.E2) *G2Affine {
+	z := g2.constE2(g2SVDWZ)
+	c1 := g2.constE2(g2SVDWC1)
+	c2 := g2.constE2(g2SVDWC2)
+	c3 := g2.constE2(g2SVDWC3)
+	c4 := g2.constE2(g2SVDWC4)
+	b := g2.constE2(g2B)
+	one := g2.Ext2.One()
+
+	// tv1 = c1·u², tv2 = 1 + tv1, tv1 = 1 - tv1
+	tv1 := g2.Ext2.Mul(c1, g2.Ext2.Square(u))
+	tv2 := g2.Ext2.Add(one, tv1)
+	tv1 = g2.Ext2.Sub(one, tv1)
+	// tv3 = inv0(tv1·tv2)
+	tv3 := g2.Ext2.Mul(tv1, tv2)
+	tv3IsZero := g2.Ext2.IsZero(tv3)
+	tv3 = g2.Ext2.Inverse(g2.Ext2.Select(tv3IsZero, one, tv3))
+	tv3 = g2.Ext2.Select(tv3IsZero, g2.Ext2.Zero(), tv3)
+	// tv4 = u·tv1·tv3·c3, x1 = c2 - tv4, x2 = c2 + tv4
+	tv4 := g2.Ext2.Mul(g2.Ext2.Mul(u, tv1), g2.Ext2.Mul(tv3, c3))
+	x1 := g2.Ext2.Sub(c2, tv4)
+	x2 := g2.Ext2.Add(c2, tv4)
+	// x3 = c4·(tv2²·tv3)² + Z
+	x3 := g2.Ext2.Mul(g2.Ext2.Square(tv2), tv3)
+	x3 = g2.Ext2.Add(g2.Ext2.Mul(c4, g2.Ext2.Square(x3)), z)
+	// gx = x³ + B
+	gx := func(x *fields_bn254.E2) *fields_bn254.E2 {
+		return g2.Ext2.Add(g2.Ext2.Mul(x, g2.Ext2.Square(x)), b)
+	}
+	gx1 := gx(x1)
+	gx2 := gx(x2)
+	gx3 := gx(x3)
+
+	// As in the G1 case, the hint returns the square roots of either gxᵢ or
+	// ξ·gxᵢ for i = 1, 2, where ξ = 9+u is a non-square, and the square root y
+	// of g(x) with sgn0(y) == sgn0(u).
+	hint, err := g2.fp.NewHint(svdwSqrtG2Hint, 6, &gx1.A0, &gx1.A1, &gx2.A0, &gx2.A1, &gx3.A0, &gx3.A1, &u.A0, &u.A1)
+	if err != nil {
+		panic(err)
+	}
+	isSquare := func(gx, s *fields_bn254.E2) frontend.Variable {
+		ss := g2.Ext2.Square(s)
+		res := g2.Ext2.IsZero(g2.Ext2.Sub(ss, gx))
+		g2.Ext2.AssertIsEqual(ss, g2.Ext2.Select(res, gx, g2.Ext2.MulByNonResidue(gx)))
+		return res
+	}
+	isGx1Square := isSquare(gx1, &fields_bn254.E2{A0: *hint[0], A1: *hint[1]})
+	isGx2Square := isSquare(gx2, &fields_bn254.E2{A0: *hint[2], A1: *hint[3]})
+	x := g2.Ext2.Select(isGx2Square, x2, x3)
+	x = g2.Ext2.Select(isGx1Square, x1, x)
+	y := &fields_bn254.E2{A0: *hint[4], A1: *hint[5]}
+	g2.Ext2.AssertIsEqual(g2.Ext2.Square(y), gx(x))
+	g2.api.AssertIsEqual(g2.sgn0(y), g2.sgn0(u))
+
+	return &G2Affine{
+		P: g2AffP{X: *x, Y: *y},
+	}
+}
+
+// clearCofactor computes [h_eff]p using the decomposition of [FKR11] (Section
+// 6.1):
+//
+//	[x₀]p + ψ([3x₀]p) + ψ²([x₀]p) + ψ³(p)
+//
+// where x₀ is the seed of the curve.
+//
+// [FKR11]: https://cacr.uwaterloo.ca/techreports/2011/cacr2011-26.pdf
+func (g2 *G2) clearCofactor(p *G2Affine) *G2Affine {
+	xp := g2.scalarMulBySeed(p)
+	res := g2.add(xp, g2.psi(g2.add(g2.double(xp), xp)))
+	res = g2.add(res, g2.psi(g2.psi(xp)))
+	return g2.add(res, g2.psi(g2.psi(g2.psi(p))))
+}
+
+// sgn0 returns the sign of the element x as defined in RFC 9380 Section 4.1,
+// i.e. sgn0(x.A0) OR (x.A0 == 0 AND sgn0(x.A1)).
+func (g2 *G2) sgn0(x *fields_bn254.E2) frontend.Variable {
+	sgn0 := func(x *emulated.Element[BaseField]) frontend.Variable {
+		x = g2.fp.Reduce(x)
+		g2.fp.AssertIsInRange(x)
+		return g2.fp.ToBits(x)[0]
+	}
+	sign0 := sgn0(&x.A0)
+	zero0 := g2.fp.IsZero(&x.A0)
+	sign1 := sgn0(&x.A1)
+	// when x.A0 == 0 then sgn0(x.A0) == 0, so the OR is a sum.
+	return g2.api.Add(sign0, g2.api.Mul(zero0, sign1))
+}
+
+// constE2 returns the constant element of Fp2 with coordinates c.
+func (g2 *G2) constE2(c [2]string) *fields_bn254.E2 {
+	return &fields_bn254.E2{
+		A0: *g2.fp.NewElement(c[0]),
+		A1: *g2.fp.NewElement(c[1]),
+	}
+}
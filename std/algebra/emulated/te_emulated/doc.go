@@ -0,0 +1,24 @@
+/*
+Package te_emulated implements elliptic curve group operations in twisted
+Edwards form.
+
+The elliptic curve is the set of points (X,Y) satisfying the equation:
+
+	aX² + Y² = 1 + dX²Y²
+
+over some base field 𝐅p for some constants a, d ∈ 𝐅p. Additionally, for every
+curve we also define its generator (base point) G. All these parameters are
+stored in the variable of type [CurveParams].
+
+When a is a square and d is a non-square in 𝐅p, the addition law is complete
+and the identity element is (0,1). The methods [Curve.Add] and [Curve.Double]
+can be used without handling any exceptional cases. The package provides the
+parameters of the Ed25519 curve, see [GetEd25519Params].
+
+This package uses field emulation (unlike package
+[github.com/consensys/gnark/std/algebra/native/twistededwards], which defines
+the curves over the native field). This allows to use any twisted Edwards curve
+over any native (SNARK) field. The drawback of this approach is the extreme cost
+of the operations.
+*/
+package te_emulated
@@ -0,0 +1,37 @@
+package te_emulated
+
+import (
+	"math/big"
+)
+
+// CurveParams defines parameters of an elliptic curve in twisted Edwards form
+// given by the equation
+//
+//	aX² + Y² = 1 + dX²Y²
+//
+// The base point is defined by (Gx, Gy).
+type CurveParams struct {
+	A        *big.Int // a in curve equation
+	D        *big.Int // d in curve equation
+	Gx       *big.Int // base point x
+	Gy       *big.Int // base point y
+	Cofactor *big.Int // cofactor of the prime order subgroup
+}
+
+// GetEd25519Params returns the curve parameters for the twisted Edwards curve
+// edwards25519 used in the Ed25519 signature scheme (RFC 8032). When
+// initialising new curve, use the base field emparams.Curve25519Fp and scalar
+// field emparams.Curve25519Fr.
+func GetEd25519Params() CurveParams {
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	d, _ := new(big.Int).SetString("37095705934669439343138083508754565189542113879843219016388785533085940283555", 10)
+	gx, _ := new(big.Int).SetString("15112221349535400772501151409588531511454012693041857206046113283949847762202", 10)
+	gy, _ := new(big.Int).SetString("46316835694926478169428394003475163141307993866256225615783033603165251855960", 10)
+	return CurveParams{
+		A:        new(big.Int).Sub(p, big.NewInt(1)),
+		D:        d,
+		Gx:       gx,
+		Gy:       gy,
+		Cofactor: big.NewInt(8),
+	}
+}
@@ -0,0 +1,262 @@
+package te_emulated
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// New returns a new [Curve] instance over the base field Base and scalar field
+// Scalars defined by the curve parameters params. It returns an error if
+// initialising the field emulation fails (for example, when the native field is
+// too small).
+func New[Base, Scalars emulated.FieldParams](api frontend.API, params CurveParams) (*Curve[Base, Scalars], error) {
+	ba, err := emulated.NewField[Base](api)
+	if err != nil {
+		return nil, fmt.Errorf("new base api: %w", err)
+	}
+	sa, err := emulated.NewField[Scalars](api)
+	if err != nil {
+		return nil, fmt.Errorf("new scalar api: %w", err)
+	}
+	var fp Base
+	minusOne := new(big.Int).Sub(fp.Modulus(), big.NewInt(1))
+	return &Curve[Base, Scalars]{
+		params:    params,
+		api:       api,
+		baseApi:   ba,
+		scalarApi: sa,
+		g: AffinePoint[Base]{
+			X: emulated.ValueOf[Base](params.Gx),
+			Y: emulated.ValueOf[Base](params.Gy),
+		},
+		a:           emulated.ValueOf[Base](params.A),
+		d:           emulated.ValueOf[Base](params.D),
+		aIsMinusOne: new(big.Int).Mod(params.A, fp.Modulus()).Cmp(minusOne) == 0,
+	}, nil
+}
+
+// Curve is an initialised curve which allows performing group operations.
+type Curve[Base, Scalars emulated.FieldParams] struct {
+	// params is the parameters of the curve
+	params CurveParams
+	// api is the native api, we construct it ourselves to be sure
+	api frontend.API
+	// baseApi is the api for point operations
+	baseApi *emulated.Field[Base]
+	// scalarApi is the api for scalar operations
+	scalarApi *emulated.Field[Scalars]
+
+	// g is the generator (base point) of the curve.
+	g AffinePoint[Base]
+
+	a           emulated.Element[Base]
+	d           emulated.Element[Base]
+	aIsMinusOne bool
+}
+
+// AffinePoint represents a point on the elliptic curve. We do not check that
+// the point is actually on the curve.
+//
+// Point (0,1) represents the identity element.
+type AffinePoint[Base emulated.FieldParams] struct {
+	X, Y emulated.Element[Base]
+}
+
+// Params returns the parameters of the curve.
+func (c *Curve[B, S]) Params() CurveParams {
+	return c.params
+}
+
+// Generator returns the base point of the curve. The method does not copy and
+// modifying the returned element leads to undefined behaviour!
+func (c *Curve[B, S]) Generator() *AffinePoint[B] {
+	return &c.g
+}
+
+// Identity returns the identity element (0,1) of the curve.
+func (c *Curve[B, S]) Identity() *AffinePoint[B] {
+	return &AffinePoint[B]{
+		X: *c.baseApi.Zero(),
+		Y: *c.baseApi.One(),
+	}
+}
+
+// Neg returns an inverse of p. It doesn't modify p.
+func (c *Curve[B, S]) Neg(p *AffinePoint[B]) *AffinePoint[B] {
+	return &AffinePoint[B]{
+		X: *c.baseApi.Neg(&p.X),
+		Y: p.Y,
+	}
+}
+
+// AssertIsEqual asserts that p and q are the same point.
+func (c *Curve[B, S]) AssertIsEqual(p, q *AffinePoint[B]) {
+	c.baseApi.AssertIsEqual(&p.X, &q.X)
+	c.baseApi.AssertIsEqual(&p.Y, &q.Y)
+}
+
+// AssertIsOnCurve asserts that p satisfies the curve equation
+//
+//	aX² + Y² = 1 + dX²Y²
+func (c *Curve[B, S]) AssertIsOnCurve(p *AffinePoint[B]) {
+	xx := c.baseApi.Mul(&p.X, &p.X)
+	yy := c.baseApi.Mul(&p.Y, &p.Y)
+	lhs := c.baseApi.Add(c.mulByA(xx), yy)
+	rhs := c.baseApi.Mul(&c.d, c.baseApi.Mul(xx, yy))
+	rhs = c.baseApi.Add(rhs, c.baseApi.One())
+	c.baseApi.AssertIsEqual(lhs, rhs)
+}
+
+// Add adds p and q and returns it. It doesn't modify p nor q.
+//
+// It uses the unified affine addition formulas
+//
+//	x3 = (x1y2 + y1x2) / (1 + dx1x2y1y2)
+//	y3 = (y1y2 - ax1x2) / (1 - dx1x2y1y2)
+//
+// which are complete when a is a square and d is a non-square in the base
+// field. In particular, p and q can be equal or the identity.
+func (c *Curve[B, S]) Add(p, q *AffinePoint[B]) *AffinePoint[B] {
+	x1y2 := c.baseApi.Mul(&p.X, &q.Y)
+	y1x2 := c.baseApi.Mul(&p.Y, &q.X)
+	x1x2 := c.baseApi.Mul(&p.X, &q.X)
+	y1y2 := c.baseApi.Mul(&p.Y, &q.Y)
+	dxy := c.baseApi.Mul(&c.d, c.baseApi.Mul(x1x2, y1y2))
+	one := c.baseApi.One()
+
+	x := c.baseApi.Div(
+		c.baseApi.Add(x1y2, y1x2),
+		c.baseApi.Add(one, dxy),
+	)
+	y := c.baseApi.Div(
+		c.baseApi.Sub(y1y2, c.mulByA(x1x2)),
+		c.baseApi.Sub(one, dxy),
+	)
+	return &AffinePoint[B]{
+		X: *x,
+		Y: *y,
+	}
+}
+
+// Double doubles p and returns it. It doesn't modify p.
+//
+// It uses the formulas
+//
+//	x3 = 2xy / (ax² + y²)
+//	y3 = (y² - ax²) / (2 - ax² - y²)
+//
+// which are obtained from [Curve.Add] by using the curve equation.
+//
+// ⚠️  p must be on the curve.
+func (c *Curve[B, S]) Double(p *AffinePoint[B]) *AffinePoint[B] {
+	xy := c.baseApi.Mul(&p.X, &p.Y)
+	axx := c.mulByA(c.baseApi.Mul(&p.X, &p.X))
+	yy := c.baseApi.Mul(&p.Y, &p.Y)
+	den := c.baseApi.Add(axx, yy)
+
+	x := c.baseApi.Div(
+		c.baseApi.MulConst(xy, big.NewInt(2)),
+		den,
+	)
+	y := c.baseApi.Div(
+		c.baseApi.Sub(yy, axx),
+		c.baseApi.Sub(c.baseApi.NewElement(2), den),
+	)
+	return &AffinePoint[B]{
+		X: *x,
+		Y: *y,
+	}
+}
+
+// Select selects between p and q given the selector b. If b == 1, then returns
+// p and q otherwise.
+func (c *Curve[B, S]) Select(b frontend.Variable, p, q *AffinePoint[B]) *AffinePoint[B] {
+	x := c.baseApi.Select(b, &p.X, &q.X)
+	y := c.baseApi.Select(b, &p.Y, &q.Y)
+	return &AffinePoint[B]{
+		X: *x,
+		Y: *y,
+	}
+}
+
+// Lookup2 performs a 2-bit lookup between i0, i1, i2, i3 based on bits b0
+// and b1. Returns:
+//   - i0 if b0=0 and b1=0,
+//   - i1 if b0=1 and b1=0,
+//   - i2 if b0=0 and b1=1,
+//   - i3 if b0=1 and b1=1.
+func (c *Curve[B, S]) Lookup2(b0, b1 frontend.Variable, i0, i1, i2, i3 *AffinePoint[B]) *AffinePoint[B] {
+	x := c.baseApi.Lookup2(b0, b1, &i0.X, &i1.X, &i2.X, &i3.X)
+	y := c.baseApi.Lookup2(b0, b1, &i0.Y, &i1.Y, &i2.Y, &i3.Y)
+	return &AffinePoint[B]{
+		X: *x,
+		Y: *y,
+	}
+}
+
+// ScalarMul computes [s]p and returns it. It doesn't modify p nor s.
+//
+// The scalar is reduced and decomposed into its canonical binary
+// representation and the multiplication is performed using the double-and-add
+// algorithm.
+//
+// ⚠️  p must be on the curve.
+func (c *Curve[B, S]) ScalarMul(p *AffinePoint[B], s *emulated.Element[S]) *AffinePoint[B] {
+	sBits := c.scalarBits(s)
+	n := len(sBits)
+	res := c.Select(sBits[n-1], p, c.Identity())
+	for i := n - 2; i >= 0; i-- {
+		res = c.Double(res)
+		tmp := c.Add(res, p)
+		res = c.Select(sBits[i], tmp, res)
+	}
+	return res
+}
+
+// ScalarMulBase computes [s]g and returns it, where g is the fixed generator.
+// It doesn't modify s.
+func (c *Curve[B, S]) ScalarMulBase(s *emulated.Element[S]) *AffinePoint[B] {
+	return c.ScalarMul(&c.g, s)
+}
+
+// DoubleBaseScalarMul computes [s1]p1 + [s2]p2 and returns it. It doesn't
+// modify the inputs.
+//
+// It uses the Strauss-Shamir trick and shares the doublings between both
+// scalar multiplications.
+//
+// ⚠️  p1 and p2 must be on the curve.
+func (c *Curve[B, S]) DoubleBaseScalarMul(p1, p2 *AffinePoint[B], s1, s2 *emulated.Element[S]) *AffinePoint[B] {
+	s1Bits := c.scalarBits(s1)
+	s2Bits := c.scalarBits(s2)
+	n := len(s1Bits)
+	p1p2 := c.Add(p1, p2)
+	id := c.Identity()
+	res := c.Lookup2(s1Bits[n-1], s2Bits[n-1], id, p1, p2, p1p2)
+	for i := n - 2; i >= 0; i-- {
+		res = c.Double(res)
+		tmp := c.Lookup2(s1Bits[i], s2Bits[i], id, p1, p2, p1p2)
+		res = c.Add(res, tmp)
+	}
+	return res
+}
+
+// scalarBits returns the canonical binary decomposition of s, least
+// significant bit first, on the bit-length of the scalar field modulus.
+func (c *Curve[B, S]) scalarBits(s *emulated.Element[S]) []frontend.Variable {
+	var fr S
+	sr := c.scalarApi.Reduce(s)
+	c.scalarApi.AssertIsInRange(sr)
+	return c.scalarApi.ToBits(sr)[:fr.Modulus().BitLen()]
+}
+
+// mulByA returns a*x, avoiding the multiplication when a = -1.
+func (c *Curve[B, S]) mulByA(x *emulated.Element[B]) *emulated.Element[B] {
+	if c.aIsMinusOne {
+		return c.baseApi.Neg(x)
+	}
+	return c.baseApi.Mul(&c.a, x)
+}
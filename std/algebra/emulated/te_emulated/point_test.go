@@ -0,0 +1,136 @@
+package te_emulated
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/emulated/emparams"
+	"github.com/consensys/gnark/test"
+)
+
+var testCurve = ecc.BN254
+
+// nativeAdd adds two points of the curve defined by params using the affine
+// addition formulas.
+func nativeAdd(params CurveParams, mod *big.Int, x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	x1x2 := new(big.Int).Mul(x1, x2)
+	y1y2 := new(big.Int).Mul(y1, y2)
+	dxy := new(big.Int).Mul(params.D, x1x2)
+	dxy.Mul(dxy, y1y2).Mod(dxy, mod)
+
+	xn := new(big.Int).Mul(x1, y2)
+	xn.Add(xn, new(big.Int).Mul(y1, x2))
+	xd := new(big.Int).Add(big.NewInt(1), dxy)
+	xd.ModInverse(xd, mod)
+	xn.Mul(xn, xd).Mod(xn, mod)
+
+	yn := new(big.Int).Mul(params.A, x1x2)
+	yn.Sub(y1y2, yn)
+	yd := new(big.Int).Sub(big.NewInt(1), dxy)
+	yd.Mod(yd, mod).ModInverse(yd, mod)
+	yn.Mul(yn, yd).Mod(yn, mod)
+	return xn, yn
+}
+
+func nativeScalarMul(params CurveParams, mod *big.Int, x, y, s *big.Int) (*big.Int, *big.Int) {
+	rx, ry := big.NewInt(0), big.NewInt(1)
+	for i := s.BitLen() - 1; i >= 0; i-- {
+		rx, ry = nativeAdd(params, mod, rx, ry, rx, ry)
+		if s.Bit(i) == 1 {
+			rx, ry = nativeAdd(params, mod, rx, ry, x, y)
+		}
+	}
+	return rx, ry
+}
+
+type AddDoubleTest[T, S emulated.FieldParams] struct {
+	P, Q     AffinePoint[T]
+	Sum, Dbl AffinePoint[T]
+}
+
+func (c *AddDoubleTest[T, S]) Define(api frontend.API) error {
+	cr, err := New[T, S](api, GetEd25519Params())
+	if err != nil {
+		return err
+	}
+	cr.AssertIsOnCurve(&c.P)
+	cr.AssertIsOnCurve(&c.Q)
+	cr.AssertIsEqual(cr.Add(&c.P, &c.Q), &c.Sum)
+	cr.AssertIsEqual(cr.Double(&c.P), &c.Dbl)
+	cr.AssertIsEqual(cr.Add(&c.P, &c.P), &c.Dbl)
+	cr.AssertIsEqual(cr.Add(&c.P, cr.Identity()), &c.P)
+	cr.AssertIsEqual(cr.Add(&c.P, cr.Neg(&c.P)), cr.Identity())
+	return nil
+}
+
+func TestAddDouble(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := GetEd25519Params()
+	var fp emparams.Curve25519Fp
+	mod := fp.Modulus()
+	px, py := nativeScalarMul(params, mod, params.Gx, params.Gy, big.NewInt(3))
+	qx, qy := nativeScalarMul(params, mod, params.Gx, params.Gy, big.NewInt(5))
+	sx, sy := nativeScalarMul(params, mod, params.Gx, params.Gy, big.NewInt(8))
+	dx, dy := nativeScalarMul(params, mod, params.Gx, params.Gy, big.NewInt(6))
+
+	circuit := AddDoubleTest[emparams.Curve25519Fp, emparams.Curve25519Fr]{}
+	witness := AddDoubleTest[emparams.Curve25519Fp, emparams.Curve25519Fr]{
+		P:   AffinePoint[emparams.Curve25519Fp]{X: emulated.ValueOf[emparams.Curve25519Fp](px), Y: emulated.ValueOf[emparams.Curve25519Fp](py)},
+		Q:   AffinePoint[emparams.Curve25519Fp]{X: emulated.ValueOf[emparams.Curve25519Fp](qx), Y: emulated.ValueOf[emparams.Curve25519Fp](qy)},
+		Sum: AffinePoint[emparams.Curve25519Fp]{X: emulated.ValueOf[emparams.Curve25519Fp](sx), Y: emulated.ValueOf[emparams.Curve25519Fp](sy)},
+		Dbl: AffinePoint[emparams.Curve25519Fp]{X: emulated.ValueOf[emparams.Curve25519Fp](dx), Y: emulated.ValueOf[emparams.Curve25519Fp](dy)},
+	}
+	err := test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+	assert.NoError(err)
+}
+
+type ScalarMulTest[T, S emulated.FieldParams] struct {
+	P, Q     AffinePoint[T]
+	S1, S2   emulated.Element[S]
+	Base     AffinePoint[T]
+	Combined AffinePoint[T]
+}
+
+func (c *ScalarMulTest[T, S]) Define(api frontend.API) error {
+	cr, err := New[T, S](api, GetEd25519Params())
+	if err != nil {
+		return err
+	}
+	cr.AssertIsEqual(cr.ScalarMulBase(&c.S1), &c.Base)
+	cr.AssertIsEqual(cr.DoubleBaseScalarMul(&c.P, &c.Q, &c.S1, &c.S2), &c.Combined)
+	return nil
+}
+
+func TestScalarMul(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := GetEd25519Params()
+	var fp emparams.Curve25519Fp
+	var fr emparams.Curve25519Fr
+	mod := fp.Modulus()
+	s1, err := rand.Int(rand.Reader, fr.Modulus())
+	assert.NoError(err)
+	s2, err := rand.Int(rand.Reader, fr.Modulus())
+	assert.NoError(err)
+	px, py := params.Gx, params.Gy
+	qx, qy := nativeScalarMul(params, mod, params.Gx, params.Gy, big.NewInt(7))
+	bx, by := nativeScalarMul(params, mod, px, py, s1)
+	t1x, t1y := nativeScalarMul(params, mod, px, py, s1)
+	t2x, t2y := nativeScalarMul(params, mod, qx, qy, s2)
+	cx, cy := nativeAdd(params, mod, t1x, t1y, t2x, t2y)
+
+	circuit := ScalarMulTest[emparams.Curve25519Fp, emparams.Curve25519Fr]{}
+	witness := ScalarMulTest[emparams.Curve25519Fp, emparams.Curve25519Fr]{
+		P:        AffinePoint[emparams.Curve25519Fp]{X: emulated.ValueOf[emparams.Curve25519Fp](px), Y: emulated.ValueOf[emparams.Curve25519Fp](py)},
+		Q:        AffinePoint[emparams.Curve25519Fp]{X: emulated.ValueOf[emparams.Curve25519Fp](qx), Y: emulated.ValueOf[emparams.Curve25519Fp](qy)},
+		S1:       emulated.ValueOf[emparams.Curve25519Fr](s1),
+		S2:       emulated.ValueOf[emparams.Curve25519Fr](s2),
+		Base:     AffinePoint[emparams.Curve25519Fp]{X: emulated.ValueOf[emparams.Curve25519Fp](bx), Y: emulated.ValueOf[emparams.Curve25519Fp](by)},
+		Combined: AffinePoint[emparams.Curve25519Fp]{X: emulated.ValueOf[emparams.Curve25519Fp](cx), Y: emulated.ValueOf[emparams.Curve25519Fp](cy)},
+	}
+	err = test.IsSolved(&circuit, &witness, testCurve.ScalarField())
+	assert.NoError(err)
+}
@@ -422,18 +422,31 @@ func NewVerifier[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra.
 
 // CheckOpeningProof asserts the validity of the opening proof for the given
 // commitment at point.
-func (v *Verifier[FR, G1El, G2El, GTEl]) CheckOpeningProof(commitment Commitment[G1El], proof OpeningProof[FR, G1El], point emulated.Element[FR], vk VerifyingKey[G1El, G2El]) error {
+//
+// With the option [algopts.WithCompleteArithmetic], the claimed value and the
+// point may be zero and the commitment may be any point of G1, including the
+// point at infinity. The quotient must not be the point at infinity, which the
+// pairing doesn't support.
+func (v *Verifier[FR, G1El, G2El, GTEl]) CheckOpeningProof(commitment Commitment[G1El], proof OpeningProof[FR, G1El], point emulated.Element[FR], vk VerifyingKey[G1El, G2El], opts ...algopts.AlgebraOption) error {
+	cfg, err := algopts.NewConfig(opts...)
+	if err != nil {
+		return fmt.Errorf("apply options: %w", err)
+	}
 
 	// [f(a)]G1 + [-a]([H(α)]G₁) = [f(a) - a*H(α)]G₁
 	pointNeg := v.scalarApi.Neg(&point)
-	totalG1, err := v.curve.MultiScalarMul([]*G1El{&vk.G1, &proof.Quotient}, []*emulated.Element[FR]{&proof.ClaimedValue, pointNeg})
+	totalG1, err := v.curve.MultiScalarMul([]*G1El{&vk.G1, &proof.Quotient}, []*emulated.Element[FR]{&proof.ClaimedValue, pointNeg}, opts...)
 	if err != nil {
 		return fmt.Errorf("check opening proof: %w", err)
 	}
 
 	// [f(a) - a*H(α)]G₁ + [-f(α)]G₁  = [f(a) - f(α) - a*H(α)]G₁
 	commitmentNeg := v.curve.Neg(&commitment.G1El)
-	totalG1 = v.curve.Add(totalG1, commitmentNeg)
+	if cfg.CompleteArithmetic {
+		totalG1 = v.curve.AddUnified(totalG1, commitmentNeg)
+	} else {
+		totalG1 = v.curve.Add(totalG1, commitmentNeg)
+	}
 
 	// e([f(a)-f(α)-a*H(α)]G₁], G₂).e([H(α)]G₁, [α]G₂) == 1
 	if err := v.pairing.PairingCheck(
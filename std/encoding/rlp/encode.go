@@ -0,0 +1,162 @@
+package rlp
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/cmp"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/std/selector"
+)
+
+// EncodeString returns the canonical RLP encoding of the string given by the
+// first length bytes of b, and the length of the encoding. The encoding is
+// returned on len(b)+1+nbBytes(len(b)) bytes, where the bytes after the
+// encoding are zero. It asserts that length <= len(b).
+func (r *RLP) EncodeString(b []uints.U8, length frontend.Variable) (enc []uints.U8, encLen frontend.Variable) {
+	api := r.api
+	if len(b) == 0 {
+		panic("empty input")
+	}
+	payload := make([]frontend.Variable, len(b))
+	for i := range b {
+		payload[i] = b[i].Val
+	}
+	hdr, hdrLen := r.header(0x80, length, len(b))
+	// a single byte less than 0x80 is its own encoding.
+	isSingle := api.Mul(api.IsZero(api.Sub(length, 1)), api.Sub(1, r.geq0x80.Lookup(payload[0])))
+	hdrLen = api.Select(isSingle, 0, hdrLen)
+	return r.concat([][]frontend.Variable{hdr, payload}, []frontend.Variable{hdrLen, length})
+}
+
+// EncodeList returns the canonical RLP encoding of the list of the given
+// items, and the length of the encoding. Every item is given by the first
+// lengths[i] bytes of items[i], which must already be RLP encoded. The
+// encoding is returned on n+1+nbBytes(n) bytes, where n is the sum of the
+// lengths of items and the bytes after the encoding are zero. It asserts that
+// lengths[i] <= len(items[i]).
+func (r *RLP) EncodeList(items [][]uints.U8, lengths []frontend.Variable) (enc []uints.U8, encLen frontend.Variable) {
+	api := r.api
+	if len(items) != len(lengths) {
+		panic("mismatching number of items and lengths")
+	}
+	parts := make([][]frontend.Variable, len(items)+1)
+	partLens := make([]frontend.Variable, len(items)+1)
+	var payloadLen frontend.Variable = 0
+	maxPayloadLen := 0
+	for i := range items {
+		parts[i+1] = make([]frontend.Variable, len(items[i]))
+		for j := range items[i] {
+			parts[i+1][j] = items[i][j].Val
+		}
+		partLens[i+1] = lengths[i]
+		payloadLen = api.Add(payloadLen, lengths[i])
+		maxPayloadLen += len(items[i])
+	}
+	parts[0], partLens[0] = r.header(0xc0, payloadLen, maxPayloadLen)
+	return r.concat(parts, partLens)
+}
+
+// header returns the header for a payload of the given length with the given
+// base prefix (0x80 for strings and 0xc0 for lists), and the length of the
+// header. The header is returned on 1+nbBytes(maxLen) bytes. It asserts that
+// length < 256^nbBytes(maxLen).
+func (r *RLP) header(base int, length frontend.Variable, maxLen int) (hdr []frontend.Variable, hdrLen frontend.Variable) {
+	api := r.api
+	nbLenBytes := nbBytes(maxLen)
+	lenBits := bits.ToBinary(api, length, bits.WithNbDigits(8*nbLenBytes))
+	lenBytes := make([]frontend.Variable, nbLenBytes)
+	for t := range lenBytes {
+		// big-endian
+		lenBytes[t] = bits.FromBinary(api, lenBits[8*(nbLenBytes-1-t):8*(nbLenBytes-t)])
+	}
+	// number of bytes of the length without the leading zeros
+	var nbSignificant, seen frontend.Variable = 0, 0
+	for t := range lenBytes {
+		seen = api.Or(seen, api.Sub(1, api.IsZero(lenBytes[t])))
+		nbSignificant = api.Add(nbSignificant, seen)
+	}
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(8*nbLenBytes+1))
+	isShort := cmp.NewBoundedComparator(api, bound, false).IsLess(length, 56)
+	isLong := api.Sub(1, isShort)
+
+	hdr = make([]frontend.Variable, 1+nbLenBytes)
+	hdr[0] = api.Select(isShort, api.Add(base, length), api.Add(base+55, nbSignificant))
+	// the significant bytes of the length follow the prefix. We look them up
+	// from the big-endian bytes followed by zeros.
+	tbl := logderivlookup.New(api)
+	for t := range lenBytes {
+		tbl.Insert(lenBytes[t])
+	}
+	for range lenBytes {
+		tbl.Insert(0)
+	}
+	for t := range lenBytes {
+		b := tbl.Lookup(api.Add(api.Sub(nbLenBytes, nbSignificant), t))[0]
+		hdr[1+t] = api.Mul(isLong, b)
+	}
+	hdrLen = api.Add(1, api.Mul(isLong, nbSignificant))
+	return hdr, hdrLen
+}
+
+// concat returns the concatenation of the first lengths[i] elements of
+// parts[i] and its length. The result is returned on the sum of the lengths
+// of parts, where the elements after the concatenation are zero. It asserts
+// that lengths[i] <= len(parts[i]).
+func (r *RLP) concat(parts [][]frontend.Variable, lengths []frontend.Variable) (res []uints.U8, resLen frontend.Variable) {
+	api := r.api
+	// we store all parts in a table. The element at position k of the result
+	// is in the table at base[i]+k-start[i], where i is the part containing
+	// k.
+	maxLen := 0
+	for i := range parts {
+		maxLen += len(parts[i])
+	}
+	tbl := logderivlookup.New(api)
+	bases := make([]int, len(parts))
+	starts := make([]frontend.Variable, len(parts))
+	resLen = 0
+	base := 0
+	for i := range parts {
+		mask := lessMask(api, len(parts[i]), lengths[i])
+		for j := range parts[i] {
+			tbl.Insert(api.Mul(mask[j], parts[i][j]))
+		}
+		bases[i] = base
+		base += len(parts[i])
+		starts[i] = resLen
+		resLen = api.Add(resLen, lengths[i])
+	}
+	// positions after the end of the concatenation are in the last part
+	for k := 0; k < maxLen; k++ {
+		tbl.Insert(0)
+	}
+
+	// shifts[k] is base[i]-start[i] for the part i containing k, which we
+	// compute as a telescoping sum over the parts starting at or before k.
+	ones := make([]frontend.Variable, maxLen)
+	for k := range ones {
+		ones[k] = 1
+	}
+	shifts := make([]frontend.Variable, maxLen)
+	for k := range shifts {
+		shifts[k] = 0 // the first part starts at 0 with base 0
+	}
+	var prevShift frontend.Variable = 0
+	for i := 1; i < len(parts); i++ {
+		shift := api.Sub(bases[i], starts[i])
+		delta := api.Sub(shift, prevShift)
+		prevShift = shift
+		started := selector.Partition(api, starts[i], true, ones)
+		for k := range shifts {
+			shifts[k] = api.Add(shifts[k], api.Mul(started[k], delta))
+		}
+	}
+	res = make([]uints.U8, maxLen)
+	for k := range res {
+		res[k] = uints.U8{Val: tbl.Lookup(api.Add(k, shifts[k]))[0]}
+	}
+	return res, resLen
+}
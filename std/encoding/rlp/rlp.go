@@ -0,0 +1,348 @@
+// Package rlp implements in-circuit decoding and encoding of Recursive Length
+// Prefix (RLP) serialized byte streams.
+//
+// RLP is the serialization format used in Ethereum for the block headers,
+// transactions, receipts and trie nodes. An item is either a string (a byte
+// array) or a list of items, which allows nested structures. See the
+// [Ethereum documentation] for the encoding rules.
+//
+// The streams are given as []uints.U8 padded to a maximum length fixed at
+// circuit compile time, with the actual length given at runtime. The decoded
+// items are described by their offsets in the stream, so that nested lists
+// can be decoded in place with [Stream.ListItems]. The decoder asserts that
+// the encoding is canonical, i.e. that every item is encoded in the shortest
+// form, as is required by Ethereum. Otherwise different encodings would
+// decode to the same value.
+//
+// [Ethereum documentation]: https://ethereum.org/en/developers/docs/data-structures-and-encoding/rlp/
+package rlp
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/lookup/logderivlookup"
+	"github.com/consensys/gnark/std/math/cmp"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/selector"
+)
+
+// RLP provides the methods for decoding and encoding RLP byte streams.
+type RLP struct {
+	api      frontend.API
+	rchecker frontend.Rangechecker
+
+	// tables indexed by the first byte of an item.
+	headerLen *byteTable // length of the header
+	shortLen  *byteTable // payload length for the short forms
+	lenOfLen  *byteTable // length of the payload length for the long forms
+	isLong    *byteTable // 1 for the long forms
+	isList    *byteTable // 1 for lists
+
+	// tables indexed by a byte.
+	geq56   *byteTable
+	geq0x80 *byteTable
+}
+
+// New returns a new [RLP] instance.
+func New(api frontend.API) (*RLP, error) {
+	return &RLP{
+		api:      api,
+		rchecker: rangecheck.New(api),
+		headerLen: newByteTable(api, func(b int) int {
+			switch {
+			case b < 0x80:
+				return 0
+			case b <= 0xb7, b >= 0xc0 && b <= 0xf7:
+				return 1
+			case b < 0xc0:
+				return 1 + b - 0xb7
+			default:
+				return 1 + b - 0xf7
+			}
+		}),
+		shortLen: newByteTable(api, func(b int) int {
+			switch {
+			case b < 0x80:
+				return 1
+			case b <= 0xb7:
+				return b - 0x80
+			case b >= 0xc0 && b <= 0xf7:
+				return b - 0xc0
+			default:
+				return 0
+			}
+		}),
+		lenOfLen: newByteTable(api, func(b int) int {
+			switch {
+			case b > 0xb7 && b < 0xc0:
+				return b - 0xb7
+			case b > 0xf7:
+				return b - 0xf7
+			default:
+				return 0
+			}
+		}),
+		isLong:  newByteTable(api, func(b int) int { return boolToInt(b > 0xb7 && b < 0xc0 || b > 0xf7) }),
+		isList:  newByteTable(api, func(b int) int { return boolToInt(b >= 0xc0) }),
+		geq56:   newByteTable(api, func(b int) int { return boolToInt(b >= 56) }),
+		geq0x80: newByteTable(api, func(b int) int { return boolToInt(b >= 0x80) }),
+	}, nil
+}
+
+// Item is a decoded RLP item. The offsets are relative to the start of the
+// stream.
+type Item struct {
+	// Offset is the offset of the first byte of the item.
+	Offset frontend.Variable
+	// PayloadOffset is the offset of the first byte of the payload.
+	PayloadOffset frontend.Variable
+	// PayloadLen is the length of the payload.
+	PayloadLen frontend.Variable
+	// End is the offset of the first byte after the item.
+	End frontend.Variable
+	// IsList is 1 when the item is a list and 0 when it is a string.
+	IsList frontend.Variable
+}
+
+// Stream is an RLP-encoded byte stream of runtime length.
+type Stream struct {
+	r          *RLP
+	data       []frontend.Variable
+	length     frontend.Variable
+	table      *logderivlookup.Table
+	cmp        *cmp.BoundedComparator
+	nbLenBytes int
+}
+
+// NewStream returns a new [Stream] for the first length bytes of data. It
+// asserts that length <= len(data) and that all bytes are in range.
+func (r *RLP) NewStream(data []uints.U8, length frontend.Variable) *Stream {
+	if len(data) < 2 {
+		panic("stream must be at least 2 bytes")
+	}
+	// the payload lengths are encoded on at most nbLenBytes bytes, which
+	// bounds all offsets and lengths for the comparisons.
+	nbLenBytes := nbBytes(len(data))
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(8*nbLenBytes+2))
+	s := &Stream{
+		r:          r,
+		data:       make([]frontend.Variable, len(data)),
+		length:     length,
+		table:      logderivlookup.New(r.api),
+		cmp:        cmp.NewBoundedComparator(r.api, bound, false),
+		nbLenBytes: nbLenBytes,
+	}
+	for i := range data {
+		r.rchecker.Check(data[i].Val, 8)
+		s.data[i] = data[i].Val
+		s.table.Insert(data[i].Val)
+	}
+	// padding for reading the header of an item at the end of the stream
+	for i := 0; i <= nbLenBytes; i++ {
+		s.table.Insert(0)
+	}
+	s.cmp.AssertIsLessEq(length, len(data))
+	return s
+}
+
+// Decode decodes the item spanning the whole stream.
+func (s *Stream) Decode() Item {
+	item := s.decode(0, s.length, 1)
+	s.r.api.AssertIsEqual(item.End, s.length)
+	return item
+}
+
+// Item decodes the item at offset, which must end at or before end.
+func (s *Stream) Item(offset, end frontend.Variable) Item {
+	return s.decode(offset, end, 1)
+}
+
+// ListItems decodes the items of the list. It returns maxItems items, where
+// only the first nbItems are defined. It asserts that list is a list of at
+// most maxItems items.
+func (s *Stream) ListItems(list Item, maxItems int) (items []Item, nbItems frontend.Variable) {
+	api := s.r.api
+	api.AssertIsEqual(list.IsList, 1)
+	items = make([]Item, maxItems)
+	nbItems = 0
+	offset := list.PayloadOffset
+	for k := range items {
+		// after the last item we decode at the end of the list, which is
+		// in the bounds of the table, but do not assert the result.
+		isItem := api.Sub(1, api.IsZero(api.Sub(offset, list.End)))
+		items[k] = s.decode(offset, list.End, isItem)
+		offset = api.Select(isItem, items[k].End, offset)
+		nbItems = api.Add(nbItems, isItem)
+	}
+	api.AssertIsEqual(offset, list.End)
+	return items, nbItems
+}
+
+// Bytes returns the payload of the string item on maxLen bytes, where the
+// bytes after the payload length are zero. It asserts that the item is a
+// string of at most maxLen bytes.
+func (s *Stream) Bytes(item Item, maxLen int) []uints.U8 {
+	s.r.api.AssertIsEqual(item.IsList, 0)
+	return s.extract(item.PayloadOffset, item.PayloadLen, maxLen)
+}
+
+// Raw returns the encoding of the item, including the header, on maxLen
+// bytes, where the bytes after the item are zero. It asserts that the
+// encoding is at most maxLen bytes. It allows for example to hash the
+// encoding of a nested item.
+func (s *Stream) Raw(item Item, maxLen int) []uints.U8 {
+	return s.extract(item.Offset, s.r.api.Sub(item.End, item.Offset), maxLen)
+}
+
+// Slice returns the stream where all bytes not in the encoding of the item
+// are zero. Unlike [Stream.Raw], the bytes of the item stay at their offsets
+// in the stream.
+func (s *Stream) Slice(item Item) []uints.U8 {
+	sliced := selector.Slice(s.r.api, item.Offset, item.End, s.data)
+	res := make([]uints.U8, len(sliced))
+	for i := range sliced {
+		res[i] = uints.U8{Val: sliced[i]}
+	}
+	return res
+}
+
+// Uint returns the unsigned integer encoded big-endian in the string item. It
+// asserts that the item is a string of at most maxBytes bytes without leading
+// zeros, as required for the canonical encoding of integers. maxBytes must be
+// small enough for the integer to fit into a native field element.
+func (s *Stream) Uint(item Item, maxBytes int) frontend.Variable {
+	api := s.r.api
+	if 8*maxBytes >= api.Compiler().FieldBitLen() {
+		panic(fmt.Sprintf("integer of %d bytes does not fit into a field element", maxBytes))
+	}
+	payload := s.Bytes(item, maxBytes)
+	hasPayload := api.Sub(1, api.IsZero(item.PayloadLen))
+	s.assertIf(hasPayload, api.IsZero(payload[0].Val), 0)
+	mask := lessMask(api, maxBytes, item.PayloadLen)
+	var res frontend.Variable = 0
+	for k := range payload {
+		res = api.Select(mask[k], api.Add(api.Mul(res, 256), payload[k].Val), res)
+	}
+	return res
+}
+
+// decode decodes the item at offset. When cond is 1 it asserts that the
+// encoding is canonical and that the item ends at or before end.
+func (s *Stream) decode(offset, end, cond frontend.Variable) Item {
+	api := s.r.api
+	inds := make([]frontend.Variable, s.nbLenBytes+1)
+	for j := range inds {
+		inds[j] = api.Add(offset, j)
+	}
+	bs := s.table.Lookup(inds...)
+	prefix := bs[0]
+	lenOfLen := s.r.lenOfLen.Lookup(prefix)
+	isLong := s.r.isLong.Lookup(prefix)
+
+	// the payload length of the long forms is encoded big-endian on lenOfLen
+	// bytes after the prefix.
+	payloadLen := s.r.shortLen.Lookup(prefix)
+	var acc, isLenOfLen frontend.Variable = 0, 0
+	for j := 1; j <= s.nbLenBytes; j++ {
+		acc = api.Add(api.Mul(acc, 256), bs[j])
+		isLenOfLenJ := api.IsZero(api.Sub(lenOfLen, j))
+		payloadLen = api.Add(payloadLen, api.Mul(isLenOfLenJ, acc))
+		isLenOfLen = api.Add(isLenOfLen, isLenOfLenJ)
+	}
+	// the length of the payload length is at most nbLenBytes. Otherwise, the
+	// payload would not fit into the stream.
+	s.assertIf(cond, isLenOfLen, isLong)
+
+	// canonical encoding: the long forms are only for payloads longer than
+	// 55 bytes and their lengths have no leading zeros. A single byte less
+	// than 0x80 is its own encoding.
+	condLong := api.Mul(cond, isLong)
+	s.assertIf(condLong, api.IsZero(bs[1]), 0)
+	s.assertIf(api.Mul(condLong, api.IsZero(api.Sub(lenOfLen, 1))), s.r.geq56.Lookup(bs[1]), 1)
+	s.assertIf(api.Mul(cond, api.IsZero(api.Sub(prefix, 0x81))), s.r.geq0x80.Lookup(bs[1]), 1)
+
+	payloadOffset := api.Add(offset, s.r.headerLen.Lookup(prefix))
+	itemEnd := api.Add(payloadOffset, payloadLen)
+	s.cmp.AssertIsLessEq(api.Mul(cond, itemEnd), end)
+	return Item{
+		Offset:        offset,
+		PayloadOffset: payloadOffset,
+		PayloadLen:    payloadLen,
+		End:           itemEnd,
+		IsList:        s.r.isList.Lookup(prefix),
+	}
+}
+
+// extract returns the n bytes of the stream starting at start on maxLen
+// bytes. It asserts that n <= maxLen.
+func (s *Stream) extract(start, n frontend.Variable, maxLen int) []uints.U8 {
+	api := s.r.api
+	mask := lessMask(api, maxLen, n)
+	res := make([]uints.U8, maxLen)
+	for k := range res {
+		// we look up the first byte for the positions after the end not to
+		// read outside of the stream.
+		b := s.table.Lookup(api.Mul(mask[k], api.Add(start, k)))[0]
+		res[k] = uints.U8{Val: api.Mul(mask[k], b)}
+	}
+	return res
+}
+
+// assertIf asserts that a == b when cond is 1.
+func (s *Stream) assertIf(cond, a, b frontend.Variable) {
+	s.r.api.AssertIsEqual(s.r.api.Mul(cond, s.r.api.Sub(a, b)), 0)
+}
+
+// lessMask returns a slice of n elements, where the element at i is 1 when
+// i < length and 0 otherwise. It asserts that 0 <= length <= n.
+func lessMask(api frontend.API, n int, length frontend.Variable) []frontend.Variable {
+	if n == 1 {
+		api.AssertIsBoolean(length)
+		return []frontend.Variable{length}
+	}
+	ones := make([]frontend.Variable, n)
+	for i := range ones {
+		ones[i] = 1
+	}
+	return selector.Partition(api, length, false, ones)
+}
+
+// nbBytes returns the number of bytes in the big-endian encoding of n.
+func nbBytes(n int) int {
+	return max((bits.Len(uint(n))+7)/8, 1)
+}
+
+// byteTable is a lookup table of the values of f for all bytes. The table is
+// only created at the first lookup, as the tables without queries would fail
+// at commit.
+type byteTable struct {
+	api frontend.API
+	f   func(b int) int
+	t   *logderivlookup.Table
+}
+
+func newByteTable(api frontend.API, f func(b int) int) *byteTable {
+	return &byteTable{api: api, f: f}
+}
+
+// Lookup returns f(b).
+func (t *byteTable) Lookup(b frontend.Variable) frontend.Variable {
+	if t.t == nil {
+		t.t = logderivlookup.New(t.api)
+		for i := 0; i < 256; i++ {
+			t.t.Insert(t.f(i))
+		}
+	}
+	return t.t.Lookup(b)[0]
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
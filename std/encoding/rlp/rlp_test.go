@@ -0,0 +1,316 @@
+package rlp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+func encodeHeader(base byte, l int) []byte {
+	if l <= 55 {
+		return []byte{base + byte(l)}
+	}
+	var lb []byte
+	for ; l > 0; l >>= 8 {
+		lb = append([]byte{byte(l)}, lb...)
+	}
+	return append([]byte{base + 55 + byte(len(lb))}, lb...)
+}
+
+func encodeString(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return bytes.Clone(b)
+	}
+	return append(encodeHeader(0x80, len(b)), b...)
+}
+
+func encodeList(items ...[]byte) []byte {
+	payload := bytes.Join(items, nil)
+	return append(encodeHeader(0xc0, len(payload)), payload...)
+}
+
+func pad(b []byte, n int) []uints.U8 {
+	res := make([]byte, n)
+	copy(res, b)
+	return uints.NewU8Array(res)
+}
+
+func randBytes(assert *test.Assert, n int) []byte {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	assert.NoError(err)
+	return b
+}
+
+type decodeCircuit struct {
+	Data   []uints.U8
+	Length frontend.Variable
+
+	NbItems   frontend.Variable
+	Short     []uints.U8
+	ShortLen  frontend.Variable
+	Int       frontend.Variable
+	Long      []uints.U8
+	LongLen   frontend.Variable
+	Nested    []uints.U8
+	NestedLen frontend.Variable
+	Single    uints.U8
+}
+
+func (c *decodeCircuit) Define(api frontend.API) error {
+	r, err := New(api)
+	if err != nil {
+		return err
+	}
+	s := r.NewStream(c.Data, c.Length)
+	list := s.Decode()
+	items, nbItems := s.ListItems(list, 6)
+	api.AssertIsEqual(nbItems, c.NbItems)
+
+	assertBytes := func(b, expected []uints.U8) {
+		for i := range expected {
+			api.AssertIsEqual(b[i].Val, expected[i].Val)
+		}
+	}
+	assertBytes(s.Bytes(items[0], len(c.Short)), c.Short)
+	api.AssertIsEqual(items[0].PayloadLen, c.ShortLen)
+	api.AssertIsEqual(s.Uint(items[1], 8), c.Int)
+	assertBytes(s.Bytes(items[2], len(c.Long)), c.Long)
+	api.AssertIsEqual(items[2].PayloadLen, c.LongLen)
+	assertBytes(s.Raw(items[3], len(c.Nested)), c.Nested)
+	api.AssertIsEqual(api.Sub(items[3].End, items[3].Offset), c.NestedLen)
+
+	nested, nbNested := s.ListItems(items[3], 3)
+	api.AssertIsEqual(nbNested, 2)
+	assertBytes(s.Bytes(nested[0], 1), []uints.U8{c.Single})
+	api.AssertIsEqual(nested[1].PayloadLen, 0)
+	return nil
+}
+
+func TestDecode(t *testing.T) {
+	const maxLen = 200
+	assert := test.NewAssert(t)
+	short := []byte("dog")
+	long := randBytes(assert, 60)
+	nested := encodeList(encodeString([]byte{0x61}), encodeString(nil))
+	for _, nbItems := range []int{4, 5} {
+		assert.Run(func(assert *test.Assert) {
+			items := [][]byte{
+				encodeString(short),
+				encodeString([]byte{0x04, 0x00}),
+				encodeString(long),
+				nested,
+			}
+			if nbItems == 5 {
+				// the list payload exceeds 55 bytes in both cases. With a long
+				// list as last item the list length is on two bytes.
+				items = append(items, encodeList(encodeString(randBytes(assert, 90))))
+			}
+			data := encodeList(items...)
+			circuit := decodeCircuit{
+				Data:   make([]uints.U8, maxLen),
+				Short:  make([]uints.U8, 8),
+				Long:   make([]uints.U8, 64),
+				Nested: make([]uints.U8, 8),
+			}
+			witness := decodeCircuit{
+				Data:      pad(data, maxLen),
+				Length:    len(data),
+				NbItems:   nbItems,
+				Short:     pad(short, 8),
+				ShortLen:  len(short),
+				Int:       0x0400,
+				Long:      pad(long, 64),
+				LongLen:   len(long),
+				Nested:    pad(nested, 8),
+				NestedLen: len(nested),
+				Single:    uints.NewU8(0x61),
+			}
+			err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+
+			// wrong length
+			witness.Length = len(data) - 1
+			err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.Error(err)
+		}, fmt.Sprintf("nbItems=%d", nbItems))
+	}
+}
+
+type canonicalCircuit struct {
+	Data   []uints.U8
+	Length frontend.Variable
+	isList bool
+}
+
+func (c *canonicalCircuit) Define(api frontend.API) error {
+	r, err := New(api)
+	if err != nil {
+		return err
+	}
+	s := r.NewStream(c.Data, c.Length)
+	item := s.Decode()
+	if c.isList {
+		s.ListItems(item, 4)
+	}
+	return nil
+}
+
+func TestDecodeCanonical(t *testing.T) {
+	const maxLen = 80
+	assert := test.NewAssert(t)
+	payload56 := randBytes(assert, 56)
+	payload40 := randBytes(assert, 40)
+	for _, tc := range []struct {
+		name   string
+		data   []byte
+		isList bool
+		valid  bool
+	}{
+		{"single byte", []byte{0x05}, false, true},
+		{"short single byte", []byte{0x81, 0x80}, false, true},
+		{"non-canonical single byte", []byte{0x81, 0x05}, false, false},
+		{"long string", append([]byte{0xb8, 56}, payload56...), false, true},
+		{"non-canonical long string", append([]byte{0xb8, 40}, payload40...), false, false},
+		{"leading zero length", append([]byte{0xb9, 0x00, 56}, payload56...), false, false},
+		{"list", []byte{0xc4, 0x83, 0x61, 0x62, 0x63}, true, true},
+		{"item exceeding list", []byte{0xc2, 0x83, 0x61, 0x62, 0x63}, true, false},
+		{"truncated string", []byte{0x83, 0x61, 0x62}, false, false},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			circuit := canonicalCircuit{Data: make([]uints.U8, maxLen), isList: tc.isList}
+			witness := canonicalCircuit{Data: pad(tc.data, maxLen), Length: len(tc.data)}
+			err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			if tc.valid {
+				assert.NoError(err)
+			} else {
+				assert.Error(err)
+			}
+		}, tc.name)
+	}
+}
+
+type encodeStringCircuit struct {
+	In          []uints.U8
+	Length      frontend.Variable
+	Expected    []uints.U8
+	ExpectedLen frontend.Variable
+}
+
+func (c *encodeStringCircuit) Define(api frontend.API) error {
+	r, err := New(api)
+	if err != nil {
+		return err
+	}
+	enc, encLen := r.EncodeString(c.In, c.Length)
+	if len(enc) != len(c.Expected) {
+		return fmt.Errorf("got %d bytes, expected %d", len(enc), len(c.Expected))
+	}
+	for i := range enc {
+		api.AssertIsEqual(enc[i].Val, c.Expected[i].Val)
+	}
+	api.AssertIsEqual(encLen, c.ExpectedLen)
+	return nil
+}
+
+func TestEncodeString(t *testing.T) {
+	const maxLen = 300
+	const encMaxLen = maxLen + 3
+	assert := test.NewAssert(t)
+	for _, in := range [][]byte{
+		nil,
+		{0x00},
+		{0x7f},
+		{0x80},
+		[]byte("dog"),
+		randBytes(assert, 55),
+		randBytes(assert, 56),
+		randBytes(assert, 255),
+		randBytes(assert, 256),
+	} {
+		assert.Run(func(assert *test.Assert) {
+			enc := encodeString(in)
+			circuit := encodeStringCircuit{
+				In:       make([]uints.U8, maxLen),
+				Expected: make([]uints.U8, encMaxLen),
+			}
+			witness := encodeStringCircuit{
+				In:          pad(in, maxLen),
+				Length:      len(in),
+				Expected:    pad(enc, encMaxLen),
+				ExpectedLen: len(enc),
+			}
+			err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, fmt.Sprintf("len=%d", len(in)))
+	}
+}
+
+type encodeListCircuit struct {
+	Items       [][]uints.U8
+	Lengths     []frontend.Variable
+	Expected    []uints.U8
+	ExpectedLen frontend.Variable
+}
+
+func (c *encodeListCircuit) Define(api frontend.API) error {
+	r, err := New(api)
+	if err != nil {
+		return err
+	}
+	enc, encLen := r.EncodeList(c.Items, c.Lengths)
+	for i := range c.Expected {
+		api.AssertIsEqual(enc[i].Val, c.Expected[i].Val)
+	}
+	api.AssertIsEqual(encLen, c.ExpectedLen)
+
+	// the encoding decodes back to the items
+	s := r.NewStream(enc, encLen)
+	items, nbItems := s.ListItems(s.Decode(), len(c.Items))
+	api.AssertIsEqual(nbItems, len(c.Items))
+	for i := range items {
+		api.AssertIsEqual(api.Sub(items[i].End, items[i].Offset), c.Lengths[i])
+	}
+	return nil
+}
+
+func TestEncodeList(t *testing.T) {
+	const maxItemLen = 40
+	assert := test.NewAssert(t)
+	for _, lengths := range [][]int{
+		{0, 1, 3},
+		{20, 20, 10},
+		{39, 39, 39},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			items := make([][]byte, len(lengths))
+			circuit := encodeListCircuit{
+				Items:   make([][]uints.U8, len(lengths)),
+				Lengths: make([]frontend.Variable, len(lengths)),
+			}
+			witness := encodeListCircuit{
+				Items:   make([][]uints.U8, len(lengths)),
+				Lengths: make([]frontend.Variable, len(lengths)),
+			}
+			for i, l := range lengths {
+				items[i] = encodeString(randBytes(assert, l))
+				circuit.Items[i] = make([]uints.U8, maxItemLen)
+				witness.Items[i] = pad(items[i], maxItemLen)
+				witness.Lengths[i] = len(items[i])
+			}
+			enc := encodeList(items...)
+			encMaxLen := len(lengths)*maxItemLen + 2
+			circuit.Expected = make([]uints.U8, encMaxLen)
+			witness.Expected = pad(enc, encMaxLen)
+			witness.ExpectedLen = len(enc)
+			err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, fmt.Sprintf("lengths=%v", lengths))
+	}
+}
@@ -0,0 +1,24 @@
+package evmprecompiles
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/ripemd160"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// RIPEMD160 implements [RIPEMD160] precompile contract at address 0x03.
+//
+// It returns the 20-byte digest of the input. The EVM left-pads the digest with
+// zeros to 32 bytes, it is up to the caller to do so if needed.
+//
+// [RIPEMD160]: https://ethereum.github.io/execution-specs/autoapi/ethereum/paris/vm/precompiled_contracts/ripemd160/index.html
+func RIPEMD160(api frontend.API, msg []uints.U8) []uints.U8 {
+	h, err := ripemd160.New(api)
+	if err != nil {
+		panic(fmt.Sprintf("new ripemd160: %v", err))
+	}
+	h.Write(msg)
+	return h.Sum()
+}
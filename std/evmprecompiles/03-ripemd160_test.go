@@ -0,0 +1,44 @@
+package evmprecompiles
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+type ripemd160Circuit struct {
+	In       []uints.U8
+	Expected [20]uints.U8
+}
+
+func (c *ripemd160Circuit) Define(api frontend.API) error {
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		return err
+	}
+	res := RIPEMD160(api, c.In)
+	for i := range c.Expected {
+		uapi.ByteAssertEq(c.Expected[i], res[i])
+	}
+	return nil
+}
+
+func TestRIPEMD160(t *testing.T) {
+	assert := test.NewAssert(t)
+	// test vector from the Ethereum precompile tests. The expected output is
+	// given without the 12 zero bytes of left-padding.
+	in, err := hex.DecodeString("38d18acb67d25c8bb9942764b62f18e17054f66a817bd4295423adf9ed98873e000000000000000000000000000000000000000000000000000000000000001b38d18acb67d25c8bb9942764b62f18e17054f66a817bd4295423adf9ed98873e789d1dd423d25f0772d2748d60f7e4b81bb14d086eba8e8e8efb6dcff8a4ae02")
+	assert.NoError(err)
+	expected, err := hex.DecodeString("9215b8d9882ff46f0dfde6684d78e831467f65e6")
+	assert.NoError(err)
+	witness := ripemd160Circuit{
+		In: uints.NewU8Array(in),
+	}
+	copy(witness.Expected[:], uints.NewU8Array(expected))
+	err = test.IsSolved(&ripemd160Circuit{In: make([]uints.U8, len(in))}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
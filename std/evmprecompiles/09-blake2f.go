@@ -0,0 +1,28 @@
+package evmprecompiles
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/std/permutation/blake2b"
+)
+
+// BLAKE2F implements [BLAKE2F] precompile contract at address 0x09.
+//
+// It applies rounds rounds of the BLAKE2b compression function on the state
+// vector h, message block m and offset counters t. The final block indicator f
+// must be boolean. The words are little-endian as in the precompile input
+// encoding.
+//
+// As the number of rounds defines the size of the circuit, it has to be known
+// at compile time.
+//
+// [BLAKE2F]: https://eips.ethereum.org/EIPS/eip-152
+func BLAKE2F(api frontend.API, rounds int, h [8]uints.U64, m [16]uints.U64, t [2]uints.U64, f frontend.Variable) [8]uints.U64 {
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		panic(fmt.Sprintf("new uints api: %v", err))
+	}
+	return blake2b.Compress(api, uapi, rounds, h, m, t, f)
+}
@@ -0,0 +1,103 @@
+package evmprecompiles
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+type blake2fCircuit struct {
+	rounds   int
+	H        [8]uints.U64
+	M        [16]uints.U64
+	T        [2]uints.U64
+	F        frontend.Variable
+	Expected [8]uints.U64
+}
+
+func (c *blake2fCircuit) Define(api frontend.API) error {
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		return err
+	}
+	res := BLAKE2F(api, c.rounds, c.H, c.M, c.T, c.F)
+	for i := range c.Expected {
+		uapi.AssertEq(c.Expected[i], res[i])
+	}
+	return nil
+}
+
+// parseBLAKE2FInput decodes the precompile input encoding
+//
+//	rounds (4 bytes, big-endian) || h (64 bytes) || m (128 bytes) || t (16 bytes) || f (1 byte)
+//
+// into the circuit witness. The words are little-endian.
+func parseBLAKE2FInput(input, output string) (*blake2fCircuit, error) {
+	in, err := hex.DecodeString(input)
+	if err != nil {
+		return nil, err
+	}
+	out, err := hex.DecodeString(output)
+	if err != nil {
+		return nil, err
+	}
+	if len(in) != 213 || len(out) != 64 {
+		return nil, fmt.Errorf("invalid test vector length")
+	}
+	w := &blake2fCircuit{
+		rounds: int(binary.BigEndian.Uint32(in[:4])),
+		F:      in[212],
+	}
+	word := func(b []byte, i int) uints.U64 {
+		return uints.NewU64(binary.LittleEndian.Uint64(b[8*i:]))
+	}
+	for i := range w.H {
+		w.H[i] = word(in[4:68], i)
+		w.Expected[i] = word(out, i)
+	}
+	for i := range w.M {
+		w.M[i] = word(in[68:196], i)
+	}
+	for i := range w.T {
+		w.T[i] = word(in[196:212], i)
+	}
+	return w, nil
+}
+
+func TestBLAKE2F(t *testing.T) {
+	assert := test.NewAssert(t)
+	// test vectors 4-7 from EIP-152
+	vectors := []struct {
+		in, out string
+	}{
+		{
+			"0000000048c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b61626300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000001",
+			"08c9bcf367e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d282e6ad7f520e511f6c3e2b8c68059b9442be0454267ce079217e1319cde05b",
+		},
+		{
+			"0000000c48c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b61626300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000001",
+			"ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923",
+		},
+		{
+			"0000000c48c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b61626300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000000",
+			"75ab69d3190a562c51aef8d88f1c2775876944407270c42c9844252c26d2875298743e7f6d5ea2f2d3e8d226039cd31b4e426ac4f2d3d666a610c2116fde4735",
+		},
+		{
+			"0000000148c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b61626300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000001",
+			"b63a380cb2897d521994a85234ee2c181b5f844d2c624c002677e9703449d2fba551b3a8333bcdf5f2f7e08993d53923de3d64fcc68c034e717b9293fed7a421",
+		},
+	}
+	for i, v := range vectors {
+		witness, err := parseBLAKE2FInput(v.in, v.out)
+		assert.NoError(err)
+		circuit := &blake2fCircuit{rounds: witness.rounds}
+		err = test.IsSolved(circuit, witness, ecc.BN254.ScalarField())
+		assert.NoError(err, "vector %d", i)
+	}
+}
@@ -0,0 +1,222 @@
+package evmprecompiles
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	fr_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	kzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/algopts"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/commitments/kzg"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+const (
+	// blobCommitmentVersionKZG is the version byte of the versioned hash of
+	// a KZG commitment.
+	blobCommitmentVersionKZG = 0x01
+	// fieldElementsPerBlob is the number of field elements in a blob.
+	fieldElementsPerBlob = 4096
+)
+
+// kzgSetupG2Tau is the compressed [τ]G₂ of the Ethereum KZG ceremony trusted
+// setup used by EIP-4844.
+const kzgSetupG2Tau = "b5bfd7dd8cdeb128843bc287230af38926187075cbfbefa81009a2ce615ac53d2914e5870cb452d2afaaab24f3499f72185cbfee53492714734429b7b38608e23926c911cceceac9a36851477ba4c60b087041de621000edc98edada20c1def2"
+
+// kzgSetupG1Tau is the compressed [τ]G₁ of the trusted setup, i.e. Σᵢ ωⁱ[Lᵢ(τ)]G₁
+// for the Lagrange basis of the setup.
+const kzgSetupG1Tau = "ad3eb50121139aa34db1d545093ac9374ab7bca2c0f3bf28e27c8dcd8fc7cb42d25926fc0c97b336e9f0fb35e5a04c81"
+
+// kzgSetup is the part of a KZG trusted setup used by the precompile.
+type kzgSetup struct {
+	vk    kzg_bls12381.VerifyingKey
+	tauG1 bls12381.G1Affine
+}
+
+// ethereumKZGSetup returns the Ethereum KZG ceremony trusted setup.
+func ethereumKZGSetup() kzgSetup {
+	var setup kzgSetup
+	tauG2, err := hex.DecodeString(kzgSetupG2Tau)
+	if err != nil {
+		panic(fmt.Sprintf("decode trusted setup: %v", err))
+	}
+	if _, err := setup.vk.G2[1].SetBytes(tauG2); err != nil {
+		panic(fmt.Sprintf("decode trusted setup: %v", err))
+	}
+	tauG1, err := hex.DecodeString(kzgSetupG1Tau)
+	if err != nil {
+		panic(fmt.Sprintf("decode trusted setup: %v", err))
+	}
+	if _, err := setup.tauG1.SetBytes(tauG1); err != nil {
+		panic(fmt.Sprintf("decode trusted setup: %v", err))
+	}
+	_, _, setup.vk.G1, setup.vk.G2[0] = bls12381.Generators()
+	return setup
+}
+
+// KZGPointEvaluation implements [KZG_POINT_EVALUATION] precompile contract at
+// address 0x0a.
+//
+// The inputs are the 32-byte versioned hash of the commitment, the 32-byte
+// big-endian evaluation point z and claimed value y and the 48-byte compressed
+// commitment and opening proof, as in the precompile input encoding. It
+// asserts that:
+//   - the versioned hash is the version byte 0x01 followed by the last 31
+//     bytes of SHA-256 of the commitment,
+//   - z and y are canonical elements of the BLS12-381 scalar field,
+//   - the commitment and the proof are valid encodings of points in G1,
+//   - the proof attests that the polynomial committed to evaluates to y at z
+//     using the Ethereum KZG ceremony trusted setup.
+//
+// The opening proof is checked with [kzg.Verifier.CheckOpeningProof].
+//
+// It returns the 64-byte precompile output, which is the number of field
+// elements in a blob followed by the scalar field modulus, both as 32-byte
+// big-endian integers.
+//
+// [KZG_POINT_EVALUATION]: https://eips.ethereum.org/EIPS/eip-4844#point-evaluation-precompile
+func KZGPointEvaluation(api frontend.API, versionedHash [32]uints.U8, evaluationPoint, claimedValue [32]uints.U8, commitment, proof [48]uints.U8) [64]uints.U8 {
+	return kzgPointEvaluation(api, ethereumKZGSetup(), versionedHash, evaluationPoint, claimedValue, commitment, proof)
+}
+
+// kzgPointEvaluation implements [KZGPointEvaluation] for the given trusted
+// setup.
+func kzgPointEvaluation(api frontend.API, setup kzgSetup, versionedHash [32]uints.U8, evaluationPoint, claimedValue [32]uints.U8, commitment, proof [48]uints.U8) [64]uints.U8 {
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		panic(fmt.Sprintf("new uints api: %v", err))
+	}
+	baseApi, err := emulated.NewField[sw_bls12381.BaseField](api)
+	if err != nil {
+		panic(fmt.Sprintf("new base api: %v", err))
+	}
+	scalarApi, err := emulated.NewField[sw_bls12381.ScalarField](api)
+	if err != nil {
+		panic(fmt.Sprintf("new scalar api: %v", err))
+	}
+	curve, err := sw_emulated.New[sw_bls12381.BaseField, sw_bls12381.ScalarField](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		panic(fmt.Sprintf("new curve: %v", err))
+	}
+	pairing, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		panic(fmt.Sprintf("new pairing: %v", err))
+	}
+	verifier, err := kzg.NewVerifier[sw_bls12381.ScalarField, sw_bls12381.G1Affine, sw_bls12381.G2Affine, sw_bls12381.GTEl](api)
+	if err != nil {
+		panic(fmt.Sprintf("new kzg verifier: %v", err))
+	}
+	vk, err := kzg.ValueOfVerifyingKeyFixed[sw_bls12381.G1Affine, sw_bls12381.G2Affine](setup.vk)
+	if err != nil {
+		panic(fmt.Sprintf("kzg verifying key: %v", err))
+	}
+	h, err := sha2.New(api)
+	if err != nil {
+		panic(fmt.Sprintf("new sha2: %v", err))
+	}
+
+	// versioned hash = 0x01 || SHA-256(commitment)[1:]
+	h.Write(commitment[:])
+	dgst := h.Sum()
+	uapi.ByteAssertEq(versionedHash[0], uints.NewU8(blobCommitmentVersionKZG))
+	for i := 1; i < len(versionedHash); i++ {
+		uapi.ByteAssertEq(versionedHash[i], dgst[i])
+	}
+
+	// z and y must be canonical
+	z := scalarApi.FromBits(bigEndianBits(api, evaluationPoint[:])...)
+	scalarApi.AssertIsInRange(z)
+	y := scalarApi.FromBits(bigEndianBits(api, claimedValue[:])...)
+	scalarApi.AssertIsInRange(y)
+
+	c, _ := decodeCompressedG1(api, baseApi, curve, pairing, commitment)
+	q, qIsInf := decodeCompressedG1(api, baseApi, curve, pairing, proof)
+
+	// The pairing doesn't support the point at infinity, which is the proof
+	// of the openings of the constant polynomials. In that case the proof is
+	// valid if C = [y]G₁, and we check instead the opening at 0 of the
+	// polynomial X, whose commitment is [τ]G₁ and quotient is G₁.
+	yG := curve.ScalarMulBase(y, algopts.WithCompleteArithmetic())
+	curve.AssertIsEqual(curve.Select(qIsInf, c, yG), yG)
+	tauG1 := sw_bls12381.NewG1Affine(setup.tauG1)
+	zero := scalarApi.Zero()
+	if err := verifier.CheckOpeningProof(
+		kzg.Commitment[sw_bls12381.G1Affine]{G1El: *curve.Select(qIsInf, &tauG1, c)},
+		kzg.OpeningProof[sw_bls12381.ScalarField, sw_bls12381.G1Affine]{
+			Quotient:     *curve.Select(qIsInf, curve.Generator(), q),
+			ClaimedValue: *scalarApi.Select(qIsInf, zero, y),
+		},
+		*scalarApi.Select(qIsInf, zero, z),
+		vk,
+		algopts.WithCompleteArithmetic(),
+	); err != nil {
+		panic(fmt.Sprintf("check opening proof: %v", err))
+	}
+
+	var ret [64]uints.U8
+	var buf [32]byte
+	big.NewInt(fieldElementsPerBlob).FillBytes(buf[:])
+	copy(ret[:32], uints.NewU8Array(buf[:]))
+	fr_bls12381.Modulus().FillBytes(buf[:])
+	copy(ret[32:], uints.NewU8Array(buf[:]))
+	return ret
+}
+
+// decodeCompressedG1 decodes the 48-byte compressed encoding of a BLS12-381 G1
+// point and asserts that it is valid and in the prime order subgroup. The
+// encoding follows the ZCash serialization format, where the three most
+// significant bits of the first byte are the compression, infinity and sign
+// flags. It returns the point and a boolean indicating whether it is the point
+// at infinity, represented as (0,0).
+func decodeCompressedG1(api frontend.API, baseApi *emulated.Field[sw_bls12381.BaseField], curve *sw_emulated.Curve[sw_bls12381.BaseField, sw_bls12381.ScalarField], pairing *sw_bls12381.Pairing, enc [48]uints.U8) (*sw_bls12381.G1Affine, frontend.Variable) {
+	encBits := bigEndianBits(api, enc[:])
+	compressed, isInf, sign := encBits[383], encBits[382], encBits[381]
+	api.AssertIsEqual(compressed, 1)
+
+	x := baseApi.FromBits(encBits[:381]...)
+	baseApi.AssertIsInRange(x)
+	// the point at infinity is encoded with all other bits zero.
+	api.AssertIsEqual(api.Mul(isInf, sign), 0)
+	api.AssertIsEqual(api.Mul(isInf, api.Sub(1, baseApi.IsZero(x))), 0)
+
+	// y² = x³ + 4. In case of the point at infinity x = 0 and the square root
+	// exists.
+	y2 := baseApi.Mul(x, baseApi.Mul(x, x))
+	y2 = baseApi.Add(y2, baseApi.NewElement(4))
+	y := baseApi.Sqrt(y2)
+	// the sign flag is set if y is lexicographically largest, i.e. y > (p-1)/2.
+	// As p is odd, this is equivalent to 2y mod p being odd.
+	largest := func(y *emulated.Element[sw_bls12381.BaseField]) frontend.Variable {
+		dy := baseApi.Reduce(baseApi.MulConst(y, big.NewInt(2)))
+		baseApi.AssertIsInRange(dy)
+		return baseApi.ToBits(dy)[0]
+	}
+	y = baseApi.Select(api.Xor(largest(y), sign), baseApi.Neg(y), y)
+	y = baseApi.Reduce(y)
+	api.AssertIsEqual(api.Mul(api.Sub(1, isInf), api.Sub(largest(y), sign)), 0)
+
+	p := &sw_bls12381.G1Affine{X: *x, Y: *y}
+	// subgroup check is not defined for the point at infinity, check the
+	// generator instead.
+	pairing.AssertIsOnG1(curve.Select(isInf, curve.Generator(), p))
+	p = curve.Select(isInf, &sw_bls12381.G1Affine{X: *baseApi.Zero(), Y: *baseApi.Zero()}, p)
+	return p, isInf
+}
+
+// bigEndianBits returns the little-endian bit decomposition of the big-endian
+// encoded bytes.
+func bigEndianBits(api frontend.API, in []uints.U8) []frontend.Variable {
+	res := make([]frontend.Variable, 0, 8*len(in))
+	for i := len(in) - 1; i >= 0; i-- {
+		res = append(res, bits.ToBinary(api, in[i].Val, bits.WithNbDigits(8))...)
+	}
+	return res
+}
@@ -0,0 +1,153 @@
+package evmprecompiles
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+type kzgPointEvalCircuit struct {
+	setup *kzgSetup
+
+	VersionedHash [32]uints.U8
+	Z, Y          [32]uints.U8
+	Commitment    [48]uints.U8
+	Proof         [48]uints.U8
+	Expected      [64]uints.U8
+}
+
+func (c *kzgPointEvalCircuit) Define(api frontend.API) error {
+	var res [64]uints.U8
+	if c.setup == nil {
+		res = KZGPointEvaluation(api, c.VersionedHash, c.Z, c.Y, c.Commitment, c.Proof)
+	} else {
+		res = kzgPointEvaluation(api, *c.setup, c.VersionedHash, c.Z, c.Y, c.Commitment, c.Proof)
+	}
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		return err
+	}
+	for i := range res {
+		uapi.ByteAssertEq(res[i], c.Expected[i])
+	}
+	return nil
+}
+
+// newKZGPointEvalWitness returns the assignment for the encoded commitment,
+// point, claimed value and proof.
+func newKZGPointEvalWitness(commitment, z, y, proof []byte) *kzgPointEvalCircuit {
+	var w kzgPointEvalCircuit
+	vh := sha256.Sum256(commitment)
+	vh[0] = blobCommitmentVersionKZG
+	copy(w.VersionedHash[:], uints.NewU8Array(vh[:]))
+	copy(w.Z[:], uints.NewU8Array(z))
+	copy(w.Y[:], uints.NewU8Array(y))
+	copy(w.Commitment[:], uints.NewU8Array(commitment))
+	copy(w.Proof[:], uints.NewU8Array(proof))
+	var buf [32]byte
+	big.NewInt(fieldElementsPerBlob).FillBytes(buf[:])
+	copy(w.Expected[:32], uints.NewU8Array(buf[:]))
+	fr.Modulus().FillBytes(buf[:])
+	copy(w.Expected[32:], uints.NewU8Array(buf[:]))
+	return &w
+}
+
+func TestKZGPointEvaluation(t *testing.T) {
+	assert := test.NewAssert(t)
+	srs, err := kzg.NewSRS(16, big.NewInt(-1))
+	assert.NoError(err)
+	poly := make([]fr.Element, 16)
+	for i := range poly {
+		poly[i].SetRandom()
+	}
+	commitment, err := kzg.Commit(poly, srs.Pk)
+	assert.NoError(err)
+	var z fr.Element
+	z.SetRandom()
+	proof, err := kzg.Open(poly, z, srs.Pk)
+	assert.NoError(err)
+	assert.NoError(kzg.Verify(&commitment, &proof, z, srs.Vk))
+
+	circuit := kzgPointEvalCircuit{setup: &kzgSetup{vk: srs.Vk, tauG1: srs.Pk.G1[1]}}
+	cBytes, zBytes, yBytes, pBytes := commitment.Bytes(), z.Bytes(), proof.ClaimedValue.Bytes(), proof.H.Bytes()
+	witness := newKZGPointEvalWitness(cBytes[:], zBytes[:], yBytes[:], pBytes[:])
+	err = test.IsSolved(&circuit, witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	var wrong fr.Element
+	wrong.Add(&proof.ClaimedValue, new(fr.Element).SetOne())
+	wBytes := wrong.Bytes()
+	witness = newKZGPointEvalWitness(cBytes[:], zBytes[:], wBytes[:], pBytes[:])
+	err = test.IsSolved(&circuit, witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
+
+func TestKZGPointEvaluationTrustedSetup(t *testing.T) {
+	assert := test.NewAssert(t)
+	// [τ]G₁ is consistent with [τ]G₂
+	setup := ethereumKZGSetup()
+	var g1Neg bls12381.G1Affine
+	g1Neg.Neg(&setup.vk.G1)
+	ok, err := bls12381.PairingCheck([]bls12381.G1Affine{setup.tauG1, g1Neg}, []bls12381.G2Affine{setup.vk.G2[0], setup.vk.G2[1]})
+	assert.NoError(err)
+	assert.True(ok)
+}
+
+// TestKZGPointEvaluationVectors checks the verify_kzg_proof test vectors of the
+// consensus specifications, from c-kzg-4844 v1.0.0 (tests/verify_kzg_proof).
+// The valid proofs must verify and the incorrect proofs and invalid inputs
+// must not. In short mode only the first vector of each case is checked.
+func TestKZGPointEvaluationVectors(t *testing.T) {
+	assert := test.NewAssert(t)
+	data, err := os.ReadFile("testdata/verify_kzg_proof.json")
+	assert.NoError(err)
+	var vectors []struct {
+		Name                    string
+		Commitment, Z, Y, Proof string
+		Output                  *bool
+	}
+	assert.NoError(json.Unmarshal(data, &vectors))
+
+	seen := make(map[string]bool)
+	for _, v := range vectors {
+		v := v
+		kind := v.Name[:strings.LastIndexByte(v.Name, '_')]
+		if testing.Short() && seen[kind] {
+			continue
+		}
+		seen[kind] = true
+		decode := func(s string, size int) []byte {
+			b, err := hex.DecodeString(s)
+			if err != nil || len(b) != size {
+				return nil
+			}
+			return b
+		}
+		commitment, z, y, proof := decode(v.Commitment, 48), decode(v.Z, 32), decode(v.Y, 32), decode(v.Proof, 48)
+		if commitment == nil || z == nil || y == nil || proof == nil {
+			// the circuit inputs have a fixed size
+			assert.Nil(v.Output, v.Name)
+			continue
+		}
+		assert.Run(func(assert *test.Assert) {
+			err := test.IsSolved(&kzgPointEvalCircuit{}, newKZGPointEvalWitness(commitment, z, y, proof), ecc.BN254.ScalarField())
+			if v.Output != nil && *v.Output {
+				assert.NoError(err)
+			} else {
+				assert.Error(err)
+			}
+		}, v.Name)
+	}
+}
@@ -0,0 +1,62 @@
+package evmprecompiles
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/algopts"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// ECMSMG1BLS implements [BLS12_G1MSM] precompile contract at address 0x0c.
+//
+// The point at infinity is encoded as (0,0) as in the precompile input
+// encoding. The points must be in the prime order subgroup G1. The scalars are
+// 32-byte integers and are not required to be reduced modulo the group order.
+// It returns ∑ᵢ [sᵢ]Pᵢ.
+//
+// [BLS12_G1MSM]: https://eips.ethereum.org/EIPS/eip-2537#abi-for-g1-msm
+func ECMSMG1BLS(api frontend.API, P []*sw_bls12381.G1Affine, s []*sw_bls12381.Scalar) *sw_bls12381.G1Affine {
+	if len(P) != len(s) {
+		panic("P and s length mismatch")
+	}
+	if len(P) == 0 {
+		panic("empty input")
+	}
+	baseApi, err := emulated.NewField[sw_bls12381.BaseField](api)
+	if err != nil {
+		panic(err)
+	}
+	curve, err := sw_emulated.New[sw_bls12381.BaseField, sw_bls12381.ScalarField](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		panic(err)
+	}
+	pair, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		panic(err)
+	}
+	// Check that Pᵢ are in G1 or (0,0)
+	for i := range P {
+		assertIsOnG1OrInfinity(api, baseApi, curve, pair, P[i])
+	}
+	// We use complete arithmetic because the points and the scalars can be
+	// zero and the intermediate sums can be (0,0)
+	res := curve.ScalarMul(P[0], s[0], algopts.WithCompleteArithmetic())
+	for i := 1; i < len(P); i++ {
+		res = curve.AddUnified(res, curve.ScalarMul(P[i], s[i], algopts.WithCompleteArithmetic()))
+	}
+	return res
+}
+
+// isInfinityG1BLS returns 1 if P is the point at infinity (0,0) and 0
+// otherwise.
+func isInfinityG1BLS(api frontend.API, baseApi *emulated.Field[sw_bls12381.BaseField], P *sw_bls12381.G1Affine) frontend.Variable {
+	return api.And(baseApi.IsZero(&P.X), baseApi.IsZero(&P.Y))
+}
+
+// assertIsOnG1OrInfinity asserts that P is in G1 or is the point at infinity.
+// The subgroup check is not defined for (0,0), so we check the generator
+// instead.
+func assertIsOnG1OrInfinity(api frontend.API, baseApi *emulated.Field[sw_bls12381.BaseField], curve *sw_emulated.Curve[sw_bls12381.BaseField, sw_bls12381.ScalarField], pair *sw_bls12381.Pairing, P *sw_bls12381.G1Affine) {
+	pair.AssertIsOnG1(curve.Select(isInfinityG1BLS(api, baseApi, P), curve.Generator(), P))
+}
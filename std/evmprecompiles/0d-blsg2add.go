@@ -0,0 +1,27 @@
+package evmprecompiles
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+)
+
+// ECAddG2BLS implements [BLS12_G2ADD] precompile contract at address 0x0d.
+//
+// The point at infinity is encoded as (0,0) as in the precompile input
+// encoding. The inputs must be on the twist, but are not required to be in the
+// prime order subgroup.
+//
+// [BLS12_G2ADD]: https://eips.ethereum.org/EIPS/eip-2537#abi-for-g2-addition
+func ECAddG2BLS(api frontend.API, P, Q *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	pair, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		panic(err)
+	}
+	g2 := sw_bls12381.NewG2(api)
+	// Check that P and Q are on the twist or (0,0)
+	pair.AssertIsOnTwist(P)
+	pair.AssertIsOnTwist(Q)
+	// We use AddUnified because P can be equal to Q, -Q and either or both can be (0,0)
+	res := g2.AddUnified(P, Q)
+	return res
+}
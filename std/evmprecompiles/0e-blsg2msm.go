@@ -0,0 +1,60 @@
+package evmprecompiles
+
+import (
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+)
+
+// ECMSMG2BLS implements [BLS12_G2MSM] precompile contract at address 0x0e.
+//
+// The point at infinity is encoded as (0,0) as in the precompile input
+// encoding. The points must be in the prime order subgroup G2. The scalars are
+// 32-byte integers and are not required to be reduced modulo the group order.
+// It returns ∑ᵢ [sᵢ]Qᵢ.
+//
+// [BLS12_G2MSM]: https://eips.ethereum.org/EIPS/eip-2537#abi-for-g2-msm
+func ECMSMG2BLS(api frontend.API, Q []*sw_bls12381.G2Affine, s []*sw_bls12381.Scalar) *sw_bls12381.G2Affine {
+	if len(Q) != len(s) {
+		panic("Q and s length mismatch")
+	}
+	if len(Q) == 0 {
+		panic("empty input")
+	}
+	pair, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		panic(err)
+	}
+	g2 := sw_bls12381.NewG2(api)
+	// Check that Qᵢ are in G2 or (0,0)
+	for i := range Q {
+		assertIsOnG2OrInfinity(api, g2, pair, Q[i])
+	}
+	// ScalarMul and AddUnified are complete, the points and the scalars can be
+	// zero and the intermediate sums can be (0,0)
+	res := g2.ScalarMul(Q[0], s[0])
+	for i := 1; i < len(Q); i++ {
+		res = g2.AddUnified(res, g2.ScalarMul(Q[i], s[i]))
+	}
+	return res
+}
+
+// isInfinityG2BLS returns 1 if Q is the point at infinity (0,0) and 0
+// otherwise.
+func isInfinityG2BLS(api frontend.API, g2 *sw_bls12381.G2, Q *sw_bls12381.G2Affine) frontend.Variable {
+	return api.And(g2.Ext2.IsZero(&Q.P.X), g2.Ext2.IsZero(&Q.P.Y))
+}
+
+// generatorG2BLS returns the generator of G2 as a constant.
+func generatorG2BLS() *sw_bls12381.G2Affine {
+	_, _, _, g := bls12381.Generators()
+	gen := sw_bls12381.NewG2Affine(g)
+	return &gen
+}
+
+// assertIsOnG2OrInfinity asserts that Q is in G2 or is the point at infinity.
+// The subgroup check is not defined for (0,0), so we check the generator
+// instead.
+func assertIsOnG2OrInfinity(api frontend.API, g2 *sw_bls12381.G2, pair *sw_bls12381.Pairing, Q *sw_bls12381.G2Affine) {
+	pair.AssertIsOnG2(g2.Select(isInfinityG2BLS(api, g2, Q), generatorG2BLS(), Q))
+}
@@ -0,0 +1,65 @@
+package evmprecompiles
+
+import (
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// ECPairBLS implements [BLS12_PAIRING_CHECK] precompile contract at address
+// 0x0f. It asserts that ∏ᵢ e(Pᵢ, Qᵢ) == 1.
+//
+// The point at infinity is encoded as (0,0) as in the precompile input
+// encoding. The points must be in the prime order subgroups G1 and G2. The
+// pairs where either point is at infinity don't contribute to the product.
+//
+// [BLS12_PAIRING_CHECK]: https://eips.ethereum.org/EIPS/eip-2537#abi-for-pairing-check
+func ECPairBLS(api frontend.API, P []*sw_bls12381.G1Affine, Q []*sw_bls12381.G2Affine) {
+	if len(P) != len(Q) {
+		panic("P and Q length mismatch")
+	}
+	if len(P) == 0 {
+		panic("empty input")
+	}
+	baseApi, err := emulated.NewField[sw_bls12381.BaseField](api)
+	if err != nil {
+		panic(err)
+	}
+	curve, err := sw_emulated.New[sw_bls12381.BaseField, sw_bls12381.ScalarField](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		panic(err)
+	}
+	pair, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		panic(err)
+	}
+	g2 := sw_bls12381.NewG2(api)
+	_, _, g1Gen, g2Gen := bls12381.Generators()
+
+	// 1- Check that Pᵢ are in G1 and Qᵢ are in G2, or (0,0)
+	// 2- Check that ∏ᵢ e(Pᵢ, Qᵢ) == 1. The Miller loop doesn't support the
+	// point at infinity, so in that case we compute the Miller loop on the
+	// generators instead and select the neutral element.
+	one := pair.One()
+	ml := one
+	for i := range P {
+		pInf := isInfinityG1BLS(api, baseApi, P[i])
+		qInf := isInfinityG2BLS(api, g2, Q[i])
+		gen1 := sw_bls12381.NewG1Affine(g1Gen)
+		gen2 := sw_bls12381.NewG2Affine(g2Gen)
+		p := curve.Select(pInf, &gen1, P[i])
+		q := g2.Select(qInf, &gen2, Q[i])
+		pair.AssertIsOnG1(p)
+		pair.AssertIsOnG2(q)
+		mli, err := pair.MillerLoop([]*sw_bls12381.G1Affine{p}, []*sw_bls12381.G2Affine{q})
+		if err != nil {
+			panic(err)
+		}
+		mli = pair.Select(api.Or(pInf, qInf), one, mli)
+		ml = pair.Mul(ml, mli)
+	}
+	res := pair.FinalExponentiation(ml)
+	pair.AssertIsEqual(res, one)
+}
@@ -0,0 +1,28 @@
+package evmprecompiles
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// ECMapToG1BLS implements [BLS12_MAP_FP_TO_G1] precompile contract at address
+// 0x10.
+//
+// The input must be a canonical element of the base field. It returns the
+// point in G1 given by the simplified SWU map followed by the isogeny and the
+// cofactor clearing, see [sw_bls12381.G1.MapToG1].
+//
+// [BLS12_MAP_FP_TO_G1]: https://eips.ethereum.org/EIPS/eip-2537#abi-for-mapping-fp-element-to-g1-point
+func ECMapToG1BLS(api frontend.API, u *emulated.Element[sw_bls12381.BaseField]) *sw_bls12381.G1Affine {
+	baseApi, err := emulated.NewField[sw_bls12381.BaseField](api)
+	if err != nil {
+		panic(err)
+	}
+	g1, err := sw_bls12381.NewG1(api)
+	if err != nil {
+		panic(err)
+	}
+	baseApi.AssertIsInRange(u)
+	return g1.MapToG1(u)
+}
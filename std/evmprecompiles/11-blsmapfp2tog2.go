@@ -0,0 +1,27 @@
+package evmprecompiles
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// ECMapToG2BLS implements [BLS12_MAP_FP2_TO_G2] precompile contract at address
+// 0x11.
+//
+// The input coordinates must be canonical elements of the base field. It
+// returns the point in G2 given by the simplified SWU map followed by the
+// isogeny and the cofactor clearing, see [sw_bls12381.G2.MapToG2].
+//
+// [BLS12_MAP_FP2_TO_G2]: https://eips.ethereum.org/EIPS/eip-2537#abi-for-mapping-fp2-element-to-g2-point
+func ECMapToG2BLS(api frontend.API, u *fields_bls12381.E2) *sw_bls12381.G2Affine {
+	baseApi, err := emulated.NewField[sw_bls12381.BaseField](api)
+	if err != nil {
+		panic(err)
+	}
+	g2 := sw_bls12381.NewG2(api)
+	baseApi.AssertIsInRange(&u.A0)
+	baseApi.AssertIsInRange(&u.A1)
+	return g2.MapToG2(u)
+}
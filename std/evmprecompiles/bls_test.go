@@ -0,0 +1,290 @@
+package evmprecompiles
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+func randomG1G2BLS() (bls12381.G1Affine, bls12381.G2Affine) {
+	_, _, g1, g2 := bls12381.Generators()
+	var s1, s2 fr.Element
+	s1.SetRandom()
+	s2.SetRandom()
+	var p bls12381.G1Affine
+	p.ScalarMultiplication(&g1, s1.BigInt(new(big.Int)))
+	var q bls12381.G2Affine
+	q.ScalarMultiplication(&g2, s2.BigInt(new(big.Int)))
+	return p, q
+}
+
+type ecaddG1BLSCircuit struct {
+	X0       sw_bls12381.G1Affine
+	X1       sw_bls12381.G1Affine
+	Expected sw_bls12381.G1Affine
+}
+
+func (c *ecaddG1BLSCircuit) Define(api frontend.API) error {
+	curve, err := sw_emulated.New[sw_bls12381.BaseField, sw_bls12381.ScalarField](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		return err
+	}
+	res := ECAddG1BLS(api, &c.X0, &c.X1)
+	curve.AssertIsEqual(res, &c.Expected)
+	return nil
+}
+
+func TestECAddG1BLSCircuitShort(t *testing.T) {
+	assert := test.NewAssert(t)
+	p, _ := randomG1G2BLS()
+	q, _ := randomG1G2BLS()
+	var infinity, negP, expected bls12381.G1Affine
+	negP.Neg(&p)
+	for _, tc := range []struct {
+		name string
+		p, q bls12381.G1Affine
+	}{
+		{"P+Q", p, q},
+		{"P+P", p, p},
+		{"P-P", p, negP},
+		{"O+Q", infinity, q},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			expected.Add(&tc.p, &tc.q)
+			witness := ecaddG1BLSCircuit{
+				X0:       sw_bls12381.NewG1Affine(tc.p),
+				X1:       sw_bls12381.NewG1Affine(tc.q),
+				Expected: sw_bls12381.NewG1Affine(expected),
+			}
+			err := test.IsSolved(&ecaddG1BLSCircuit{}, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, tc.name)
+	}
+}
+
+type ecmsmG1BLSCircuit struct {
+	P        [3]sw_bls12381.G1Affine
+	S        [3]sw_bls12381.Scalar
+	Expected sw_bls12381.G1Affine
+}
+
+func (c *ecmsmG1BLSCircuit) Define(api frontend.API) error {
+	curve, err := sw_emulated.New[sw_bls12381.BaseField, sw_bls12381.ScalarField](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		return err
+	}
+	P := make([]*sw_bls12381.G1Affine, len(c.P))
+	S := make([]*sw_bls12381.Scalar, len(c.S))
+	for i := range c.P {
+		P[i] = &c.P[i]
+		S[i] = &c.S[i]
+	}
+	res := ECMSMG1BLS(api, P, S)
+	curve.AssertIsEqual(res, &c.Expected)
+	return nil
+}
+
+func TestECMSMG1BLSCircuitShort(t *testing.T) {
+	assert := test.NewAssert(t)
+	p0, _ := randomG1G2BLS()
+	p1, _ := randomG1G2BLS()
+	var infinity bls12381.G1Affine
+	var s0, s2 fr.Element
+	s0.SetRandom()
+	s2.SetRandom()
+	// the scalars in the precompile input don't have to be reduced.
+	s1 := new(big.Int).Add(fr.Modulus(), big.NewInt(5))
+	var expected, tmp bls12381.G1Affine
+	expected.ScalarMultiplication(&p0, s0.BigInt(new(big.Int)))
+	tmp.ScalarMultiplication(&p1, s1)
+	expected.Add(&expected, &tmp)
+	witness := ecmsmG1BLSCircuit{
+		P:        [3]sw_bls12381.G1Affine{sw_bls12381.NewG1Affine(p0), sw_bls12381.NewG1Affine(p1), sw_bls12381.NewG1Affine(infinity)},
+		S:        [3]sw_bls12381.Scalar{sw_bls12381.NewScalar(s0), emulated.ValueOf[sw_bls12381.ScalarField](s1), sw_bls12381.NewScalar(s2)},
+		Expected: sw_bls12381.NewG1Affine(expected),
+	}
+	err := test.IsSolved(&ecmsmG1BLSCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type ecaddG2BLSCircuit struct {
+	X0       sw_bls12381.G2Affine
+	X1       sw_bls12381.G2Affine
+	Expected sw_bls12381.G2Affine
+}
+
+func (c *ecaddG2BLSCircuit) Define(api frontend.API) error {
+	g2 := sw_bls12381.NewG2(api)
+	res := ECAddG2BLS(api, &c.X0, &c.X1)
+	g2.AssertIsEqual(res, &c.Expected)
+	return nil
+}
+
+func TestECAddG2BLSCircuitShort(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, p := randomG1G2BLS()
+	_, q := randomG1G2BLS()
+	var infinity, negP, expected bls12381.G2Affine
+	negP.Neg(&p)
+	for _, tc := range []struct {
+		name string
+		p, q bls12381.G2Affine
+	}{
+		{"P+Q", p, q},
+		{"P+P", p, p},
+		{"P-P", p, negP},
+		{"P+O", p, infinity},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			expected.Add(&tc.p, &tc.q)
+			witness := ecaddG2BLSCircuit{
+				X0:       sw_bls12381.NewG2Affine(tc.p),
+				X1:       sw_bls12381.NewG2Affine(tc.q),
+				Expected: sw_bls12381.NewG2Affine(expected),
+			}
+			err := test.IsSolved(&ecaddG2BLSCircuit{}, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, tc.name)
+	}
+}
+
+type ecmsmG2BLSCircuit struct {
+	Q        [2]sw_bls12381.G2Affine
+	S        [2]sw_bls12381.Scalar
+	Expected sw_bls12381.G2Affine
+}
+
+func (c *ecmsmG2BLSCircuit) Define(api frontend.API) error {
+	g2 := sw_bls12381.NewG2(api)
+	Q := make([]*sw_bls12381.G2Affine, len(c.Q))
+	S := make([]*sw_bls12381.Scalar, len(c.S))
+	for i := range c.Q {
+		Q[i] = &c.Q[i]
+		S[i] = &c.S[i]
+	}
+	res := ECMSMG2BLS(api, Q, S)
+	g2.AssertIsEqual(res, &c.Expected)
+	return nil
+}
+
+func TestECMSMG2BLSCircuitShort(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, q0 := randomG1G2BLS()
+	var infinity bls12381.G2Affine
+	var s0, s1 fr.Element
+	s0.SetRandom()
+	s1.SetRandom()
+	var expected bls12381.G2Affine
+	expected.ScalarMultiplication(&q0, s0.BigInt(new(big.Int)))
+	witness := ecmsmG2BLSCircuit{
+		Q:        [2]sw_bls12381.G2Affine{sw_bls12381.NewG2Affine(q0), sw_bls12381.NewG2Affine(infinity)},
+		S:        [2]sw_bls12381.Scalar{sw_bls12381.NewScalar(s0), sw_bls12381.NewScalar(s1)},
+		Expected: sw_bls12381.NewG2Affine(expected),
+	}
+	err := test.IsSolved(&ecmsmG2BLSCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type ecpairBLSCircuit struct {
+	P [3]sw_bls12381.G1Affine
+	Q [3]sw_bls12381.G2Affine
+}
+
+func (c *ecpairBLSCircuit) Define(api frontend.API) error {
+	P := make([]*sw_bls12381.G1Affine, len(c.P))
+	Q := make([]*sw_bls12381.G2Affine, len(c.Q))
+	for i := range c.P {
+		P[i] = &c.P[i]
+		Q[i] = &c.Q[i]
+	}
+	ECPairBLS(api, P, Q)
+	return nil
+}
+
+func TestECPairBLSCircuitShort(t *testing.T) {
+	assert := test.NewAssert(t)
+	p, q := randomG1G2BLS()
+	p2, q2 := randomG1G2BLS()
+	var g1Inf bls12381.G1Affine
+	var g2Inf bls12381.G2Affine
+	var negP bls12381.G1Affine
+	negP.Neg(&p)
+	// e(P, Q) · e(-P, Q) · e(O, Q') == 1
+	witness := ecpairBLSCircuit{
+		P: [3]sw_bls12381.G1Affine{sw_bls12381.NewG1Affine(p), sw_bls12381.NewG1Affine(negP), sw_bls12381.NewG1Affine(g1Inf)},
+		Q: [3]sw_bls12381.G2Affine{sw_bls12381.NewG2Affine(q), sw_bls12381.NewG2Affine(q), sw_bls12381.NewG2Affine(q2)},
+	}
+	err := test.IsSolved(&ecpairBLSCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// e(P, Q) · e(P', O) · e(O, Q') != 1
+	witness = ecpairBLSCircuit{
+		P: [3]sw_bls12381.G1Affine{sw_bls12381.NewG1Affine(p), sw_bls12381.NewG1Affine(p2), sw_bls12381.NewG1Affine(g1Inf)},
+		Q: [3]sw_bls12381.G2Affine{sw_bls12381.NewG2Affine(q), sw_bls12381.NewG2Affine(g2Inf), sw_bls12381.NewG2Affine(q2)},
+	}
+	err = test.IsSolved(&ecpairBLSCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
+
+type ecmapToG1BLSCircuit struct {
+	U        emulated.Element[sw_bls12381.BaseField]
+	Expected sw_bls12381.G1Affine
+}
+
+func (c *ecmapToG1BLSCircuit) Define(api frontend.API) error {
+	curve, err := sw_emulated.New[sw_bls12381.BaseField, sw_bls12381.ScalarField](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		return err
+	}
+	res := ECMapToG1BLS(api, &c.U)
+	curve.AssertIsEqual(res, &c.Expected)
+	return nil
+}
+
+func TestECMapToG1BLSCircuitShort(t *testing.T) {
+	assert := test.NewAssert(t)
+	var u fp.Element
+	u.SetRandom()
+	expected := bls12381.MapToG1(u)
+	witness := ecmapToG1BLSCircuit{
+		U:        emulated.ValueOf[sw_bls12381.BaseField](u),
+		Expected: sw_bls12381.NewG1Affine(expected),
+	}
+	err := test.IsSolved(&ecmapToG1BLSCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type ecmapToG2BLSCircuit struct {
+	U        fields_bls12381.E2
+	Expected sw_bls12381.G2Affine
+}
+
+func (c *ecmapToG2BLSCircuit) Define(api frontend.API) error {
+	g2 := sw_bls12381.NewG2(api)
+	res := ECMapToG2BLS(api, &c.U)
+	g2.AssertIsEqual(res, &c.Expected)
+	return nil
+}
+
+func TestECMapToG2BLSCircuitShort(t *testing.T) {
+	assert := test.NewAssert(t)
+	var u bls12381.E2
+	u.SetRandom()
+	expected := bls12381.MapToG2(u)
+	witness := ecmapToG2BLSCircuit{
+		U:        fields_bls12381.FromE2(&u),
+		Expected: sw_bls12381.NewG2Affine(expected),
+	}
+	err := test.IsSolved(&ecmapToG2BLSCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
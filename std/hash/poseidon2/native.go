@@ -0,0 +1,101 @@
+package poseidon2
+
+import (
+	"errors"
+	stdhash "hash"
+	"math/big"
+
+	"github.com/consensys/gnark/std/permutation/poseidon2"
+)
+
+type nativeDigest struct {
+	params    *poseidon2.Parameters
+	modulus   *big.Int
+	blockSize int
+	state     *big.Int
+	data      []*big.Int
+}
+
+// NewNativeHasher returns a native Poseidon2 hasher over the field defined by
+// modulus. It computes the same digest as the in-circuit hasher returned by
+// [NewMerkleDamgardHasher] and can be used for computing witnesses, Merkle
+// roots or as a challenge hash function.
+//
+// Each block of [stdhash.Hash.BlockSize] bytes written represents a big-endian
+// field element. Shorter writes are left-padded.
+func NewNativeHasher(modulus *big.Int) (stdhash.Hash, error) {
+	params, err := poseidon2.GetDefaultParameters(modulus)
+	if err != nil {
+		return nil, err
+	}
+	return &nativeDigest{
+		params:    params,
+		modulus:   params.Modulus(),
+		blockSize: (modulus.BitLen() + 7) / 8,
+		state:     new(big.Int),
+	}, nil
+}
+
+// Write (via the embedded io.Writer interface) adds more data to the running
+// hash.
+//
+// If len(p) is not a multiple of BlockSize or any of the blocks represent an
+// integer larger than the modulus, this function returns an error.
+func (d *nativeDigest) Write(p []byte) (int, error) {
+	// as in gnark-crypto MiMC, we left-pad short inputs for writing small
+	// values without hashing them to the field first.
+	if len(p) > 0 && len(p) < d.blockSize {
+		pp := make([]byte, d.blockSize)
+		copy(pp[len(pp)-len(p):], p)
+		p = pp
+	}
+	if len(p)%d.blockSize != 0 {
+		return 0, errors.New("invalid input length: must represent a list of field elements, expects a []byte of len m*BlockSize")
+	}
+	elems := make([]*big.Int, 0, len(p)/d.blockSize)
+	for start := 0; start < len(p); start += d.blockSize {
+		e := new(big.Int).SetBytes(p[start : start+d.blockSize])
+		if e.Cmp(d.modulus) >= 0 {
+			return 0, errors.New("input block is not a canonical field element")
+		}
+		elems = append(elems, e)
+	}
+	d.data = append(d.data, elems...)
+	return len(p), nil
+}
+
+// Sum appends the current hash to b and returns the resulting slice. The
+// written data is flushed, but the state is kept.
+func (d *nativeDigest) Sum(b []byte) []byte {
+	for _, v := range d.data {
+		d.state = d.compress(d.state, v)
+	}
+	d.data = nil
+	res := make([]byte, d.blockSize)
+	d.state.FillBytes(res)
+	return append(b, res...)
+}
+
+// Reset resets the Hash to its initial state.
+func (d *nativeDigest) Reset() {
+	d.data = nil
+	d.state = new(big.Int)
+}
+
+// Size returns the number of bytes Sum will return.
+func (d *nativeDigest) Size() int {
+	return d.blockSize
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d *nativeDigest) BlockSize() int {
+	return d.blockSize
+}
+
+func (d *nativeDigest) compress(left, right *big.Int) *big.Int {
+	vars := []*big.Int{new(big.Int).Set(left), new(big.Int).Set(right)}
+	if err := d.params.Permutation(vars); err != nil {
+		panic(err) // this would never happen
+	}
+	return vars[1].Add(vars[1], right).Mod(vars[1], d.modulus)
+}
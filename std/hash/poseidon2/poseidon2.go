@@ -0,0 +1,66 @@
+// Package poseidon2 implements Poseidon2 hash function in Merkle-Damgård mode.
+//
+// The hash function is built on top of the compression function of the
+// Poseidon2 permutation [poseidon2.Permutation.Compress] with the default
+// parameters of the native field. The in-circuit hasher [NewMerkleDamgardHasher]
+// implements [hash.FieldHasher] and is registered under the name [Name]. The
+// native hasher [NewNativeHasher] computes the same digests off-circuit.
+package poseidon2
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/permutation/poseidon2"
+)
+
+// Name is the name under which the hasher is registered in [hash.Register].
+const Name = "poseidon2"
+
+func init() {
+	hash.Register(Name, func(api frontend.API) (hash.FieldHasher, error) {
+		return NewMerkleDamgardHasher(api)
+	})
+}
+
+type digest struct {
+	api   frontend.API
+	perm  *poseidon2.Permutation
+	state frontend.Variable
+	data  []frontend.Variable
+}
+
+// NewMerkleDamgardHasher returns a Poseidon2 hasher for the native field of
+// api. The initial state is zero and every written element is absorbed with
+// the compression function.
+func NewMerkleDamgardHasher(api frontend.API) (hash.FieldHasher, error) {
+	perm, err := poseidon2.NewPoseidon2(api)
+	if err != nil {
+		return nil, err
+	}
+	return &digest{
+		api:   api,
+		perm:  perm,
+		state: 0,
+	}, nil
+}
+
+// Write adds more data to the running hash.
+func (d *digest) Write(data ...frontend.Variable) {
+	d.data = append(d.data, data...)
+}
+
+// Reset resets the Hash to its initial state.
+func (d *digest) Reset() {
+	d.data = nil
+	d.state = 0
+}
+
+// Sum returns the current digest. The written data is flushed, but the state
+// is kept so that following writes continue the running hash.
+func (d *digest) Sum() frontend.Variable {
+	for _, v := range d.data {
+		d.state = d.perm.Compress(d.state, v)
+	}
+	d.data = nil
+	return d.state
+}
@@ -0,0 +1,82 @@
+package poseidon2
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/test"
+)
+
+type poseidon2Circuit struct {
+	ExpectedResult frontend.Variable `gnark:"data,public"`
+	Data           [10]frontend.Variable
+}
+
+func (circuit *poseidon2Circuit) Define(api frontend.API) error {
+	h, err := hash.GetFieldHasher(Name, api)
+	if err != nil {
+		return err
+	}
+	h.Write(circuit.Data[:]...)
+	result := h.Sum()
+	api.AssertIsEqual(result, circuit.ExpectedResult)
+	return nil
+}
+
+func TestPoseidon2All(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, curve := range gnark.Curves() {
+
+		// minimal cs res = hash(data)
+		var circuit, validWitness, invalidWitness poseidon2Circuit
+
+		modulus := curve.ScalarField()
+		var data [10]big.Int
+		data[0].Sub(modulus, big.NewInt(1))
+		for i := 1; i < 10; i++ {
+			data[i].Add(&data[i-1], &data[i-1]).Mod(&data[i], modulus)
+		}
+
+		// running Poseidon2 (Go)
+		goPoseidon2, err := NewNativeHasher(modulus)
+		assert.NoError(err)
+		for i := 0; i < 10; i++ {
+			_, err = goPoseidon2.Write(data[i].Bytes())
+			assert.NoError(err)
+		}
+		expectedh := goPoseidon2.Sum(nil)
+
+		// assert correctness against correct witness
+		for i := 0; i < 10; i++ {
+			validWitness.Data[i] = data[i].String()
+		}
+		validWitness.ExpectedResult = expectedh
+
+		// assert failure against wrong witness
+		for i := 0; i < 10; i++ {
+			invalidWitness.Data[i] = data[i].Sub(&data[i], big.NewInt(1)).String()
+		}
+		invalidWitness.ExpectedResult = expectedh
+
+		assert.CheckCircuit(&circuit,
+			test.WithValidAssignment(&validWitness),
+			test.WithInvalidAssignment(&invalidWitness),
+			test.WithCurves(curve))
+	}
+}
+
+func TestNativeWriteNonCanonical(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, curve := range gnark.Curves() {
+		h, err := NewNativeHasher(curve.ScalarField())
+		assert.NoError(err)
+		buf := make([]byte, h.BlockSize())
+		curve.ScalarField().FillBytes(buf)
+		_, err = h.Write(buf)
+		assert.Error(err)
+	}
+}
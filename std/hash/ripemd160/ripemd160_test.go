@@ -0,0 +1,65 @@
+package ripemd160
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+type ripemd160Circuit struct {
+	In       []uints.U8
+	Expected [20]uints.U8
+}
+
+func (c *ripemd160Circuit) Define(api frontend.API) error {
+	h, err := New(api)
+	if err != nil {
+		return err
+	}
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.In)
+	res := h.Sum()
+	if len(res) != 20 {
+		return fmt.Errorf("not 20 bytes")
+	}
+	for i := range c.Expected {
+		uapi.ByteAssertEq(c.Expected[i], res[i])
+	}
+	return nil
+}
+
+func TestRIPEMD160(t *testing.T) {
+	// test vectors from the RIPEMD-160 specification
+	vectors := []struct {
+		in, out string
+	}{
+		{"", "9c1185a5c5e9fc54612808977ee8f548b2258d31"},
+		{"abc", "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc"},
+		{"message digest", "5d0689ef49d2fae572b881b123a85ffa21595f36"},
+		{"abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq", "12a053384a9c0c88e405a06c27dcf49ada62eb2b"},
+		{strings.Repeat("1234567890", 8), "9b752e45573d4b39f4dbd3323cab82bf63326bfb"},
+	}
+	for _, v := range vectors {
+		dgst, err := hex.DecodeString(v.out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		witness := ripemd160Circuit{
+			In: uints.NewU8Array([]byte(v.in)),
+		}
+		copy(witness.Expected[:], uints.NewU8Array(dgst))
+		err = test.IsSolved(&ripemd160Circuit{In: make([]uints.U8, len(v.in))}, &witness, ecc.BN254.ScalarField())
+		if err != nil {
+			t.Fatalf("input %q: %v", v.in, err)
+		}
+	}
+}
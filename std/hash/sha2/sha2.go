@@ -1,7 +1,8 @@
 // Package sha2 implements SHA2 hash computation.
 //
-// This package extends the SHA2 permutation function [sha2] into a full SHA2
-// hash.
+// This package extends the SHA2 permutation functions [sha2] into full SHA2
+// hashes. [New] returns a SHA-256 hasher built on 32-bit words and [New512] and
+// [New384] return SHA-512 and SHA-384 hashers built on 64-bit words.
 package sha2
 
 import (
@@ -104,7 +105,7 @@ func (d *digest) FixedLengthSum(length frontend.Variable) []uints.U8 {
 	last8BytesPos := d.api.Sub(totalLen, 8)
 
 	var dataLenBtyes [8]frontend.Variable
-	d.bigEndianPutUint64(dataLenBtyes[:], d.api.Mul(length, 8))
+	bigEndianPutUint64(d.api, dataLenBtyes[:], d.api.Mul(length, 8))
 
 	for i := range data {
 		isPaddingStartPos := d.api.IsZero(d.api.Sub(i, length))
@@ -160,9 +161,9 @@ func (d *digest) mod64(v frontend.Variable) frontend.Variable {
 	return lower
 }
 
-func (d *digest) bigEndianPutUint64(b []frontend.Variable, x frontend.Variable) {
-	bts := bits.ToBinary(d.api, x, bits.WithNbDigits(64))
+func bigEndianPutUint64(api frontend.API, b []frontend.Variable, x frontend.Variable) {
+	bts := bits.ToBinary(api, x, bits.WithNbDigits(64))
 	for i := 0; i < 8; i++ {
-		b[i] = bits.FromBinary(d.api, bts[(8-i-1)*8:(8-i)*8])
+		b[i] = bits.FromBinary(api, bts[(8-i-1)*8:(8-i)*8])
 	}
 }
@@ -2,6 +2,7 @@ package sha2
 
 import (
 	"crypto/sha256"
+	"crypto/sha512"
 	"fmt"
 	"testing"
 
@@ -89,3 +90,142 @@ func TestSHA2FixedLengthSum(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+type sha512Circuit struct {
+	In       []uints.U8
+	Expected [64]uints.U8
+}
+
+func (c *sha512Circuit) Define(api frontend.API) error {
+	h, err := New512(api)
+	if err != nil {
+		return err
+	}
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.In)
+	res := h.Sum()
+	if len(res) != 64 {
+		return fmt.Errorf("not 64 bytes")
+	}
+	for i := range c.Expected {
+		uapi.ByteAssertEq(c.Expected[i], res[i])
+	}
+	return nil
+}
+
+func TestSHA512(t *testing.T) {
+	// lengths around the padding boundaries
+	for _, l := range []int{0, 3, 111, 112, 128, 300} {
+		bts := make([]byte, l)
+		for i := range bts {
+			bts[i] = byte(i)
+		}
+		dgst := sha512.Sum512(bts)
+		witness := sha512Circuit{
+			In: uints.NewU8Array(bts),
+		}
+		copy(witness.Expected[:], uints.NewU8Array(dgst[:]))
+		err := test.IsSolved(&sha512Circuit{In: make([]uints.U8, len(bts))}, &witness, ecc.BN254.ScalarField())
+		if err != nil {
+			t.Fatalf("length %d: %v", l, err)
+		}
+	}
+}
+
+type sha384Circuit struct {
+	In       []uints.U8
+	Expected [48]uints.U8
+}
+
+func (c *sha384Circuit) Define(api frontend.API) error {
+	h, err := New384(api)
+	if err != nil {
+		return err
+	}
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.In)
+	res := h.Sum()
+	if len(res) != 48 {
+		return fmt.Errorf("not 48 bytes")
+	}
+	for i := range c.Expected {
+		uapi.ByteAssertEq(c.Expected[i], res[i])
+	}
+	return nil
+}
+
+func TestSHA384(t *testing.T) {
+	bts := make([]byte, 310)
+	dgst := sha512.Sum384(bts)
+	witness := sha384Circuit{
+		In: uints.NewU8Array(bts),
+	}
+	copy(witness.Expected[:], uints.NewU8Array(dgst[:]))
+	err := test.IsSolved(&sha384Circuit{In: make([]uints.U8, len(bts))}, &witness, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type sha512FixedLengthCircuit struct {
+	In       []uints.U8
+	Length   frontend.Variable
+	Expected []uints.U8
+	is384    bool
+}
+
+func (c *sha512FixedLengthCircuit) Define(api frontend.API) error {
+	newHasher := New512
+	if c.is384 {
+		newHasher = New384
+	}
+	h, err := newHasher(api)
+	if err != nil {
+		return err
+	}
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.In)
+	res := h.FixedLengthSum(c.Length)
+	if len(res) != len(c.Expected) {
+		return fmt.Errorf("expected %d bytes, got %d", len(c.Expected), len(res))
+	}
+	for i := range c.Expected {
+		uapi.ByteAssertEq(c.Expected[i], res[i])
+	}
+	return nil
+}
+
+func TestSHA512FixedLengthSum(t *testing.T) {
+	bts := make([]byte, 260)
+	for i := range bts {
+		bts[i] = byte(i)
+	}
+	for _, length := range []int{0, 111, 112, 200} {
+		dgst512 := sha512.Sum512(bts[:length])
+		dgst384 := sha512.Sum384(bts[:length])
+		for _, tc := range []struct {
+			is384 bool
+			dgst  []byte
+		}{{false, dgst512[:]}, {true, dgst384[:]}} {
+			circuit := sha512FixedLengthCircuit{In: make([]uints.U8, len(bts)), Expected: make([]uints.U8, len(tc.dgst)), is384: tc.is384}
+			witness := sha512FixedLengthCircuit{
+				In:       uints.NewU8Array(bts),
+				Length:   length,
+				Expected: uints.NewU8Array(tc.dgst),
+			}
+			err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			if err != nil {
+				t.Fatalf("length %d (SHA-384: %t): %v", length, tc.is384, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package sha2
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/math/bitslice"
+	"github.com/consensys/gnark/std/math/cmp"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/std/permutation/sha2"
+)
+
+var _seed512 = uints.NewU64Array([]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+})
+
+var _seed384 = uints.NewU64Array([]uint64{
+	0xcbbb9d5dc1059ed8, 0x629a292a367cd507, 0x9159015a3070dd17, 0x152fecd8f70e5939,
+	0x67332667ffc00b31, 0x8eb44a8768581511, 0xdb0c2e0d64f98fa7, 0x47b5481dbefa4fa4,
+})
+
+type digest512 struct {
+	api  frontend.API
+	uapi *uints.BinaryField[uints.U64]
+	in   []uints.U8
+	seed []uints.U64
+	size int
+}
+
+// New512 returns a new SHA-512 hasher.
+func New512(api frontend.API) (hash.BinaryFixedLengthHasher, error) {
+	return newDigest512(api, _seed512, 64)
+}
+
+// New384 returns a new SHA-384 hasher. SHA-384 uses the SHA-512 compression
+// function with a different initial state and truncates the digest to 48
+// bytes.
+func New384(api frontend.API) (hash.BinaryFixedLengthHasher, error) {
+	return newDigest512(api, _seed384, 48)
+}
+
+func newDigest512(api frontend.API, seed []uints.U64, size int) (*digest512, error) {
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		return nil, err
+	}
+	return &digest512{api: api, uapi: uapi, seed: seed, size: size}, nil
+}
+
+func (d *digest512) Write(data []uints.U8) {
+	d.in = append(d.in, data...)
+}
+
+func (d *digest512) padded(bytesLen int) []uints.U8 {
+	zeroPadLen := 111 - bytesLen%128
+	if zeroPadLen < 0 {
+		zeroPadLen += 128
+	}
+	buf := make([]uints.U8, len(d.in), len(d.in)+17+zeroPadLen)
+	copy(buf, d.in)
+	buf = append(buf, uints.NewU8(0x80))
+	buf = append(buf, uints.NewU8Array(make([]uint8, zeroPadLen))...)
+	// the message length is encoded on 128 bits, we only support inputs
+	// shorter than 2^64 bits.
+	lenbuf := make([]uint8, 16)
+	binary.BigEndian.PutUint64(lenbuf[8:], uint64(8*bytesLen))
+	buf = append(buf, uints.NewU8Array(lenbuf)...)
+	return buf
+}
+
+func (d *digest512) Sum() []uints.U8 {
+	var runningDigest [8]uints.U64
+	var buf [128]uints.U8
+	copy(runningDigest[:], d.seed)
+	padded := d.padded(len(d.in))
+	for i := 0; i < len(padded)/128; i++ {
+		copy(buf[:], padded[i*128:(i+1)*128])
+		runningDigest = sha2.Permute512(d.uapi, runningDigest, buf)
+	}
+	return d.output(runningDigest)
+}
+
+func (d *digest512) FixedLengthSum(length frontend.Variable) []uints.U8 {
+	// same approach as for SHA-256, but with 128-byte blocks and the input
+	// length encoded on 16 bytes.
+	data := make([]uints.U8, len(d.in))
+	copy(data, d.in)
+
+	comparator := cmp.NewBoundedComparator(d.api, big.NewInt(int64(len(data)+128+16)), false)
+
+	for i := 0; i < 128+16; i++ {
+		data = append(data, uints.NewU8(0))
+	}
+
+	lenMod128 := d.mod128(length)
+	lenMod128Less112 := comparator.IsLess(lenMod128, 112)
+
+	paddingCount := d.api.Sub(128, lenMod128)
+	paddingCount = d.api.Select(lenMod128Less112, paddingCount, d.api.Add(paddingCount, 128))
+
+	totalLen := d.api.Add(length, paddingCount)
+	last16BytesPos := d.api.Sub(totalLen, 16)
+
+	// the upper 8 bytes of the length are always zero as we only support
+	// inputs shorter than 2^64 bits.
+	var dataLenBtyes [16]frontend.Variable
+	for i := 0; i < 8; i++ {
+		dataLenBtyes[i] = 0
+	}
+	bigEndianPutUint64(d.api, dataLenBtyes[8:], d.api.Mul(length, 8))
+
+	for i := range data {
+		isPaddingStartPos := d.api.IsZero(d.api.Sub(i, length))
+		data[i].Val = d.api.Select(isPaddingStartPos, 0x80, data[i].Val)
+
+		isPaddingPos := comparator.IsLess(length, i)
+		data[i].Val = d.api.Select(isPaddingPos, 0, data[i].Val)
+	}
+
+	for i := range data {
+		isLast16BytesPos := d.api.IsZero(d.api.Sub(i, last16BytesPos))
+		for j := 0; j < 16; j++ {
+			if i+j < len(data) {
+				data[i+j].Val = d.api.Select(isLast16BytesPos, dataLenBtyes[j], data[i+j].Val)
+			}
+		}
+	}
+
+	var runningDigest [8]uints.U64
+	var resultDigest [8]uints.U64
+	var buf [128]uints.U8
+	copy(runningDigest[:], d.seed)
+	copy(resultDigest[:], d.seed)
+
+	for i := 0; i < len(data)/128; i++ {
+		copy(buf[:], data[i*128:(i+1)*128])
+		runningDigest = sha2.Permute512(d.uapi, runningDigest, buf)
+
+		isInRange := comparator.IsLess(i*128, totalLen)
+
+		for j := 0; j < 8; j++ {
+			for k := 0; k < 8; k++ {
+				resultDigest[j][k].Val = d.api.Select(isInRange, runningDigest[j][k].Val, resultDigest[j][k].Val)
+			}
+		}
+	}
+
+	return d.output(resultDigest)
+}
+
+func (d *digest512) Reset() {
+	d.in = nil
+}
+
+func (d *digest512) Size() int { return d.size }
+
+// output serializes the state and truncates it to the digest size.
+func (d *digest512) output(state [8]uints.U64) []uints.U8 {
+	var ret []uints.U8
+	for i := range state {
+		ret = append(ret, d.uapi.UnpackMSB(state[i])...)
+	}
+	return ret[:d.size]
+}
+
+func (d *digest512) mod128(v frontend.Variable) frontend.Variable {
+	lower, _ := bitslice.Partition(d.api, v, 7, bitslice.WithNbDigits(64))
+	return lower
+}
@@ -1,11 +1,17 @@
 package sha3
 
 import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
 	"github.com/consensys/gnark/std/math/uints"
 	"github.com/consensys/gnark/std/permutation/keccakf"
+	"github.com/consensys/gnark/std/selector"
 )
 
+var _ hash.BinaryFixedLengthHasher = (*digest)(nil)
+
 type digest struct {
+	api       frontend.API
 	uapi      *uints.BinaryField[uints.U64]
 	state     [25]uints.U64 // 1600 bits state: 25 x 64
 	in        []uints.U8    // input to be digested
@@ -32,6 +38,70 @@ func (d *digest) Sum() []uints.U8 {
 	return d.squeezeBlocks()
 }
 
+// FixedLengthSum returns the digest of the first length bytes of the input.
+// The length must be at most the number of bytes written.
+func (d *digest) FixedLengthSum(length frontend.Variable) []uints.U8 {
+	// the padding is at least one byte, so the padded input fits in one more
+	// block than the maximum length input.
+	nbBlocks := len(d.in)/d.rate + 1
+	data := make([]uints.U8, nbBlocks*d.rate)
+	copy(data, d.in)
+	for i := len(d.in); i < len(data); i++ {
+		data[i] = uints.NewU8(0)
+	}
+
+	// mask[i] is 1 for i < length and 0 otherwise. It also asserts that
+	// length <= len(data).
+	ones := make([]frontend.Variable, len(data))
+	for i := range ones {
+		ones[i] = 1
+	}
+	mask := selector.Partition(d.api, length, false, ones)
+	if len(d.in) < len(mask) {
+		d.api.AssertIsEqual(mask[len(d.in)], 0)
+	}
+
+	// isLastBlock[i] is 1 when the padding starts in block i, i.e. when
+	// i*rate <= length < (i+1)*rate.
+	isLastBlock := make([]frontend.Variable, nbBlocks)
+	prev := frontend.Variable(1)
+	for i := range isLastBlock {
+		isLastBlock[i] = d.api.Sub(prev, mask[(i+1)*d.rate-1])
+		prev = mask[(i+1)*d.rate-1]
+	}
+
+	prev = 1
+	for i := range data {
+		// the domain separation byte is at position length and the final bit
+		// of the padding at the end of the last block. As the domain
+		// separation byte is less than 0x80, we can add them.
+		isPaddingStart := d.api.Sub(prev, mask[i])
+		prev = mask[i]
+		v := d.api.Mul(mask[i], data[i].Val)
+		v = d.api.Add(v, d.api.Mul(isPaddingStart, d.dsbyte))
+		if (i+1)%d.rate == 0 {
+			v = d.api.Add(v, d.api.Mul(isLastBlock[i/d.rate], 0x80))
+		}
+		data[i].Val = v
+	}
+
+	blocks := d.composeBlocks(data)
+	result := make([]uints.U8, d.outputLen)
+	for i := range result {
+		result[i] = uints.NewU8(0)
+	}
+	// absorb in a copy of the digest, so that its state is unchanged
+	c := *d
+	for i, block := range blocks {
+		c.absorbing([][]uints.U64{block})
+		squeezed := c.squeezeBlocks()
+		for j := range result {
+			result[j].Val = d.api.Select(isLastBlock[i], squeezed[j].Val, result[j].Val)
+		}
+	}
+	return result
+}
+
 func (d *digest) padding() []uints.U8 {
 	padded := make([]uints.U8, len(d.in))
 	copy(padded[:], d.in[:])
@@ -88,3 +88,75 @@ func TestSHA3(t *testing.T) {
 		}, name)
 	}
 }
+
+type sha3FixedLengthCircuit struct {
+	In       []uints.U8
+	Length   frontend.Variable
+	Expected []uints.U8
+
+	hasher string
+}
+
+func (c *sha3FixedLengthCircuit) Define(api frontend.API) error {
+	newHasher, ok := testCases[c.hasher]
+	if !ok {
+		return fmt.Errorf("hash function unknown: %s", c.hasher)
+	}
+	h, err := newHasher.zk(api)
+	if err != nil {
+		return err
+	}
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		return err
+	}
+
+	fh, ok := h.(zkhash.BinaryFixedLengthHasher)
+	if !ok {
+		return fmt.Errorf("hash function %s doesn't support fixed length sum", c.hasher)
+	}
+	fh.Write(c.In)
+	// the state of the hasher is unchanged by the sum
+	for j := 0; j < 2; j++ {
+		res := fh.FixedLengthSum(c.Length)
+		for i := range c.Expected {
+			uapi.ByteAssertEq(c.Expected[i], res[i])
+		}
+	}
+	return nil
+}
+
+func TestSHA3FixedLengthSum(t *testing.T) {
+	assert := test.NewAssert(t)
+	in := make([]byte, 310)
+	_, err := rand.Reader.Read(in)
+	assert.NoError(err)
+
+	for name := range testCases {
+		// lengths around the block boundaries
+		for _, length := range []int{0, 135, 136, 310} {
+			assert.Run(func(assert *test.Assert) {
+				strategy := testCases[name]
+				h := strategy.native()
+				h.Write(in[:length])
+				expected := h.Sum(nil)
+
+				circuit := &sha3FixedLengthCircuit{
+					In:       make([]uints.U8, len(in)),
+					Expected: make([]uints.U8, len(expected)),
+					hasher:   name,
+				}
+
+				witness := &sha3FixedLengthCircuit{
+					In:       uints.NewU8Array(in),
+					Length:   length,
+					Expected: uints.NewU8Array(expected),
+				}
+
+				if err := test.IsSolved(circuit, witness, ecc.BN254.ScalarField()); err != nil {
+					t.Fatalf("%s: %s", name, err)
+				}
+			}, name, fmt.Sprintf("length=%d", length))
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Package tofield implements hashing arbitrary byte strings to field elements.
+//
+// The construction follows [RFC 9380]: the message is expanded into a
+// pseudo-random byte string using expand_message_xmd with SHA-256, which is
+// then interpreted as big-endian integers and reduced modulo the field
+// modulus. The outputs are compatible with the hash_to_field implementations
+// of gnark-crypto, for example [fp.Hash] for the BLS12-381 base field.
+//
+// [RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380
+// [fp.Hash]: https://pkg.go.dev/github.com/consensys/gnark-crypto/ecc/bls12-381/fp#Hash
+package tofield
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// securityLevel is the target security level k in bits, which defines the
+// number of bytes to sample for every field element.
+const securityLevel = 128
+
+// ExpandMsgXmd expands the message msg into lenInBytes pseudo-random bytes
+// using expand_message_xmd with SHA-256 as defined in [RFC 9380] Section
+// 5.3.1. The domain separation tag dst is fixed at circuit compile time.
+//
+// [RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380#name-expand_message_xmd
+func ExpandMsgXmd(api frontend.API, msg []uints.U8, dst []byte, lenInBytes int) ([]uints.U8, error) {
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		return nil, fmt.Errorf("new uints api: %w", err)
+	}
+	const bInBytes = 32
+	const rInBytes = 64
+	ell := (lenInBytes + bInBytes - 1) / bInBytes
+	if ell > 255 || lenInBytes > 65535 || lenInBytes <= 0 {
+		return nil, errors.New("invalid lenInBytes")
+	}
+	if len(dst) > 255 {
+		return nil, errors.New("invalid domain size (>255 bytes)")
+	}
+	// DST_prime = DST ∥ I2OSP(len(DST), 1)
+	dstPrime := uints.NewU8Array(append(append([]byte{}, dst...), uint8(len(dst))))
+	h := func(in ...[]uints.U8) ([]uints.U8, error) {
+		h, err := sha2.New(api)
+		if err != nil {
+			return nil, fmt.Errorf("new sha2: %w", err)
+		}
+		for i := range in {
+			h.Write(in[i])
+		}
+		return h.Sum(), nil
+	}
+
+	// b₀ = H(Z_pad ∥ msg ∥ l_i_b_str ∥ I2OSP(0, 1) ∥ DST_prime)
+	b0, err := h(
+		uints.NewU8Array(make([]byte, rInBytes)),
+		msg,
+		uints.NewU8Array([]byte{uint8(lenInBytes >> 8), uint8(lenInBytes), 0}),
+		dstPrime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// b₁ = H(b₀ ∥ I2OSP(1, 1) ∥ DST_prime)
+	bi, err := h(b0, []uints.U8{uints.NewU8(1)}, dstPrime)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]uints.U8, 0, ell*bInBytes)
+	res = append(res, bi...)
+	for i := 2; i <= ell; i++ {
+		// bᵢ = H(strxor(b₀, bᵢ₋₁) ∥ I2OSP(i, 1) ∥ DST_prime)
+		strxor := make([]uints.U8, 0, bInBytes)
+		for j := 0; j < bInBytes; j += 4 {
+			x := uapi.Xor(uapi.PackMSB(b0[j:j+4]...), uapi.PackMSB(bi[j:j+4]...))
+			strxor = append(strxor, uapi.UnpackMSB(x)...)
+		}
+		if bi, err = h(strxor, []uints.U8{uints.NewU8(uint8(i))}, dstPrime); err != nil {
+			return nil, err
+		}
+		res = append(res, bi...)
+	}
+	return res[:lenInBytes], nil
+}
+
+// Hash hashes the message msg to count elements of the field defined by T as
+// hash_to_field in [RFC 9380] Section 5.2. It samples L = ⌈(⌈log₂(q)⌉ + 128)/8⌉
+// bytes per element using [ExpandMsgXmd] with the domain separation tag dst.
+//
+// [RFC 9380]: https://www.rfc-editor.org/rfc/rfc9380#name-hash_to_field-implementatio
+func Hash[T emulated.FieldParams](api frontend.API, msg []uints.U8, dst []byte, count int) ([]*emulated.Element[T], error) {
+	f, err := emulated.NewField[T](api)
+	if err != nil {
+		return nil, fmt.Errorf("new field: %w", err)
+	}
+	var fp T
+	l := (fp.Modulus().BitLen() + securityLevel + 7) / 8
+	uniformBytes, err := ExpandMsgXmd(api, msg, dst, count*l)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*emulated.Element[T], count)
+	for i := range res {
+		res[i] = fromBigEndianBytes(api, f, uniformBytes[i*l:(i+1)*l])
+	}
+	return res, nil
+}
+
+// fromBigEndianBytes returns the integer encoded in big-endian by in reduced
+// modulo the field modulus. The input is processed in chunks of 16 bytes, so
+// that every chunk fits into a field element.
+func fromBigEndianBytes[T emulated.FieldParams](api frontend.API, f *emulated.Field[T], in []uints.U8) *emulated.Element[T] {
+	const chunkSize = 16
+	shift := f.NewElement(new(big.Int).Lsh(big.NewInt(1), 8*chunkSize))
+	var res *emulated.Element[T]
+	for len(in) > 0 {
+		n := len(in) % chunkSize
+		if n == 0 {
+			n = chunkSize
+		}
+		chunkBits := make([]frontend.Variable, 0, 8*n)
+		for i := n - 1; i >= 0; i-- {
+			chunkBits = append(chunkBits, bits.ToBinary(api, in[i].Val, bits.WithNbDigits(8))...)
+		}
+		chunk := f.FromBits(chunkBits...)
+		if res == nil {
+			res = chunk
+		} else {
+			res = f.Add(f.Mul(res, shift), chunk)
+		}
+		in = in[n:]
+	}
+	return f.Reduce(res)
+}
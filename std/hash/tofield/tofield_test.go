@@ -0,0 +1,132 @@
+package tofield
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	fp_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	fp_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/field/hash"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+type expandMsgXmdCircuit struct {
+	Msg      []uints.U8
+	Expected []uints.U8
+	dst      []byte
+}
+
+func (c *expandMsgXmdCircuit) Define(api frontend.API) error {
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		return err
+	}
+	res, err := ExpandMsgXmd(api, c.Msg, c.dst, len(c.Expected))
+	if err != nil {
+		return err
+	}
+	if len(res) != len(c.Expected) {
+		return fmt.Errorf("expected %d bytes, got %d", len(c.Expected), len(res))
+	}
+	for i := range c.Expected {
+		uapi.ByteAssertEq(c.Expected[i], res[i])
+	}
+	return nil
+}
+
+func TestExpandMsgXmd(t *testing.T) {
+	assert := test.NewAssert(t)
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA256-128")
+	for _, tc := range []struct {
+		msg        string
+		lenInBytes int
+	}{
+		{"", 0x20},
+		{"abc", 0x20},
+		{"abcdef0123456789", 0x80},
+		{"", 0x3a},
+	} {
+		expected, err := hash.ExpandMsgXmd([]byte(tc.msg), dst, tc.lenInBytes)
+		assert.NoError(err)
+		circuit := expandMsgXmdCircuit{
+			Msg:      make([]uints.U8, len(tc.msg)),
+			Expected: make([]uints.U8, tc.lenInBytes),
+			dst:      dst,
+		}
+		witness := expandMsgXmdCircuit{
+			Msg:      uints.NewU8Array([]byte(tc.msg)),
+			Expected: uints.NewU8Array(expected),
+		}
+		err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err, "msg %q, length %d", tc.msg, tc.lenInBytes)
+	}
+}
+
+type hashCircuit[T emulated.FieldParams] struct {
+	Msg      []uints.U8
+	Expected []emulated.Element[T]
+	dst      []byte
+}
+
+func (c *hashCircuit[T]) Define(api frontend.API) error {
+	f, err := emulated.NewField[T](api)
+	if err != nil {
+		return err
+	}
+	res, err := Hash[T](api, c.Msg, c.dst, len(c.Expected))
+	if err != nil {
+		return err
+	}
+	for i := range c.Expected {
+		f.AssertIsEqual(res[i], &c.Expected[i])
+	}
+	return nil
+}
+
+func TestHashBLS12381(t *testing.T) {
+	assert := test.NewAssert(t)
+	msg := []byte("abcdef0123456789")
+	dst := []byte("QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_RO_")
+	expected, err := fp_bls12381.Hash(msg, dst, 4)
+	assert.NoError(err)
+	circuit := hashCircuit[emulated.BLS12381Fp]{
+		Msg:      make([]uints.U8, len(msg)),
+		Expected: make([]emulated.Element[emulated.BLS12381Fp], len(expected)),
+		dst:      dst,
+	}
+	witness := hashCircuit[emulated.BLS12381Fp]{
+		Msg:      uints.NewU8Array(msg),
+		Expected: make([]emulated.Element[emulated.BLS12381Fp], len(expected)),
+	}
+	for i := range expected {
+		witness.Expected[i] = emulated.ValueOf[emulated.BLS12381Fp](expected[i])
+	}
+	err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+func TestHashBN254(t *testing.T) {
+	assert := test.NewAssert(t)
+	msg := []byte("abc")
+	dst := []byte("QUUX-V01-CS02-with-BN254G1_XMD:SHA-256_SVDW_RO_")
+	expected, err := fp_bn254.Hash(msg, dst, 2)
+	assert.NoError(err)
+	circuit := hashCircuit[emulated.BN254Fp]{
+		Msg:      make([]uints.U8, len(msg)),
+		Expected: make([]emulated.Element[emulated.BN254Fp], len(expected)),
+		dst:      dst,
+	}
+	witness := hashCircuit[emulated.BN254Fp]{
+		Msg:      uints.NewU8Array(msg),
+		Expected: make([]emulated.Element[emulated.BN254Fp], len(expected)),
+	}
+	for i := range expected {
+		witness.Expected[i] = emulated.ValueOf[emulated.BN254Fp](expected[i])
+	}
+	err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
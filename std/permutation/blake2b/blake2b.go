@@ -0,0 +1,79 @@
+// Package blake2b implements the BLAKE2b compression function F.
+//
+// The compression function is defined in [RFC 7693] and is parametrized by the
+// number of rounds as in [EIP-152]. BLAKE2b uses little-endian 64-bit words.
+//
+// [RFC 7693]: https://www.rfc-editor.org/rfc/rfc7693
+// [EIP-152]: https://eips.ethereum.org/EIPS/eip-152
+package blake2b
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+var _IV = uints.NewU64Array([]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+})
+
+var _sigma = [10][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// Compress applies rounds rounds of the BLAKE2b compression function F on the
+// state h, message block m and offset counter t. The boolean final indicates
+// whether this is the final block. The number of rounds defines the size of
+// the circuit and thus has to be known at compile time.
+func Compress(api frontend.API, uapi *uints.BinaryField[uints.U64], rounds int, h [8]uints.U64, m [16]uints.U64, t [2]uints.U64, final frontend.Variable) [8]uints.U64 {
+	var v [16]uints.U64
+	copy(v[:8], h[:])
+	copy(v[8:], _IV)
+	v[12] = uapi.Xor(v[12], t[0])
+	v[13] = uapi.Xor(v[13], t[1])
+
+	// invert all bits of v[14] if final block by XORing with all-ones mask.
+	api.AssertIsBoolean(final)
+	var mask uints.U64
+	for i := range mask {
+		mask[i] = uapi.ByteValueOf(api.Mul(final, 0xff))
+	}
+	v[14] = uapi.Xor(v[14], mask)
+
+	g := func(a, b, c, d int, x, y uints.U64) {
+		v[a] = uapi.Add(v[a], v[b], x)
+		v[d] = uapi.Lrot(uapi.Xor(v[d], v[a]), -32)
+		v[c] = uapi.Add(v[c], v[d])
+		v[b] = uapi.Lrot(uapi.Xor(v[b], v[c]), -24)
+		v[a] = uapi.Add(v[a], v[b], y)
+		v[d] = uapi.Lrot(uapi.Xor(v[d], v[a]), -16)
+		v[c] = uapi.Add(v[c], v[d])
+		v[b] = uapi.Lrot(uapi.Xor(v[b], v[c]), -63)
+	}
+
+	for i := 0; i < rounds; i++ {
+		s := _sigma[i%10]
+		g(0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	for i := 0; i < 8; i++ {
+		h[i] = uapi.Xor(h[i], v[i], v[i+8])
+	}
+	return h
+}
@@ -0,0 +1,78 @@
+package blake2b_test
+
+import (
+	"encoding/binary"
+	"strconv"
+	"testing"
+
+	"github.com/consensys/gnark/std/math/uints"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	compress "github.com/consensys/gnark/std/permutation/blake2b"
+	"github.com/consensys/gnark/test"
+)
+
+type blake2bCircuit struct {
+	H        [8]uints.U64
+	M        [16]uints.U64
+	T        [2]uints.U64
+	Final    frontend.Variable
+	Expected [8]uints.U64 `gnark:",public"`
+}
+
+func (c *blake2bCircuit) Define(api frontend.API) error {
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		return err
+	}
+	res := compress.Compress(api, uapi, 12, c.H, c.M, c.T, c.Final)
+	for i := range res {
+		uapi.AssertEq(res[i], c.Expected[i])
+	}
+	return nil
+}
+
+// TestCompress checks the compression of the single block of a message
+// against the BLAKE2b-512 digest of the message.
+func TestCompress(t *testing.T) {
+	iv := [8]uint64{
+		0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+		0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+	}
+	assert := test.NewAssert(t)
+	for _, size := range []int{0, 3, 128} {
+		msg := make([]byte, size)
+		for i := range msg {
+			msg[i] = byte(i)
+		}
+		digest := blake2b.Sum512(msg)
+		var block [128]byte
+		copy(block[:], msg)
+
+		witness := blake2bCircuit{Final: 1}
+		for i := range witness.H {
+			h := iv[i]
+			if i == 0 {
+				// digest length 64, no key, fanout and depth 1
+				h ^= 0x01010040
+			}
+			witness.H[i] = uints.NewU64(h)
+			witness.Expected[i] = uints.NewU64(binary.LittleEndian.Uint64(digest[8*i:]))
+		}
+		for i := range witness.M {
+			witness.M[i] = uints.NewU64(binary.LittleEndian.Uint64(block[8*i:]))
+		}
+		witness.T = [2]uints.U64{uints.NewU64(uint64(size)), uints.NewU64(0)}
+
+		assert.Run(func(assert *test.Assert) {
+			assert.ProverSucceeded(&blake2bCircuit{}, &witness,
+				test.WithCurves(ecc.BN254),
+				test.WithBackends(backend.GROTH16, backend.PLONK),
+				test.NoProverChecks(),
+				test.NoFuzzing())
+		}, "size", strconv.Itoa(size))
+	}
+}
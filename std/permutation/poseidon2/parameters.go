@@ -0,0 +1,201 @@
+package poseidon2
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/internal/utils"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	ErrInvalidSizebuffer = errors.New("the size of the input should match the size of the hash buffer")
+	ErrUnsupportedWidth  = errors.New("only widths 2 and 3 are supported")
+)
+
+const (
+	// defaultWidth is the width of the permutation used for hashing in
+	// Merkle-Damgård mode (one element of state, one element of input).
+	defaultWidth = 2
+	// defaultNbFullRounds is the number of full rounds, split evenly before
+	// and after the partial rounds.
+	defaultNbFullRounds = 8
+	// defaultNbPartialRounds is the number of partial rounds. It is chosen
+	// for 128 bits of security with S-box degree 5 and is conservative for
+	// higher degrees.
+	defaultNbPartialRounds = 56
+)
+
+// Parameters describe a Poseidon2 instance over a prime field.
+type Parameters struct {
+	// Width is the number of field elements in the state.
+	Width int
+
+	// DegreeSBox is the exponent d of the S-box x ↦ xᵈ. It is the smallest
+	// integer d > 1 such that gcd(d, p-1) = 1.
+	DegreeSBox int
+
+	// NbFullRounds is the number of full rounds. Half of them are performed
+	// before the partial rounds and half of them after.
+	NbFullRounds int
+
+	// NbPartialRounds is the number of partial rounds.
+	NbPartialRounds int
+
+	// RoundKeys are the round constants. Full rounds have Width constants,
+	// partial rounds have a single constant applied to the first element.
+	RoundKeys [][]*big.Int
+
+	modulus *big.Int
+}
+
+// NewParameters returns the Poseidon2 parameters over the field defined by
+// modulus. The round keys are derived deterministically from the description
+// of the instance (see [Parameters.String]).
+func NewParameters(modulus *big.Int, width, nbFullRounds, nbPartialRounds int) (*Parameters, error) {
+	if width != 2 && width != 3 {
+		return nil, ErrUnsupportedWidth
+	}
+	if nbFullRounds%2 != 0 {
+		return nil, errors.New("the number of full rounds must be even")
+	}
+	d, err := sboxDegree(modulus)
+	if err != nil {
+		return nil, err
+	}
+	p := &Parameters{
+		Width:           width,
+		DegreeSBox:      d,
+		NbFullRounds:    nbFullRounds,
+		NbPartialRounds: nbPartialRounds,
+		modulus:         new(big.Int).Set(modulus),
+	}
+	p.initRC(p.String())
+	return p, nil
+}
+
+// GetDefaultParameters returns the parameters used by the Poseidon2 hash
+// function over the field defined by modulus.
+func GetDefaultParameters(modulus *big.Int) (*Parameters, error) {
+	return NewParameters(modulus, defaultWidth, defaultNbFullRounds, defaultNbPartialRounds)
+}
+
+// String returns a description of the instance. It is used as seed for
+// deriving the round keys, as in gnark-crypto.
+func (p *Parameters) String() string {
+	field := "0x" + p.modulus.Text(16)
+	if curve := utils.FieldToCurve(p.modulus); curve != ecc.UNKNOWN {
+		field = strings.ToUpper(curve.String())
+	}
+	return fmt.Sprintf("Poseidon2-%s[t=%d,rF=%d,rP=%d,d=%d]", field, p.Width, p.NbFullRounds, p.NbPartialRounds, p.DegreeSBox)
+}
+
+// Modulus returns the modulus of the field the instance is defined over.
+func (p *Parameters) Modulus() *big.Int {
+	return new(big.Int).Set(p.modulus)
+}
+
+// initRC derives the round keys from the seed by iterating Keccak-256.
+func (p *Parameters) initRC(seed string) {
+	h := sha3.NewLegacyKeccak256()
+	_, _ = h.Write([]byte(seed))
+	rnd := h.Sum(nil) // pre hash before use
+
+	next := func() *big.Int {
+		h.Reset()
+		_, _ = h.Write(rnd)
+		rnd = h.Sum(nil)
+		return new(big.Int).Mod(new(big.Int).SetBytes(rnd), p.modulus)
+	}
+
+	rf := p.NbFullRounds / 2
+	p.RoundKeys = make([][]*big.Int, p.NbFullRounds+p.NbPartialRounds)
+	for i := range p.RoundKeys {
+		n := p.Width
+		if i >= rf && i < rf+p.NbPartialRounds {
+			n = 1
+		}
+		p.RoundKeys[i] = make([]*big.Int, n)
+		for j := range p.RoundKeys[i] {
+			p.RoundKeys[i][j] = next()
+		}
+	}
+}
+
+// Permutation applies the permutation on the input natively. The input is
+// modified in place and its length must be equal to Width.
+func (p *Parameters) Permutation(input []*big.Int) error {
+	if len(input) != p.Width {
+		return ErrInvalidSizebuffer
+	}
+	for i := range input {
+		input[i].Mod(input[i], p.modulus)
+	}
+	d := big.NewInt(int64(p.DegreeSBox))
+	sbox := func(x *big.Int) {
+		x.Exp(x, d, p.modulus)
+	}
+	rf := p.NbFullRounds / 2
+
+	p.matMulExternalNative(input)
+	for i := 0; i < rf; i++ {
+		for j := range input {
+			input[j].Add(input[j], p.RoundKeys[i][j])
+			sbox(input[j])
+		}
+		p.matMulExternalNative(input)
+	}
+	for i := rf; i < rf+p.NbPartialRounds; i++ {
+		input[0].Add(input[0], p.RoundKeys[i][0])
+		sbox(input[0])
+		p.matMulInternalNative(input)
+	}
+	for i := rf + p.NbPartialRounds; i < p.NbFullRounds+p.NbPartialRounds; i++ {
+		for j := range input {
+			input[j].Add(input[j], p.RoundKeys[i][j])
+			sbox(input[j])
+		}
+		p.matMulExternalNative(input)
+	}
+	return nil
+}
+
+// matMulExternalNative multiplies the state by circ(2, 1, ..., 1).
+func (p *Parameters) matMulExternalNative(s []*big.Int) {
+	sum := new(big.Int)
+	for i := range s {
+		sum.Add(sum, s[i])
+	}
+	for i := range s {
+		s[i].Add(s[i], sum).Mod(s[i], p.modulus)
+	}
+}
+
+// matMulInternalNative multiplies the state by 1 + diag(1, ..., 1, 2).
+func (p *Parameters) matMulInternalNative(s []*big.Int) {
+	sum := new(big.Int)
+	for i := range s {
+		sum.Add(sum, s[i])
+	}
+	last := len(s) - 1
+	for i := 0; i < last; i++ {
+		s[i].Add(s[i], sum).Mod(s[i], p.modulus)
+	}
+	s[last].Lsh(s[last], 1).Add(s[last], sum).Mod(s[last], p.modulus)
+}
+
+// sboxDegree returns the smallest d > 1 such that x ↦ xᵈ is a permutation of
+// the field, i.e. gcd(d, p-1) = 1.
+func sboxDegree(modulus *big.Int) (int, error) {
+	pMinusOne := new(big.Int).Sub(modulus, big.NewInt(1))
+	var gcd big.Int
+	for d := int64(3); d < 256; d += 2 {
+		if gcd.GCD(nil, nil, big.NewInt(d), pMinusOne).IsInt64() && gcd.Int64() == 1 {
+			return int(d), nil
+		}
+	}
+	return 0, errors.New("no suitable S-box degree found")
+}
@@ -0,0 +1,131 @@
+// Package poseidon2 implements the Poseidon2 permutation.
+//
+// Poseidon2 is a permutation over a prime field designed to be efficient in
+// arithmetic circuits. See the [original paper] by Grassi, Khovratovich and
+// Schofnegger for the full details.
+//
+// This package exposes both the in-circuit permutation [Permutation] and the
+// native permutation [Parameters.Permutation] over the same instance. For
+// hashing, see [github.com/consensys/gnark/std/hash/poseidon2] which applies
+// the Merkle-Damgård construction on top of the compression function
+// [Permutation.Compress].
+//
+// The default instance has width 2, 8 full rounds and 56 partial rounds and the
+// S-box degree is the smallest d such that x ↦ xᵈ is a permutation of the
+// field. The instances are the ones of gnark-crypto, except over the scalar
+// fields of BLS12-377 and BLS24-315 where gnark-crypto uses other degrees.
+//
+// [original paper]: https://eprint.iacr.org/2023/323.pdf
+package poseidon2
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// Permutation stores the buffer of the Poseidon2 permutation and provides
+// Poseidon2 permutation methods on the buffer.
+type Permutation struct {
+	api    frontend.API
+	params *Parameters
+}
+
+// NewPoseidon2 returns a new Poseidon2 permutation instance with the default
+// parameters for the native field.
+func NewPoseidon2(api frontend.API) (*Permutation, error) {
+	params, err := GetDefaultParameters(api.Compiler().Field())
+	if err != nil {
+		return nil, err
+	}
+	return &Permutation{api: api, params: params}, nil
+}
+
+// NewPoseidon2FromParameters returns a new Poseidon2 permutation instance with
+// the given width and number of rounds over the native field.
+func NewPoseidon2FromParameters(api frontend.API, width, nbFullRounds, nbPartialRounds int) (*Permutation, error) {
+	params, err := NewParameters(api.Compiler().Field(), width, nbFullRounds, nbPartialRounds)
+	if err != nil {
+		return nil, err
+	}
+	return &Permutation{api: api, params: params}, nil
+}
+
+// Parameters returns the parameters of the permutation instance.
+func (h *Permutation) Parameters() *Parameters {
+	return h.params
+}
+
+// Permutation applies the permutation on the input. The input is modified in
+// place and its length must be equal to the width of the instance.
+func (h *Permutation) Permutation(input []frontend.Variable) error {
+	if len(input) != h.params.Width {
+		return ErrInvalidSizebuffer
+	}
+	rf := h.params.NbFullRounds / 2
+
+	h.matMulExternalInPlace(input)
+	for i := 0; i < rf; i++ {
+		h.fullRound(input, i)
+	}
+	for i := rf; i < rf+h.params.NbPartialRounds; i++ {
+		input[0] = h.api.Add(input[0], h.params.RoundKeys[i][0])
+		input[0] = h.sBox(input[0])
+		h.matMulInternalInPlace(input)
+	}
+	for i := rf + h.params.NbPartialRounds; i < h.params.NbFullRounds+h.params.NbPartialRounds; i++ {
+		h.fullRound(input, i)
+	}
+	return nil
+}
+
+// Compress applies the permutation on left and right and returns the right
+// element of the output added to right (feed-forward). It is the compression
+// function used for the Merkle-Damgård construction and Merkle trees and is
+// only defined for instances of width 2.
+func (h *Permutation) Compress(left, right frontend.Variable) frontend.Variable {
+	vars := [2]frontend.Variable{left, right}
+	if err := h.Permutation(vars[:]); err != nil {
+		panic(err) // this would never happen
+	}
+	return h.api.Add(vars[1], right)
+}
+
+func (h *Permutation) fullRound(input []frontend.Variable, round int) {
+	for j := range input {
+		input[j] = h.api.Add(input[j], h.params.RoundKeys[round][j])
+		input[j] = h.sBox(input[j])
+	}
+	h.matMulExternalInPlace(input)
+}
+
+// sBox returns xᵈ using square-and-multiply.
+func (h *Permutation) sBox(x frontend.Variable) frontend.Variable {
+	d := big.NewInt(int64(h.params.DegreeSBox))
+	res := x
+	for i := d.BitLen() - 2; i >= 0; i-- {
+		res = h.api.Mul(res, res)
+		if d.Bit(i) == 1 {
+			res = h.api.Mul(res, x)
+		}
+	}
+	return res
+}
+
+// matMulExternalInPlace multiplies the state by circ(2, 1, ..., 1).
+func (h *Permutation) matMulExternalInPlace(s []frontend.Variable) {
+	sum := h.api.Add(s[0], s[1], s[2:]...)
+	for i := range s {
+		s[i] = h.api.Add(s[i], sum)
+	}
+}
+
+// matMulInternalInPlace multiplies the state by 1 + diag(1, ..., 1, 2).
+func (h *Permutation) matMulInternalInPlace(s []frontend.Variable) {
+	sum := h.api.Add(s[0], s[1], s[2:]...)
+	last := len(s) - 1
+	for i := 0; i < last; i++ {
+		s[i] = h.api.Add(s[i], sum)
+	}
+	s[last] = h.api.Add(h.api.Mul(s[last], 2), sum)
+}
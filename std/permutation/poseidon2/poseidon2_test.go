@@ -0,0 +1,139 @@
+package poseidon2
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type poseidon2Circuit struct {
+	width    int
+	Input    []frontend.Variable
+	Expected []frontend.Variable `gnark:",public"`
+}
+
+func (c *poseidon2Circuit) Define(api frontend.API) error {
+	h, err := NewPoseidon2FromParameters(api, c.width, defaultNbFullRounds, defaultNbPartialRounds)
+	if err != nil {
+		return err
+	}
+	input := make([]frontend.Variable, len(c.Input))
+	copy(input, c.Input)
+	if err := h.Permutation(input); err != nil {
+		return err
+	}
+	for i := range input {
+		api.AssertIsEqual(input[i], c.Expected[i])
+	}
+	return nil
+}
+
+func TestPoseidon2(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, curve := range gnark.Curves() {
+		for _, width := range []int{2, 3} {
+			params, err := NewParameters(curve.ScalarField(), width, defaultNbFullRounds, defaultNbPartialRounds)
+			assert.NoError(err)
+
+			input := make([]*big.Int, width)
+			for i := range input {
+				input[i] = big.NewInt(int64(i))
+			}
+			output := make([]*big.Int, width)
+			for i := range output {
+				output[i] = new(big.Int).Set(input[i])
+			}
+			assert.NoError(params.Permutation(output))
+
+			circuit := poseidon2Circuit{width: width, Input: make([]frontend.Variable, width), Expected: make([]frontend.Variable, width)}
+			assignment := poseidon2Circuit{Input: make([]frontend.Variable, width), Expected: make([]frontend.Variable, width)}
+			invalid := poseidon2Circuit{Input: make([]frontend.Variable, width), Expected: make([]frontend.Variable, width)}
+			for i := 0; i < width; i++ {
+				assignment.Input[i] = input[i]
+				assignment.Expected[i] = output[i]
+				invalid.Input[i] = input[i]
+				invalid.Expected[i] = output[i]
+			}
+			invalid.Expected[0] = new(big.Int).Add(output[0], big.NewInt(1))
+
+			assert.CheckCircuit(&circuit,
+				test.WithValidAssignment(&assignment),
+				test.WithInvalidAssignment(&invalid),
+				test.WithCurves(curve),
+				test.NoFuzzing(), test.NoSerializationChecks())
+		}
+	}
+}
+
+func TestSBoxDegree(t *testing.T) {
+	assert := test.NewAssert(t)
+	one := big.NewInt(1)
+	for _, curve := range gnark.Curves() {
+		params, err := GetDefaultParameters(curve.ScalarField())
+		assert.NoError(err)
+		// d is the smallest integer such that gcd(d, p-1) = 1
+		pMinusOne := new(big.Int).Sub(curve.ScalarField(), one)
+		var gcd big.Int
+		for d := 2; d < params.DegreeSBox; d++ {
+			assert.NotEqual(0, gcd.GCD(nil, nil, big.NewInt(int64(d)), pMinusOne).Cmp(one), curve.String())
+		}
+		assert.Equal(0, gcd.GCD(nil, nil, big.NewInt(int64(params.DegreeSBox)), pMinusOne).Cmp(one), curve.String())
+	}
+	params, err := GetDefaultParameters(ecc.BN254.ScalarField())
+	assert.NoError(err)
+	assert.Equal(5, params.DegreeSBox)
+}
+
+func TestUnsupportedWidth(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, err := NewParameters(ecc.BN254.ScalarField(), 4, defaultNbFullRounds, defaultNbPartialRounds)
+	assert.ErrorIs(err, ErrUnsupportedWidth)
+}
+
+// TestKnownAnswers checks the permutation of (0, 1, ..., t-1) against the
+// outputs of gnark-crypto v0.18.0 (ecc/*/fr/poseidon2) with 8 full rounds and
+// 56 partial rounds.
+func TestKnownAnswers(t *testing.T) {
+	assert := test.NewAssert(t)
+	vectors := map[ecc.ID][2][]string{
+		ecc.BN254: {
+			{"111cbc7deb1c075b15d7bcff4b1aeb8700f8356dfa8db534c59adb38a6bdc488", "f17a6781f8987a971ca00fb8b7d68cb18be89ef4e486bcab05311a411d1f30"},
+			{"82f11489a46e970637851f0cd8ddf88979910f0f69e914a25c658774563d8f6", "1a0cfee223aaa444b1fc88e6c5dc5de298d59b412143c28c9ecdb9dc0adfb7b", "9461d5e8e0c3d71f60374bac02ae7003f288ef7d59e71341cab897972c4a37"},
+		},
+		ecc.BLS12_381: {
+			{"4c66ef965a12c4e5117a205bcc8c295424a0f036aa61811d3b6c759fc327a89e", "4db27f5b3e5cd12338508187e01a84f04ffa8c394cb3d296ac881e934c835e0"},
+			{"57e803ed9a1e1abf03e4b3f51d62cf5c9e220feedaff5ac67b8257010c967283", "67a4a7e120ec30e0692050825d73d66bd2bdd9a69116dfa48221c1cf9de13ccb", "6a5bd34fa242bb8c004054b6745181d5be34a56f3109641791bfefa67cca4c51"},
+		},
+		ecc.BLS24_317: {
+			{"20e1c8e24887aef990c22a52c81cfcdb665701233b43b86fbd890645b65ad6fd", "1f6dc7a9d5b86ac980156b17d9248eeebeee0eede6b52a5a78b01da8a3d6f36c"},
+			{"3f277e63ee5580f49b5d87b401c662b6bbde2c3411890e603b8091f06b4abe47", "1ea5fb3d4712448744a7a8cc805c3d2cc65b42d4eaa0d0822355dc16ce24f4a8", "259c0e8d7a7ce65a457a2f439aaa62130c51546f1402a00914036541370b1d44"},
+		},
+		ecc.BW6_633: {
+			{"1c918a885998a7e7650b54ef8342816a28492d351bd574f652fe089a1285a919a8af7f2ec77ae32", "262df912b73d39745065351421870302d7b53cc683f109b350a3d26ba51fd321ee99d310ab5e099"},
+			{"2ded4c517e7c460c37ed19ea0cac963aef28d11584b188bf27d5fb4e6de6e6072fc5f143ecd5ea", "4319a8d5185556b9ea0530c3bd0d5df51c4271694a3b910937a842a34525e78e98c707ade3bdf84", "3fc09e628d22da6e7a2db4217e56f06a17ec12452bb9d90ab248a53911581e2b945f1eebfb3361a"},
+		},
+		ecc.BW6_761: {
+			{"15f5cd5360aef137d77474ceacf9c51e6a895a3e149ed4d2668fd839a02e485951fb888933e203581a2fef1e6b5976b", "50d1a046efe883a11d4b4246efc22193547eb7a14fe47be7893ec67babb34842bb8e85a20f17450fc584a60561bbb0"},
+			{"10557491cf2d000ecb37587d5aee1fd46e7c3f26ebfc6b56bee2d114a1cfaaef90ac9b215d69426f19d0745d83be237", "9805c413cbe630730d0bfd3a2a709fcce1b6e1ba8cf3d3c083143bc21532ae442828ae66ec51310a5be8987ee00bb7", "19facba22ebffc4171a248855c0cdef0f559585f77bf6b7c10d2e58aad06875a02425d996f8f645565da3518aeca918"},
+		},
+	}
+	for curve, v := range vectors {
+		for _, expected := range v {
+			width := len(expected)
+			params, err := NewParameters(curve.ScalarField(), width, 8, 56)
+			assert.NoError(err)
+			state := make([]*big.Int, width)
+			for i := range state {
+				state[i] = big.NewInt(int64(i))
+			}
+			assert.NoError(params.Permutation(state))
+			for i := range state {
+				assert.Equal(expected[i], state[i].Text(16), "%s width %d", curve, width)
+			}
+		}
+	}
+}
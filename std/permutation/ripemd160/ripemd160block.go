@@ -0,0 +1,106 @@
+// Package ripemd160 implements the RIPEMD-160 compression function.
+//
+// This package exposes only the compression function. For the full hash
+// function, see [github.com/consensys/gnark/std/hash/ripemd160] package.
+//
+// RIPEMD-160 uses little-endian words, unlike SHA2.
+package ripemd160
+
+import (
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// message word selection for the left and right lines.
+var _r = [80]int{
+	0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+	7, 4, 13, 1, 10, 6, 15, 3, 12, 0, 9, 5, 2, 14, 11, 8,
+	3, 10, 14, 4, 9, 15, 8, 1, 2, 7, 0, 6, 13, 11, 5, 12,
+	1, 9, 11, 10, 0, 8, 12, 4, 13, 3, 7, 15, 14, 5, 6, 2,
+	4, 0, 5, 9, 7, 12, 2, 10, 14, 1, 3, 8, 11, 6, 15, 13,
+}
+
+var _rp = [80]int{
+	5, 14, 7, 0, 9, 2, 11, 4, 13, 6, 15, 8, 1, 10, 3, 12,
+	6, 11, 3, 7, 0, 13, 5, 10, 14, 15, 8, 12, 4, 9, 1, 2,
+	15, 5, 1, 3, 7, 14, 6, 9, 11, 8, 12, 2, 10, 0, 4, 13,
+	8, 6, 4, 1, 3, 11, 15, 0, 5, 12, 2, 13, 9, 7, 10, 14,
+	12, 15, 10, 4, 1, 5, 8, 7, 6, 2, 13, 14, 0, 3, 9, 11,
+}
+
+// rotation amounts for the left and right lines.
+var _s = [80]int{
+	11, 14, 15, 12, 5, 8, 7, 9, 11, 13, 14, 15, 6, 7, 9, 8,
+	7, 6, 8, 13, 11, 9, 7, 15, 7, 12, 15, 9, 11, 7, 13, 12,
+	11, 13, 6, 7, 14, 9, 13, 15, 14, 8, 13, 6, 5, 12, 7, 5,
+	11, 12, 14, 15, 14, 15, 9, 8, 9, 14, 5, 6, 8, 6, 5, 12,
+	9, 15, 5, 11, 6, 8, 13, 12, 5, 12, 13, 14, 11, 8, 5, 6,
+}
+
+var _sp = [80]int{
+	8, 9, 9, 11, 13, 15, 15, 5, 7, 7, 8, 11, 14, 14, 12, 6,
+	9, 13, 15, 7, 12, 8, 9, 11, 7, 7, 12, 7, 6, 15, 13, 11,
+	9, 7, 15, 11, 8, 6, 6, 14, 12, 13, 5, 14, 13, 13, 7, 5,
+	15, 5, 8, 11, 14, 14, 6, 14, 6, 9, 12, 9, 12, 5, 15, 8,
+	8, 5, 12, 9, 12, 5, 14, 6, 8, 13, 6, 5, 15, 13, 11, 11,
+}
+
+var _K = uints.NewU32Array([]uint32{0x00000000, 0x5a827999, 0x6ed9eba1, 0x8f1bbcdc, 0xa953fd4e})
+var _Kp = uints.NewU32Array([]uint32{0x50a28be6, 0x5c4dd124, 0x6d703ef3, 0x7a6d76e9, 0x00000000})
+
+// Permute applies the RIPEMD-160 compression function on the 64-byte block p
+// given the current hash state.
+func Permute(uapi *uints.BinaryField[uints.U32], currentHash [5]uints.U32, p [64]uints.U8) (newHash [5]uints.U32) {
+	var x [16]uints.U32
+	for i := range x {
+		x[i] = uapi.PackLSB(p[4*i], p[4*i+1], p[4*i+2], p[4*i+3])
+	}
+
+	al, bl, cl, dl, el := currentHash[0], currentHash[1], currentHash[2], currentHash[3], currentHash[4]
+	ar, br, cr, dr, er := currentHash[0], currentHash[1], currentHash[2], currentHash[3], currentHash[4]
+
+	for j := 0; j < 80; j++ {
+		round := j / 16
+		// left line uses the boolean functions f1..f5, right line f5..f1.
+		t := uapi.Add(al, f(uapi, round, bl, cl, dl), x[_r[j]], _K[round])
+		t = uapi.Add(uapi.Lrot(t, _s[j]), el)
+		al, el, dl, cl, bl = el, dl, uapi.Lrot(cl, 10), bl, t
+
+		t = uapi.Add(ar, f(uapi, 4-round, br, cr, dr), x[_rp[j]], _Kp[round])
+		t = uapi.Add(uapi.Lrot(t, _sp[j]), er)
+		ar, er, dr, cr, br = er, dr, uapi.Lrot(cr, 10), br, t
+	}
+
+	t := uapi.Add(currentHash[1], cl, dr)
+	currentHash[1] = uapi.Add(currentHash[2], dl, er)
+	currentHash[2] = uapi.Add(currentHash[3], el, ar)
+	currentHash[3] = uapi.Add(currentHash[4], al, br)
+	currentHash[4] = uapi.Add(currentHash[0], bl, cr)
+	currentHash[0] = t
+
+	return currentHash
+}
+
+// f computes the boolean function of the given round. As the binary field
+// doesn't provide OR, it is expressed using the other operations: when the
+// operands of OR are disjoint it equals XOR, otherwise we use De Morgan's law.
+func f(uapi *uints.BinaryField[uints.U32], round int, x, y, z uints.U32) uints.U32 {
+	switch round {
+	case 0:
+		// x ^ y ^ z
+		return uapi.Xor(x, y, z)
+	case 1:
+		// (x & y) | (^x & z)
+		return uapi.Xor(uapi.And(x, y), uapi.And(uapi.Not(x), z))
+	case 2:
+		// (x | ^y) ^ z
+		return uapi.Xor(uapi.Not(uapi.And(uapi.Not(x), y)), z)
+	case 3:
+		// (x & z) | (y & ^z)
+		return uapi.Xor(uapi.And(x, z), uapi.And(y, uapi.Not(z)))
+	case 4:
+		// x ^ (y | ^z)
+		return uapi.Xor(x, uapi.Not(uapi.And(uapi.Not(y), z)))
+	default:
+		panic("invalid round")
+	}
+}
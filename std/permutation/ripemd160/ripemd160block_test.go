@@ -0,0 +1,71 @@
+package ripemd160_test
+
+import (
+	"encoding/binary"
+	"strconv"
+	"testing"
+
+	"github.com/consensys/gnark/std/math/uints"
+	"golang.org/x/crypto/ripemd160" //nolint:staticcheck // reference implementation
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	permutation "github.com/consensys/gnark/std/permutation/ripemd160"
+	"github.com/consensys/gnark/test"
+)
+
+type ripemd160Circuit struct {
+	CurrentHash [5]uints.U32
+	In          [64]uints.U8
+	Expected    [5]uints.U32 `gnark:",public"`
+}
+
+func (c *ripemd160Circuit) Define(api frontend.API) error {
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		return err
+	}
+	res := permutation.Permute(uapi, c.CurrentHash, c.In)
+	for i := range res {
+		uapi.AssertEq(res[i], c.Expected[i])
+	}
+	return nil
+}
+
+// TestPermute checks the compression of the single padded block of a message
+// against the RIPEMD-160 digest of the message.
+func TestPermute(t *testing.T) {
+	iv := [5]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476, 0xc3d2e1f0}
+	assert := test.NewAssert(t)
+	for _, size := range []int{0, 3, 55} {
+		msg := make([]byte, size)
+		for i := range msg {
+			msg[i] = byte(i)
+		}
+		h := ripemd160.New()
+		h.Write(msg)
+		digest := h.Sum(nil)
+		var block [64]byte
+		copy(block[:], msg)
+		block[size] = 0x80
+		binary.LittleEndian.PutUint64(block[56:], uint64(size)*8)
+
+		var witness ripemd160Circuit
+		for i := range witness.CurrentHash {
+			witness.CurrentHash[i] = uints.NewU32(iv[i])
+			witness.Expected[i] = uints.NewU32(binary.LittleEndian.Uint32(digest[4*i:]))
+		}
+		for i := range witness.In {
+			witness.In[i] = uints.NewU8(block[i])
+		}
+
+		assert.Run(func(assert *test.Assert) {
+			assert.ProverSucceeded(&ripemd160Circuit{}, &witness,
+				test.WithCurves(ecc.BN254),
+				test.WithBackends(backend.GROTH16, backend.PLONK),
+				test.NoProverChecks(),
+				test.NoFuzzing())
+		}, "size", strconv.Itoa(size))
+	}
+}
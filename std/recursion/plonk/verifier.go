@@ -67,6 +67,9 @@ func ValueOfProof[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra
 		if !ok {
 			return ret, fmt.Errorf("expected sw_bls12377.Proof, got %T", proof)
 		}
+		if len(tProof.W) > 0 {
+			return ret, fmt.Errorf("constraints with more than 3 wires are not supported in recursion, got %d wires", 3+len(tProof.W))
+		}
 		for i := range r.LRO {
 			r.LRO[i], err = kzg.ValueOfCommitment[sw_bls12377.G1Affine](tProof.LRO[i])
 			if err != nil {
@@ -104,6 +107,9 @@ func ValueOfProof[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra
 		if !ok {
 			return ret, fmt.Errorf("expected sw_bls12381.Proof, got %T", proof)
 		}
+		if len(tProof.W) > 0 {
+			return ret, fmt.Errorf("constraints with more than 3 wires are not supported in recursion, got %d wires", 3+len(tProof.W))
+		}
 		for i := range r.LRO {
 			r.LRO[i], err = kzg.ValueOfCommitment[sw_bls12381.G1Affine](tProof.LRO[i])
 			if err != nil {
@@ -141,6 +147,9 @@ func ValueOfProof[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra
 		if !ok {
 			return ret, fmt.Errorf("expected sw_bls24315.Proof, got %T", proof)
 		}
+		if len(tProof.W) > 0 {
+			return ret, fmt.Errorf("constraints with more than 3 wires are not supported in recursion, got %d wires", 3+len(tProof.W))
+		}
 		for i := range r.LRO {
 			r.LRO[i], err = kzg.ValueOfCommitment[sw_bls24315.G1Affine](tProof.LRO[i])
 			if err != nil {
@@ -179,6 +188,9 @@ func ValueOfProof[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra
 		if !ok {
 			return ret, fmt.Errorf("expected sw_bls12377.Proof, got %T", proof)
 		}
+		if len(tProof.W) > 0 {
+			return ret, fmt.Errorf("constraints with more than 3 wires are not supported in recursion, got %d wires", 3+len(tProof.W))
+		}
 		for i := range r.LRO {
 			r.LRO[i], err = kzg.ValueOfCommitment[sw_bw6761.G1Affine](tProof.LRO[i])
 			if err != nil {
@@ -216,6 +228,9 @@ func ValueOfProof[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra
 		if !ok {
 			return ret, fmt.Errorf("expected sw_bls12377.Proof, got %T", proof)
 		}
+		if len(tProof.W) > 0 {
+			return ret, fmt.Errorf("constraints with more than 3 wires are not supported in recursion, got %d wires", 3+len(tProof.W))
+		}
 		for i := range r.LRO {
 			r.LRO[i], err = kzg.ValueOfCommitment[sw_bn254.G1Affine](tProof.LRO[i])
 			if err != nil {
@@ -380,7 +395,7 @@ func ValueOfBaseVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT, G
 
 // ValueOfCircuitVerifyingKey returns the witness for the unique part of the
 // verification key. Returns an error if there is a mismatch between type
-// arguments and given witness.
+// arguments and given witness, or if the key has custom gates.
 func ValueOfCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT](vk backend_plonk.VerifyingKey) (CircuitVerifyingKey[FR, G1El], error) {
 	var ret CircuitVerifyingKey[FR, G1El]
 	var err error
@@ -390,6 +405,12 @@ func ValueOfCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT
 		if !ok {
 			return ret, fmt.Errorf("expected bls12377.VerifyingKey, got %T", vk)
 		}
+		if len(tVk.Qg) > 0 {
+			return ret, fmt.Errorf("custom gates are not supported in recursion, got %d", len(tVk.Qg))
+		}
+		if len(tVk.Qw) > 0 {
+			return ret, fmt.Errorf("constraints with more than 3 wires are not supported in recursion, got %d wires", 3+len(tVk.Qw))
+		}
 		r.Size = tVk.Size
 		r.SizeInv = sw_bls12377.NewScalar(tVk.SizeInv)
 		r.Generator = sw_bls12377.NewScalar(tVk.Generator)
@@ -435,6 +456,12 @@ func ValueOfCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT
 		if !ok {
 			return ret, fmt.Errorf("expected bls12381.VerifyingKey, got %T", vk)
 		}
+		if len(tVk.Qg) > 0 {
+			return ret, fmt.Errorf("custom gates are not supported in recursion, got %d", len(tVk.Qg))
+		}
+		if len(tVk.Qw) > 0 {
+			return ret, fmt.Errorf("constraints with more than 3 wires are not supported in recursion, got %d wires", 3+len(tVk.Qw))
+		}
 		r.Size = tVk.Size
 		r.SizeInv = sw_bls12381.NewScalar(tVk.SizeInv)
 		r.Generator = sw_bls12381.NewScalar(tVk.Generator)
@@ -480,6 +507,12 @@ func ValueOfCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT
 		if !ok {
 			return ret, fmt.Errorf("expected bls24315.VerifyingKey, got %T", vk)
 		}
+		if len(tVk.Qg) > 0 {
+			return ret, fmt.Errorf("custom gates are not supported in recursion, got %d", len(tVk.Qg))
+		}
+		if len(tVk.Qw) > 0 {
+			return ret, fmt.Errorf("constraints with more than 3 wires are not supported in recursion, got %d wires", 3+len(tVk.Qw))
+		}
 		r.Size = tVk.Size
 		r.SizeInv = sw_bls24315.NewScalar(tVk.SizeInv)
 		r.Generator = sw_bls24315.NewScalar(tVk.Generator)
@@ -525,6 +558,12 @@ func ValueOfCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT
 		if !ok {
 			return ret, fmt.Errorf("expected bls12377.VerifyingKey, got %T", vk)
 		}
+		if len(tVk.Qg) > 0 {
+			return ret, fmt.Errorf("custom gates are not supported in recursion, got %d", len(tVk.Qg))
+		}
+		if len(tVk.Qw) > 0 {
+			return ret, fmt.Errorf("constraints with more than 3 wires are not supported in recursion, got %d wires", 3+len(tVk.Qw))
+		}
 		r.Size = tVk.Size
 		r.SizeInv = sw_bw6761.NewScalar(tVk.SizeInv)
 		r.Generator = sw_bw6761.NewScalar(tVk.Generator)
@@ -570,6 +609,12 @@ func ValueOfCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT
 		if !ok {
 			return ret, fmt.Errorf("expected bn254.VerifyingKey, got %T", vk)
 		}
+		if len(tVk.Qg) > 0 {
+			return ret, fmt.Errorf("custom gates are not supported in recursion, got %d", len(tVk.Qg))
+		}
+		if len(tVk.Qw) > 0 {
+			return ret, fmt.Errorf("constraints with more than 3 wires are not supported in recursion, got %d wires", 3+len(tVk.Qw))
+		}
 		r.Size = tVk.Size
 		r.SizeInv = sw_bn254.NewScalar(tVk.SizeInv)
 		r.Generator = sw_bn254.NewScalar(tVk.Generator)
@@ -0,0 +1,200 @@
+package bls
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// MinPk verifies BLS signatures in the minimal-pubkey-size variant, where the
+// public keys are in G1 and the signatures and hashed messages in G2.
+//
+// The type parameters define the pairing and currently the BLS12-381 and BN254
+// groups of [sw_bls12381] and [sw_bn254] are supported.
+type MinPk[G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT] struct {
+	pairing algebra.Pairing[G1El, G2El, GtEl]
+	curve   curve[G1El, G2El]
+	dst     []byte
+}
+
+// NewMinPk returns a new verifier in the minimal-pubkey-size variant with the
+// domain separation tag dst used for hashing the messages to G2.
+func NewMinPk[G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT](api frontend.API, dst []byte) (*MinPk[G1El, G2El, GtEl], error) {
+	pairing, err := algebra.GetPairing[G1El, G2El, GtEl](api)
+	if err != nil {
+		return nil, fmt.Errorf("get pairing: %w", err)
+	}
+	c, err := getCurve[G1El, G2El](api)
+	if err != nil {
+		return nil, fmt.Errorf("get curve: %w", err)
+	}
+	return &MinPk[G1El, G2El, GtEl]{
+		pairing: pairing,
+		curve:   c,
+		dst:     dst,
+	}, nil
+}
+
+// Verify asserts that the signature sig verifies for the message msg and the
+// public key pk, i.e. that e(pk, H(msg)) == e(g₁, sig). It asserts that pk and
+// sig are in the prime order subgroups.
+func (v *MinPk[G1El, G2El, GtEl]) Verify(pk *G1El, msg []uints.U8, sig *G2El) error {
+	return v.AggregateVerify([]*G1El{pk}, [][]uints.U8{msg}, sig)
+}
+
+// AggregateVerify asserts that the aggregate signature sig verifies for the
+// messages msgs and the corresponding public keys pks, i.e. that
+// ∏ᵢ e(pkᵢ, H(msgᵢ)) == e(g₁, sig). It asserts that all public keys and sig
+// are in the prime order subgroups.
+//
+// The messages are not checked to be distinct. In the basic scheme, where
+// distinct messages prevent rogue key attacks, this has to be ensured by the
+// caller.
+func (v *MinPk[G1El, G2El, GtEl]) AggregateVerify(pks []*G1El, msgs [][]uints.U8, sig *G2El) error {
+	if len(pks) == 0 || len(pks) != len(msgs) {
+		return errors.New("mismatching number of public keys and messages")
+	}
+	P := make([]*G1El, 0, len(pks)+1)
+	Q := make([]*G2El, 0, len(pks)+1)
+	for i := range pks {
+		v.pairing.AssertIsOnG1(pks[i])
+		h, err := v.curve.hashToG2(msgs[i], v.dst)
+		if err != nil {
+			return fmt.Errorf("hash to G2: %w", err)
+		}
+		P = append(P, pks[i])
+		Q = append(Q, h)
+	}
+	v.pairing.AssertIsOnG2(sig)
+	// the Miller loop stores the computed lines in the G2 inputs, copy not to
+	// modify the caller's points.
+	sigCopy := *sig
+	P = append(P, v.curve.negGeneratorG1())
+	Q = append(Q, &sigCopy)
+	return v.pairing.PairingCheck(P, Q)
+}
+
+// FastAggregateVerify asserts that the aggregate signature sig verifies for
+// the message msg signed by all public keys pks, i.e. that
+// e(∑ᵢ pkᵢ, H(msg)) == e(g₁, sig). It asserts that sig is in the prime order
+// subgroup.
+//
+// ⚠️  The public keys are not checked. As in the proof of possession scheme,
+// they have to be validated beforehand to prevent rogue key attacks, for
+// example by proving their possession or by committing to a known set of
+// keys.
+func (v *MinPk[G1El, G2El, GtEl]) FastAggregateVerify(pks []*G1El, msg []uints.U8, sig *G2El) error {
+	if len(pks) == 0 {
+		return errors.New("no public keys")
+	}
+	pk := pks[0]
+	for i := 1; i < len(pks); i++ {
+		pk = v.curve.addG1(pk, pks[i])
+	}
+	v.pairing.AssertIsOnG2(sig)
+	h, err := v.curve.hashToG2(msg, v.dst)
+	if err != nil {
+		return fmt.Errorf("hash to G2: %w", err)
+	}
+	// see [MinPk.AggregateVerify] for copying sig.
+	sigCopy := *sig
+	return v.pairing.PairingCheck([]*G1El{pk, v.curve.negGeneratorG1()}, []*G2El{h, &sigCopy})
+}
+
+// MinSig verifies BLS signatures in the minimal-signature-size variant, where
+// the public keys are in G2 and the signatures and hashed messages in G1.
+//
+// The type parameters define the pairing and currently the BLS12-381 and BN254
+// groups of [sw_bls12381] and [sw_bn254] are supported.
+type MinSig[G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT] struct {
+	pairing algebra.Pairing[G1El, G2El, GtEl]
+	curve   curve[G1El, G2El]
+	dst     []byte
+}
+
+// NewMinSig returns a new verifier in the minimal-signature-size variant with
+// the domain separation tag dst used for hashing the messages to G1.
+func NewMinSig[G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT](api frontend.API, dst []byte) (*MinSig[G1El, G2El, GtEl], error) {
+	pairing, err := algebra.GetPairing[G1El, G2El, GtEl](api)
+	if err != nil {
+		return nil, fmt.Errorf("get pairing: %w", err)
+	}
+	c, err := getCurve[G1El, G2El](api)
+	if err != nil {
+		return nil, fmt.Errorf("get curve: %w", err)
+	}
+	return &MinSig[G1El, G2El, GtEl]{
+		pairing: pairing,
+		curve:   c,
+		dst:     dst,
+	}, nil
+}
+
+// Verify asserts that the signature sig verifies for the message msg and the
+// public key pk, i.e. that e(H(msg), pk) == e(sig, g₂). It asserts that pk and
+// sig are in the prime order subgroups.
+func (v *MinSig[G1El, G2El, GtEl]) Verify(pk *G2El, msg []uints.U8, sig *G1El) error {
+	return v.AggregateVerify([]*G2El{pk}, [][]uints.U8{msg}, sig)
+}
+
+// AggregateVerify asserts that the aggregate signature sig verifies for the
+// messages msgs and the corresponding public keys pks, i.e. that
+// ∏ᵢ e(H(msgᵢ), pkᵢ) == e(sig, g₂). It asserts that all public keys and sig
+// are in the prime order subgroups.
+//
+// The messages are not checked to be distinct. In the basic scheme, where
+// distinct messages prevent rogue key attacks, this has to be ensured by the
+// caller.
+func (v *MinSig[G1El, G2El, GtEl]) AggregateVerify(pks []*G2El, msgs [][]uints.U8, sig *G1El) error {
+	if len(pks) == 0 || len(pks) != len(msgs) {
+		return errors.New("mismatching number of public keys and messages")
+	}
+	P := make([]*G1El, 0, len(pks)+1)
+	Q := make([]*G2El, 0, len(pks)+1)
+	for i := range pks {
+		v.pairing.AssertIsOnG2(pks[i])
+		h, err := v.curve.hashToG1(msgs[i], v.dst)
+		if err != nil {
+			return fmt.Errorf("hash to G1: %w", err)
+		}
+		// the Miller loop stores the computed lines in the G2 inputs, copy not
+		// to modify the caller's points.
+		pk := *pks[i]
+		P = append(P, h)
+		Q = append(Q, &pk)
+	}
+	v.pairing.AssertIsOnG1(sig)
+	P = append(P, sig)
+	Q = append(Q, v.curve.negGeneratorG2())
+	return v.pairing.PairingCheck(P, Q)
+}
+
+// FastAggregateVerify asserts that the aggregate signature sig verifies for
+// the message msg signed by all public keys pks, i.e. that
+// e(H(msg), ∑ᵢ pkᵢ) == e(sig, g₂). It asserts that sig is in the prime order
+// subgroup.
+//
+// ⚠️  The public keys are not checked. As in the proof of possession scheme,
+// they have to be validated beforehand to prevent rogue key attacks, for
+// example by proving their possession or by committing to a known set of
+// keys.
+func (v *MinSig[G1El, G2El, GtEl]) FastAggregateVerify(pks []*G2El, msg []uints.U8, sig *G1El) error {
+	if len(pks) == 0 {
+		return errors.New("no public keys")
+	}
+	pk := pks[0]
+	for i := 1; i < len(pks); i++ {
+		pk = v.curve.addG2(pk, pks[i])
+	}
+	v.pairing.AssertIsOnG1(sig)
+	h, err := v.curve.hashToG1(msg, v.dst)
+	if err != nil {
+		return fmt.Errorf("hash to G1: %w", err)
+	}
+	// see [MinSig.AggregateVerify] for copying pk.
+	pkCopy := *pk
+	return v.pairing.PairingCheck([]*G1El{h, sig}, []*G2El{&pkCopy, v.curve.negGeneratorG2()})
+}
@@ -0,0 +1,281 @@
+package bls
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+const (
+	dstMinPk  = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+	dstMinSig = "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_"
+)
+
+type verifyMode int
+
+const (
+	modeVerify verifyMode = iota
+	modeAggregateVerify
+	modeFastAggregateVerify
+)
+
+type minPkCircuit[G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT] struct {
+	Pks  []G1El
+	Msgs [][]uints.U8
+	Sig  G2El
+	mode verifyMode
+}
+
+func (c *minPkCircuit[G1El, G2El, GtEl]) Define(api frontend.API) error {
+	v, err := NewMinPk[G1El, G2El, GtEl](api, []byte(dstMinPk))
+	if err != nil {
+		return err
+	}
+	pks := make([]*G1El, len(c.Pks))
+	for i := range pks {
+		pks[i] = &c.Pks[i]
+	}
+	switch c.mode {
+	case modeVerify:
+		return v.Verify(pks[0], c.Msgs[0], &c.Sig)
+	case modeAggregateVerify:
+		return v.AggregateVerify(pks, c.Msgs, &c.Sig)
+	default:
+		return v.FastAggregateVerify(pks, c.Msgs[0], &c.Sig)
+	}
+}
+
+type minSigCircuit[G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT] struct {
+	Pks  []G2El
+	Msgs [][]uints.U8
+	Sig  G1El
+	mode verifyMode
+}
+
+func (c *minSigCircuit[G1El, G2El, GtEl]) Define(api frontend.API) error {
+	v, err := NewMinSig[G1El, G2El, GtEl](api, []byte(dstMinSig))
+	if err != nil {
+		return err
+	}
+	pks := make([]*G2El, len(c.Pks))
+	for i := range pks {
+		pks[i] = &c.Pks[i]
+	}
+	switch c.mode {
+	case modeVerify:
+		return v.Verify(pks[0], c.Msgs[0], &c.Sig)
+	case modeAggregateVerify:
+		return v.AggregateVerify(pks, c.Msgs, &c.Sig)
+	default:
+		return v.FastAggregateVerify(pks, c.Msgs[0], &c.Sig)
+	}
+}
+
+// testMessages returns the messages to sign for the given mode and number of
+// signers.
+func testMessages(mode verifyMode, n int) [][]byte {
+	switch mode {
+	case modeVerify:
+		return [][]byte{[]byte("hello")}
+	case modeAggregateVerify:
+		msgs := make([][]byte, n)
+		for i := range msgs {
+			msgs[i] = []byte{'m', 's', 'g', byte(i)}
+		}
+		return msgs
+	default:
+		return [][]byte{[]byte("sync committee")}
+	}
+}
+
+func randomSecretKeys(n int, mod *big.Int) []*big.Int {
+	sks := make([]*big.Int, n)
+	for i := range sks {
+		sk, err := rand.Int(rand.Reader, mod)
+		if err != nil {
+			panic(err)
+		}
+		sks[i] = sk
+	}
+	return sks
+}
+
+func msgAssignment(msgs [][]byte) (placeholder, assignment [][]uints.U8) {
+	placeholder = make([][]uints.U8, len(msgs))
+	assignment = make([][]uints.U8, len(msgs))
+	for i := range msgs {
+		placeholder[i] = make([]uints.U8, len(msgs[i]))
+		assignment[i] = uints.NewU8Array(msgs[i])
+	}
+	return placeholder, assignment
+}
+
+func TestMinPkBLS12381(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, _, g1, _ := bls12381.Generators()
+	for _, tc := range []struct {
+		name string
+		mode verifyMode
+		n    int
+	}{
+		{"verify", modeVerify, 1},
+		{"aggregate", modeAggregateVerify, 2},
+		{"fast-aggregate", modeFastAggregateVerify, 3},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			msgs := testMessages(tc.mode, tc.n)
+			sks := randomSecretKeys(tc.n, bls12381.ID.ScalarField())
+			var sig bls12381.G2Affine
+			pks := make([]sw_bls12381.G1Affine, tc.n)
+			for i := range sks {
+				var pk bls12381.G1Affine
+				pk.ScalarMultiplication(&g1, sks[i])
+				pks[i] = sw_bls12381.NewG1Affine(pk)
+				h, err := bls12381.HashToG2(msgs[i%len(msgs)], []byte(dstMinPk))
+				assert.NoError(err)
+				h.ScalarMultiplication(&h, sks[i])
+				sig.Add(&sig, &h)
+			}
+			msgPlaceholder, msgAssignment := msgAssignment(msgs)
+			circuit := minPkCircuit[sw_bls12381.G1Affine, sw_bls12381.G2Affine, sw_bls12381.GTEl]{
+				Pks:  make([]sw_bls12381.G1Affine, tc.n),
+				Msgs: msgPlaceholder,
+				mode: tc.mode,
+			}
+			witness := minPkCircuit[sw_bls12381.G1Affine, sw_bls12381.G2Affine, sw_bls12381.GTEl]{
+				Pks:  pks,
+				Msgs: msgAssignment,
+				Sig:  sw_bls12381.NewG2Affine(sig),
+			}
+			err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+
+			// signature for a different message must not verify
+			msgAssignment[0][0] = uints.NewU8(msgs[0][0] + 1)
+			err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.Error(err)
+		}, tc.name)
+	}
+}
+
+func TestMinSigBLS12381(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, _, _, g2 := bls12381.Generators()
+	for _, tc := range []struct {
+		name string
+		mode verifyMode
+		n    int
+	}{
+		{"verify", modeVerify, 1},
+		{"aggregate", modeAggregateVerify, 2},
+		{"fast-aggregate", modeFastAggregateVerify, 3},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			msgs := testMessages(tc.mode, tc.n)
+			sks := randomSecretKeys(tc.n, bls12381.ID.ScalarField())
+			var sig bls12381.G1Affine
+			pks := make([]sw_bls12381.G2Affine, tc.n)
+			for i := range sks {
+				var pk bls12381.G2Affine
+				pk.ScalarMultiplication(&g2, sks[i])
+				pks[i] = sw_bls12381.NewG2Affine(pk)
+				h, err := bls12381.HashToG1(msgs[i%len(msgs)], []byte(dstMinSig))
+				assert.NoError(err)
+				h.ScalarMultiplication(&h, sks[i])
+				sig.Add(&sig, &h)
+			}
+			msgPlaceholder, msgAssignment := msgAssignment(msgs)
+			circuit := minSigCircuit[sw_bls12381.G1Affine, sw_bls12381.G2Affine, sw_bls12381.GTEl]{
+				Pks:  make([]sw_bls12381.G2Affine, tc.n),
+				Msgs: msgPlaceholder,
+				mode: tc.mode,
+			}
+			witness := minSigCircuit[sw_bls12381.G1Affine, sw_bls12381.G2Affine, sw_bls12381.GTEl]{
+				Pks:  pks,
+				Msgs: msgAssignment,
+				Sig:  sw_bls12381.NewG1Affine(sig),
+			}
+			err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+
+			// signature for a different message must not verify
+			msgAssignment[0][0] = uints.NewU8(msgs[0][0] + 1)
+			err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.Error(err)
+		}, tc.name)
+	}
+}
+
+func TestMinPkBN254(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, _, g1, _ := bn254.Generators()
+	const n = 2
+	msgs := testMessages(modeFastAggregateVerify, n)
+	sks := randomSecretKeys(n, bn254.ID.ScalarField())
+	var sig bn254.G2Affine
+	pks := make([]sw_bn254.G1Affine, n)
+	for i := range sks {
+		var pk bn254.G1Affine
+		pk.ScalarMultiplication(&g1, sks[i])
+		pks[i] = sw_bn254.NewG1Affine(pk)
+		h, err := bn254.HashToG2(msgs[0], []byte(dstMinPk))
+		assert.NoError(err)
+		h.ScalarMultiplication(&h, sks[i])
+		sig.Add(&sig, &h)
+	}
+	msgPlaceholder, msgAssignment := msgAssignment(msgs)
+	circuit := minPkCircuit[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl]{
+		Pks:  make([]sw_bn254.G1Affine, n),
+		Msgs: msgPlaceholder,
+		mode: modeFastAggregateVerify,
+	}
+	witness := minPkCircuit[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl]{
+		Pks:  pks,
+		Msgs: msgAssignment,
+		Sig:  sw_bn254.NewG2Affine(sig),
+	}
+	err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+func TestMinSigBN254(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, _, _, g2 := bn254.Generators()
+	const n = 2
+	msgs := testMessages(modeAggregateVerify, n)
+	sks := randomSecretKeys(n, bn254.ID.ScalarField())
+	var sig bn254.G1Affine
+	pks := make([]sw_bn254.G2Affine, n)
+	for i := range sks {
+		var pk bn254.G2Affine
+		pk.ScalarMultiplication(&g2, sks[i])
+		pks[i] = sw_bn254.NewG2Affine(pk)
+		h, err := bn254.HashToG1(msgs[i], []byte(dstMinSig))
+		assert.NoError(err)
+		h.ScalarMultiplication(&h, sks[i])
+		sig.Add(&sig, &h)
+	}
+	msgPlaceholder, msgAssignment := msgAssignment(msgs)
+	circuit := minSigCircuit[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl]{
+		Pks:  make([]sw_bn254.G2Affine, n),
+		Msgs: msgPlaceholder,
+		mode: modeAggregateVerify,
+	}
+	witness := minSigCircuit[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl]{
+		Pks:  pks,
+		Msgs: msgAssignment,
+		Sig:  sw_bn254.NewG1Affine(sig),
+	}
+	err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
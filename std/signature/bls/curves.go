@@ -0,0 +1,159 @@
+package bls
+
+import (
+	"fmt"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// curve defines the group operations needed for BLS signature verification in
+// addition to the pairing.
+type curve[G1El algebra.G1ElementT, G2El algebra.G2ElementT] interface {
+	// hashToG1 hashes the message to G1 using the domain separation tag dst.
+	hashToG1(msg []uints.U8, dst []byte) (*G1El, error)
+	// hashToG2 hashes the message to G2 using the domain separation tag dst.
+	hashToG2(msg []uints.U8, dst []byte) (*G2El, error)
+	// addG1 adds any two points in G1, where (0,0) is the point at infinity.
+	addG1(p, q *G1El) *G1El
+	// addG2 adds any two points in G2, where (0,0) is the point at infinity.
+	addG2(p, q *G2El) *G2El
+	// negGeneratorG1 returns the negated generator of G1.
+	negGeneratorG1() *G1El
+	// negGeneratorG2 returns the negated generator of G2, possibly with
+	// precomputed lines.
+	negGeneratorG2() *G2El
+}
+
+// getCurve returns the [curve] implementation corresponding to the groups type
+// parameters.
+func getCurve[G1El algebra.G1ElementT, G2El algebra.G2ElementT](api frontend.API) (curve[G1El, G2El], error) {
+	var ret curve[G1El, G2El]
+	switch s := any(&ret).(type) {
+	case *curve[sw_bls12381.G1Affine, sw_bls12381.G2Affine]:
+		c, err := newCurveBLS12381(api)
+		if err != nil {
+			return ret, err
+		}
+		*s = c
+	case *curve[sw_bn254.G1Affine, sw_bn254.G2Affine]:
+		c, err := newCurveBN254(api)
+		if err != nil {
+			return ret, err
+		}
+		*s = c
+	default:
+		return ret, fmt.Errorf("unknown type parametrisation")
+	}
+	return ret, nil
+}
+
+type curveBLS12381 struct {
+	g1    *sw_bls12381.G1
+	g2    *sw_bls12381.G2
+	curve *sw_emulated.Curve[sw_bls12381.BaseField, sw_bls12381.ScalarField]
+}
+
+func newCurveBLS12381(api frontend.API) (*curveBLS12381, error) {
+	g1, err := sw_bls12381.NewG1(api)
+	if err != nil {
+		return nil, fmt.Errorf("new G1: %w", err)
+	}
+	c, err := sw_emulated.New[sw_bls12381.BaseField, sw_bls12381.ScalarField](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		return nil, fmt.Errorf("new curve: %w", err)
+	}
+	return &curveBLS12381{
+		g1:    g1,
+		g2:    sw_bls12381.NewG2(api),
+		curve: c,
+	}, nil
+}
+
+func (c *curveBLS12381) hashToG1(msg []uints.U8, dst []byte) (*sw_bls12381.G1Affine, error) {
+	return c.g1.HashToG1(msg, dst)
+}
+
+func (c *curveBLS12381) hashToG2(msg []uints.U8, dst []byte) (*sw_bls12381.G2Affine, error) {
+	return c.g2.HashToG2(msg, dst)
+}
+
+func (c *curveBLS12381) addG1(p, q *sw_bls12381.G1Affine) *sw_bls12381.G1Affine {
+	return c.curve.AddUnified(p, q)
+}
+
+func (c *curveBLS12381) addG2(p, q *sw_bls12381.G2Affine) *sw_bls12381.G2Affine {
+	return c.g2.AddUnified(p, q)
+}
+
+func (c *curveBLS12381) negGeneratorG1() *sw_bls12381.G1Affine {
+	_, _, g1, _ := bls12381.Generators()
+	g1.Neg(&g1)
+	res := sw_bls12381.NewG1Affine(g1)
+	return &res
+}
+
+func (c *curveBLS12381) negGeneratorG2() *sw_bls12381.G2Affine {
+	_, _, _, g2 := bls12381.Generators()
+	g2.Neg(&g2)
+	res := sw_bls12381.NewG2AffineFixed(g2)
+	return &res
+}
+
+type curveBN254 struct {
+	g1    *sw_bn254.G1
+	g2    *sw_bn254.G2
+	curve *sw_emulated.Curve[sw_bn254.BaseField, sw_bn254.ScalarField]
+}
+
+func newCurveBN254(api frontend.API) (*curveBN254, error) {
+	g1, err := sw_bn254.NewG1(api)
+	if err != nil {
+		return nil, fmt.Errorf("new G1: %w", err)
+	}
+	c, err := sw_emulated.New[sw_bn254.BaseField, sw_bn254.ScalarField](api, sw_emulated.GetBN254Params())
+	if err != nil {
+		return nil, fmt.Errorf("new curve: %w", err)
+	}
+	return &curveBN254{
+		g1:    g1,
+		g2:    sw_bn254.NewG2(api),
+		curve: c,
+	}, nil
+}
+
+func (c *curveBN254) hashToG1(msg []uints.U8, dst []byte) (*sw_bn254.G1Affine, error) {
+	return c.g1.HashToG1(msg, dst)
+}
+
+func (c *curveBN254) hashToG2(msg []uints.U8, dst []byte) (*sw_bn254.G2Affine, error) {
+	return c.g2.HashToG2(msg, dst)
+}
+
+func (c *curveBN254) addG1(p, q *sw_bn254.G1Affine) *sw_bn254.G1Affine {
+	return c.curve.AddUnified(p, q)
+}
+
+func (c *curveBN254) addG2(p, q *sw_bn254.G2Affine) *sw_bn254.G2Affine {
+	return c.g2.AddUnified(p, q)
+}
+
+func (c *curveBN254) negGeneratorG1() *sw_bn254.G1Affine {
+	_, _, g1, _ := bn254.Generators()
+	g1.Neg(&g1)
+	res := sw_bn254.NewG1Affine(g1)
+	return &res
+}
+
+func (c *curveBN254) negGeneratorG2() *sw_bn254.G2Affine {
+	_, _, _, g2 := bn254.Generators()
+	g2.Neg(&g2)
+	res := sw_bn254.NewG2AffineFixed(g2)
+	return &res
+}
@@ -0,0 +1,24 @@
+// Package bls implements BLS signature verification over pairing-friendly
+// curves.
+//
+// The package follows the [BLS signature draft]. It depends on the emulated
+// pairings [sw_bls12381.Pairing] and [sw_bn254.Pairing] and on the in-circuit
+// hash-to-curve of the corresponding group gadgets, so that signatures created
+// by standard implementations verify in-circuit. Both variants of the scheme
+// are supported:
+//   - [MinPk] has public keys in G1 and signatures in G2. It is the variant
+//     used in the Ethereum consensus layer.
+//   - [MinSig] has public keys in G2 and signatures in G1.
+//
+// For every variant we implement the verification of a single signature
+// (Verify), of an aggregate signature over distinct messages (AggregateVerify)
+// and of an aggregate signature over the same message (FastAggregateVerify).
+// The latter needs only two Miller loops regardless of the number of signers,
+// which makes it suitable for proving the Ethereum sync-committee signatures.
+//
+// The domain separation tag is fixed at circuit compile time. For example, the
+// Ethereum consensus layer uses the proof of possession scheme with the tag
+// BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_.
+//
+// [BLS signature draft]: https://datatracker.ietf.org/doc/html/draft-irtf-cfrg-bls-signature-05
+package bls
@@ -0,0 +1,160 @@
+// Package ed25519 implements Ed25519 signature verification.
+//
+// The package depends on the [emulated/te_emulated] package for twisted
+// Edwards group operations using non-native arithmetic and on the in-circuit
+// SHA-512 hash function [sha2.New512] for computing the challenge. Thus we can
+// verify Ed25519 signatures over any native field.
+//
+// The public key and the signature are given in their standard 32- and 64-byte
+// encodings as defined in [RFC 8032] and the points are decompressed in-circuit.
+// The verification is cofactorless, i.e. it checks that
+//
+//	[S]B = R + [k]A
+//
+// which corresponds to the behaviour of the Go standard library
+// [crypto/ed25519.Verify].
+//
+// [RFC 8032]: https://www.rfc-editor.org/rfc/rfc8032
+package ed25519
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/te_emulated"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/emulated/emparams"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+type (
+	// BaseField is the base field of the edwards25519 curve.
+	BaseField = emparams.Curve25519Fp
+	// ScalarField is the scalar field of the prime order subgroup of the
+	// edwards25519 curve.
+	ScalarField = emparams.Curve25519Fr
+)
+
+// PublicKey represents the encoded public key A to verify the signature for.
+type PublicKey struct {
+	A [ed25519.PublicKeySize]uints.U8
+}
+
+// Signature represents the encoded signature (R, S) for some message.
+type Signature struct {
+	R [32]uints.U8
+	S [32]uints.U8
+}
+
+// ValueOfPublicKey returns the witness assignment of the public key pk.
+func ValueOfPublicKey(pk ed25519.PublicKey) PublicKey {
+	var res PublicKey
+	copy(res.A[:], uints.NewU8Array(pk))
+	return res
+}
+
+// ValueOfSignature returns the witness assignment of the encoded signature
+// sig. It panics if sig is not [ed25519.SignatureSize] bytes long.
+func ValueOfSignature(sig []byte) Signature {
+	if len(sig) != ed25519.SignatureSize {
+		panic(fmt.Sprintf("invalid signature length %d", len(sig)))
+	}
+	var res Signature
+	copy(res.R[:], uints.NewU8Array(sig[:32]))
+	copy(res.S[:], uints.NewU8Array(sig[32:]))
+	return res
+}
+
+// Verify asserts that the signature sig verifies for the message msg and public
+// key pk.
+//
+// The encoded points A and R must be canonical encodings of points on the
+// curve and the encoded scalar S must be canonical.
+func (pk PublicKey) Verify(api frontend.API, msg []uints.U8, sig *Signature) {
+	cr, err := te_emulated.New[BaseField, ScalarField](api, te_emulated.GetEd25519Params())
+	if err != nil {
+		panic(err)
+	}
+	baseApi, err := emulated.NewField[BaseField](api)
+	if err != nil {
+		panic(err)
+	}
+	scalarApi, err := emulated.NewField[ScalarField](api)
+	if err != nil {
+		panic(err)
+	}
+	h, err := sha2.New512(api)
+	if err != nil {
+		panic(err)
+	}
+
+	a := decodePoint(api, baseApi, cr.Params(), pk.A[:])
+	r := decodePoint(api, baseApi, cr.Params(), sig.R[:])
+
+	sBits := bytesToBits(api, sig.S[:])
+	s := scalarApi.FromBits(sBits...)
+	scalarApi.AssertIsInRange(s)
+
+	// k = SHA-512(R || A || M) interpreted as a little-endian integer.
+	h.Write(sig.R[:])
+	h.Write(pk.A[:])
+	h.Write(msg)
+	kBits := bytesToBits(api, h.Sum())
+	var fr ScalarField
+	shift := new(big.Int).Lsh(big.NewInt(1), 256)
+	shift.Mod(shift, fr.Modulus())
+	kLo := scalarApi.FromBits(kBits[:256]...)
+	kHi := scalarApi.FromBits(kBits[256:]...)
+	k := scalarApi.Add(kLo, scalarApi.Mul(kHi, scalarApi.NewElement(shift)))
+
+	// [S]B - [k]A == R
+	lhs := cr.DoubleBaseScalarMul(cr.Generator(), cr.Neg(a), s, k)
+	cr.AssertIsEqual(lhs, r)
+}
+
+// decodePoint decodes the 32-byte encoding of a point as defined in RFC 8032
+// Section 5.1.3 and returns it. The decoded point is on the curve by
+// construction.
+func decodePoint(api frontend.API, baseApi *emulated.Field[BaseField], params te_emulated.CurveParams, enc []uints.U8) *te_emulated.AffinePoint[BaseField] {
+	encBits := bytesToBits(api, enc)
+	y := baseApi.FromBits(encBits[:255]...)
+	baseApi.AssertIsInRange(y)
+	sign := encBits[255]
+
+	// x² = (1 - y²) / (a - dy²)
+	one := baseApi.One()
+	yy := baseApi.Mul(y, y)
+	num := baseApi.Sub(one, yy)
+	den := baseApi.Sub(baseApi.NewElement(params.A), baseApi.Mul(baseApi.NewElement(params.D), yy))
+	xx := baseApi.Div(num, den)
+	x := baseApi.Sqrt(xx)
+
+	// choose the root with the least significant bit equal to sign. When x = 0
+	// and sign = 1, the encoding is invalid and the assertion fails.
+	x = baseApi.Reduce(x)
+	baseApi.AssertIsInRange(x)
+	lsb := baseApi.ToBits(x)[0]
+	x = baseApi.Select(api.Xor(lsb, sign), baseApi.Neg(x), x)
+	x = baseApi.Reduce(x)
+	baseApi.AssertIsInRange(x)
+	api.AssertIsEqual(baseApi.ToBits(x)[0], sign)
+
+	return &te_emulated.AffinePoint[BaseField]{
+		X: *x,
+		Y: *y,
+	}
+}
+
+// bytesToBits returns the little-endian bit decomposition of the little-endian
+// encoded bytes.
+func bytesToBits(api frontend.API, in []uints.U8) []frontend.Variable {
+	res := make([]frontend.Variable, 0, 8*len(in))
+	for i := range in {
+		res = append(res, bits.ToBinary(api, in[i].Val, bits.WithNbDigits(8))...)
+	}
+	return res
+}
@@ -0,0 +1,162 @@
+package ed25519
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+type ed25519Circuit struct {
+	Msg []uints.U8
+	Pub PublicKey
+	Sig Signature
+}
+
+func (c *ed25519Circuit) Define(api frontend.API) error {
+	c.Pub.Verify(api, c.Msg, &c.Sig)
+	return nil
+}
+
+func newEd25519Assignment(pub ed25519.PublicKey, msg, sig []byte) (*ed25519Circuit, *ed25519Circuit) {
+	circuit := ed25519Circuit{
+		Msg: make([]uints.U8, len(msg)),
+	}
+	witness := ed25519Circuit{
+		Msg: uints.NewU8Array(msg),
+		Pub: ValueOfPublicKey(pub),
+		Sig: ValueOfSignature(sig),
+	}
+	return &circuit, &witness
+}
+
+func TestEd25519(t *testing.T) {
+	assert := test.NewAssert(t)
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(err)
+	msg := []byte("testing Ed25519 verification in a circuit")
+	sig := ed25519.Sign(priv, msg)
+
+	circuit, witness := newEd25519Assignment(pub, msg, sig)
+	err = test.IsSolved(circuit, witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// tampered message
+	tampered := make([]byte, len(msg))
+	copy(tampered, msg)
+	tampered[0] ^= 1
+	witness.Msg = uints.NewU8Array(tampered)
+	err = test.IsSolved(circuit, witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
+
+// TestEd25519Vectors checks the test vectors of RFC 8032 Section 7.1.
+func TestEd25519Vectors(t *testing.T) {
+	assert := test.NewAssert(t)
+	vectors := []struct {
+		name, secret, public, msg, sig string
+	}{
+		{
+			name:   "TEST 1",
+			secret: "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+			public: "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			msg:    "",
+			sig:    "e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b",
+		},
+		{
+			name:   "TEST 2",
+			secret: "4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+			public: "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+			msg:    "72",
+			sig:    "92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00",
+		},
+		{
+			name:   "TEST 3",
+			secret: "c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7",
+			public: "fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025",
+			msg:    "af82",
+			sig:    "6291d657deec24024827e69c3abe01a30ce548a284743a445e3680d7db5ac3ac18ff9b538d16f290ae67f760984dc6594a7c15e9716ed28dc027beceea1ec40a",
+		},
+	}
+	decode := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		assert.NoError(err)
+		return b
+	}
+	for _, v := range vectors {
+		secret, pub, msg, sig := decode(v.secret), ed25519.PublicKey(decode(v.public)), decode(v.msg), decode(v.sig)
+		// the vectors are consistent with the standard library
+		assert.Equal(pub, ed25519.NewKeyFromSeed(secret).Public(), v.name)
+		assert.Equal(sig, ed25519.Sign(ed25519.NewKeyFromSeed(secret), msg), v.name)
+
+		circuit, witness := newEd25519Assignment(pub, msg, sig)
+		err := test.IsSolved(circuit, witness, ecc.BN254.ScalarField())
+		assert.NoError(err, v.name)
+	}
+}
+
+// TestEd25519Invalid checks that the malleable and invalid encodings are
+// rejected.
+func TestEd25519Invalid(t *testing.T) {
+	assert := test.NewAssert(t)
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(err)
+	msg := []byte("testing invalid Ed25519 signatures")
+	sig := ed25519.Sign(priv, msg)
+
+	// S+L verifies the equation [S]B = R + [k]A but is not canonical
+	var fr ScalarField
+	s := new(big.Int).SetBytes(reverse(sig[32:]))
+	s.Add(s, fr.Modulus())
+	nonCanonical := append(append([]byte{}, sig[:32]...), reverse(s.FillBytes(make([]byte, 32)))...)
+	circuit, witness := newEd25519Assignment(pub, msg, nonCanonical)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()), "S ≥ L")
+
+	// R and A not on the curve
+	notOnCurve := notOnCurveEncoding()
+	invalidR := append(append([]byte{}, notOnCurve...), sig[32:]...)
+	circuit, witness = newEd25519Assignment(pub, msg, invalidR)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()), "R not on the curve")
+	circuit, witness = newEd25519Assignment(notOnCurve, msg, sig)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()), "A not on the curve")
+
+	// wrong message
+	circuit, witness = newEd25519Assignment(pub, []byte("testing invalid Ed25519 signaturez"), sig)
+	assert.Error(test.IsSolved(circuit, witness, ecc.BN254.ScalarField()), "wrong message")
+}
+
+// notOnCurveEncoding returns the encoding of a y coordinate for which there
+// is no x such that (x, y) is on the curve.
+func notOnCurveEncoding() []byte {
+	var fp BaseField
+	p := fp.Modulus()
+	// d = -121665/121666
+	d := new(big.Int).ModInverse(big.NewInt(121666), p)
+	d.Mul(d, big.NewInt(-121665)).Mod(d, p)
+	for y := int64(2); ; y++ {
+		// x² = (1 - y²) / (-1 - dy²)
+		yy := big.NewInt(y * y)
+		num := new(big.Int).Sub(big.NewInt(1), yy)
+		den := new(big.Int).Mul(d, yy)
+		den.Add(den, big.NewInt(1)).Neg(den).Mod(den, p)
+		xx := new(big.Int).Mul(num, den.ModInverse(den, p))
+		xx.Mod(xx, p)
+		if big.Jacobi(xx, p) == -1 {
+			return reverse(big.NewInt(y).FillBytes(make([]byte, 32)))
+		}
+	}
+}
+
+func reverse(b []byte) []byte {
+	res := make([]byte, len(b))
+	for i := range b {
+		res[len(b)-1-i] = b[i]
+	}
+	return res
+}
@@ -131,7 +131,7 @@ func (assert *Assert) compile(circuit frontend.Circuit, curveID ecc.ID, backendI
 	switch backendID {
 	case backend.GROTH16:
 		newBuilder = r1cs.NewBuilder
-	case backend.PLONK:
+	case backend.PLONK, backend.PLONKFRI:
 		newBuilder = scs.NewBuilder
 	default:
 		panic("not implemented")
@@ -7,6 +7,7 @@ import (
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/plonkfri"
 	"github.com/consensys/gnark/backend/solidity"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint"
@@ -110,6 +111,13 @@ func (assert *Assert) CheckCircuit(circuit frontend.Circuit, opts ...TestingOpti
 						concreteBackend = _groth16
 					case backend.PLONK:
 						concreteBackend = _plonk
+					case backend.PLONKFRI:
+						// PLONK-FRI doesn't implement the commitments, the
+						// custom gates and the wide constraints
+						if unsupported := plonkfriUnsupported(ccs); unsupported != "" {
+							assert.t.Skipf("PLONK-FRI doesn't support %s", unsupported)
+						}
+						concreteBackend = _plonkfri
 					default:
 						panic("backend not implemented")
 					}
@@ -148,7 +156,9 @@ func (assert *Assert) CheckCircuit(circuit frontend.Circuit, opts ...TestingOpti
 							}
 
 							// check proof serialization
-							assert.roundTripCheck(proof, proofBuilder, "proof")
+							if proofBuilder != nil {
+								assert.roundTripCheck(proof, proofBuilder, "proof")
+							}
 						}, "valid_witness")
 					}
 
@@ -162,7 +172,7 @@ func (assert *Assert) CheckCircuit(circuit frontend.Circuit, opts ...TestingOpti
 					}
 
 					// check serialization of proving and verifying keys
-					if opt.checkSerialization && ccs.GetNbConstraints() <= serializationThreshold && (curve == ecc.BN254 || curve == ecc.BLS12_381) {
+					if opt.checkSerialization && pkBuilder != nil && ccs.GetNbConstraints() <= serializationThreshold && (curve == ecc.BN254 || curve == ecc.BLS12_381) {
 						assert.roundTripCheck(pk, pkBuilder, "proving_key")
 						assert.roundTripCheck(vk, vkBuilder, "verifying_key")
 					}
@@ -280,4 +290,43 @@ var (
 			return plonk.Verify(proof.(plonk.Proof), vk.(plonk.VerifyingKey), publicWitness, opts...)
 		},
 	}
+
+	_plonkfri = tBackend{
+		setup: func(ccs constraint.ConstraintSystem, curve ecc.ID) (
+			pk, vk any,
+			pkBuilder, vkBuilder, proofBuilder func() any,
+			err error) {
+			// PLONK-FRI keys and proofs are not serializable, we return nil
+			// builders to skip the round trip checks.
+			pk, vk, err = plonkfri.Setup(ccs)
+			return pk, vk, nil, nil, nil, err
+		},
+		prove: func(ccs constraint.ConstraintSystem, pk any, fullWitness witness.Witness, opts ...backend.ProverOption) (proof any, err error) {
+			return plonkfri.Prove(ccs, pk.(plonkfri.ProvingKey), fullWitness, opts...)
+		},
+		verify: func(proof, vk any, publicWitness witness.Witness, opts ...backend.VerifierOption) error {
+			return plonkfri.Verify(proof.(plonkfri.Proof), vk.(plonkfri.VerifyingKey), publicWitness, opts...)
+		},
+	}
 )
+
+// plonkfriUnsupported returns the feature of the constraint system which is
+// not supported by PLONK-FRI, if any.
+func plonkfriUnsupported(ccs constraint.ConstraintSystem) string {
+	if len(ccs.GetCommitments().CommitmentIndexes()) > 0 {
+		return "commitments"
+	}
+	spr, ok := ccs.(interface {
+		GetNbWires() int
+		GetCustomGates() []constraint.CustomGate
+	})
+	switch {
+	case !ok:
+		return ""
+	case len(spr.GetCustomGates()) > 0:
+		return "custom gates"
+	case spr.GetNbWires() > 3:
+		return "constraints with more than 3 wires"
+	}
+	return ""
+}
@@ -26,20 +26,22 @@ var testEngineChecks = profile{
 	curves:   []ecc.ID{ecc.BN254, ecc.BLS12_381},
 }
 
+// PLONK-FRI compiles the same SparseR1CS as PLONK, solving it again would not
+// check more; it is run by the prover checks.
 var constraintSolverChecks = profile{
 	backends: []backend.ID{backend.GROTH16, backend.PLONK},
 	curves:   []ecc.ID{ecc.BN254, ecc.BLS12_381},
 }
 
 var proverChecks = profile{
-	backends:      []backend.ID{backend.GROTH16, backend.PLONK},
+	backends:      []backend.ID{backend.GROTH16, backend.PLONK, backend.PLONKFRI},
 	curves:        []ecc.ID{ecc.BN254, ecc.BLS12_381, ecc.BW6_761},
 	checkSolidity: true && SolcCheck,
 	checkProver:   true,
 }
 
 var releaseChecks = profile{
-	backends:           []backend.ID{backend.GROTH16, backend.PLONK},
+	backends:           []backend.ID{backend.GROTH16, backend.PLONK, backend.PLONKFRI},
 	curves:             []ecc.ID{ecc.BN254, ecc.BLS12_381, ecc.BW6_761, ecc.BLS12_377},
 	checkSolidity:      true && SolcCheck,
 	checkProver:        true,
@@ -59,6 +59,7 @@ type engine struct {
 	kvstore.Store
 	blueprints        []constraint.Blueprint
 	internalVariables []*big.Int
+	customGates       [][]frontend.GateTerm
 }
 
 // TestEngineOption defines an option for the test engine.
@@ -692,6 +693,60 @@ func (e *engine) Commit(v ...frontend.Variable) (frontend.Variable, error) {
 	return res, nil
 }
 
+func (e *engine) DefineCustomGate(name string, terms ...frontend.GateTerm) (int, error) {
+	if len(terms) == 0 {
+		return 0, fmt.Errorf("custom gate %q has no term", name)
+	}
+	for _, t := range terms {
+		if d := int(t.A) + int(t.B) + int(t.C); d > constraint.MaxCustomGateDegree {
+			return 0, fmt.Errorf("custom gate %q has degree %d, the maximal degree is %d", name, d, constraint.MaxCustomGateDegree)
+		}
+	}
+	e.customGates = append(e.customGates, terms)
+	return len(e.customGates) - 1, nil
+}
+
+func (e *engine) EvaluateCustomGate(gate int, a, b frontend.Variable, qG int) frontend.Variable {
+	for _, t := range e.customGates[gate] {
+		if t.C != 0 {
+			panic(fmt.Sprintf("custom gate %d depends on o", gate))
+		}
+	}
+	return e.Mul(e.evaluateCustomGate(gate, a, b, 0), qG)
+}
+
+func (e *engine) AddCustomGateConstraint(gate int, a, b, o frontend.Variable, qG, qL, qR, qO, qM, qC int) {
+	e.AssertIsEqual(
+		e.Add(
+			e.Mul(e.evaluateCustomGate(gate, a, b, o), qG),
+			e.Mul(a, qL),
+			e.Mul(b, qR),
+			e.Mul(a, b, qM),
+			e.Mul(o, qO),
+			qC,
+		),
+		0,
+	)
+}
+
+// evaluateCustomGate returns gate(a, b, o) = ∑ Coeff.aᴬ.bᴮ.oᶜ.
+func (e *engine) evaluateCustomGate(gate int, a, b, o frontend.Variable) frontend.Variable {
+	res := new(big.Int)
+	for _, t := range e.customGates[gate] {
+		m := e.toBigInt(t.Coeff)
+		for _, f := range [...]struct {
+			v frontend.Variable
+			n uint8
+		}{{a, t.A}, {b, t.B}, {o, t.C}} {
+			for j := uint8(0); j < f.n; j++ {
+				m = e.Mul(m, f.v).(*big.Int)
+			}
+		}
+		res = e.Add(res, m).(*big.Int)
+	}
+	return res
+}
+
 func (e *engine) Defer(cb func(frontend.API) error) {
 	circuitdefer.Put(e, cb)
 }